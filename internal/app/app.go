@@ -2,14 +2,20 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"github.com/Ramcache/travel-backend/internal/helpers"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 
 	"github.com/Ramcache/travel-backend/internal/config"
+	"github.com/Ramcache/travel-backend/internal/documents"
 	"github.com/Ramcache/travel-backend/internal/handlers"
+	"github.com/Ramcache/travel-backend/internal/notifications"
+	"github.com/Ramcache/travel-backend/internal/payments"
+	"github.com/Ramcache/travel-backend/internal/phone"
 	"github.com/Ramcache/travel-backend/internal/repository"
 	"github.com/Ramcache/travel-backend/internal/services"
 )
@@ -32,6 +38,12 @@ type App struct {
 	reviewsRepo      *repository.ReviewRepo
 	tripRouteRepo    *repository.TripRouteRepository
 	cloudflareRepo   *repository.CloudflareRepository
+	travellerRepo    *repository.TravellerProfileRepo
+	auditRepo        *repository.AuditRepo
+	paymentRepo      *repository.PaymentRepo
+	scheduleRepo     *repository.PaymentScheduleRepo
+	documentRepo     *repository.DocumentTemplateRepo
+	customerRepo     *repository.CustomerRepo
 
 	// services
 	AuthService         *services.AuthService
@@ -48,6 +60,17 @@ type App struct {
 	tripPageService     *services.TripPageService
 	tripRouteService    *services.TripRouteService
 	cloudflareService   *services.CloudflareService
+	travellerService    *services.TravellerProfileService
+	paymentService      *services.PaymentService
+	documentService     *services.DocumentService
+	customerService     *services.CustomerService
+
+	OrderCRMService *services.OrderCRMService
+
+	PaymentScheduleService *services.PaymentScheduleService
+
+	NotificationService *services.NotificationService
+	TelegramBotService  *services.TelegramBotService
 
 	// handlers
 	AuthHandler         *handlers.AuthHandler
@@ -68,6 +91,27 @@ type App struct {
 	DateHandler         *handlers.DateHandler
 	MediaHandler        *handlers.MediaHandler
 	CloudflareHandler   *handlers.CloudflareHandler
+
+	TravellerProfileHandler *handlers.TravellerProfileHandler
+	AuditHandler            *handlers.AuditHandler
+	PaymentHandler          *handlers.PaymentHandler
+	PaymentScheduleHandler  *handlers.PaymentScheduleHandler
+	DocumentHandler         *handlers.DocumentHandler
+	OrderCRMHandler         *handlers.OrderCRMHandler
+	CustomerHandler         *handlers.CustomerHandler
+	MyOrderHandler          *handlers.MyOrderHandler
+	CancellationHandler     *handlers.CancellationHandler
+	NotificationHandler     *handlers.NotificationHandler
+
+	NotificationTemplateHandler *handlers.NotificationTemplateHandler
+	TelegramHandler             *handlers.TelegramHandler
+	NotificationRoutingHandler  *handlers.NotificationRoutingHandler
+	ReportService               *services.ReportService
+	ReportHandler               *handlers.ReportHandler
+	PasswordHandler             *handlers.PasswordHandler
+	VerificationHandler         *handlers.VerificationHandler
+	RoleHandler                 *handlers.RoleHandler
+	LoginLockHandler            *handlers.LoginLockHandler
 }
 
 func New(ctx context.Context, cfg *config.Config, pool *pgxpool.Pool, log *zap.SugaredLogger) *App {
@@ -84,19 +128,119 @@ func New(ctx context.Context, cfg *config.Config, pool *pgxpool.Pool, log *zap.S
 	reviewsRepo := repository.NewReviewRepo(pool)
 	tripRouteRepo := repository.NewTripRouteRepository(pool)
 	cloudflareRepo := repository.NewCloudflareRepository(cfg.Cloudflare.APIToken)
+	travellerRepo := repository.NewTravellerProfileRepo(pool)
+	auditRepo := repository.NewAuditRepo(pool)
+	paymentRepo := repository.NewPaymentRepo(pool)
+	scheduleRepo := repository.NewPaymentScheduleRepo(pool)
+	documentRepo := repository.NewDocumentTemplateRepo(pool)
+	customerRepo := repository.NewCustomerRepo(pool)
+	cancellationRepo := repository.NewCancellationRepo(pool)
+	notificationRepo := repository.NewNotificationRepo(pool)
+	notificationTemplateRepo := repository.NewNotificationTemplateRepo(pool)
+	notificationRoutingRepo := repository.NewNotificationRoutingRepo(pool)
+	reportRepo := repository.NewReportRepo(pool)
+	sessionRepo := repository.NewSessionRepo(pool)
+	passwordResetRepo := repository.NewPasswordResetRepo(pool)
+	verificationRepo := repository.NewVerificationRepo(pool)
+	roleRepo := repository.NewRoleRepo(pool)
+	loginThrottleRepo := repository.NewLoginThrottleRepo(pool)
 
 	// helpers
 	telegramClient := helpers.NewTelegramClient(cfg.TG.TelegramToken, cfg.TG.TelegramChat)
 
+	// без ключа шифрования профили туристов недоступны (сервис вернёт ErrEncryptionUnavailable)
+	cipher, err := helpers.NewCipher(cfg.EncryptionKey)
+	if err != nil {
+		log.Warnw("encryption disabled: traveller passport profiles are unavailable", "err", err)
+	}
+
+	paymentProvider, err := newPaymentProvider(cfg.Payments, cfg.AppEnv, log)
+	if err != nil {
+		log.Fatalw("payment provider is not configured", "err", err)
+	}
+	paymentReturnURL := cfg.Payments.ReturnURL
+	if paymentReturnURL == "" {
+		paymentReturnURL = cfg.FrontendURL
+	}
+
+	renderer := documents.NewRenderer(cfg.Documents.FontPath, cfg.Documents.FontBoldPath)
+	if !renderer.Available() {
+		log.Warnw("document font not found: PDF documents are unavailable", "path", cfg.Documents.FontPath)
+	}
+	company := documents.Company{
+		Name:    cfg.Documents.CompanyName,
+		Details: strings.ReplaceAll(cfg.Documents.CompanyDetails, `\n`, "\n"),
+	}
+
+	if err := phone.SetDefaultCountry(cfg.PhoneDefaultCountry); err != nil {
+		log.Warnw("unknown PHONE_DEFAULT_COUNTRY, falling back to RU", "country", cfg.PhoneDefaultCountry)
+	}
+
+	trackingURL := cfg.OrderTrackingURL
+	if trackingURL == "" {
+		trackingURL = strings.TrimRight(cfg.AppBaseURL, "/") + "/api/v1/orders/track/"
+	}
+
+	notificationLinks := notifications.Links{
+		TrackingURL: trackingURL,
+		FrontendURL: cfg.FrontendURL,
+		AdminURL:    cfg.Notifications.AdminURL,
+	}
+	notificationTemplateService := services.NewNotificationTemplateService(notificationTemplateRepo, notificationLinks, cfg.Notifications.Locale, log)
+	notificationRenderer := notifications.NewTemplateRenderer(notificationTemplateService, notifications.NewDefaultRenderer(notificationLinks), notificationLinks, cfg.Notifications.Locale, log)
+	telegramBot := notifications.NewTelegram(notifications.TelegramConfig{
+		Token:  cfg.TG.TelegramToken,
+		ChatID: cfg.TG.TelegramChat,
+	})
+	notificationRoutingService := services.NewNotificationRoutingService(notificationRoutingRepo, log)
+	notifier := newNotifier(cfg, telegramBot, notificationRenderer, log).UseRules(notificationRoutingService)
+	notificationService := services.NewNotificationService(notificationRepo, notifier, services.OutboxConfig{
+		MaxAttempts: cfg.Notifications.MaxAttempts,
+		RetryBase:   cfg.Notifications.RetryBase,
+	}, log)
+
 	// services
-	authService := services.NewAuthService(userRepo, cfg.JWTSecret, cfg.JWTTTL, log)
+	verificationService := services.NewVerificationService(userRepo, verificationRepo, orderRepo, notificationService, services.VerificationConfig{
+		CodeTTL:     cfg.Verification.CodeTTL,
+		MaxAttempts: cfg.Verification.MaxAttempts,
+	}, log)
+	loginGuardService := services.NewLoginGuardService(loginThrottleRepo, notificationService, services.LoginGuardConfig{
+		MaxFailures:      cfg.LoginGuard.MaxFailures,
+		MaxFailuresPerIP: cfg.LoginGuard.MaxFailuresPerIP,
+		Lockout:          cfg.LoginGuard.Lockout,
+	}, log)
+	authService := services.NewAuthService(userRepo, orderRepo, sessionRepo, services.TokenConfig{
+		Secret:     cfg.JWTSecret,
+		AccessTTL:  cfg.JWTTTL,
+		RefreshTTL: cfg.RefreshTTL,
+	}, log).
+		UseVerification(verificationService, cfg.Verification.RequireVerifiedEmail).
+		UseLoginGuard(loginGuardService)
 	currencyService := services.NewCurrencyService(5*time.Minute, log)
-	tripService := services.NewTripService(tripRepo, orderRepo, hotelRepo, tripRouteRepo, telegramClient, cfg.FrontendURL, log)
+	travellerService := services.NewTravellerProfileService(travellerRepo, auditRepo, cipher, log)
+	scheduleService := services.NewPaymentScheduleService(scheduleRepo, orderRepo, tripRepo, notificationService, cfg.Payments.ReminderLead, log)
+	digestConfig := newDigestConfig(cfg.Digest, log)
+	tripService := services.NewTripService(tripRepo, orderRepo, hotelRepo, tripRouteRepo, travellerService, scheduleService, notificationService, trackingURL, log).
+		UseViewsLocation(digestConfig.Location).
+		UseTelegramWebApp(cfg.TG.TelegramToken)
 	newsService := services.NewNewsService(newsRepo, newsCategoryRepo, log)
 	newsCategoryService := services.NewNewsCategoryService(newsCategoryRepo, log)
 	statsService := services.NewStatsService(statsRepo)
-	orderService := services.NewOrderService(orderRepo)
-	feedbackService := services.NewFeedbackService(feedbackRepo, telegramClient, log)
+	orderService := services.NewOrderService(orderRepo, notificationService)
+	orderCRMService := services.NewOrderCRMService(orderRepo, notificationService, log)
+	customerService := services.NewCustomerService(customerRepo, log)
+	paymentService := services.NewPaymentService(paymentRepo, orderService, scheduleService, paymentProvider, paymentReturnURL, log)
+	documentService := services.NewDocumentService(orderRepo, tripRepo, hotelRepo, tripRouteRepo, scheduleRepo, documentRepo, renderer, company, telegramClient, log)
+	myOrderService := services.NewMyOrderService(orderRepo, tripRepo, paymentRepo, scheduleService, documentService, log)
+	cancellationService := services.NewCancellationService(cancellationRepo, orderRepo, orderService, tripRepo, paymentRepo, paymentService, notificationService, log)
+	telegramBotService := services.NewTelegramBotService(userRepo, orderService, orderCRMService, orderRepo, tripRepo, feedbackRepo, statsRepo, telegramBot, notificationRenderer, log)
+	reportService := services.NewReportService(reportRepo, notificationService, digestConfig, log)
+	passwordService := services.NewPasswordService(userRepo, passwordResetRepo, sessionRepo, notificationService, services.PasswordResetConfig{
+		URL: passwordResetURL(cfg),
+		TTL: cfg.PasswordReset.TTL,
+	}, log)
+	roleService := services.NewRoleService(roleRepo, log)
+	feedbackService := services.NewFeedbackService(feedbackRepo, notificationService, log)
 	hotelService := services.NewHotelService(hotelRepo)
 	searchService := services.NewSearchService(searchRepo, cfg.FrontendURL)
 	reviewsService := services.NewReviewService(reviewsRepo, log)
@@ -114,7 +258,7 @@ func New(ctx context.Context, cfg *config.Config, pool *pgxpool.Pool, log *zap.S
 
 	// handlers
 	authHandler := handlers.NewAuthHandler(authService, log)
-	userHandler := handlers.NewUserHandler(userRepo, log)
+	userHandler := handlers.NewUserHandler(userRepo, authService, log)
 	currencyHandler := handlers.NewCurrencyHandler(currencyService, log)
 	tripHandler := handlers.NewTripHandler(tripService, orderService, hotelService, log)
 	newsHandler := handlers.NewNewsHandler(newsService, log)
@@ -131,6 +275,24 @@ func New(ctx context.Context, cfg *config.Config, pool *pgxpool.Pool, log *zap.S
 	dateHandler := handlers.NewDateHandler(log)
 	mediaHandler := handlers.NewMediaHandler(cfg, pool, log)
 	cloudflareHandler := handlers.NewCloudflareHandler(cloudflareService, log)
+	travellerProfileHandler := handlers.NewTravellerProfileHandler(travellerService, log)
+	auditHandler := handlers.NewAuditHandler(auditRepo, log)
+	paymentHandler := handlers.NewPaymentHandler(paymentService, log)
+	paymentScheduleHandler := handlers.NewPaymentScheduleHandler(scheduleService, log)
+	documentHandler := handlers.NewDocumentHandler(documentService, log)
+	orderCRMHandler := handlers.NewOrderCRMHandler(orderCRMService, log)
+	customerHandler := handlers.NewCustomerHandler(customerService, log)
+	myOrderHandler := handlers.NewMyOrderHandler(myOrderService, log)
+	cancellationHandler := handlers.NewCancellationHandler(cancellationService, log)
+	notificationHandler := handlers.NewNotificationHandler(notificationService, log)
+	notificationTemplateHandler := handlers.NewNotificationTemplateHandler(notificationTemplateService, log)
+	notificationRoutingHandler := handlers.NewNotificationRoutingHandler(notificationRoutingService, log)
+	reportHandler := handlers.NewReportHandler(reportService, log)
+	passwordHandler := handlers.NewPasswordHandler(passwordService, log)
+	verificationHandler := handlers.NewVerificationHandler(verificationService, log)
+	roleHandler := handlers.NewRoleHandler(roleService, log)
+	loginLockHandler := handlers.NewLoginLockHandler(loginGuardService, log)
+	telegramHandler := handlers.NewTelegramHandler(telegramBotService, cfg.TG.WebhookSecret, log)
 
 	return &App{
 		Config:              cfg,
@@ -165,5 +327,145 @@ func New(ctx context.Context, cfg *config.Config, pool *pgxpool.Pool, log *zap.S
 		DateHandler:         dateHandler,
 		MediaHandler:        mediaHandler,
 		CloudflareHandler:   cloudflareHandler,
+
+		travellerRepo:           travellerRepo,
+		auditRepo:               auditRepo,
+		travellerService:        travellerService,
+		TravellerProfileHandler: travellerProfileHandler,
+		AuditHandler:            auditHandler,
+		paymentRepo:             paymentRepo,
+		paymentService:          paymentService,
+		PaymentHandler:          paymentHandler,
+		scheduleRepo:            scheduleRepo,
+		PaymentScheduleService:  scheduleService,
+		PaymentScheduleHandler:  paymentScheduleHandler,
+		documentRepo:            documentRepo,
+		documentService:         documentService,
+		DocumentHandler:         documentHandler,
+		OrderCRMService:         orderCRMService,
+		OrderCRMHandler:         orderCRMHandler,
+		customerRepo:            customerRepo,
+		customerService:         customerService,
+		CustomerHandler:         customerHandler,
+		MyOrderHandler:          myOrderHandler,
+		CancellationHandler:     cancellationHandler,
+		NotificationService:     notificationService,
+		NotificationHandler:     notificationHandler,
+
+		NotificationTemplateHandler: notificationTemplateHandler,
+		TelegramBotService:          telegramBotService,
+		TelegramHandler:             telegramHandler,
+		NotificationRoutingHandler:  notificationRoutingHandler,
+		ReportService:               reportService,
+		ReportHandler:               reportHandler,
+		PasswordHandler:             passwordHandler,
+		VerificationHandler:         verificationHandler,
+		RoleHandler:                 roleHandler,
+		LoginLockHandler:            loginLockHandler,
+	}
+}
+
+// newDigestConfig разбирает расписание сводок; с ошибкой в конфиге — 09:00 по Москве
+func newDigestConfig(cfg config.DigestConfig, log *zap.SugaredLogger) services.DigestConfig {
+	out := services.DigestConfig{Periods: cfg.Periods, At: 9 * time.Hour}
+
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		log.Warnw("invalid REPORT_DIGEST_TIMEZONE, falling back to Europe/Moscow", "timezone", cfg.Timezone, "err", err)
+		loc, _ = time.LoadLocation("Europe/Moscow")
+	}
+	out.Location = loc
+
+	at, err := time.Parse("15:04", cfg.Time)
+	if err != nil {
+		log.Warnw("invalid REPORT_DIGEST_TIME, falling back to 09:00", "time", cfg.Time)
+	} else {
+		out.At = time.Duration(at.Hour())*time.Hour + time.Duration(at.Minute())*time.Minute
+	}
+	return out
+}
+
+// newPaymentProvider выбирает платёжного провайдера по конфигу. Фейковый провайдер
+// выдаёт ненастоящие ссылки на оплату, поэтому без явного PAYMENT_PROVIDER он включается
+// только в dev, а неизвестное значение останавливает запуск
+func newPaymentProvider(cfg config.PaymentsConfig, env string, log *zap.SugaredLogger) (payments.Provider, error) {
+	if cfg.WebhookSecret == "" {
+		log.Warnw("PAYMENT_WEBHOOK_SECRET is empty: payment webhooks will be rejected")
+	}
+
+	switch cfg.Provider {
+	case "yookassa":
+		return payments.NewYooKassa(payments.YooKassaConfig{
+			ShopID:        cfg.YooKassa.ShopID,
+			SecretKey:     cfg.YooKassa.SecretKey,
+			BaseURL:       cfg.YooKassa.APIURL,
+			WebhookSecret: cfg.WebhookSecret,
+		}), nil
+	case "fake":
+		if env != "dev" {
+			log.Warnw("fake payment provider is enabled outside dev", "env", env)
+		}
+		return payments.NewFakeProvider(cfg.WebhookSecret), nil
+	case "":
+		if env != "dev" {
+			return nil, fmt.Errorf("PAYMENT_PROVIDER is required in %q environment", env)
+		}
+		log.Warnw("PAYMENT_PROVIDER is empty, using fake provider in dev")
+		return payments.NewFakeProvider(cfg.WebhookSecret), nil
+	default:
+		return nil, fmt.Errorf("unknown PAYMENT_PROVIDER %q", cfg.Provider)
+	}
+}
+
+// newNotifier включает каналы, для которых задан конфиг, и раскладывает по ним события.
+// Тексты берутся из шаблонов в БД, без шаблона — встроенные
+func newNotifier(cfg *config.Config, telegram *notifications.Telegram, renderer notifications.Renderer, log *zap.SugaredLogger) *notifications.Router {
+	nc := cfg.Notifications
+
+	spec := nc.Routes
+	if spec == "" {
+		spec = notifications.DefaultRoutes
+	}
+	routes, err := notifications.ParseRoutes(spec)
+	if err != nil {
+		log.Warnw("invalid NOTIFY_ROUTES, falling back to defaults", "err", err)
+		routes, _ = notifications.ParseRoutes(notifications.DefaultRoutes)
+	}
+
+	var notifiers []notifications.Notifier
+	if cfg.TG.TelegramToken != "" {
+		notifiers = append(notifiers, telegram)
+	}
+	if nc.SMTP.Host != "" {
+		notifiers = append(notifiers, notifications.NewEmail(notifications.SMTPConfig{
+			Host:     nc.SMTP.Host,
+			Port:     nc.SMTP.Port,
+			Username: nc.SMTP.Username,
+			Password: nc.SMTP.Password,
+			From:     nc.SMTP.From,
+			To:       nc.SMTP.To,
+		}))
+	}
+	if nc.SMS.URL != "" {
+		notifiers = append(notifiers, notifications.NewSMS(notifications.SMSConfig{
+			URL:   nc.SMS.URL,
+			Token: nc.SMS.Token,
+			To:    nc.SMS.To,
+		}))
+	}
+
+	return notifications.NewRouter(routes, renderer, log, notifiers...)
+}
+
+// passwordResetURL — начало ссылки на сброс пароля: PASSWORD_RESET_URL
+// или страница сайта {FRONTEND_URL}/reset-password
+func passwordResetURL(cfg *config.Config) string {
+	if cfg.PasswordReset.URL != "" {
+		return cfg.PasswordReset.URL
+	}
+	base := cfg.FrontendURL
+	if base == "" {
+		base = cfg.AppBaseURL
 	}
+	return strings.TrimRight(base, "/") + "/reset-password?token="
 }
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Ramcache/travel-backend/internal/config"
+	"github.com/Ramcache/travel-backend/internal/logger"
+	"github.com/Ramcache/travel-backend/internal/phone"
+	"github.com/Ramcache/travel-backend/internal/repository"
+	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/Ramcache/travel-backend/internal/storage"
+)
+
+func NewPhonesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "phones",
+		Short: "Phone number maintenance",
+	}
+	cmd.AddCommand(newPhonesNormalizeCmd())
+	return cmd
+}
+
+func newPhonesNormalizeCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "normalize",
+		Short: "Convert stored phone numbers to E.164 and merge duplicate customers",
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg := config.Load()
+
+			log := logger.New(cfg.AppEnv)
+			defer log.Sync()
+
+			if err := phone.SetDefaultCountry(cfg.PhoneDefaultCountry); err != nil {
+				log.Fatalw("unknown PHONE_DEFAULT_COUNTRY", "country", cfg.PhoneDefaultCountry)
+			}
+
+			ctx := context.Background()
+
+			pool, err := storage.NewPostgres(ctx, storage.PostgresConfig{
+				DSN:         cfg.DB.URL,
+				MaxConns:    cfg.DB.MaxConns,
+				MinConns:    cfg.DB.MinConns,
+				ConnTimeout: cfg.DB.ConnTimeout,
+				IdleTimeout: cfg.DB.IdleTimeout,
+			})
+			if err != nil {
+				log.Fatalw("db connect error", "err", err)
+			}
+			defer pool.Close()
+
+			svc := services.NewPhoneService(repository.NewPhoneRepo(pool), repository.NewCustomerRepo(pool), log)
+			res, err := svc.Normalize(ctx, dryRun)
+			if err != nil {
+				log.Fatalw("phones normalize error", "err", err)
+			}
+
+			log.Infow("phones normalized",
+				"dry_run", res.DryRun,
+				"orders", res.Orders,
+				"feedbacks", res.Feedbacks,
+				"customers", res.Customers,
+			)
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "only count numbers that would change")
+	return cmd
+}
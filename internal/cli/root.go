@@ -10,6 +10,8 @@ func NewRootCmd() *cobra.Command {
 
 	rootCmd.AddCommand(NewServeCmd())
 	rootCmd.AddCommand(NewMigrateCmd())
+	rootCmd.AddCommand(NewPhonesCmd())
+	rootCmd.AddCommand(NewTelegramCmd())
 
 	return rootCmd
 }
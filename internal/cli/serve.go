@@ -46,7 +46,20 @@ func NewServeCmd() *cobra.Command {
 				application.ProfileHandler, application.NewsCategoryHandler, application.StatsHandler,
 				application.OrderHandler, application.FeedbackHandler, application.HotelHandler, application.SearchHandler,
 				application.ReviewsHandler, application.TripRouteHandler, application.TripPageHandler,
-				application.DateHandler, application.MediaHandler, application.CloudflareHandler, cfg.JWTSecret, log, pool)
+				application.DateHandler, application.MediaHandler, application.CloudflareHandler,
+				application.TravellerProfileHandler, application.AuditHandler, application.PaymentHandler, application.PaymentScheduleHandler, application.DocumentHandler, application.OrderCRMHandler, application.CustomerHandler, application.MyOrderHandler, application.CancellationHandler, application.NotificationHandler, application.NotificationTemplateHandler,
+				application.TelegramHandler, application.NotificationRoutingHandler, application.ReportHandler, application.PasswordHandler, application.VerificationHandler, application.RoleHandler, application.LoginLockHandler, cfg.JWTSecret, application.AuthService, log, pool)
+
+			// напоминания о платежах по графику
+			reminderCtx, stopReminders := context.WithCancel(ctx)
+			defer stopReminders()
+			go application.PaymentScheduleService.RunReminders(reminderCtx, cfg.Payments.ReminderInterval)
+			// напоминания менеджерам о запланированных звонках
+			go application.OrderCRMService.RunFollowUpReminders(reminderCtx, cfg.FollowUpReminderInterval)
+			// доставка уведомлений из outbox
+			go application.NotificationService.RunDispatcher(reminderCtx, cfg.Notifications.OutboxInterval)
+			// утренние сводки владельцу
+			go application.ReportService.RunDigests(reminderCtx, cfg.Digest.Interval)
 
 			addr := fmt.Sprintf(":%s", cfg.AppPort)
 
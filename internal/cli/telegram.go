@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Ramcache/travel-backend/internal/config"
+	"github.com/Ramcache/travel-backend/internal/logger"
+	"github.com/Ramcache/travel-backend/internal/notifications"
+)
+
+func NewTelegramCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "telegram",
+		Short: "Telegram bot maintenance",
+	}
+	cmd.AddCommand(newTelegramSetWebhookCmd())
+	return cmd
+}
+
+func newTelegramSetWebhookCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set-webhook",
+		Short: "Register TG_WEBHOOK_URL with the Bot API using TG_WEBHOOK_SECRET",
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg := config.Load()
+
+			log := logger.New(cfg.AppEnv)
+			defer log.Sync()
+
+			if cfg.TG.TelegramToken == "" || cfg.TG.WebhookURL == "" || cfg.TG.WebhookSecret == "" {
+				log.Fatalw("TG_TOKEN, TG_WEBHOOK_URL and TG_WEBHOOK_SECRET are required")
+			}
+
+			bot := notifications.NewTelegram(notifications.TelegramConfig{Token: cfg.TG.TelegramToken})
+			if err := bot.SetWebhook(context.Background(), cfg.TG.WebhookURL, cfg.TG.WebhookSecret, []string{"message", "callback_query"}); err != nil {
+				log.Fatalw("telegram set-webhook error", "err", err)
+			}
+			log.Infow("telegram webhook set", "url", cfg.TG.WebhookURL)
+		},
+	}
+}
@@ -15,6 +15,7 @@ type Config struct {
 	AppPort     string
 	JWTSecret   string
 	JWTTTL      time.Duration
+	RefreshTTL  time.Duration
 	DB          DBConfig
 	TG          TelegramConfig
 	FrontendURL string
@@ -23,6 +24,28 @@ type Config struct {
 	UploadDir   string
 	MaxUploadMB int
 	Cloudflare  CloudflareConfig
+
+	// ключ шифрования чувствительных данных (паспорта туристов)
+	EncryptionKey string
+
+	Payments  PaymentsConfig
+	Documents DocumentsConfig
+
+	// как часто проверять заказы с наступившей датой звонка
+	FollowUpReminderInterval time.Duration
+
+	// страна для номеров без кода: RU, KZ, SA
+	PhoneDefaultCountry string
+
+	// начало ссылки отслеживания заказа, к нему дописывается токен;
+	// пусто — {APP_BASE_URL}/api/v1/orders/track/
+	OrderTrackingURL string
+
+	Notifications NotificationsConfig
+	Digest        DigestConfig
+	PasswordReset PasswordResetConfig
+	Verification  VerificationConfig
+	LoginGuard    LoginGuardConfig
 }
 
 type DBConfig struct {
@@ -36,7 +59,112 @@ type DBConfig struct {
 type TelegramConfig struct {
 	TelegramToken string
 	TelegramChat  string
+	// WebhookURL — публичный адрес POST /telegram/webhook для команды telegram set-webhook
+	WebhookURL string
+	// WebhookSecret — значение X-Telegram-Bot-Api-Secret-Token; пустое — вебхук отклоняется
+	WebhookSecret string
+}
+
+// NotificationsConfig — каналы уведомлений помимо Telegram и маршруты событий по ним
+type NotificationsConfig struct {
+	// пусто — маршруты по умолчанию (notifications.DefaultRoutes)
+	Routes string
+	// ссылка на админку в уведомлениях менеджерам
+	AdminURL string
+	// язык шаблонов уведомлений
+	Locale string
+	SMTP   SMTPConfig
+	SMS    SMSConfig
+
+	// outbox: как часто отправлять накопившееся, сколько раз пытаться
+	// и пауза перед второй попыткой (дальше удваивается)
+	OutboxInterval time.Duration
+	MaxAttempts    int
+	RetryBase      time.Duration
+}
+
+// DigestConfig — сводки владельцу (событие report.digest, каналы — по NOTIFY_ROUTES)
+type DigestConfig struct {
+	// daily, weekly; пусто — сводки не рассылаются
+	Periods []string
+	// во сколько слать, "HH:MM" в Timezone; недельная уходит в понедельник за прошлую неделю
+	Time     string
+	Timezone string
+	// как часто проверять, не пора ли слать
+	Interval time.Duration
+}
+
+// PasswordResetConfig — ссылка на сброс пароля (событие auth.password_reset)
+type PasswordResetConfig struct {
+	// начало ссылки, к нему дописывается токен; пусто — {FRONTEND_URL}/reset-password?token=
+	URL string
+	// сколько действует ссылка
+	TTL time.Duration
+}
+
+// VerificationConfig — коды подтверждения email и телефона (событие auth.verification)
+type VerificationConfig struct {
+	// не пускать в аккаунт, пока email не подтверждён
+	RequireVerifiedEmail bool
+	// сколько действует код
+	CodeTTL time.Duration
+	// сколько неверных вводов выдерживает код
+	MaxAttempts int
+}
+
+// LoginGuardConfig — защита входа от подбора пароля
+type LoginGuardConfig struct {
+	// после стольких неудачных входов подряд аккаунт блокируется
+	MaxFailures int
+	// то же для одного IP, по всем аккаунтам
+	MaxFailuresPerIP int
+	// на сколько блокируется вход
+	Lockout time.Duration
+}
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+type SMSConfig struct {
+	URL   string
+	Token string
+	To    []string
+}
+
+// PaymentsConfig — онлайн-оплата заказов; Provider: fake (по умолчанию) или yookassa
+type PaymentsConfig struct {
+	Provider      string
+	ReturnURL     string
+	WebhookSecret string
+	YooKassa      YooKassaConfig
+
+	// напоминания о платежах по графику
+	ReminderInterval time.Duration
+	ReminderLead     time.Duration
 }
+
+// DocumentsConfig — PDF-документы по заказам (счёт, ваучер, договор).
+// Нужен TTF-шрифт с кириллицей; без него генерация недоступна
+type DocumentsConfig struct {
+	FontPath     string
+	FontBoldPath string
+	CompanyName  string
+	// реквизиты компании, по строке на реквизит
+	CompanyDetails string
+}
+
+type YooKassaConfig struct {
+	ShopID    string
+	SecretKey string
+	APIURL    string
+}
+
 type CloudflareConfig struct {
 	APIToken string `env:"CLOUDFLARE_API_TOKEN"`
 	ZoneID   string `env:"CLOUDFLARE_ZONE_ID"`
@@ -50,7 +178,8 @@ func Load() *Config {
 		log.Println("ℹ️ .env not found, using system env only")
 	}
 
-	ttl := getEnvDuration("JWT_TTL", 24*time.Hour)
+	// JWT_TTL — срок access-токена; дольше живёт сессия (JWT_REFRESH_TTL), токен обновляется через /auth/refresh
+	ttl := getEnvDuration("JWT_TTL", 15*time.Minute)
 	log.Println("👉 JWT_TTL loaded as:", ttl)
 
 	dbURL := getEnv("DB_URL", "")
@@ -65,6 +194,7 @@ func Load() *Config {
 		AppPort:     getEnv("APP_PORT", "8080"),
 		JWTSecret:   getEnv("APP_JWT_SECRET", "changeme"),
 		JWTTTL:      ttl,
+		RefreshTTL:  getEnvDuration("JWT_REFRESH_TTL", 30*24*time.Hour),
 		FrontendURL: getEnv("FRONTEND_URL", ""),
 		AppBaseURL:  getEnv("APP_BASE_URL", "http://localhost:8080"),
 		UploadDir:   getEnv("UPLOAD_DIR", "uploads"),
@@ -79,11 +209,76 @@ func Load() *Config {
 		TG: TelegramConfig{
 			TelegramToken: getEnv("TG_TOKEN", ""),
 			TelegramChat:  getEnv("TG_CHAT", ""),
+			WebhookURL:    getEnv("TG_WEBHOOK_URL", ""),
+			WebhookSecret: getEnv("TG_WEBHOOK_SECRET", ""),
 		},
 		Cloudflare: CloudflareConfig{
 			APIToken: getEnv("CLOUDFLARE_API_TOKEN", ""),
 			ZoneID:   getEnv("CLOUDFLARE_ZONE_ID", ""),
 		},
+		EncryptionKey: getEnv("APP_ENCRYPTION_KEY", ""),
+		Payments: PaymentsConfig{
+			Provider:      getEnv("PAYMENT_PROVIDER", ""),
+			ReturnURL:     getEnv("PAYMENT_RETURN_URL", ""),
+			WebhookSecret: getEnv("PAYMENT_WEBHOOK_SECRET", ""),
+			YooKassa: YooKassaConfig{
+				ShopID:    getEnv("YOOKASSA_SHOP_ID", ""),
+				SecretKey: getEnv("YOOKASSA_SECRET_KEY", ""),
+				APIURL:    getEnv("YOOKASSA_API_URL", "https://api.yookassa.ru/v3"),
+			},
+			ReminderInterval: getEnvDuration("PAYMENT_REMINDER_INTERVAL", time.Hour),
+			ReminderLead:     getEnvDuration("PAYMENT_REMINDER_LEAD", 72*time.Hour),
+		},
+		FollowUpReminderInterval: getEnvDuration("FOLLOWUP_REMINDER_INTERVAL", 5*time.Minute),
+		PhoneDefaultCountry:      getEnv("PHONE_DEFAULT_COUNTRY", "RU"),
+		OrderTrackingURL:         getEnv("ORDER_TRACKING_URL", ""),
+		Notifications: NotificationsConfig{
+			Routes:   getEnv("NOTIFY_ROUTES", ""),
+			AdminURL: getEnv("NOTIFY_ADMIN_URL", "https://web95.tech/admin.html"),
+			Locale:   getEnv("NOTIFY_LOCALE", "ru"),
+			SMTP: SMTPConfig{
+				Host:     getEnv("SMTP_HOST", ""),
+				Port:     int(getEnvInt("SMTP_PORT", 587)),
+				Username: getEnv("SMTP_USERNAME", ""),
+				Password: getEnv("SMTP_PASSWORD", ""),
+				From:     getEnv("SMTP_FROM", ""),
+				To:       getEnvList("NOTIFY_EMAIL_TO"),
+			},
+			SMS: SMSConfig{
+				URL:   getEnv("SMS_GATEWAY_URL", ""),
+				Token: getEnv("SMS_GATEWAY_TOKEN", ""),
+				To:    getEnvList("NOTIFY_SMS_TO"),
+			},
+			OutboxInterval: getEnvDuration("NOTIFY_OUTBOX_INTERVAL", 5*time.Second),
+			MaxAttempts:    int(getEnvInt("NOTIFY_MAX_ATTEMPTS", 8)),
+			RetryBase:      getEnvDuration("NOTIFY_RETRY_BASE", 30*time.Second),
+		},
+		Digest: DigestConfig{
+			Periods:  getEnvList("REPORT_DIGEST_PERIODS"),
+			Time:     getEnv("REPORT_DIGEST_TIME", "09:00"),
+			Timezone: getEnv("REPORT_DIGEST_TIMEZONE", "Europe/Moscow"),
+			Interval: getEnvDuration("REPORT_DIGEST_INTERVAL", time.Minute),
+		},
+		PasswordReset: PasswordResetConfig{
+			URL: getEnv("PASSWORD_RESET_URL", ""),
+			TTL: getEnvDuration("PASSWORD_RESET_TTL", time.Hour),
+		},
+		Verification: VerificationConfig{
+			RequireVerifiedEmail: getEnvBool("AUTH_REQUIRE_VERIFIED_EMAIL", false),
+			CodeTTL:              getEnvDuration("VERIFICATION_CODE_TTL", 15*time.Minute),
+			MaxAttempts:          int(getEnvInt("VERIFICATION_MAX_ATTEMPTS", 5)),
+		},
+		LoginGuard: LoginGuardConfig{
+			MaxFailures:      int(getEnvInt("LOGIN_MAX_FAILURES", 5)),
+			MaxFailuresPerIP: int(getEnvInt("LOGIN_MAX_FAILURES_PER_IP", 20)),
+			Lockout:          getEnvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+		},
+		Documents: DocumentsConfig{
+			FontPath:       getEnv("DOCUMENTS_FONT_PATH", "/usr/share/fonts/dejavu/DejaVuSans.ttf"),
+			FontBoldPath:   getEnv("DOCUMENTS_FONT_BOLD_PATH", "/usr/share/fonts/dejavu/DejaVuSans-Bold.ttf"),
+			CompanyName:    getEnv("DOCUMENTS_COMPANY_NAME", ""),
+			CompanyDetails: getEnv("DOCUMENTS_COMPANY_DETAILS", ""),
+		},
 	}
 }
 
@@ -94,6 +289,17 @@ func getEnv(key, def string) string {
 	return def
 }
 
+// getEnvList — значения через запятую, пустые элементы отбрасываются
+func getEnvList(key string) []string {
+	var out []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
 func getEnvInt(key string, def int32) int32 {
 	if val, ok := os.LookupEnv(key); ok {
 		if parsed, err := strconv.Atoi(val); err == nil {
@@ -112,6 +318,15 @@ func getEnvDuration(key string, def time.Duration) time.Duration {
 	return def
 }
 
+func getEnvBool(key string, def bool) bool {
+	if val, ok := os.LookupEnv(key); ok {
+		if parsed, err := strconv.ParseBool(val); err == nil {
+			return parsed
+		}
+	}
+	return def
+}
+
 var cgroupFilePath = "/proc/1/cgroup"
 
 func isRunningInDocker() bool {
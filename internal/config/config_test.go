@@ -117,6 +117,7 @@ func TestLoad(t *testing.T) {
 		"APP_PORT":        "9090",
 		"APP_JWT_SECRET":  "supersecret",
 		"JWT_TTL":         "1h",
+		"JWT_REFRESH_TTL": "168h",
 		"FRONTEND_URL":    "https://example.com",
 		"DB_URL":          "postgres://localhost:5432/app",
 		"DB_MAX_CONNS":    "20",
@@ -150,6 +151,9 @@ func TestLoad(t *testing.T) {
 	if cfg.JWTTTL != time.Hour {
 		t.Fatalf("expected JWTTTL 1h, got %s", cfg.JWTTTL)
 	}
+	if cfg.RefreshTTL != 7*24*time.Hour {
+		t.Fatalf("expected RefreshTTL 168h, got %s", cfg.RefreshTTL)
+	}
 	if cfg.FrontendURL != "https://example.com" {
 		t.Fatalf("expected FrontendURL https://example.com, got %s", cfg.FrontendURL)
 	}
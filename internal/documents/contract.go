@@ -0,0 +1,100 @@
+package documents
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+)
+
+// DefaultContractTemplate — шаблон договора по умолчанию (text/template).
+// Администратор может заменить его своим; доступны поля Data и функции money, date, traveller_type.
+const DefaultContractTemplate = `ДОГОВОР № {{.Number}}
+о реализации туристского продукта
+
+Дата: {{date .Date}}
+
+{{.Company.Name}}, именуемое в дальнейшем «Турагент», с одной стороны, и {{.Order.UserName}}, телефон {{.Order.UserPhone}}, именуемый(ая) в дальнейшем «Заказчик», с другой стороны, заключили настоящий договор о нижеследующем.
+
+1. ПРЕДМЕТ ДОГОВОРА
+1.1. Турагент обязуется оказать Заказчику услуги по бронированию и оплате туристского продукта «{{.TripTitle}}»{{if .Trip}} на период с {{date .Trip.StartDate}} по {{date .Trip.EndDate}}, город вылета — {{.Trip.DepartureCity}}{{end}}.
+1.2. Состав туристов:
+{{- range $i, $t := .Order.Travellers}}
+   {{inc $i}}. {{$t.FullName}} ({{traveller_type $t.TravellerType}})
+{{- else}}
+   1. {{.Order.UserName}}
+{{- end}}
+
+2. СТОИМОСТЬ И ПОРЯДОК ОПЛАТЫ
+2.1. Общая стоимость туристского продукта составляет {{money .Total}} руб.
+{{- if .Installments}}
+2.2. Оплата производится по графику:
+{{- range .Installments}}
+   — {{.Title}}: {{money .Amount}} руб. до {{date .DueDate}}
+{{- end}}
+{{- else}}
+2.2. Оплата производится в полном объёме до начала поездки.
+{{- end}}
+
+3. ОТВЕТСТВЕННОСТЬ СТОРОН
+3.1. Стороны несут ответственность за неисполнение или ненадлежащее исполнение обязательств в соответствии с законодательством РФ.
+3.2. При отказе Заказчика от поездки Турагент возвращает оплаченные средства за вычетом фактически понесённых расходов.
+
+4. РЕКВИЗИТЫ ТУРАГЕНТА
+{{.Company.Name}}
+{{.Company.Details}}
+
+Турагент ____________________          Заказчик ____________________
+`
+
+var contractFuncs = template.FuncMap{
+	"money":          Money,
+	"date":           Date,
+	"traveller_type": TravellerType,
+	"inc":            func(i int) int { return i + 1 },
+}
+
+// RenderContractText подставляет данные заказа в шаблон договора
+func RenderContractText(tpl string, d Data) (string, error) {
+	t, err := template.New("contract").Funcs(contractFuncs).Option("missingkey=error").Parse(tpl)
+	if err != nil {
+		return "", fmt.Errorf("parse contract template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, d); err != nil {
+		return "", fmt.Errorf("execute contract template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// SampleData — тестовый заказ для проверки шаблона перед сохранением
+func SampleData() Data {
+	total := 250000.0
+	birth := time.Date(1985, 3, 14, 0, 0, 0, 0, time.UTC)
+	return Data{
+		Date:    time.Now(),
+		Company: Company{Name: "ООО «Турагент»", Details: "ИНН 0000000000"},
+		Order: models.Order{
+			ID:         1,
+			UserName:   "Иванов Иван",
+			UserPhone:  "+79990000000",
+			TotalPrice: &total,
+			Travellers: []models.OrderTraveller{
+				{FullName: "Иванов Иван", TravellerType: models.TravellerAdult, BirthDate: &birth},
+			},
+		},
+		Trip: &models.Trip{
+			Title:         "Умра",
+			DepartureCity: "Москва",
+			StartDate:     time.Now().AddDate(0, 1, 0),
+			EndDate:       time.Now().AddDate(0, 1, 10),
+		},
+		Installments: []models.OrderInstallment{
+			{Title: "Задаток", Amount: 75000, DueDate: time.Now().AddDate(0, 0, 3)},
+			{Title: "Остаток", Amount: 175000, DueDate: time.Now().AddDate(0, 0, 20)},
+		},
+	}
+}
@@ -0,0 +1,128 @@
+// Package documents — генерация PDF-документов по заказу: счёт, ваучер и договор.
+package documents
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+)
+
+// Виды документов
+const (
+	KindInvoice  = "invoice"
+	KindVoucher  = "voucher"
+	KindContract = "contract"
+)
+
+var (
+	ErrUnknownKind     = errors.New("unknown document kind")
+	ErrFontUnavailable = errors.New("document font is not available")
+)
+
+// IsValidKind — поддерживается ли вид документа
+func IsValidKind(kind string) bool {
+	switch kind {
+	case KindInvoice, KindVoucher, KindContract:
+		return true
+	}
+	return false
+}
+
+// Company — реквизиты турагентства для шапки документов
+type Company struct {
+	Name    string
+	Details string // адрес, ИНН, телефон — произвольный многострочный текст
+}
+
+// Data — всё, что нужно для документов по заказу
+type Data struct {
+	Date         time.Time
+	Company      Company
+	Order        models.Order
+	Trip         *models.Trip
+	Hotels       []models.Hotel
+	Routes       []models.TripRoute
+	Installments []models.OrderInstallment
+}
+
+// Number — номер документа (совпадает с номером заказа)
+func (d Data) Number() string {
+	return strconv.Itoa(d.Order.ID)
+}
+
+// Total — сумма заказа; для заявок без тура — 0
+func (d Data) Total() float64 {
+	if d.Order.TotalPrice == nil {
+		return 0
+	}
+	return *d.Order.TotalPrice
+}
+
+// TripTitle — название тура или свободной заявки
+func (d Data) TripTitle() string {
+	if d.Trip != nil {
+		return d.Trip.Title
+	}
+	if d.Order.Name != nil && *d.Order.Name != "" {
+		return *d.Order.Name
+	}
+	return "—"
+}
+
+var travellerTypeTitles = map[string]string{
+	models.TravellerAdult:  "взрослый",
+	models.TravellerChild:  "ребёнок",
+	models.TravellerInfant: "младенец",
+}
+
+// TravellerType — тип туриста по-русски
+func TravellerType(t string) string {
+	if title, ok := travellerTypeTitles[t]; ok {
+		return title
+	}
+	return t
+}
+
+// Money — сумма с разделителями разрядов: 150 000,50
+func Money(v float64) string {
+	neg := v < 0
+	if neg {
+		v = -v
+	}
+	s := strconv.FormatFloat(v, 'f', 2, 64)
+	intPart, frac := s[:len(s)-3], s[len(s)-2:]
+
+	var b strings.Builder
+	for i, r := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteRune(' ')
+		}
+		b.WriteRune(r)
+	}
+
+	out := b.String()
+	if frac != "00" {
+		out += "," + frac
+	}
+	if neg {
+		out = "-" + out
+	}
+	return out
+}
+
+// Date — дата в формате ДД.ММ.ГГГГ, пустая строка для нулевой даты
+func Date(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("02.01.2006")
+}
+
+// FileName — имя файла документа
+func FileName(kind string, orderID int) string {
+	return fmt.Sprintf("%s-%d.pdf", kind, orderID)
+}
@@ -0,0 +1,100 @@
+package documents_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ramcache/travel-backend/internal/documents"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testFont ищет TTF с кириллицей: DOCUMENTS_TEST_FONT или системный DejaVu
+func testFont(t *testing.T) string {
+	t.Helper()
+	candidates := []string{
+		os.Getenv("DOCUMENTS_TEST_FONT"),
+		"/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf",
+		"/usr/share/fonts/dejavu/DejaVuSans.ttf",
+	}
+	for _, p := range candidates {
+		if p == "" {
+			continue
+		}
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	t.Skip("no TTF font with cyrillic glyphs found, set DOCUMENTS_TEST_FONT")
+	return ""
+}
+
+func TestMoney(t *testing.T) {
+	assert.Equal(t, "0", documents.Money(0))
+	assert.Equal(t, "999", documents.Money(999))
+	assert.Equal(t, "150 000", documents.Money(150000))
+	assert.Equal(t, "1 234 567,50", documents.Money(1234567.5))
+	assert.Equal(t, "-1 000", documents.Money(-1000))
+}
+
+func TestRenderContractText_Default(t *testing.T) {
+	d := documents.SampleData()
+	d.Date = time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	text, err := documents.RenderContractText(documents.DefaultContractTemplate, d)
+	require.NoError(t, err)
+	assert.Contains(t, text, "ДОГОВОР № 1")
+	assert.Contains(t, text, "Дата: 15.01.2026")
+	assert.Contains(t, text, "1. Иванов Иван (взрослый)")
+	assert.Contains(t, text, "250 000 руб.")
+	assert.Contains(t, text, "Задаток: 75 000 руб.")
+}
+
+func TestRenderContractText_NoTripNoTravellers(t *testing.T) {
+	name := "Индивидуальный тур"
+	d := documents.Data{Order: models.Order{ID: 5, UserName: "Пётр", Name: &name}}
+
+	text, err := documents.RenderContractText(documents.DefaultContractTemplate, d)
+	require.NoError(t, err)
+	assert.Contains(t, text, "«Индивидуальный тур»")
+	assert.Contains(t, text, "1. Пётр")
+	assert.Contains(t, text, "в полном объёме")
+}
+
+func TestRenderContractText_InvalidTemplate(t *testing.T) {
+	_, err := documents.RenderContractText("{{.Order.Unknown}}", documents.SampleData())
+	assert.Error(t, err)
+
+	_, err = documents.RenderContractText("{{if}}", documents.SampleData())
+	assert.Error(t, err)
+}
+
+func TestRenderer_UnavailableFont(t *testing.T) {
+	r := documents.NewRenderer("/nonexistent/font.ttf", "")
+	_, err := r.Render(documents.KindInvoice, documents.SampleData(), "")
+	assert.ErrorIs(t, err, documents.ErrFontUnavailable)
+
+	_, err = r.Render("receipt", documents.SampleData(), "")
+	assert.ErrorIs(t, err, documents.ErrUnknownKind)
+}
+
+func TestRenderer_AllKinds(t *testing.T) {
+	r := documents.NewRenderer(testFont(t), "")
+	d := documents.SampleData()
+	d.Hotels = []models.Hotel{{Name: "Hilton", City: "Мекка", Stars: 5, Nights: 7, Meals: "Завтрак"}}
+	d.Routes = []models.TripRoute{{City: "Медина", Transport: "Автобус", Duration: "5ч"}}
+
+	text, err := documents.RenderContractText(documents.DefaultContractTemplate, d)
+	require.NoError(t, err)
+
+	for _, kind := range []string{documents.KindInvoice, documents.KindVoucher, documents.KindContract} {
+		pdf, err := r.Render(kind, d, text)
+		require.NoError(t, err, kind)
+		assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-")), kind)
+		assert.True(t, strings.Contains(string(pdf[len(pdf)-10:]), "%%EOF"), kind)
+	}
+}
@@ -0,0 +1,253 @@
+package documents
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+const fontFamily = "doc"
+
+// Renderer рисует PDF. Для кириллицы нужен TTF-шрифт (например, DejaVuSans),
+// путь к нему задаётся конфигом; без шрифта Render возвращает ErrFontUnavailable.
+type Renderer struct {
+	fontPath     string
+	boldFontPath string
+}
+
+// NewRenderer — boldFontPath необязателен, тогда заголовки рисуются обычным начертанием
+func NewRenderer(fontPath, boldFontPath string) *Renderer {
+	return &Renderer{fontPath: fontPath, boldFontPath: boldFontPath}
+}
+
+// Available — найден ли шрифт для генерации документов
+func (r *Renderer) Available() bool {
+	return fileExists(r.fontPath)
+}
+
+// Render формирует PDF указанного вида. contractText нужен только для договора.
+func (r *Renderer) Render(kind string, d Data, contractText string) ([]byte, error) {
+	if !IsValidKind(kind) {
+		return nil, ErrUnknownKind
+	}
+	regular, err := os.ReadFile(r.fontPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFontUnavailable, err)
+	}
+	bold := regular
+	if fileExists(r.boldFontPath) {
+		if bold, err = os.ReadFile(r.boldFontPath); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrFontUnavailable, err)
+		}
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes(fontFamily, "", regular)
+	pdf.AddUTF8FontFromBytes(fontFamily, "B", bold)
+	pdf.SetCreationDate(d.Date)
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AddPage()
+
+	switch kind {
+	case KindInvoice:
+		writeInvoice(pdf, d)
+	case KindVoucher:
+		writeVoucher(pdf, d)
+	case KindContract:
+		writeContract(pdf, contractText)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("render %s pdf: %w", kind, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ---- разметка ----
+
+func writeHeader(pdf *gofpdf.Fpdf, d Data, title string) {
+	pdf.SetFont(fontFamily, "B", 12)
+	pdf.CellFormat(0, 6, d.Company.Name, "", 1, "L", false, 0, "")
+	if d.Company.Details != "" {
+		pdf.SetFont(fontFamily, "", 9)
+		pdf.MultiCell(0, 4.5, d.Company.Details, "", "L", false)
+	}
+	pdf.Ln(6)
+
+	pdf.SetFont(fontFamily, "B", 16)
+	pdf.CellFormat(0, 9, fmt.Sprintf("%s № %s от %s", title, d.Number(), Date(d.Date)), "", 1, "C", false, 0, "")
+	pdf.Ln(4)
+}
+
+func writeSection(pdf *gofpdf.Fpdf, title string) {
+	pdf.Ln(3)
+	pdf.SetFont(fontFamily, "B", 11)
+	pdf.CellFormat(0, 7, title, "", 1, "L", false, 0, "")
+	pdf.SetFont(fontFamily, "", 10)
+}
+
+func writeField(pdf *gofpdf.Fpdf, label, value string) {
+	pdf.SetFont(fontFamily, "B", 10)
+	pdf.CellFormat(45, 6, label, "", 0, "L", false, 0, "")
+	pdf.SetFont(fontFamily, "", 10)
+	pdf.MultiCell(0, 6, value, "", "L", false)
+}
+
+// writeTable — таблица с заголовком; widths в мм, align на колонку (L/C/R)
+func writeTable(pdf *gofpdf.Fpdf, header []string, widths []float64, align string, rows [][]string) {
+	pdf.SetFont(fontFamily, "B", 9)
+	pdf.SetFillColor(235, 235, 235)
+	for i, h := range header {
+		pdf.CellFormat(widths[i], 7, h, "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont(fontFamily, "", 9)
+	for _, row := range rows {
+		for i, cell := range row {
+			pdf.CellFormat(widths[i], 6.5, cell, "1", 0, string(align[i]), false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+}
+
+func writeInvoice(pdf *gofpdf.Fpdf, d Data) {
+	writeHeader(pdf, d, "Счёт на оплату")
+
+	writeField(pdf, "Плательщик:", fmt.Sprintf("%s, %s", d.Order.UserName, d.Order.UserPhone))
+	writeField(pdf, "Тур:", d.TripTitle())
+	if d.Trip != nil {
+		writeField(pdf, "Даты поездки:", fmt.Sprintf("%s — %s", Date(d.Trip.StartDate), Date(d.Trip.EndDate)))
+	}
+
+	writeSection(pdf, "Услуги")
+	var rows [][]string
+	for i, t := range d.Order.Travellers {
+		price := 0.0
+		if t.Price != nil {
+			price = *t.Price
+		}
+		rows = append(rows, []string{
+			strconv.Itoa(i + 1),
+			fmt.Sprintf("%s — %s (%s)", d.TripTitle(), t.FullName, TravellerType(t.TravellerType)),
+			"1",
+			Money(price),
+			Money(price),
+		})
+	}
+	if len(rows) == 0 {
+		rows = append(rows, []string{"1", d.TripTitle(), "1", Money(d.Total()), Money(d.Total())})
+	}
+	writeTable(pdf,
+		[]string{"№", "Наименование", "Кол-во", "Цена, руб.", "Сумма, руб."},
+		[]float64{10, 95, 17, 29, 29}, "CLCRR", rows)
+
+	pdf.Ln(2)
+	pdf.SetFont(fontFamily, "B", 11)
+	pdf.CellFormat(0, 7, fmt.Sprintf("Итого к оплате: %s руб.", Money(d.Total())), "", 1, "R", false, 0, "")
+
+	if len(d.Installments) > 0 {
+		writeSection(pdf, "График платежей")
+		rows = rows[:0]
+		for _, in := range d.Installments {
+			rows = append(rows, []string{in.Title, Date(in.DueDate), Money(in.Amount), Money(in.PaidAmount)})
+		}
+		writeTable(pdf,
+			[]string{"Платёж", "Срок", "Сумма, руб.", "Оплачено, руб."},
+			[]float64{70, 30, 40, 40}, "LCRR", rows)
+	}
+}
+
+func writeVoucher(pdf *gofpdf.Fpdf, d Data) {
+	writeHeader(pdf, d, "Туристический ваучер")
+
+	writeField(pdf, "Заказчик:", fmt.Sprintf("%s, %s", d.Order.UserName, d.Order.UserPhone))
+	writeField(pdf, "Тур:", d.TripTitle())
+	if d.Trip != nil {
+		writeField(pdf, "Даты поездки:", fmt.Sprintf("%s — %s", Date(d.Trip.StartDate), Date(d.Trip.EndDate)))
+		if d.Trip.DepartureCity != "" {
+			writeField(pdf, "Город вылета:", d.Trip.DepartureCity)
+		}
+	}
+
+	writeSection(pdf, "Туристы")
+	var rows [][]string
+	for i, t := range d.Order.Travellers {
+		birth := ""
+		if t.BirthDate != nil {
+			birth = Date(*t.BirthDate)
+		}
+		room := ""
+		if t.Room != nil {
+			room = *t.Room
+		}
+		rows = append(rows, []string{strconv.Itoa(i + 1), t.FullName, TravellerType(t.TravellerType), birth, room})
+	}
+	if len(rows) == 0 {
+		rows = append(rows, []string{"1", d.Order.UserName, TravellerType("adult"), "", ""})
+	}
+	writeTable(pdf,
+		[]string{"№", "ФИО", "Тип", "Дата рождения", "Номер"},
+		[]float64{10, 80, 30, 32, 28}, "CLCCC", rows)
+
+	if len(d.Hotels) > 0 {
+		writeSection(pdf, "Проживание")
+		rows = rows[:0]
+		for _, h := range d.Hotels {
+			stars := ""
+			if h.Stars > 0 {
+				stars = strings.Repeat("*", h.Stars)
+			}
+			distance := ""
+			if h.DistanceText.Valid {
+				distance = h.DistanceText.String
+			} else if h.Distance > 0 {
+				distance = strconv.FormatFloat(h.Distance, 'f', -1, 64) + " км"
+			}
+			rows = append(rows, []string{h.Name + " " + stars, h.City, strconv.Itoa(h.Nights), h.Meals, distance})
+		}
+		writeTable(pdf,
+			[]string{"Отель", "Город", "Ночей", "Питание", "До святынь"},
+			[]float64{60, 35, 17, 35, 33}, "LLCLL", rows)
+	}
+
+	if len(d.Routes) > 0 {
+		writeSection(pdf, "Маршрут")
+		rows = rows[:0]
+		for i, rt := range d.Routes {
+			rows = append(rows, []string{strconv.Itoa(i + 1), rt.City, rt.Transport, rt.Duration, rt.StopTime})
+		}
+		writeTable(pdf,
+			[]string{"№", "Город", "Транспорт", "В пути", "Стоянка"},
+			[]float64{10, 60, 40, 35, 35}, "CLLCC", rows)
+	}
+
+	pdf.Ln(6)
+	pdf.SetFont(fontFamily, "", 9)
+	pdf.MultiCell(0, 5, "Ваучер является подтверждением бронирования. Возьмите его с собой в поездку вместе с паспортом.", "", "L", false)
+}
+
+func writeContract(pdf *gofpdf.Fpdf, text string) {
+	pdf.SetFont(fontFamily, "", 10)
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			pdf.Ln(3)
+			continue
+		}
+		pdf.MultiCell(0, 5, line, "", "J", false)
+	}
+}
+
+func fileExists(path string) bool {
+	if path == "" {
+		return false
+	}
+	st, err := os.Stat(path)
+	return err == nil && !st.IsDir()
+}
@@ -0,0 +1,121 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// flushEvery — сколько строк буферизуем перед отправкой клиенту
+const flushEvery = 500
+
+const utf8BOM = "\ufeff"
+
+type csvWriter struct {
+	w    io.Writer
+	csv  *csv.Writer
+	rows int
+}
+
+// newCSVWriter пишет CSV с разделителем «;» и BOM — так файл корректно
+// открывается в русской локали Excel
+func newCSVWriter(w io.Writer) *csvWriter {
+	c := csv.NewWriter(w)
+	c.Comma = ';'
+	return &csvWriter{w: w, csv: c}
+}
+
+func (c *csvWriter) WriteHeader(columns []string) error {
+	if _, err := io.WriteString(c.w, utf8BOM); err != nil {
+		return err
+	}
+	return c.csv.Write(columns)
+}
+
+func (c *csvWriter) WriteRow(values []any) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = formatCSVValue(v)
+	}
+	if err := c.csv.Write(record); err != nil {
+		return err
+	}
+
+	c.rows++
+	if c.rows%flushEvery == 0 {
+		return c.flush()
+	}
+	return nil
+}
+
+func (c *csvWriter) Close() error {
+	return c.flush()
+}
+
+func (c *csvWriter) flush() error {
+	c.csv.Flush()
+	if err := c.csv.Error(); err != nil {
+		return err
+	}
+	if f, ok := c.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+func formatCSVValue(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return escapeFormula(val)
+	case *string:
+		if val == nil {
+			return ""
+		}
+		return escapeFormula(*val)
+	case float64:
+		return strconv.FormatFloat(val, 'f', 2, 64)
+	case *float64:
+		if val == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*val, 'f', 2, 64)
+	case bool:
+		if val {
+			return "да"
+		}
+		return "нет"
+	case time.Time:
+		return val.Format("2006-01-02 15:04:05")
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// plainValue — телефон в международном формате или просто число со знаком:
+// такие значения Excel не считает формулой, и портить их префиксом нельзя
+var plainValue = regexp.MustCompile(`^(\+\d[\d\s()-]*|-\d+([.,]\d+)?)$`)
+
+// escapeFormula не даёт Excel выполнить текст как формулу: значения, начинающиеся
+// с = + - @, табуляции или перевода строки, получают префикс «'».
+// Телефоны вида +79991234567 и отрицательные числа остаются как есть
+func escapeFormula(s string) string {
+	if s == "" {
+		return s
+	}
+	switch s[0] {
+	case '+', '-':
+		if plainValue.MatchString(s) {
+			return s
+		}
+		return "'" + s
+	case '=', '@', '\t', '\r':
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,52 @@
+// Package export пишет табличные выгрузки (CSV, XLSX) построчно,
+// чтобы большие выборки не собирались целиком в памяти.
+package export
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// Writer — построчная запись таблицы. Close дописывает хвост файла
+// (для XLSX — весь архив), поэтому вызывается один раз в конце.
+type Writer interface {
+	WriteHeader(columns []string) error
+	WriteRow(values []any) error
+	Close() error
+}
+
+// NewWriter создаёт writer нужного формата поверх w
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV, "":
+		return newCSVWriter(w), nil
+	case FormatXLSX:
+		return newXLSXWriter(w, "Export")
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// ContentType — MIME-тип файла выгрузки
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// FileName — имя файла вида orders_20251018.csv
+func FileName(prefix, format string, now time.Time) string {
+	if format == "" {
+		format = FormatCSV
+	}
+	return prefix + "_" + now.Format("20060102") + "." + format
+}
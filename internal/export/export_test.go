@@ -0,0 +1,102 @@
+package export_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+
+	"github.com/Ramcache/travel-backend/internal/export"
+)
+
+func writeSample(t *testing.T, format string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := export.NewWriter(format, &buf)
+	require.NoError(t, err)
+
+	title := "Умра; весна"
+	price := 1500.5
+	require.NoError(t, w.WriteHeader([]string{"ID", "Создан", "Тур", "Цена", "Прочитан"}))
+	require.NoError(t, w.WriteRow([]any{1, time.Date(2025, 10, 1, 9, 30, 0, 0, time.UTC), &title, &price, true}))
+	require.NoError(t, w.WriteRow([]any{2, time.Date(2025, 10, 2, 0, 0, 0, 0, time.UTC), (*string)(nil), (*float64)(nil), false}))
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestCSVWriter(t *testing.T) {
+	out := string(writeSample(t, export.FormatCSV))
+	assert.Equal(t,
+		"\ufeffID;Создан;Тур;Цена;Прочитан\n"+
+			"1;2025-10-01 09:30:00;\"Умра; весна\";1500.50;да\n"+
+			"2;2025-10-02 00:00:00;;;нет\n",
+		out)
+}
+
+func TestXLSXWriter(t *testing.T) {
+	f, err := excelize.OpenReader(bytes.NewReader(writeSample(t, export.FormatXLSX)))
+	require.NoError(t, err)
+	defer f.Close()
+
+	rows, err := f.GetRows("Export")
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, []string{"ID", "Создан", "Тур", "Цена", "Прочитан"}, rows[0])
+	assert.Equal(t, "Умра; весна", rows[1][2])
+	assert.Equal(t, "1500.5", rows[1][3])
+	assert.Equal(t, "нет", rows[2][4])
+}
+
+func TestNewWriter_UnknownFormat(t *testing.T) {
+	_, err := export.NewWriter("pdf", &bytes.Buffer{})
+	assert.ErrorIs(t, err, export.ErrUnknownFormat)
+}
+
+func writeFormulas(t *testing.T, format string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := export.NewWriter(format, &buf)
+	require.NoError(t, err)
+
+	hyperlink := `=HYPERLINK("http://evil.example","Открыть")`
+	require.NoError(t, w.WriteHeader([]string{"Имя", "Телефон", "Комментарий", "Сумма"}))
+	require.NoError(t, w.WriteRow([]any{"@SUM(A1:A2)", "+79991234567", &hyperlink, -150.0}))
+	require.NoError(t, w.WriteRow([]any{"-1+1", "+7 (999) 123-45-67", "+1+cmd|' /C calc'!A0", 0.0}))
+	require.NoError(t, w.WriteRow([]any{"-150,50", "\tcmd", "обычный текст", 1.0}))
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestCSVWriter_EscapesFormulas(t *testing.T) {
+	out := string(writeFormulas(t, export.FormatCSV))
+	assert.Equal(t,
+		"\ufeffИмя;Телефон;Комментарий;Сумма\n"+
+			"'@SUM(A1:A2);+79991234567;\"'=HYPERLINK(\"\"http://evil.example\"\",\"\"Открыть\"\")\";-150.00\n"+
+			"'-1+1;+7 (999) 123-45-67;'+1+cmd|' /C calc'!A0;0.00\n"+
+			"-150,50;'\tcmd;обычный текст;1.00\n",
+		out)
+}
+
+func TestXLSXWriter_FormulasStayText(t *testing.T) {
+	f, err := excelize.OpenReader(bytes.NewReader(writeFormulas(t, export.FormatXLSX)))
+	require.NoError(t, err)
+	defer f.Close()
+
+	formula, err := f.GetCellFormula("Export", "C2")
+	require.NoError(t, err)
+	assert.Empty(t, formula)
+	value, err := f.GetCellValue("Export", "C2")
+	require.NoError(t, err)
+	assert.Equal(t, `=HYPERLINK("http://evil.example","Открыть")`, value)
+
+	typ, err := f.GetCellType("Export", "A2")
+	require.NoError(t, err)
+	assert.Equal(t, excelize.CellTypeInlineString, typ)
+	// числа остаются числами
+	typ, err = f.GetCellType("Export", "D2")
+	require.NoError(t, err)
+	assert.NotEqual(t, excelize.CellTypeInlineString, typ)
+}
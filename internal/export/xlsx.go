@@ -0,0 +1,102 @@
+package export
+
+import (
+	"io"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// xlsxWriter пишет строки через StreamWriter excelize: строки сверх
+// внутреннего буфера сбрасываются во временный файл, а не держатся в памяти.
+// Сам архив отдаётся клиенту в Close — формат XLSX не позволяет
+// начать отдачу раньше, чем записана последняя строка.
+type xlsxWriter struct {
+	w      io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+	date   int
+}
+
+func newXLSXWriter(w io.Writer, sheet string) (*xlsxWriter, error) {
+	f := excelize.NewFile()
+	if err := f.SetSheetName("Sheet1", sheet); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	format := "yyyy-mm-dd hh:mm"
+	date, err := f.NewStyle(&excelize.Style{CustomNumFmt: &format})
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &xlsxWriter{w: w, file: f, stream: sw, date: date}, nil
+}
+
+func (x *xlsxWriter) WriteHeader(columns []string) error {
+	values := make([]any, len(columns))
+	for i, c := range columns {
+		values[i] = c
+	}
+	return x.writeRow(values)
+}
+
+func (x *xlsxWriter) WriteRow(values []any) error {
+	cells := make([]any, len(values))
+	for i, v := range values {
+		cells[i] = x.cell(v)
+	}
+	return x.writeRow(cells)
+}
+
+func (x *xlsxWriter) Close() error {
+	defer x.file.Close()
+	if err := x.stream.Flush(); err != nil {
+		return err
+	}
+	_, err := x.file.WriteTo(x.w)
+	return err
+}
+
+func (x *xlsxWriter) writeRow(values []any) error {
+	x.row++
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return err
+	}
+	return x.stream.SetRow(cell, values)
+}
+
+// cell разыменовывает указатели: excelize не умеет писать *string/*float64.
+// Текст пишется строковой ячейкой без формулы, поэтому «=…» из данных клиента не выполняется
+func (x *xlsxWriter) cell(v any) any {
+	switch val := v.(type) {
+	case string:
+		return excelize.Cell{Value: val}
+	case *string:
+		if val == nil {
+			return nil
+		}
+		return excelize.Cell{Value: *val}
+	case *float64:
+		if val == nil {
+			return nil
+		}
+		return *val
+	case bool:
+		if val {
+			return "да"
+		}
+		return "нет"
+	case time.Time:
+		return excelize.Cell{StyleID: x.date, Value: val}
+	default:
+		return v
+	}
+}
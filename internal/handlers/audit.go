@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/repository"
+)
+
+type AuditHandler struct {
+	repo *repository.AuditRepo
+	log  *zap.SugaredLogger
+}
+
+func NewAuditHandler(repo *repository.AuditRepo, log *zap.SugaredLogger) *AuditHandler {
+	return &AuditHandler{repo: repo, log: log}
+}
+
+// List
+// @Summary Audit log (admin)
+// @Description Журнал аудита доступа к чувствительным данным
+// @Tags Admin — Audit
+// @Security Bearer
+// @Produce json
+// @Param entity query string false "Сущность (traveller_profile, ...)"
+// @Param entity_id query int false "ID сущности"
+// @Param limit query int false "Количество записей (по умолчанию 50)"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} models.AuditEntry
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/audit [get]
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	offset, _ := strconv.Atoi(q.Get("offset"))
+	entityID, _ := strconv.Atoi(q.Get("entity_id"))
+
+	list, err := h.repo.List(r.Context(), q.Get("entity"), entityID, limit, offset)
+	if err != nil {
+		h.log.Errorw("Ошибка получения журнала аудита", "err", err)
+		helpers.Error(w, http.StatusInternalServerError, "Не удалось получить журнал аудита")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, list)
+}
@@ -3,13 +3,17 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 
 	"go.uber.org/zap"
 
 	"github.com/Ramcache/travel-backend/internal/helpers"
 	"github.com/Ramcache/travel-backend/internal/models"
 	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/Ramcache/travel-backend/internal/validators"
 )
 
 type AuthHandler struct {
@@ -67,6 +71,8 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} models.AuthResponse
 // @Failure 400 {object} helpers.ErrorData "Некорректный запрос"
 // @Failure 401 {object} helpers.ErrorData "Неверный email или пароль"
+// @Failure 403 {object} helpers.ErrorData "Email не подтверждён (AUTH_REQUIRE_VERIFIED_EMAIL)"
+// @Failure 429 {object} helpers.ErrorData "Слишком много неудачных попыток; ждать — в заголовке Retry-After"
 // @Failure 500 {object} helpers.ErrorData "Ошибка сервера"
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
@@ -76,13 +82,24 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		helpers.Error(w, http.StatusBadRequest, "Некорректный запрос")
 		return
 	}
+	req.IP = helpers.ClientIP(r)
 
-	token, err := h.service.Login(r.Context(), req)
+	resp, err := h.service.Login(r.Context(), req)
 	if err != nil {
 		switch {
 		case errors.Is(err, services.ErrInvalidCredentials):
 			h.log.Warnw("Неудачная попытка входа", "email", req.Email)
 			helpers.Error(w, http.StatusUnauthorized, "Неверный email или пароль")
+		case errors.Is(err, services.ErrEmailNotVerified):
+			helpers.Error(w, http.StatusForbidden, "Подтвердите email: введите код из письма или запросите новый")
+		case errors.Is(err, services.ErrLoginLocked):
+			secs := 1
+			var locked *services.LoginLockedError
+			if errors.As(err, &locked) {
+				secs = int(math.Ceil(locked.RetryAfter.Seconds()))
+			}
+			w.Header().Set("Retry-After", strconv.Itoa(secs))
+			helpers.Error(w, http.StatusTooManyRequests, fmt.Sprintf("Слишком много неудачных попыток входа, повторите через %d сек.", secs))
 		default:
 			h.log.Errorw("Ошибка логина", "email", req.Email, "err", err)
 			helpers.Error(w, http.StatusInternalServerError, "Ошибка входа")
@@ -91,5 +108,89 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	h.log.Infow("Пользователь вошёл в систему", "email", req.Email)
-	helpers.JSON(w, http.StatusOK, models.AuthResponse{Token: token})
+	helpers.JSON(w, http.StatusOK, resp)
+}
+
+// Refresh
+// @Summary Обновить токены
+// @Description Меняет refresh-токен на новую пару токенов; старый refresh-токен больше не действует.
+// @Description Повторное использование уже обменянного refresh-токена закрывает сессию.
+// @Tags System — Auth
+// @Accept json
+// @Produce json
+// @Param data body models.RefreshRequest true "refresh token"
+// @Success 200 {object} models.AuthResponse
+// @Failure 400 {object} helpers.ErrorData "Некорректный запрос"
+// @Failure 401 {object} helpers.ErrorData "Сессия истекла, войдите заново"
+// @Failure 500 {object} helpers.ErrorData "Ошибка сервера"
+// @Router /auth/refresh [post]
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req models.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Errorw("Ошибка декодирования JSON при обновлении токена", "err", err)
+		helpers.Error(w, http.StatusBadRequest, "Некорректный запрос")
+		return
+	}
+	if err := validators.Validate.Struct(req); err != nil {
+		helpers.Error(w, http.StatusBadRequest, validators.TranslateValidationErrors(err))
+		return
+	}
+
+	resp, err := h.service.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidRefreshToken):
+			helpers.Error(w, http.StatusUnauthorized, "Сессия истекла, войдите заново")
+		default:
+			h.log.Errorw("Ошибка обновления токена", "err", err)
+			helpers.Error(w, http.StatusInternalServerError, "Не удалось обновить токен")
+		}
+		return
+	}
+
+	helpers.JSON(w, http.StatusOK, resp)
+}
+
+// Logout
+// @Summary Выйти
+// @Description Закрывает текущую сессию: её access- и refresh-токены перестают действовать.
+// @Tags System — Auth
+// @Security Bearer
+// @Success 204 "No Content"
+// @Failure 401 {object} helpers.ErrorData "Не авторизован"
+// @Failure 500 {object} helpers.ErrorData "Ошибка сервера"
+// @Router /auth/logout [post]
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	userID := helpers.GetUserID(r.Context())
+	sessionID := helpers.GetSessionID(r.Context())
+
+	err := h.service.Logout(r.Context(), sessionID, userID)
+	if err != nil && !errors.Is(err, services.ErrSessionNotFound) {
+		h.log.Errorw("Ошибка выхода", "user_id", userID, "session_id", sessionID, "err", err)
+		helpers.Error(w, http.StatusInternalServerError, "Не удалось выйти")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// LogoutAll
+// @Summary Выйти на всех устройствах
+// @Description Закрывает все сессии пользователя, включая текущую.
+// @Tags System — Auth
+// @Security Bearer
+// @Success 204 "No Content"
+// @Failure 401 {object} helpers.ErrorData "Не авторизован"
+// @Failure 500 {object} helpers.ErrorData "Ошибка сервера"
+// @Router /auth/logout-all [post]
+func (h *AuthHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	userID := helpers.GetUserID(r.Context())
+	if err := h.service.LogoutAll(r.Context(), userID); err != nil {
+		h.log.Errorw("Ошибка выхода со всех устройств", "user_id", userID, "err", err)
+		helpers.Error(w, http.StatusInternalServerError, "Не удалось выйти")
+		return
+	}
+
+	h.log.Infow("Пользователь вышел на всех устройствах", "user_id", userID)
+	w.WriteHeader(http.StatusNoContent)
 }
@@ -9,6 +9,7 @@ import (
 
 	"github.com/Ramcache/travel-backend/internal/handlers"
 	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/services"
 	"github.com/stretchr/testify/mock"
 	"go.uber.org/zap/zaptest"
 )
@@ -23,9 +24,25 @@ func (m *MockAuthService) Register(ctx context.Context, req models.RegisterReque
 	}
 	return nil, args.Error(1)
 }
-func (m *MockAuthService) Login(ctx context.Context, req models.LoginRequest) (string, error) {
+func (m *MockAuthService) Login(ctx context.Context, req models.LoginRequest) (*models.AuthResponse, error) {
 	args := m.Called(ctx, req)
-	return args.String(0), args.Error(1)
+	if v := args.Get(0); v != nil {
+		return v.(*models.AuthResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *MockAuthService) Refresh(ctx context.Context, token string) (*models.AuthResponse, error) {
+	args := m.Called(ctx, token)
+	if v := args.Get(0); v != nil {
+		return v.(*models.AuthResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *MockAuthService) Logout(ctx context.Context, sessionID int64, userID int) error {
+	return m.Called(ctx, sessionID, userID).Error(0)
+}
+func (m *MockAuthService) LogoutAll(ctx context.Context, userID int) error {
+	return m.Called(ctx, userID).Error(0)
 }
 func (m *MockAuthService) GetByID(context.Context, int) (*models.User, error) { return nil, nil }
 func (m *MockAuthService) UpdateProfile(context.Context, int, models.UpdateProfileRequest) (*models.User, error) {
@@ -59,7 +76,7 @@ func TestAuthHandler_Login_OK(t *testing.T) {
 	h := handlers.NewAuthHandler(mockSvc, zaptest.NewLogger(t).Sugar())
 
 	mockSvc.On("Login", mock.Anything, mock.AnythingOfType("models.LoginRequest")).
-		Return("token123", nil)
+		Return(&models.AuthResponse{Token: "token123", RefreshToken: "refresh123", ExpiresIn: 900}, nil)
 
 	body := []byte(`{"email":"a@b.com","password":"123456"}`)
 	req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(body))
@@ -72,3 +89,33 @@ func TestAuthHandler_Login_OK(t *testing.T) {
 		t.Fatalf("expected 200, got %d", w.Code)
 	}
 }
+
+func TestAuthHandler_Refresh_Expired(t *testing.T) {
+	mockSvc := new(MockAuthService)
+	h := handlers.NewAuthHandler(mockSvc, zaptest.NewLogger(t).Sugar())
+
+	mockSvc.On("Refresh", mock.Anything, "stale").Return(nil, services.ErrInvalidRefreshToken)
+
+	body := []byte(`{"refresh_token":"stale"}`)
+	req := httptest.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	h.Refresh(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", w.Code)
+	}
+}
+
+func TestAuthHandler_Refresh_MissingToken(t *testing.T) {
+	mockSvc := new(MockAuthService)
+	h := handlers.NewAuthHandler(mockSvc, zaptest.NewLogger(t).Sugar())
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewReader([]byte(`{}`)))
+	w := httptest.NewRecorder()
+	h.Refresh(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+	mockSvc.AssertNotCalled(t, "Refresh", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,359 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/Ramcache/travel-backend/internal/validators"
+)
+
+type CancellationHandler struct {
+	service *services.CancellationService
+	log     *zap.SugaredLogger
+}
+
+func NewCancellationHandler(service *services.CancellationService, log *zap.SugaredLogger) *CancellationHandler {
+	return &CancellationHandler{service: service, log: log}
+}
+
+func (h *CancellationHandler) writeError(w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, services.ErrOrderNotFound):
+		helpers.Error(w, http.StatusNotFound, "Заказ не найден")
+	case errors.Is(err, services.ErrTripNotFound):
+		helpers.Error(w, http.StatusNotFound, "Тур не найден")
+	case errors.Is(err, services.ErrCancellationNotFound):
+		helpers.Error(w, http.StatusNotFound, "Нет заявки на отмену, ожидающей решения")
+	case errors.Is(err, services.ErrCancellationInProgress):
+		helpers.Error(w, http.StatusConflict, "Заявка уже обрабатывается")
+	case errors.Is(err, services.ErrCancellationPending):
+		helpers.Error(w, http.StatusConflict, "Заявка на отмену уже отправлена")
+	case errors.Is(err, services.ErrCancellationNotAllowed):
+		helpers.Error(w, http.StatusConflict, "Заказ нельзя отменить в текущем статусе")
+	case errors.Is(err, services.ErrTripAlreadyStarted):
+		helpers.Error(w, http.StatusConflict, "Тур уже начался, отмена невозможна")
+	case errors.Is(err, services.ErrInvalidTransition):
+		helpers.Error(w, http.StatusConflict, "Статус заказа изменился, обновите страницу")
+	case errors.Is(err, services.ErrPaymentNotRefundable):
+		helpers.Error(w, http.StatusConflict, "Платёж нельзя вернуть")
+	case helpers.IsInvalidInput(err):
+		helpers.Error(w, http.StatusBadRequest, err.Error())
+	default:
+		h.log.Errorw(msg, "err", err)
+		helpers.Error(w, http.StatusInternalServerError, msg)
+	}
+}
+
+// decodeCancelRequest — тело необязательно: причину отмены можно не указывать
+func decodeCancelRequest(w http.ResponseWriter, r *http.Request) (models.CancelOrderRequest, bool) {
+	var req models.CancelOrderRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			helpers.Error(w, http.StatusBadRequest, "Некорректные данные")
+			return req, false
+		}
+	}
+	if err := validators.Validate.Struct(req); err != nil {
+		helpers.Error(w, http.StatusBadRequest, validators.TranslateValidationErrors(err))
+		return req, false
+	}
+	return req, true
+}
+
+// QuoteOwn
+// @Summary Cancellation quote for my booking
+// @Description Штраф и сумма к возврату при отмене заказа сегодня, правила отмены тура и последняя заявка на отмену
+// @Tags Profile — Orders
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {object} models.CancellationQuote
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData "Заказ не найден"
+// @Failure 500 {object} helpers.ErrorData
+// @Router /profile/orders/{id}/cancellation [get]
+func (h *CancellationHandler) QuoteOwn(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	q, err := h.service.QuoteOwn(r.Context(), helpers.GetUserID(r.Context()), id)
+	if err != nil {
+		h.writeError(w, err, "Не удалось рассчитать отмену")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, q)
+}
+
+// RequestOwn
+// @Summary Request cancellation of my booking
+// @Description Отправить заявку на отмену заказа. Заказ переходит в статус cancellation_requested до решения менеджера; в ответе — рассчитанный возврат
+// @Tags Profile — Orders
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param data body models.CancelOrderRequest false "Причина отмены"
+// @Success 200 {object} models.CancellationQuote
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData "Заказ не найден"
+// @Failure 409 {object} helpers.ErrorData "Заказ нельзя отменить"
+// @Failure 500 {object} helpers.ErrorData
+// @Router /profile/orders/{id}/cancellation [post]
+func (h *CancellationHandler) RequestOwn(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+	req, ok := decodeCancelRequest(w, r)
+	if !ok {
+		return
+	}
+
+	q, err := h.service.RequestOwn(r.Context(), helpers.GetUserID(r.Context()), id, req.Reason)
+	if err != nil {
+		h.writeError(w, err, "Не удалось отправить заявку на отмену")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, q)
+}
+
+// QuoteByToken
+// @Summary Cancellation quote by tracking link
+// @Description Штраф и сумма к возврату при отмене заказа по публичной ссылке
+// @Tags Public — Orders
+// @Produce json
+// @Param token path string true "Tracking token"
+// @Success 200 {object} models.CancellationQuote
+// @Failure 404 {object} helpers.ErrorData "Заказ не найден"
+// @Failure 429 {object} helpers.ErrorData
+// @Failure 500 {object} helpers.ErrorData
+// @Router /orders/track/{token}/cancellation [get]
+func (h *CancellationHandler) QuoteByToken(w http.ResponseWriter, r *http.Request) {
+	q, err := h.service.QuoteByToken(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		h.writeError(w, err, "Не удалось рассчитать отмену")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, q)
+}
+
+// RequestByToken
+// @Summary Request cancellation by tracking link
+// @Description Отправить заявку на отмену заказа по публичной ссылке. Заказ переходит в статус cancellation_requested до решения менеджера
+// @Tags Public — Orders
+// @Accept json
+// @Produce json
+// @Param token path string true "Tracking token"
+// @Param data body models.CancelOrderRequest false "Причина отмены"
+// @Success 200 {object} models.CancellationQuote
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData "Заказ не найден"
+// @Failure 409 {object} helpers.ErrorData "Заказ нельзя отменить"
+// @Failure 429 {object} helpers.ErrorData
+// @Failure 500 {object} helpers.ErrorData
+// @Router /orders/track/{token}/cancellation [post]
+func (h *CancellationHandler) RequestByToken(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeCancelRequest(w, r)
+	if !ok {
+		return
+	}
+
+	q, err := h.service.RequestByToken(r.Context(), chi.URLParam(r, "token"), req.Reason)
+	if err != nil {
+		h.writeError(w, err, "Не удалось отправить заявку на отмену")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, q)
+}
+
+// GetRules
+// @Summary Trip cancellation rules (admin)
+// @Description Штрафы за отмену тура по числу дней до начала
+// @Tags Admin — Cancellations
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Trip ID"
+// @Success 200 {array} models.TripCancellationRule
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData "Тур не найден"
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/trips/{id}/cancellation-rules [get]
+func (h *CancellationHandler) GetRules(w http.ResponseWriter, r *http.Request) {
+	tripID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	rules, err := h.service.Rules(r.Context(), tripID)
+	if err != nil {
+		h.writeError(w, err, "Не удалось получить правила отмены")
+		return
+	}
+	if rules == nil {
+		rules = []models.TripCancellationRule{}
+	}
+	helpers.JSON(w, http.StatusOK, rules)
+}
+
+// SetRules
+// @Summary Replace trip cancellation rules (admin)
+// @Description Заменить штрафы за отмену тура. Отмена не позднее чем за days_before дней до начала — удерживается penalty_percent от стоимости заказа; отмена позже самой ближней ступени — 100%. Пустой список — отмена без штрафа
+// @Tags Admin — Cancellations
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Trip ID"
+// @Param data body []models.TripCancellationRuleRequest true "Правила"
+// @Success 200 {array} models.TripCancellationRule
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData "Тур не найден"
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/trips/{id}/cancellation-rules [put]
+func (h *CancellationHandler) SetRules(w http.ResponseWriter, r *http.Request) {
+	tripID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	var reqs []models.TripCancellationRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректные данные")
+		return
+	}
+
+	rules, err := h.service.SetRules(r.Context(), tripID, reqs)
+	if err != nil {
+		h.writeError(w, err, "Не удалось сохранить правила отмены")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, rules)
+}
+
+// List
+// @Summary Cancellation requests (admin)
+// @Description Заявки клиентов на отмену заказов, новые сверху
+// @Tags Admin — Cancellations
+// @Security Bearer
+// @Produce json
+// @Param status query string false "Фильтр по статусу заявки (pending/processing/approved/rejected)"
+// @Param limit query int false "Количество (20)"
+// @Param offset query int false "Смещение (0)"
+// @Success 200 {object} services.CancellationsWithTotal
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/cancellations [get]
+func (h *CancellationHandler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 {
+		limit = 20
+	}
+	offset, _ := strconv.Atoi(q.Get("offset"))
+
+	result, err := h.service.List(r.Context(), q.Get("status"), limit, offset)
+	if err != nil {
+		h.writeError(w, err, "Не удалось получить заявки на отмену")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, result)
+}
+
+// Approve
+// @Summary Approve order cancellation (admin)
+// @Description Одобрить отмену: вернуть деньги через платёжного провайдера (по умолчанию оплаченное минус штраф) и закрыть заказ. Если оплата была вне системы, refunded_amount меньше refund_amount — остаток возвращается вручную
+// @Tags Admin — Cancellations
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param data body models.ApproveCancellationRequest false "Сумма возврата и комментарий"
+// @Success 200 {object} models.OrderCancellation
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData "Заказ или заявка не найдены"
+// @Failure 409 {object} helpers.ErrorData
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/orders/{id}/cancellation/approve [post]
+func (h *CancellationHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	var req models.ApproveCancellationRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			helpers.Error(w, http.StatusBadRequest, "Некорректные данные")
+			return
+		}
+	}
+
+	c, err := h.service.Approve(r.Context(), id, req, adminActor(r))
+	if err != nil {
+		h.writeError(w, err, "Не удалось одобрить отмену")
+		return
+	}
+
+	h.log.Infow("Отмена заказа одобрена", "order_id", id, "refund", c.RefundAmount, "refunded", c.RefundedAmount)
+	helpers.JSON(w, http.StatusOK, c)
+}
+
+// Reject
+// @Summary Reject order cancellation (admin)
+// @Description Отклонить заявку на отмену — заказ возвращается в прежний статус
+// @Tags Admin — Cancellations
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param data body models.RejectCancellationRequest false "Комментарий"
+// @Success 200 {object} models.OrderCancellation
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData "Заказ или заявка не найдены"
+// @Failure 409 {object} helpers.ErrorData
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/orders/{id}/cancellation/reject [post]
+func (h *CancellationHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	var req models.RejectCancellationRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			helpers.Error(w, http.StatusBadRequest, "Некорректные данные")
+			return
+		}
+	}
+
+	c, err := h.service.Reject(r.Context(), id, req.Comment, adminActor(r))
+	if err != nil {
+		h.writeError(w, err, "Не удалось отклонить отмену")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, c)
+}
+
+func adminActor(r *http.Request) models.Actor {
+	var actor models.Actor
+	if uid := helpers.GetUserID(r.Context()); uid > 0 {
+		actor.ID = &uid
+	}
+	return actor
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/services"
+)
+
+type CustomerHandler struct {
+	service *services.CustomerService
+	log     *zap.SugaredLogger
+}
+
+func NewCustomerHandler(service *services.CustomerService, log *zap.SugaredLogger) *CustomerHandler {
+	return &CustomerHandler{service: service, log: log}
+}
+
+func (h *CustomerHandler) writeError(w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, services.ErrCustomerNotFound):
+		helpers.Error(w, http.StatusNotFound, "Клиент не найден")
+	case helpers.IsInvalidInput(err):
+		helpers.Error(w, http.StatusBadRequest, err.Error())
+	default:
+		h.log.Errorw(msg, "err", err)
+		helpers.Error(w, http.StatusInternalServerError, msg)
+	}
+}
+
+// List
+// @Summary Customers list (admin)
+// @Description Клиенты с количеством заказов, суммой оплаченных заказов и датой последнего обращения
+// @Tags Admin — Customers
+// @Security Bearer
+// @Produce json
+// @Param q query string false "Поиск по имени или телефону"
+// @Param limit query int false "Количество (20)"
+// @Param offset query int false "Смещение (0)"
+// @Success 200 {object} services.CustomersWithTotal
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/customers [get]
+func (h *CustomerHandler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 {
+		limit = 20
+	}
+	offset, _ := strconv.Atoi(q.Get("offset"))
+
+	result, err := h.service.List(r.Context(), q.Get("q"), limit, offset)
+	if err != nil {
+		h.writeError(w, err, "Не удалось получить список клиентов")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, result)
+}
+
+// Get
+// @Summary Get customer (admin)
+// @Tags Admin — Customers
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Customer ID"
+// @Success 200 {object} models.CustomerSummary
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData "Клиент не найден"
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/customers/{id} [get]
+func (h *CustomerHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	c, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		h.writeError(w, err, "Не удалось получить клиента")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, c)
+}
+
+// Timeline
+// @Summary Customer timeline (admin)
+// @Description Заказы, заявки, смены статусов и заметки клиента, новые сверху
+// @Tags Admin — Customers
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Customer ID"
+// @Success 200 {array} models.CustomerTimelineItem
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData "Клиент не найден"
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/customers/{id}/timeline [get]
+func (h *CustomerHandler) Timeline(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	items, err := h.service.Timeline(r.Context(), id)
+	if err != nil {
+		h.writeError(w, err, "Не удалось получить историю клиента")
+		return
+	}
+	if items == nil {
+		items = []models.CustomerTimelineItem{}
+	}
+	helpers.JSON(w, http.StatusOK, items)
+}
+
+// Merge
+// @Summary Merge duplicate customer (admin)
+// @Description Перенести заказы и заявки дубликата в этого клиента. Дубликат скрывается из списка, новые заявки с его телефоном привязываются к основному клиенту
+// @Tags Admin — Customers
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Customer ID (основной)"
+// @Param data body models.MergeCustomersRequest true "Дубликат"
+// @Success 200 {object} models.CustomerSummary
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData "Клиент не найден"
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/customers/{id}/merge [post]
+func (h *CustomerHandler) Merge(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	var req models.MergeCustomersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный JSON")
+		return
+	}
+
+	c, err := h.service.Merge(r.Context(), id, req.DuplicateID)
+	if err != nil {
+		h.writeError(w, err, "Не удалось объединить клиентов")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, c)
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/services"
+)
+
+type DocumentHandler struct {
+	service *services.DocumentService
+	log     *zap.SugaredLogger
+}
+
+func NewDocumentHandler(service *services.DocumentService, log *zap.SugaredLogger) *DocumentHandler {
+	return &DocumentHandler{service: service, log: log}
+}
+
+func (h *DocumentHandler) writeError(w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, services.ErrOrderNotFound):
+		helpers.Error(w, http.StatusNotFound, "Заказ не найден")
+	case errors.Is(err, services.ErrNoCustomerChat):
+		helpers.Error(w, http.StatusConflict, "У заказа нет Telegram-чата клиента")
+	case errors.Is(err, services.ErrDocumentsUnavailable):
+		h.log.Warnw("documents unavailable", "err", err)
+		helpers.Error(w, http.StatusServiceUnavailable, "Генерация документов не настроена")
+	case errors.Is(err, services.ErrTelegramDisabled):
+		helpers.Error(w, http.StatusServiceUnavailable, "Telegram не настроен")
+	case helpers.IsInvalidInput(err):
+		helpers.Error(w, http.StatusBadRequest, err.Error())
+	default:
+		h.log.Errorw(msg, "err", err)
+		helpers.Error(w, http.StatusInternalServerError, msg)
+	}
+}
+
+// Download
+// @Summary Order document PDF (admin)
+// @Description Сформировать PDF по заказу: invoice — счёт, voucher — ваучер, contract — договор
+// @Tags Admin — Documents
+// @Security Bearer
+// @Produce application/pdf
+// @Param id path int true "Order ID"
+// @Param kind path string true "invoice | voucher | contract"
+// @Success 200 {file} file
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData "Заказ не найден"
+// @Failure 503 {object} helpers.ErrorData "Генерация документов не настроена"
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/orders/{id}/documents/{kind}.pdf [get]
+func (h *DocumentHandler) Download(w http.ResponseWriter, r *http.Request) {
+	orderID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	name, pdf, err := h.service.Render(r.Context(), orderID, chi.URLParam(r, "kind"))
+	if err != nil {
+		h.writeError(w, err, "Не удалось сформировать документ")
+		return
+	}
+	writePDF(w, name, pdf)
+}
+
+func writePDF(w http.ResponseWriter, name string, pdf []byte) {
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", name))
+	w.Header().Set("Content-Length", strconv.Itoa(len(pdf)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(pdf)
+}
+
+// Send
+// @Summary Send order document to customer (admin)
+// @Description Отправить PDF-документ в Telegram-чат клиента, из которого оформлен заказ
+// @Tags Admin — Documents
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param kind path string true "invoice | voucher | contract"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData "Заказ не найден"
+// @Failure 409 {object} helpers.ErrorData "У заказа нет Telegram-чата клиента"
+// @Failure 503 {object} helpers.ErrorData
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/orders/{id}/documents/{kind}/send [post]
+func (h *DocumentHandler) Send(w http.ResponseWriter, r *http.Request) {
+	orderID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	if err := h.service.SendToCustomer(r.Context(), orderID, chi.URLParam(r, "kind")); err != nil {
+		h.writeError(w, err, "Не удалось отправить документ")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, map[string]string{"status": "sent"})
+}
+
+// GetContractTemplate
+// @Summary Contract template (admin)
+// @Description Шаблон договора (text/template). is_default=true — используется встроенный шаблон
+// @Tags Admin — Documents
+// @Security Bearer
+// @Produce json
+// @Success 200 {object} models.DocumentTemplate
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/documents/templates/contract [get]
+func (h *DocumentHandler) GetContractTemplate(w http.ResponseWriter, r *http.Request) {
+	tpl, err := h.service.ContractTemplate(r.Context())
+	if err != nil {
+		h.writeError(w, err, "Не удалось получить шаблон договора")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, tpl)
+}
+
+// SetContractTemplate
+// @Summary Update contract template (admin)
+// @Description Сохранить шаблон договора. Шаблон проверяется подстановкой тестового заказа; доступны поля .Order, .Trip, .Company, .Date и функции money, date, traveller_type, inc
+// @Tags Admin — Documents
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param data body models.DocumentTemplateRequest true "Шаблон"
+// @Success 200 {object} models.DocumentTemplate
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/documents/templates/contract [put]
+func (h *DocumentHandler) SetContractTemplate(w http.ResponseWriter, r *http.Request) {
+	var req models.DocumentTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный JSON")
+		return
+	}
+	if req.Body == "" {
+		helpers.Error(w, http.StatusBadRequest, "Шаблон не может быть пустым")
+		return
+	}
+
+	tpl, err := h.service.SetContractTemplate(r.Context(), req.Body, helpers.GetUserID(r.Context()))
+	if err != nil {
+		h.writeError(w, err, "Не удалось сохранить шаблон договора")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, tpl)
+}
+
+// ResetContractTemplate
+// @Summary Reset contract template (admin)
+// @Description Удалить пользовательский шаблон договора и вернуть встроенный
+// @Tags Admin — Documents
+// @Security Bearer
+// @Produce json
+// @Success 200 {object} models.DocumentTemplate
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/documents/templates/contract [delete]
+func (h *DocumentHandler) ResetContractTemplate(w http.ResponseWriter, r *http.Request) {
+	tpl, err := h.service.SetContractTemplate(r.Context(), "", helpers.GetUserID(r.Context()))
+	if err != nil {
+		h.writeError(w, err, "Не удалось сбросить шаблон договора")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, tpl)
+}
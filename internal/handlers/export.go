@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/export"
+	"github.com/Ramcache/travel-backend/internal/helpers"
+)
+
+// parseDateRange читает from/to из query. Дата без времени в to включается целиком
+func parseDateRange(q url.Values) (from, to *time.Time, err error) {
+	if v := q.Get("from"); v != "" {
+		t, err := helpers.ParseDateAny(v)
+		if err != nil {
+			return nil, nil, helpers.ErrInvalidInput("некорректный параметр from")
+		}
+		from = &t
+	}
+	if v := q.Get("to"); v != "" {
+		t, err := helpers.ParseDateAny(v)
+		if err != nil {
+			return nil, nil, helpers.ErrInvalidInput("некорректный параметр to")
+		}
+		if len(v) == len("2006-01-02") {
+			t = t.AddDate(0, 0, 1)
+		}
+		to = &t
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, nil, helpers.ErrInvalidInput("from должен быть раньше to")
+	}
+	return from, to, nil
+}
+
+// sentWriter запоминает, начали ли мы отдавать тело: после этого
+// ошибку уже нельзя вернуть JSON-ом, только оборвать выгрузку
+type sentWriter struct {
+	http.ResponseWriter
+	sent bool
+}
+
+func (w *sentWriter) Write(p []byte) (int, error) {
+	w.sent = true
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *sentWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// streamExport отдаёт выгрузку в формате из ?format= (csv по умолчанию).
+// Ошибки до начала записи отдаются как обычно через onError
+func streamExport(w http.ResponseWriter, r *http.Request, prefix string, log *zap.SugaredLogger,
+	write func(export.Writer) error, onError func(error)) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = export.FormatCSV
+	}
+
+	sw := &sentWriter{ResponseWriter: w}
+	ew, err := export.NewWriter(format, sw)
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Неизвестный формат выгрузки, допустимо: csv, xlsx")
+		return
+	}
+
+	w.Header().Set("Content-Type", export.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.FileName(prefix, format, time.Now())))
+
+	err = write(ew)
+	if err == nil {
+		err = ew.Close()
+	}
+	if err == nil {
+		return
+	}
+	if !sw.sent {
+		w.Header().Del("Content-Disposition")
+		onError(err)
+		return
+	}
+	log.Errorw("Выгрузка прервана", "export", prefix, "err", err)
+}
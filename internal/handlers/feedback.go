@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"encoding/json"
+	"github.com/Ramcache/travel-backend/internal/export"
 	"github.com/Ramcache/travel-backend/internal/helpers"
 	"github.com/Ramcache/travel-backend/internal/models"
 	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/Ramcache/travel-backend/internal/validators"
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
 	"net/http"
@@ -39,8 +41,16 @@ func (h *FeedbackHandler) Create(w http.ResponseWriter, r *http.Request) {
 		helpers.Error(w, http.StatusBadRequest, "Некорректное тело запроса")
 		return
 	}
+	if err := validators.Validate.Struct(req); err != nil {
+		helpers.Error(w, http.StatusBadRequest, validators.TranslateValidationErrors(err))
+		return
+	}
 
 	if err := h.service.Create(r.Context(), req); err != nil {
+		if helpers.IsInvalidInput(err) {
+			helpers.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		h.log.Errorw("Ошибка при сохранении feedback", "err", err)
 		helpers.Error(w, http.StatusInternalServerError, "Не удалось отправить заявку")
 		return
@@ -89,6 +99,45 @@ func (h *FeedbackHandler) List(w http.ResponseWriter, r *http.Request) {
 	helpers.JSON(w, http.StatusOK, result)
 }
 
+// Export feedbacks
+// @Summary Export feedbacks
+// @Description Выгрузка заявок в CSV или XLSX. Фильтры как у списка плюс период по дате создания
+// @Tags Admin — Feedback
+// @Security Bearer
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "csv (по умолчанию) или xlsx"
+// @Param phone query string false "Фильтр по телефону"
+// @Param is_read query bool false "Фильтр по прочитанности"
+// @Param from query string false "Создана с (YYYY-MM-DD или RFC3339)"
+// @Param to query string false "Создана по (YYYY-MM-DD включительно или RFC3339)"
+// @Success 200 {file} file
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/feedbacks/export [get]
+func (h *FeedbackHandler) Export(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	from, to, err := parseDateRange(q)
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	f := models.FeedbackFilter{Phone: q.Get("phone"), From: from, To: to}
+	if v := q.Get("is_read"); v != "" {
+		b := v == "true" || v == "1"
+		f.IsRead = &b
+	}
+
+	streamExport(w, r, "feedbacks", h.log, func(ew export.Writer) error {
+		return h.service.Export(r.Context(), f, ew)
+	}, func(err error) {
+		h.log.Errorw("Ошибка выгрузки заявок", "err", err)
+		helpers.Error(w, http.StatusInternalServerError, "Не удалось выгрузить заявки")
+	})
+}
+
 // MarkAsRead
 // @Summary Mark feedback as read
 // @Tags Admin — Feedback
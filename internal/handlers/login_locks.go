@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/services"
+)
+
+type LoginLockHandler struct {
+	service *services.LoginGuardService
+	log     *zap.SugaredLogger
+}
+
+func NewLoginLockHandler(service *services.LoginGuardService, log *zap.SugaredLogger) *LoginLockHandler {
+	return &LoginLockHandler{service: service, log: log}
+}
+
+// List
+// @Summary Login locks (admin)
+// @Description Email и IP, с которых вход сейчас закрыт после серии неудачных попыток
+// @Tags Admin — Users
+// @Security Bearer
+// @Produce json
+// @Success 200 {array} models.LoginLock
+// @Failure 403 {object} helpers.ErrorData "Нужно право users.write"
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/login-locks [get]
+func (h *LoginLockHandler) List(w http.ResponseWriter, r *http.Request) {
+	list, err := h.service.Locks(r.Context())
+	if err != nil {
+		h.log.Errorw("Не удалось получить блокировки входа", "err", err)
+		helpers.Error(w, http.StatusInternalServerError, "Не удалось получить блокировки входа")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, list)
+}
+
+// Unlock
+// @Summary Unlock login (admin)
+// @Description Снимает блокировку и сбрасывает счётчик неудачных попыток: вход открывается сразу.
+// @Description Передайте email или ip
+// @Tags Admin — Users
+// @Security Bearer
+// @Param email query string false "Email аккаунта"
+// @Param ip query string false "IP-адрес"
+// @Success 204
+// @Failure 400 {object} helpers.ErrorData "Нужен email или ip"
+// @Failure 404 {object} helpers.ErrorData "Неудачных попыток не было"
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/login-locks [delete]
+func (h *LoginLockHandler) Unlock(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	kind, key := models.LoginByEmail, q.Get("email")
+	if key == "" {
+		kind, key = models.LoginByIP, q.Get("ip")
+	}
+
+	err := h.service.Unlock(r.Context(), kind, key)
+	switch {
+	case err == nil:
+		h.log.Infow("Блокировка входа снята", "kind", kind, "key", key, "by", helpers.GetUserID(r.Context()))
+		w.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, services.ErrLoginLockNotFound):
+		helpers.Error(w, http.StatusNotFound, "Для этого email или IP нет неудачных попыток входа")
+	case helpers.IsInvalidInput(err):
+		helpers.Error(w, http.StatusBadRequest, "Укажите email или ip")
+	default:
+		h.log.Errorw("Не удалось снять блокировку входа", "err", err)
+		helpers.Error(w, http.StatusInternalServerError, "Не удалось снять блокировку входа")
+	}
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/services"
+)
+
+type MyOrderHandler struct {
+	service *services.MyOrderService
+	log     *zap.SugaredLogger
+}
+
+func NewMyOrderHandler(service *services.MyOrderService, log *zap.SugaredLogger) *MyOrderHandler {
+	return &MyOrderHandler{service: service, log: log}
+}
+
+func (h *MyOrderHandler) writeError(w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, services.ErrOrderNotFound):
+		helpers.Error(w, http.StatusNotFound, "Заказ не найден")
+	case errors.Is(err, services.ErrDocumentNotReady):
+		helpers.Error(w, http.StatusConflict, "Документ пока недоступен для этого заказа")
+	case errors.Is(err, services.ErrDocumentsUnavailable):
+		h.log.Warnw("documents unavailable", "err", err)
+		helpers.Error(w, http.StatusServiceUnavailable, "Генерация документов не настроена")
+	case helpers.IsInvalidInput(err):
+		helpers.Error(w, http.StatusBadRequest, err.Error())
+	default:
+		h.log.Errorw(msg, "err", err)
+		helpers.Error(w, http.StatusInternalServerError, msg)
+	}
+}
+
+// List
+// @Summary My bookings
+// @Description Заказы текущего пользователя, новые сверху
+// @Tags Profile — Orders
+// @Security Bearer
+// @Produce json
+// @Param limit query int false "Количество (20)"
+// @Param offset query int false "Смещение (0)"
+// @Success 200 {object} services.MyOrdersWithTotal
+// @Failure 401 {object} helpers.ErrorData
+// @Failure 500 {object} helpers.ErrorData
+// @Router /profile/orders [get]
+func (h *MyOrderHandler) List(w http.ResponseWriter, r *http.Request) {
+	uid := helpers.GetUserID(r.Context())
+	q := r.URL.Query()
+
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 {
+		limit = 20
+	}
+	offset, _ := strconv.Atoi(q.Get("offset"))
+
+	result, err := h.service.List(r.Context(), uid, limit, offset)
+	if err != nil {
+		h.writeError(w, err, "Не удалось получить заказы")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, result)
+}
+
+// Get
+// @Summary My booking details
+// @Description Статус, история, документы и оплаты заказа текущего пользователя
+// @Tags Profile — Orders
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {object} models.MyOrderDetails
+// @Failure 404 {object} helpers.ErrorData "Заказ не найден"
+// @Failure 500 {object} helpers.ErrorData
+// @Router /profile/orders/{id} [get]
+func (h *MyOrderHandler) Get(w http.ResponseWriter, r *http.Request) {
+	uid := helpers.GetUserID(r.Context())
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	d, err := h.service.Get(r.Context(), uid, id)
+	if err != nil {
+		h.writeError(w, err, "Не удалось получить заказ")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, d)
+}
+
+// Document
+// @Summary My booking document PDF
+// @Description Счёт (invoice), договор (contract) или ваучер (voucher) по своему заказу
+// @Tags Profile — Orders
+// @Security Bearer
+// @Produce application/pdf
+// @Param id path int true "Order ID"
+// @Param kind path string true "invoice | voucher | contract"
+// @Success 200 {file} file
+// @Failure 404 {object} helpers.ErrorData "Заказ не найден"
+// @Failure 409 {object} helpers.ErrorData "Документ пока недоступен"
+// @Failure 503 {object} helpers.ErrorData "Генерация документов не настроена"
+// @Router /profile/orders/{id}/documents/{kind}.pdf [get]
+func (h *MyOrderHandler) Document(w http.ResponseWriter, r *http.Request) {
+	uid := helpers.GetUserID(r.Context())
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	name, pdf, err := h.service.Document(r.Context(), uid, id, chi.URLParam(r, "kind"))
+	if err != nil {
+		h.writeError(w, err, "Не удалось сформировать документ")
+		return
+	}
+	writePDF(w, name, pdf)
+}
+
+// Track
+// @Summary Track order
+// @Description Публичная ссылка на заказ без входа: тур, история статусов, график оплат и документы. Имя, телефон и туристы скрыты частично
+// @Tags Public — Orders
+// @Produce json
+// @Param token path string true "Tracking token"
+// @Success 200 {object} models.TrackedOrder
+// @Failure 404 {object} helpers.ErrorData "Заказ не найден"
+// @Failure 429 {object} helpers.ErrorData
+// @Router /orders/track/{token} [get]
+func (h *MyOrderHandler) Track(w http.ResponseWriter, r *http.Request) {
+	d, err := h.service.Track(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		h.writeError(w, err, "Не удалось получить заказ")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, d)
+}
+
+// TrackDocument
+// @Summary Tracked order document PDF
+// @Description Счёт, договор или ваучер по публичной ссылке на заказ. Как и в карточке заказа, ФИО и телефон скрыты частично, даты рождения туристов не выводятся
+// @Tags Public — Orders
+// @Produce application/pdf
+// @Param token path string true "Tracking token"
+// @Param kind path string true "invoice | voucher | contract"
+// @Success 200 {file} file
+// @Failure 404 {object} helpers.ErrorData "Заказ не найден"
+// @Failure 409 {object} helpers.ErrorData "Документ пока недоступен"
+// @Failure 503 {object} helpers.ErrorData "Генерация документов не настроена"
+// @Router /orders/track/{token}/documents/{kind}.pdf [get]
+func (h *MyOrderHandler) TrackDocument(w http.ResponseWriter, r *http.Request) {
+	name, pdf, err := h.service.TrackDocument(r.Context(), chi.URLParam(r, "token"), chi.URLParam(r, "kind"))
+	if err != nil {
+		h.writeError(w, err, "Не удалось сформировать документ")
+		return
+	}
+	writePDF(w, name, pdf)
+}
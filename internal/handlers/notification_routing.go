@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/Ramcache/travel-backend/internal/validators"
+)
+
+type NotificationRoutingHandler struct {
+	service *services.NotificationRoutingService
+	log     *zap.SugaredLogger
+}
+
+func NewNotificationRoutingHandler(service *services.NotificationRoutingService, log *zap.SugaredLogger) *NotificationRoutingHandler {
+	return &NotificationRoutingHandler{service: service, log: log}
+}
+
+func (h *NotificationRoutingHandler) writeError(w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, services.ErrDestinationNotFound):
+		helpers.Error(w, http.StatusNotFound, "Чат не найден")
+	case helpers.IsInvalidInput(err):
+		helpers.Error(w, http.StatusBadRequest, err.Error())
+	default:
+		h.log.Errorw(msg, "err", err)
+		helpers.Error(w, http.StatusInternalServerError, msg)
+	}
+}
+
+func decodeDestinationRequest(w http.ResponseWriter, r *http.Request) (models.NotificationDestinationRequest, bool) {
+	var req models.NotificationDestinationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректные данные")
+		return req, false
+	}
+	if err := validators.Validate.Struct(req); err != nil {
+		helpers.Error(w, http.StatusBadRequest, validators.TranslateValidationErrors(err))
+		return req, false
+	}
+	return req, true
+}
+
+// List
+// @Summary Notification destinations (admin)
+// @Description Чаты Telegram для уведомлений менеджерам и правила маршрутизации. Событие уходит во все чаты, где подошло хотя бы одно правило; если не подошло ни одно — в чат по умолчанию (TG_CHAT)
+// @Tags Admin — Notification routing
+// @Security Bearer
+// @Produce json
+// @Success 200 {array} models.NotificationDestination
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/notification-destinations [get]
+func (h *NotificationRoutingHandler) List(w http.ResponseWriter, r *http.Request) {
+	list, err := h.service.List(r.Context())
+	if err != nil {
+		h.writeError(w, err, "Не удалось получить чаты")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, list)
+}
+
+// Get
+// @Summary Notification destination (admin)
+// @Tags Admin — Notification routing
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Destination ID"
+// @Success 200 {object} models.NotificationDestination
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/notification-destinations/{id} [get]
+func (h *NotificationRoutingHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	d, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		h.writeError(w, err, "Не удалось получить чат")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, d)
+}
+
+// Create
+// @Summary Create notification destination (admin)
+// @Description Правило подходит, если совпали все заданные условия: событие, тип тура, город вылета, сумма заказа (границы включительно). В тихие часы уведомления копятся и уходят в их конец
+// @Tags Admin — Notification routing
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param data body models.NotificationDestinationRequest true "Чат и правила"
+// @Success 201 {object} models.NotificationDestination
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/notification-destinations [post]
+func (h *NotificationRoutingHandler) Create(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeDestinationRequest(w, r)
+	if !ok {
+		return
+	}
+
+	d, err := h.service.Create(r.Context(), req)
+	if err != nil {
+		h.writeError(w, err, "Не удалось создать чат")
+		return
+	}
+	helpers.JSON(w, http.StatusCreated, d)
+}
+
+// Update
+// @Summary Update notification destination (admin)
+// @Description Правила чата заменяются переданными
+// @Tags Admin — Notification routing
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Destination ID"
+// @Param data body models.NotificationDestinationRequest true "Чат и правила"
+// @Success 200 {object} models.NotificationDestination
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/notification-destinations/{id} [put]
+func (h *NotificationRoutingHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+	req, ok := decodeDestinationRequest(w, r)
+	if !ok {
+		return
+	}
+
+	d, err := h.service.Update(r.Context(), id, req)
+	if err != nil {
+		h.writeError(w, err, "Не удалось обновить чат")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, d)
+}
+
+// Delete
+// @Summary Delete notification destination (admin)
+// @Description Удаляет чат вместе с правилами. Уже поставленные в очередь уведомления будут доставлены
+// @Tags Admin — Notification routing
+// @Security Bearer
+// @Param id path int true "Destination ID"
+// @Success 204
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/notification-destinations/{id} [delete]
+func (h *NotificationRoutingHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		h.writeError(w, err, "Не удалось удалить чат")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/Ramcache/travel-backend/internal/validators"
+)
+
+type NotificationTemplateHandler struct {
+	service *services.NotificationTemplateService
+	log     *zap.SugaredLogger
+}
+
+func NewNotificationTemplateHandler(service *services.NotificationTemplateService, log *zap.SugaredLogger) *NotificationTemplateHandler {
+	return &NotificationTemplateHandler{service: service, log: log}
+}
+
+func (h *NotificationTemplateHandler) writeError(w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, services.ErrTemplateNotFound):
+		helpers.Error(w, http.StatusNotFound, "Шаблон не найден")
+	case errors.Is(err, services.ErrTemplateExists):
+		helpers.Error(w, http.StatusConflict, "Шаблон для этого события, канала и языка уже есть")
+	case helpers.IsInvalidInput(err):
+		helpers.Error(w, http.StatusBadRequest, err.Error())
+	default:
+		h.log.Errorw(msg, "err", err)
+		helpers.Error(w, http.StatusInternalServerError, msg)
+	}
+}
+
+func decodeTemplateRequest(w http.ResponseWriter, r *http.Request) (models.NotificationTemplateRequest, bool) {
+	var req models.NotificationTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректные данные")
+		return req, false
+	}
+	if err := validators.Validate.Struct(req); err != nil {
+		helpers.Error(w, http.StatusBadRequest, validators.TranslateValidationErrors(err))
+		return req, false
+	}
+	return req, true
+}
+
+// List
+// @Summary Notification templates (admin)
+// @Description Шаблоны уведомлений. Если для события, аудитории, канала и языка шаблона нет или он выключен, отправляется встроенный текст
+// @Tags Admin — Notification templates
+// @Security Bearer
+// @Produce json
+// @Param event query string false "Событие (order.created/order.status_changed/feedback.received/report.digest/auth.password_reset/auth.verification/auth.account_locked/order.cancellation_requested/payment.reminder/order.follow_up)"
+// @Param channel query string false "Канал (telegram/email/sms)"
+// @Param locale query string false "Язык"
+// @Success 200 {array} models.NotificationTemplate
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/notification-templates [get]
+func (h *NotificationTemplateHandler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	list, err := h.service.List(r.Context(), models.NotificationTemplateFilter{
+		EventType: q.Get("event"),
+		Channel:   q.Get("channel"),
+		Locale:    q.Get("locale"),
+	})
+	if err != nil {
+		h.writeError(w, err, "Не удалось получить шаблоны")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, list)
+}
+
+// Get
+// @Summary Notification template (admin)
+// @Tags Admin — Notification templates
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Template ID"
+// @Success 200 {object} models.NotificationTemplate
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/notification-templates/{id} [get]
+func (h *NotificationTemplateHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	t, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		h.writeError(w, err, "Не удалось получить шаблон")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, t)
+}
+
+// Create
+// @Summary Create notification template (admin)
+// @Description Go template: тело для Telegram и email — html/template (значения экранируются), для SMS — text/template. Переменные: .Event, .At, .Order, .Trip, .Feedback, .Customer, .From, .To, .FromTitle, .ToTitle, .Actor, .Comment, .TrackingURL, .TripURL, .AdminURL; функции price, datetime, date, status. Шаблон проверяется на примере заказа
+// @Tags Admin — Notification templates
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param data body models.NotificationTemplateRequest true "Шаблон"
+// @Success 201 {object} models.NotificationTemplate
+// @Failure 400 {object} helpers.ErrorData "Ошибка валидации или в шаблоне"
+// @Failure 409 {object} helpers.ErrorData "Шаблон уже есть"
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/notification-templates [post]
+func (h *NotificationTemplateHandler) Create(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeTemplateRequest(w, r)
+	if !ok {
+		return
+	}
+
+	t, err := h.service.Create(r.Context(), req, adminActor(r).ID)
+	if err != nil {
+		h.writeError(w, err, "Не удалось создать шаблон")
+		return
+	}
+	helpers.JSON(w, http.StatusCreated, t)
+}
+
+// Update
+// @Summary Update notification template (admin)
+// @Tags Admin — Notification templates
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Template ID"
+// @Param data body models.NotificationTemplateRequest true "Шаблон"
+// @Success 200 {object} models.NotificationTemplate
+// @Failure 400 {object} helpers.ErrorData "Ошибка валидации или в шаблоне"
+// @Failure 404 {object} helpers.ErrorData
+// @Failure 409 {object} helpers.ErrorData "Шаблон уже есть"
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/notification-templates/{id} [put]
+func (h *NotificationTemplateHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+	req, ok := decodeTemplateRequest(w, r)
+	if !ok {
+		return
+	}
+
+	t, err := h.service.Update(r.Context(), id, req, adminActor(r).ID)
+	if err != nil {
+		h.writeError(w, err, "Не удалось обновить шаблон")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, t)
+}
+
+// Delete
+// @Summary Delete notification template (admin)
+// @Description После удаления снова отправляется встроенный текст
+// @Tags Admin — Notification templates
+// @Security Bearer
+// @Param id path int true "Template ID"
+// @Success 204
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/notification-templates/{id} [delete]
+func (h *NotificationTemplateHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		h.writeError(w, err, "Не удалось удалить шаблон")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Preview
+// @Summary Preview notification template (admin)
+// @Description Отрендерить шаблон на примере заказа, не сохраняя его
+// @Tags Admin — Notification templates
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param data body models.NotificationTemplateRequest true "Шаблон"
+// @Success 200 {object} models.NotificationPreview
+// @Failure 400 {object} helpers.ErrorData "Ошибка валидации или в шаблоне"
+// @Router /admin/notification-templates/preview [post]
+func (h *NotificationTemplateHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeTemplateRequest(w, r)
+	if !ok {
+		return
+	}
+
+	p, err := h.service.Preview(req)
+	if err != nil {
+		h.writeError(w, err, "Не удалось отрендерить шаблон")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, p)
+}
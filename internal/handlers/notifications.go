@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/services"
+)
+
+type NotificationHandler struct {
+	service *services.NotificationService
+	log     *zap.SugaredLogger
+}
+
+func NewNotificationHandler(service *services.NotificationService, log *zap.SugaredLogger) *NotificationHandler {
+	return &NotificationHandler{service: service, log: log}
+}
+
+func (h *NotificationHandler) writeError(w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, services.ErrNotificationNotFound):
+		helpers.Error(w, http.StatusNotFound, "Уведомление не найдено")
+	case errors.Is(err, services.ErrNotificationSecret):
+		helpers.Error(w, http.StatusConflict, "В уведомлении одноразовая ссылка или код: пусть пользователь запросит новые")
+	case helpers.IsInvalidInput(err):
+		helpers.Error(w, http.StatusBadRequest, err.Error())
+	default:
+		h.log.Errorw(msg, "err", err)
+		helpers.Error(w, http.StatusInternalServerError, msg)
+	}
+}
+
+// List
+// @Summary Notification outbox (admin)
+// @Description Исходящие уведомления, новые сверху. dead — попытки исчерпаны, уведомление можно отправить заново.
+// @Description Текст с одноразовой ссылкой или кодом (auth.password_reset, auth.verification) скрыт
+// @Tags Admin — Notifications
+// @Security Bearer
+// @Produce json
+// @Param status query string false "Фильтр по статусу (pending/sent/dead)"
+// @Param event query string false "Фильтр по событию (order.created/order.status_changed/feedback.received/report.digest/auth.password_reset/auth.verification/auth.account_locked/order.cancellation_requested/payment.reminder/order.follow_up)"
+// @Param channel query string false "Фильтр по каналу (telegram/email/sms)"
+// @Param limit query int false "Количество (20)"
+// @Param offset query int false "Смещение (0)"
+// @Success 200 {object} services.NotificationsWithTotal
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/notifications [get]
+func (h *NotificationHandler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 {
+		limit = 20
+	}
+	offset, _ := strconv.Atoi(q.Get("offset"))
+
+	f := models.NotificationFilter{
+		Status:    q.Get("status"),
+		EventType: q.Get("event"),
+		Channel:   q.Get("channel"),
+	}
+	result, err := h.service.List(r.Context(), f, limit, offset)
+	if err != nil {
+		h.writeError(w, err, "Не удалось получить уведомления")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, result)
+}
+
+// Get
+// @Summary Notification details (admin)
+// @Description Текст, получатели, попытки и последняя ошибка доставки
+// @Tags Admin — Notifications
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Notification ID"
+// @Success 200 {object} models.OutboxNotification
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/notifications/{id} [get]
+func (h *NotificationHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	n, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		h.writeError(w, err, "Не удалось получить уведомление")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, n)
+}
+
+// Resend
+// @Summary Resend notification (admin)
+// @Description Поставить уведомление в очередь заново со сброшенным счётчиком попыток; диспетчер отправит его в ближайший проход
+// @Tags Admin — Notifications
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Notification ID"
+// @Success 200 {object} models.OutboxNotification
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData
+// @Failure 409 {object} helpers.ErrorData "Ссылку на сброс пароля и коды подтверждения повторно не отправляем"
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/notifications/{id}/resend [post]
+func (h *NotificationHandler) Resend(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	n, err := h.service.Resend(r.Context(), id)
+	if err != nil {
+		h.writeError(w, err, "Не удалось отправить уведомление повторно")
+		return
+	}
+	h.log.Infow("notification_resend", "id", id, "admin_id", helpers.GetUserID(r.Context()))
+	helpers.JSON(w, http.StatusOK, n)
+}
@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/services"
+)
+
+type OrderCRMHandler struct {
+	service *services.OrderCRMService
+	log     *zap.SugaredLogger
+}
+
+func NewOrderCRMHandler(service *services.OrderCRMService, log *zap.SugaredLogger) *OrderCRMHandler {
+	return &OrderCRMHandler{service: service, log: log}
+}
+
+func (h *OrderCRMHandler) writeError(w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, services.ErrOrderNotFound):
+		helpers.Error(w, http.StatusNotFound, "Заказ не найден")
+	case errors.Is(err, services.ErrNoteNotFound):
+		helpers.Error(w, http.StatusNotFound, "Заметка не найдена")
+	case errors.Is(err, services.ErrAssignmentChanged):
+		helpers.Error(w, http.StatusConflict, "Ответственный уже изменён, обновите заказ")
+	case helpers.IsInvalidInput(err):
+		helpers.Error(w, http.StatusBadRequest, err.Error())
+	default:
+		h.log.Errorw(msg, "err", err)
+		helpers.Error(w, http.StatusInternalServerError, msg)
+	}
+}
+
+// Assign
+// @Summary Assign order manager (admin)
+// @Description Назначить ответственного менеджера (админ или менеджер). user_id=null снимает назначение. Переназначение попадает в историю заказа
+// @Tags Admin — Orders CRM
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param data body models.AssignOrderRequest true "Менеджер"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData "Заказ не найден"
+// @Failure 409 {object} helpers.ErrorData "Ответственный уже изменён"
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/orders/{id}/assignee [put]
+func (h *OrderCRMHandler) Assign(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	var req models.AssignOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный JSON")
+		return
+	}
+
+	var actor models.Actor
+	if uid := helpers.GetUserID(r.Context()); uid > 0 {
+		actor.ID = &uid
+	}
+
+	if err := h.service.Assign(r.Context(), id, req.UserID, actor, req.Comment); err != nil {
+		h.writeError(w, err, "Не удалось назначить менеджера")
+		return
+	}
+
+	h.log.Infow("Ответственный по заказу изменён", "id", id, "assignee", req.UserID)
+	helpers.JSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// ListNotes
+// @Summary Order notes (admin)
+// @Description Внутренние заметки по заказу, новые сверху
+// @Tags Admin — Orders CRM
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {array} models.OrderNote
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData "Заказ не найден"
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/orders/{id}/notes [get]
+func (h *OrderCRMHandler) ListNotes(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	notes, err := h.service.Notes(r.Context(), id)
+	if err != nil {
+		h.writeError(w, err, "Не удалось получить заметки")
+		return
+	}
+	if notes == nil {
+		notes = []models.OrderNote{}
+	}
+	helpers.JSON(w, http.StatusOK, notes)
+}
+
+// AddNote
+// @Summary Add order note (admin)
+// @Description Добавить внутреннюю заметку; автор — текущий пользователь
+// @Tags Admin — Orders CRM
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param data body models.OrderNoteRequest true "Заметка"
+// @Success 201 {object} models.OrderNote
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData "Заказ не найден"
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/orders/{id}/notes [post]
+func (h *OrderCRMHandler) AddNote(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	var req models.OrderNoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный JSON")
+		return
+	}
+
+	note, err := h.service.AddNote(r.Context(), id, helpers.GetUserID(r.Context()), req.Body)
+	if err != nil {
+		h.writeError(w, err, "Не удалось добавить заметку")
+		return
+	}
+	helpers.JSON(w, http.StatusCreated, note)
+}
+
+// DeleteNote
+// @Summary Delete order note (admin)
+// @Description Удалить свою заметку
+// @Tags Admin — Orders CRM
+// @Security Bearer
+// @Param id path int true "Order ID"
+// @Param noteId path int true "Note ID"
+// @Success 204
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData "Заметка не найдена"
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/orders/{id}/notes/{noteId} [delete]
+func (h *OrderCRMHandler) DeleteNote(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+	noteID, err := strconv.Atoi(chi.URLParam(r, "noteId"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID заметки")
+		return
+	}
+
+	if err := h.service.DeleteNote(r.Context(), id, noteID, helpers.GetUserID(r.Context())); err != nil {
+		h.writeError(w, err, "Не удалось удалить заметку")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetFollowUp
+// @Summary Set next call (admin)
+// @Description Дата следующего звонка клиенту. Когда она наступит, в Telegram придёт напоминание. null снимает напоминание
+// @Tags Admin — Orders CRM
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param data body models.FollowUpRequest true "Дата звонка (RFC3339)"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData "Заказ не найден"
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/orders/{id}/follow-up [put]
+func (h *OrderCRMHandler) SetFollowUp(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	var req models.FollowUpRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный JSON")
+		return
+	}
+
+	if err := h.service.SetFollowUp(r.Context(), id, req.NextCallAt); err != nil {
+		h.writeError(w, err, "Не удалось сохранить дату звонка")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// SetTags
+// @Summary Set order tags (admin)
+// @Description Заменить метки заказа. Метки приводятся к нижнему регистру, повторы убираются
+// @Tags Admin — Orders CRM
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param data body models.OrderTagsRequest true "Метки"
+// @Success 200 {object} models.OrderTagsRequest
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData "Заказ не найден"
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/orders/{id}/tags [put]
+func (h *OrderCRMHandler) SetTags(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	var req models.OrderTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный JSON")
+		return
+	}
+
+	tags, err := h.service.SetTags(r.Context(), id, req.Tags)
+	if err != nil {
+		h.writeError(w, err, "Не удалось сохранить метки")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, models.OrderTagsRequest{Tags: tags})
+}
@@ -2,8 +2,9 @@ package handlers
 
 import (
 	"errors"
+	"github.com/Ramcache/travel-backend/internal/export"
 	"github.com/Ramcache/travel-backend/internal/helpers"
-	"github.com/Ramcache/travel-backend/internal/repository"
+	"github.com/Ramcache/travel-backend/internal/models"
 	"github.com/Ramcache/travel-backend/internal/services"
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
@@ -29,10 +30,14 @@ func NewOrderHandler(service *services.OrderService, log *zap.SugaredLogger) *Or
 // @Produce json
 // @Param limit query int false "Количество записей (по умолчанию 20)"
 // @Param offset query int false "Смещение (по умолчанию 0)"
-// @Param status query string false "Фильтр по статусу (new/in_progress/done/canceled)"
+// @Param status query string false "Фильтр по статусу (new/confirmed/paid/cancellation_requested/completed/cancelled/rejected/refunded)"
 // @Param phone query string false "Фильтр по телефону"
 // @Param is_read query bool false "Фильтр по прочитанности"
+// @Param assigned query string false "Ответственный: me — назначенные мне, none — без менеджера, или ID менеджера"
+// @Param overdue query bool false "Только с просроченным звонком"
+// @Param tag query string false "Фильтр по метке"
 // @Success 200 {object} services.OrdersWithTotal
+// @Failure 400 {object} helpers.ErrorData
 // @Failure 500 {object} helpers.ErrorData "Не удалось получить список заказов"
 // @Router /admin/orders [get]
 func (h *OrderHandler) List(w http.ResponseWriter, r *http.Request) {
@@ -44,17 +49,18 @@ func (h *OrderHandler) List(w http.ResponseWriter, r *http.Request) {
 	}
 	offset, _ := strconv.Atoi(q.Get("offset"))
 
-	status := q.Get("status")
-	phone := q.Get("phone")
-
-	var isRead *bool
-	if v := q.Get("is_read"); v != "" {
-		b := v == "true" || v == "1"
-		isRead = &b
+	f, err := parseOrderFilter(r)
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	result, err := h.service.List(r.Context(), limit, offset, status, phone, isRead)
+	result, err := h.service.List(r.Context(), limit, offset, f)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidStatus) {
+			helpers.Error(w, http.StatusBadRequest, "Некорректный статус")
+			return
+		}
 		h.log.Errorw("Ошибка получения списка заказов", "err", err)
 		helpers.Error(w, http.StatusInternalServerError, "Не удалось получить список заказов")
 		return
@@ -64,16 +70,134 @@ func (h *OrderHandler) List(w http.ResponseWriter, r *http.Request) {
 	helpers.JSON(w, http.StatusOK, result)
 }
 
+// parseOrderFilter — общие фильтры списка и выгрузки заказов
+func parseOrderFilter(r *http.Request) (models.OrderFilter, error) {
+	q := r.URL.Query()
+
+	from, to, err := parseDateRange(q)
+	if err != nil {
+		return models.OrderFilter{}, err
+	}
+
+	f := models.OrderFilter{
+		Status: q.Get("status"),
+		Phone:  q.Get("phone"),
+		Tag:    strings.ToLower(strings.TrimSpace(q.Get("tag"))),
+		From:   from,
+		To:     to,
+	}
+	if v := q.Get("is_read"); v != "" {
+		b := v == "true" || v == "1"
+		f.IsRead = &b
+	}
+	if v := q.Get("overdue"); v != "" {
+		f.OverdueFollowUp = v == "true" || v == "1"
+	}
+
+	switch v := q.Get("assigned"); v {
+	case "":
+	case "me":
+		uid := helpers.GetUserID(r.Context())
+		f.AssignedTo = &uid
+	case "none":
+		f.Unassigned = true
+	default:
+		id, err := strconv.Atoi(v)
+		if err != nil || id <= 0 {
+			return models.OrderFilter{}, helpers.ErrInvalidInput("некорректный параметр assigned")
+		}
+		f.AssignedTo = &id
+	}
+	return f, nil
+}
+
+// Export
+// @Summary Export orders (admin)
+// @Description Выгрузка заказов в CSV или XLSX с названием и ценой тура. Фильтры как у списка плюс период по дате создания
+// @Tags Admin — Orders
+// @Security Bearer
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "csv (по умолчанию) или xlsx"
+// @Param status query string false "Фильтр по статусу"
+// @Param phone query string false "Фильтр по телефону"
+// @Param is_read query bool false "Фильтр по прочитанности"
+// @Param assigned query string false "Ответственный: me, none или ID менеджера"
+// @Param overdue query bool false "Только с просроченным звонком"
+// @Param tag query string false "Фильтр по метке"
+// @Param from query string false "Создан с (YYYY-MM-DD или RFC3339)"
+// @Param to query string false "Создан по (YYYY-MM-DD включительно или RFC3339)"
+// @Success 200 {file} file
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/orders/export [get]
+func (h *OrderHandler) Export(w http.ResponseWriter, r *http.Request) {
+	f, err := parseOrderFilter(r)
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	streamExport(w, r, "orders", h.log, func(ew export.Writer) error {
+		return h.service.Export(r.Context(), f, ew)
+	}, func(err error) {
+		if errors.Is(err, services.ErrInvalidStatus) {
+			helpers.Error(w, http.StatusBadRequest, "Некорректный статус")
+			return
+		}
+		h.log.Errorw("Ошибка выгрузки заказов", "err", err)
+		helpers.Error(w, http.StatusInternalServerError, "Не удалось выгрузить заказы")
+	})
+}
+
+// Get
+// @Summary Get order (admin)
+// @Description Получить заказ со списком туристов
+// @Tags Admin — Orders
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {object} models.Order
+// @Failure 400 {object} helpers.ErrorData "Некорректный ID"
+// @Failure 404 {object} helpers.ErrorData "Заказ не найден"
+// @Failure 500 {object} helpers.ErrorData "Не удалось получить заказ"
+// @Router /admin/orders/{id} [get]
+func (h *OrderHandler) Get(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.log.Warnw("Некорректный ID заказа", "id", idStr, "err", err)
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	order, err := h.service.GetByID(r.Context(), id)
+	switch {
+	case errors.Is(err, services.ErrOrderNotFound):
+		helpers.Error(w, http.StatusNotFound, "Заказ не найден")
+		return
+	case err != nil:
+		h.log.Errorw("Ошибка получения заказа", "id", id, "err", err)
+		helpers.Error(w, http.StatusInternalServerError, "Не удалось получить заказ")
+		return
+	}
+
+	helpers.JSON(w, http.StatusOK, order)
+}
+
 // UpdateStatus
 // @Summary Update order status
-// @Description Обновить статус заказа (admin)
+// @Description Перевести заказ в новый статус (admin). Допустимые переходы: new→confirmed|rejected|cancelled, confirmed→paid|cancelled, paid→completed|refunded.
+// @Description В cancellation_requested и из него заказ переходит только через заявку на отмену (/admin/orders/{id}/cancellation)
 // @Tags Admin — Orders
 // @Security Bearer
 // @Param id path int true "Order ID"
-// @Param status query string true "Новый статус (new/in_progress/done/canceled)"
+// @Param status query string true "Новый статус (confirmed/paid/completed/cancelled/rejected/refunded)"
+// @Param comment query string false "Комментарий к смене статуса"
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} helpers.ErrorData "Некорректные данные"
 // @Failure 404 {object} helpers.ErrorData "Заказ не найден"
+// @Failure 409 {object} helpers.ErrorData "Недопустимый переход статуса"
 // @Failure 500 {object} helpers.ErrorData "Не удалось обновить статус"
 // @Router /admin/orders/{id}/status [post]
 func (h *OrderHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
@@ -91,12 +215,25 @@ func (h *OrderHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.UpdateStatus(r.Context(), id, status); err != nil {
-		if errors.Is(err, repository.ErrNotFound) {
-			h.log.Warnw("Заказ не найден при обновлении статуса", "id", id)
-			helpers.Error(w, http.StatusNotFound, "Заказ не найден")
-			return
-		}
+	var actor models.Actor
+	if uid := helpers.GetUserID(r.Context()); uid > 0 {
+		actor.ID = &uid
+	}
+
+	err = h.service.SetStatus(r.Context(), id, status, actor, r.URL.Query().Get("comment"))
+	switch {
+	case errors.Is(err, services.ErrInvalidStatus):
+		helpers.Error(w, http.StatusBadRequest, "Некорректный статус")
+		return
+	case errors.Is(err, services.ErrOrderNotFound):
+		h.log.Warnw("Заказ не найден при обновлении статуса", "id", id)
+		helpers.Error(w, http.StatusNotFound, "Заказ не найден")
+		return
+	case errors.Is(err, services.ErrInvalidTransition):
+		h.log.Warnw("Недопустимый переход статуса заказа", "id", id, "status", status, "err", err)
+		helpers.Error(w, http.StatusConflict, "Недопустимый переход статуса")
+		return
+	case err != nil:
 		h.log.Errorw("Ошибка при обновлении статуса заказа", "id", id, "err", err)
 		helpers.Error(w, http.StatusInternalServerError, "Не удалось обновить статус")
 		return
@@ -106,6 +243,41 @@ func (h *OrderHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 	helpers.JSON(w, http.StatusOK, map[string]string{"status": "ok"})
 }
 
+// History
+// @Summary Get order status history (admin)
+// @Description История изменения статусов заказа: кто, когда и с каким комментарием
+// @Tags Admin — Orders
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {array} models.OrderStatusChange
+// @Failure 400 {object} helpers.ErrorData "Некорректный ID"
+// @Failure 404 {object} helpers.ErrorData "Заказ не найден"
+// @Failure 500 {object} helpers.ErrorData "Не удалось получить историю"
+// @Router /admin/orders/{id}/history [get]
+func (h *OrderHandler) History(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.log.Warnw("Некорректный ID заказа", "id", idStr, "err", err)
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	list, err := h.service.History(r.Context(), id)
+	switch {
+	case errors.Is(err, services.ErrOrderNotFound):
+		helpers.Error(w, http.StatusNotFound, "Заказ не найден")
+		return
+	case err != nil:
+		h.log.Errorw("Ошибка получения истории заказа", "id", id, "err", err)
+		helpers.Error(w, http.StatusInternalServerError, "Не удалось получить историю")
+		return
+	}
+
+	helpers.JSON(w, http.StatusOK, list)
+}
+
 // MarkAsRead
 // @Summary Mark order as read
 // @Description Пометить заказ как прочитанный
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/Ramcache/travel-backend/internal/validators"
+)
+
+type PasswordHandler struct {
+	service *services.PasswordService
+	log     *zap.SugaredLogger
+}
+
+func NewPasswordHandler(service *services.PasswordService, log *zap.SugaredLogger) *PasswordHandler {
+	return &PasswordHandler{service: service, log: log}
+}
+
+// Forgot
+// @Summary Запросить сброс пароля
+// @Description Отправляет на email пользователя одноразовую ссылку для смены пароля (событие auth.password_reset).
+// @Description Ответ одинаковый для зарегистрированных и незнакомых адресов.
+// @Tags System — Auth
+// @Accept json
+// @Param data body models.ForgotPasswordRequest true "email"
+// @Success 204 "No Content"
+// @Failure 400 {object} helpers.ErrorData "Некорректный запрос"
+// @Failure 429 {object} helpers.ErrorData "Слишком много запросов"
+// @Failure 500 {object} helpers.ErrorData "Ошибка сервера"
+// @Router /auth/password/forgot [post]
+func (h *PasswordHandler) Forgot(w http.ResponseWriter, r *http.Request) {
+	var req models.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный запрос")
+		return
+	}
+	if err := validators.Validate.Struct(req); err != nil {
+		helpers.Error(w, http.StatusBadRequest, validators.TranslateValidationErrors(err))
+		return
+	}
+
+	if err := h.service.Forgot(r.Context(), req.Email); err != nil {
+		h.log.Errorw("Ошибка запроса сброса пароля", "err", err)
+		helpers.Error(w, http.StatusInternalServerError, "Не удалось отправить ссылку, попробуйте позже")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Reset
+// @Summary Задать новый пароль по ссылке
+// @Description Токен из ссылки действует один раз. После смены пароля все сессии пользователя закрываются.
+// @Tags System — Auth
+// @Accept json
+// @Param data body models.ResetPasswordRequest true "токен и новый пароль"
+// @Success 204 "No Content"
+// @Failure 400 {object} helpers.ErrorData "Ссылка недействительна или устарела"
+// @Failure 429 {object} helpers.ErrorData "Слишком много запросов"
+// @Failure 500 {object} helpers.ErrorData "Ошибка сервера"
+// @Router /auth/password/reset [post]
+func (h *PasswordHandler) Reset(w http.ResponseWriter, r *http.Request) {
+	var req models.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный запрос")
+		return
+	}
+	if err := validators.Validate.Struct(req); err != nil {
+		helpers.Error(w, http.StatusBadRequest, validators.TranslateValidationErrors(err))
+		return
+	}
+
+	err := h.service.Reset(r.Context(), req.Token, req.Password)
+	switch {
+	case errors.Is(err, services.ErrInvalidResetToken), errors.Is(err, services.ErrNotFound):
+		helpers.Error(w, http.StatusBadRequest, "Ссылка для смены пароля недействительна или устарела")
+		return
+	case err != nil:
+		h.log.Errorw("Ошибка сброса пароля", "err", err)
+		helpers.Error(w, http.StatusInternalServerError, "Не удалось сменить пароль")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Change
+// @Summary Сменить пароль
+// @Description Требует текущий пароль. Остальные сессии пользователя закрываются, текущая остаётся.
+// @Tags System — Auth
+// @Security Bearer
+// @Accept json
+// @Param data body models.ChangePasswordRequest true "текущий и новый пароль"
+// @Success 204 "No Content"
+// @Failure 400 {object} helpers.ErrorData "Некорректный запрос"
+// @Failure 401 {object} helpers.ErrorData "Не авторизован"
+// @Failure 403 {object} helpers.ErrorData "Неверный текущий пароль"
+// @Failure 429 {object} helpers.ErrorData "Слишком много запросов"
+// @Failure 500 {object} helpers.ErrorData "Ошибка сервера"
+// @Router /profile/password [put]
+func (h *PasswordHandler) Change(w http.ResponseWriter, r *http.Request) {
+	uid := helpers.GetUserID(r.Context())
+
+	var req models.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный запрос")
+		return
+	}
+	if err := validators.Validate.Struct(req); err != nil {
+		helpers.Error(w, http.StatusBadRequest, validators.TranslateValidationErrors(err))
+		return
+	}
+
+	err := h.service.Change(r.Context(), uid, helpers.GetSessionID(r.Context()), req)
+	switch {
+	case errors.Is(err, services.ErrWrongPassword):
+		helpers.Error(w, http.StatusForbidden, "Неверный текущий пароль")
+		return
+	case errors.Is(err, services.ErrNotFound):
+		helpers.Error(w, http.StatusNotFound, "Пользователь не найден")
+		return
+	case err != nil:
+		h.log.Errorw("Ошибка смены пароля", "uid", uid, "err", err)
+		helpers.Error(w, http.StatusInternalServerError, "Не удалось сменить пароль")
+		return
+	}
+
+	h.log.Infow("Пароль изменён", "uid", uid)
+	w.WriteHeader(http.StatusNoContent)
+}
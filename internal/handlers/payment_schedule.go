@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/services"
+)
+
+type PaymentScheduleHandler struct {
+	service *services.PaymentScheduleService
+	log     *zap.SugaredLogger
+}
+
+func NewPaymentScheduleHandler(service *services.PaymentScheduleService, log *zap.SugaredLogger) *PaymentScheduleHandler {
+	return &PaymentScheduleHandler{service: service, log: log}
+}
+
+func (h *PaymentScheduleHandler) writeError(w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, services.ErrTripNotFound):
+		helpers.Error(w, http.StatusNotFound, "Тур не найден")
+	case errors.Is(err, services.ErrOrderNotFound):
+		helpers.Error(w, http.StatusNotFound, "Заказ не найден")
+	case errors.Is(err, services.ErrScheduleHasPayments):
+		helpers.Error(w, http.StatusConflict, "По графику уже есть оплаты")
+	case helpers.IsInvalidInput(err):
+		helpers.Error(w, http.StatusBadRequest, err.Error())
+	default:
+		h.log.Errorw(msg, "err", err)
+		helpers.Error(w, http.StatusInternalServerError, msg)
+	}
+}
+
+// GetRules
+// @Summary Trip payment rules (admin)
+// @Description Правила графика платежей тура (задаток, остаток)
+// @Tags Admin — Payment schedule
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Trip ID"
+// @Success 200 {array} models.TripPaymentRule
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData "Тур не найден"
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/trips/{id}/payment-rules [get]
+func (h *PaymentScheduleHandler) GetRules(w http.ResponseWriter, r *http.Request) {
+	tripID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	rules, err := h.service.Rules(r.Context(), tripID)
+	if err != nil {
+		h.writeError(w, err, "Не удалось получить правила оплаты")
+		return
+	}
+	if rules == nil {
+		rules = []models.TripPaymentRule{}
+	}
+	helpers.JSON(w, http.StatusOK, rules)
+}
+
+// SetRules
+// @Summary Replace trip payment rules (admin)
+// @Description Заменить правила графика платежей тура. due_anchor: booking/start_date/booking_deadline; правило без percent и amount — платёж на остаток. Пустой список — оплата одним платежом
+// @Tags Admin — Payment schedule
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Trip ID"
+// @Param data body []models.TripPaymentRuleRequest true "Правила"
+// @Success 200 {array} models.TripPaymentRule
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData "Тур не найден"
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/trips/{id}/payment-rules [put]
+func (h *PaymentScheduleHandler) SetRules(w http.ResponseWriter, r *http.Request) {
+	tripID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	var req []models.TripPaymentRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректные данные")
+		return
+	}
+
+	rules, err := h.service.SetRules(r.Context(), tripID, req)
+	if err != nil {
+		h.writeError(w, err, "Не удалось сохранить правила оплаты")
+		return
+	}
+
+	h.log.Infow("Правила оплаты тура обновлены", "trip_id", tripID, "count", len(rules))
+	helpers.JSON(w, http.StatusOK, rules)
+}
+
+// GetSchedule
+// @Summary Order payment schedule (admin)
+// @Description График платежей заказа: суммы, сроки, оплачено и остаток
+// @Tags Admin — Payment schedule
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {object} models.OrderSchedule
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData "Заказ не найден"
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/orders/{id}/schedule [get]
+func (h *PaymentScheduleHandler) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	orderID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	sch, err := h.service.Schedule(r.Context(), orderID)
+	if err != nil {
+		h.writeError(w, err, "Не удалось получить график платежей")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, sch)
+}
+
+// Regenerate
+// @Summary Regenerate order payment schedule (admin)
+// @Description Пересоздать график заказа по текущим правилам тура. Недоступно, если по графику уже были оплаты
+// @Tags Admin — Payment schedule
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {object} models.OrderSchedule
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData "Заказ не найден"
+// @Failure 409 {object} helpers.ErrorData "По графику уже есть оплаты"
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/orders/{id}/schedule [post]
+func (h *PaymentScheduleHandler) Regenerate(w http.ResponseWriter, r *http.Request) {
+	orderID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	sch, err := h.service.Regenerate(r.Context(), orderID)
+	if err != nil {
+		h.writeError(w, err, "Не удалось пересоздать график платежей")
+		return
+	}
+
+	h.log.Infow("График платежей пересоздан", "order_id", orderID, "installments", len(sch.Installments))
+	helpers.JSON(w, http.StatusOK, sch)
+}
+
+// Overdue
+// @Summary Overdue installments (admin)
+// @Description Просроченные платежи по активным заказам
+// @Tags Admin — Payment schedule
+// @Security Bearer
+// @Produce json
+// @Param limit query int false "Количество записей (по умолчанию 50)"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} models.OverdueInstallment
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/installments/overdue [get]
+func (h *PaymentScheduleHandler) Overdue(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	offset, _ := strconv.Atoi(q.Get("offset"))
+
+	list, err := h.service.Overdue(r.Context(), limit, offset)
+	if err != nil {
+		h.writeError(w, err, "Не удалось получить просроченные платежи")
+		return
+	}
+	if list == nil {
+		list = []models.OverdueInstallment{}
+	}
+	helpers.JSON(w, http.StatusOK, list)
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/payments"
+	"github.com/Ramcache/travel-backend/internal/services"
+)
+
+// ограничение размера тела вебхука
+const maxWebhookBody = 1 << 20
+
+type PaymentHandler struct {
+	service *services.PaymentService
+	log     *zap.SugaredLogger
+}
+
+func NewPaymentHandler(service *services.PaymentService, log *zap.SugaredLogger) *PaymentHandler {
+	return &PaymentHandler{service: service, log: log}
+}
+
+// writeError — общий маппинг ошибок платёжного сервиса
+func (h *PaymentHandler) writeError(w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, services.ErrOrderNotFound):
+		helpers.Error(w, http.StatusNotFound, "Заказ не найден")
+	case errors.Is(err, services.ErrPaymentNotFound):
+		helpers.Error(w, http.StatusNotFound, "Платёж не найден")
+	case errors.Is(err, services.ErrOrderNotPayable):
+		helpers.Error(w, http.StatusConflict, "Заказ нельзя оплатить в текущем статусе")
+	case errors.Is(err, services.ErrPaymentNotRefundable):
+		helpers.Error(w, http.StatusConflict, "Платёж нельзя вернуть")
+	case errors.Is(err, payments.ErrRefundRejected):
+		helpers.Error(w, http.StatusConflict, "Провайдер отклонил возврат")
+	case helpers.IsInvalidInput(err):
+		helpers.Error(w, http.StatusBadRequest, err.Error())
+	default:
+		h.log.Errorw(msg, "err", err)
+		helpers.Error(w, http.StatusInternalServerError, msg)
+	}
+}
+
+// Create
+// @Summary Create order payment (admin)
+// @Description Создать платёж у провайдера и получить ссылку на оплату. Заказ должен быть в статусе confirmed
+// @Tags Admin — Payments
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param data body models.CreatePaymentRequest false "Сумма (по умолчанию — остаток) и return_url"
+// @Success 201 {object} models.Payment
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData "Заказ не найден"
+// @Failure 409 {object} helpers.ErrorData "Заказ нельзя оплатить в текущем статусе"
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/orders/{id}/payments [post]
+func (h *PaymentHandler) Create(w http.ResponseWriter, r *http.Request) {
+	orderID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	var req models.CreatePaymentRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			helpers.Error(w, http.StatusBadRequest, "Некорректные данные")
+			return
+		}
+	}
+
+	p, err := h.service.CreateForOrder(r.Context(), orderID, req)
+	if err != nil {
+		h.writeError(w, err, "Не удалось создать платёж")
+		return
+	}
+
+	h.log.Infow("Платёж создан", "order_id", orderID, "payment_id", p.ID)
+	helpers.JSON(w, http.StatusCreated, p)
+}
+
+// ListByOrder
+// @Summary Order payments (admin)
+// @Description Платежи по заказу
+// @Tags Admin — Payments
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {array} models.Payment
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData "Заказ не найден"
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/orders/{id}/payments [get]
+func (h *PaymentHandler) ListByOrder(w http.ResponseWriter, r *http.Request) {
+	orderID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	list, err := h.service.ListByOrder(r.Context(), orderID)
+	if err != nil {
+		h.writeError(w, err, "Не удалось получить платежи")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, list)
+}
+
+// Refund
+// @Summary Refund payment (admin)
+// @Description Вернуть платёж полностью или частично через провайдера.
+// @Description Если провайдер не ответил, возврат остаётся незавершённым: повторный запрос проведёт его, а не создаст новый
+// @Tags Admin — Payments
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Payment ID"
+// @Param data body models.RefundPaymentRequest false "Сумма возврата (по умолчанию — весь остаток) и комментарий"
+// @Success 200 {object} models.Payment
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData "Платёж не найден"
+// @Failure 409 {object} helpers.ErrorData "Платёж нельзя вернуть или провайдер отклонил возврат"
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/payments/{id}/refund [post]
+func (h *PaymentHandler) Refund(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	var req models.RefundPaymentRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			helpers.Error(w, http.StatusBadRequest, "Некорректные данные")
+			return
+		}
+	}
+
+	var actor models.Actor
+	if uid := helpers.GetUserID(r.Context()); uid > 0 {
+		actor.ID = &uid
+	}
+
+	p, err := h.service.Refund(r.Context(), id, req.Amount, actor, req.Comment)
+	if err != nil {
+		h.writeError(w, err, "Не удалось выполнить возврат")
+		return
+	}
+
+	h.log.Infow("Возврат выполнен", "payment_id", id, "refunded", p.RefundedAmount)
+	helpers.JSON(w, http.StatusOK, p)
+}
+
+// Webhook
+// @Summary Payment provider webhook
+// @Description Уведомление платёжного провайдера о смене статуса платежа. Подпись проверяется, повторные уведомления игнорируются
+// @Tags Payments
+// @Accept json
+// @Success 200
+// @Failure 400 {object} helpers.ErrorData "Некорректные данные или сумма не совпадает с платежом"
+// @Failure 401 {object} helpers.ErrorData "Неверная подпись"
+// @Failure 500 {object} helpers.ErrorData
+// @Router /payments/webhook [post]
+func (h *PaymentHandler) Webhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBody))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректные данные")
+		return
+	}
+
+	err = h.service.HandleWebhook(r.Context(), r.Header, body)
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusOK)
+	case errors.Is(err, payments.ErrInvalidSignature):
+		h.log.Warnw("Вебхук оплаты с неверной подписью", "ip", r.RemoteAddr)
+		helpers.Error(w, http.StatusUnauthorized, "Неверная подпись")
+	case errors.Is(err, services.ErrPaymentAmountMismatch):
+		// событие не применяется: сумма не та, что выставлена клиенту
+		h.log.Errorw("Сумма в вебхуке оплаты не совпадает с платежом", "err", err)
+		helpers.Error(w, http.StatusBadRequest, "Сумма не совпадает с платежом")
+	case errors.Is(err, payments.ErrUnsupportedEvent), errors.Is(err, services.ErrPaymentNotFound):
+		// отвечаем 200, чтобы провайдер не повторял доставку бесполезного события
+		h.log.Warnw("Вебхук оплаты пропущен", "err", err)
+		w.WriteHeader(http.StatusOK)
+	default:
+		h.log.Errorw("Ошибка обработки вебхука оплаты", "err", err)
+		helpers.Error(w, http.StatusInternalServerError, "Не удалось обработать уведомление")
+	}
+}
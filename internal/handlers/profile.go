@@ -46,6 +46,9 @@ func (h *ProfileHandler) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// по правам фронтенд решает, какие разделы админки показывать
+	u.Permissions = helpers.GetPermissions(r.Context())
+
 	h.log.Infow("Профиль успешно загружен", "uid", uid)
 	helpers.JSON(w, http.StatusOK, u)
 }
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/services"
+)
+
+type ReportHandler struct {
+	service *services.ReportService
+	log     *zap.SugaredLogger
+}
+
+func NewReportHandler(service *services.ReportService, log *zap.SugaredLogger) *ReportHandler {
+	return &ReportHandler{service: service, log: log}
+}
+
+// Digest
+// @Summary Business digest (admin)
+// @Description Сводка за вчера (daily) или прошлую неделю с понедельника (weekly): заказы по статусам, заявки на консультацию, туры по просмотрам с конверсией в заказы (заказов на 100 просмотров), вылеты на две недели вперёд с бронями. Та же сводка рассылается по расписанию (REPORT_DIGEST_*)
+// @Tags Admin — Stats
+// @Security Bearer
+// @Produce json
+// @Param period query string false "daily (по умолчанию) или weekly"
+// @Success 200 {object} models.Digest
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/reports/digest [get]
+func (h *ReportHandler) Digest(w http.ResponseWriter, r *http.Request) {
+	period := r.URL.Query().Get("period")
+	if period == "" {
+		period = models.DigestDaily
+	}
+
+	d, err := h.service.Digest(r.Context(), period, time.Now())
+	if err != nil {
+		if helpers.IsInvalidInput(err) {
+			helpers.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.log.Errorw("Не удалось собрать сводку", "period", period, "err", err)
+		helpers.Error(w, http.StatusInternalServerError, "Не удалось собрать сводку")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, d)
+}
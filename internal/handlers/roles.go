@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/Ramcache/travel-backend/internal/validators"
+)
+
+type RoleHandler struct {
+	service *services.RoleService
+	log     *zap.SugaredLogger
+}
+
+func NewRoleHandler(service *services.RoleService, log *zap.SugaredLogger) *RoleHandler {
+	return &RoleHandler{service: service, log: log}
+}
+
+func (h *RoleHandler) writeError(w http.ResponseWriter, err error, msg string) {
+	status, _, _ := helpers.MapPgErr(err)
+	switch {
+	case errors.Is(err, services.ErrRoleNotFound):
+		helpers.Error(w, http.StatusNotFound, "Роль не найдена")
+	case errors.Is(err, services.ErrRoleProtected):
+		helpers.Error(w, http.StatusConflict, "Встроенную роль нельзя удалить или переименовать, а права роли admin — изменить")
+	case errors.Is(err, services.ErrRoleInUse):
+		helpers.Error(w, http.StatusConflict, "Роль назначена пользователям: сначала смените им роль")
+	case status == http.StatusConflict:
+		helpers.Error(w, http.StatusConflict, "Роль с таким названием уже есть")
+	case helpers.IsInvalidInput(err):
+		helpers.Error(w, http.StatusBadRequest, err.Error())
+	default:
+		h.log.Errorw(msg, "err", err)
+		helpers.Error(w, http.StatusInternalServerError, msg)
+	}
+}
+
+func decodeRoleRequest(w http.ResponseWriter, r *http.Request) (models.RoleRequest, bool) {
+	var req models.RoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректные данные")
+		return req, false
+	}
+	if err := validators.Validate.Struct(req); err != nil {
+		helpers.Error(w, http.StatusBadRequest, validators.TranslateValidationErrors(err))
+		return req, false
+	}
+	return req, true
+}
+
+// List
+// @Summary Roles (admin)
+// @Description Роли с правами и числом пользователей. Права проверяются на каждом запросе: изменения действуют сразу
+// @Tags Admin — Roles
+// @Security Bearer
+// @Produce json
+// @Success 200 {array} models.Role
+// @Failure 403 {object} helpers.ErrorData "Нужно право roles.manage"
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/roles [get]
+func (h *RoleHandler) List(w http.ResponseWriter, r *http.Request) {
+	list, err := h.service.List(r.Context())
+	if err != nil {
+		h.writeError(w, err, "Не удалось получить роли")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, list)
+}
+
+// Permissions
+// @Summary Permissions catalog (admin)
+// @Description Все права, которые можно выдать роли
+// @Tags Admin — Roles
+// @Security Bearer
+// @Produce json
+// @Success 200 {array} models.Permission
+// @Failure 403 {object} helpers.ErrorData "Нужно право roles.manage"
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/permissions [get]
+func (h *RoleHandler) Permissions(w http.ResponseWriter, r *http.Request) {
+	list, err := h.service.Permissions(r.Context())
+	if err != nil {
+		h.writeError(w, err, "Не удалось получить права")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, list)
+}
+
+// Get
+// @Summary Role (admin)
+// @Tags Admin — Roles
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Role ID"
+// @Success 200 {object} models.Role
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/roles/{id} [get]
+func (h *RoleHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	role, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		h.writeError(w, err, "Не удалось получить роль")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, role)
+}
+
+// Create
+// @Summary Create role (admin)
+// @Tags Admin — Roles
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param data body models.RoleRequest true "Роль и её права"
+// @Success 201 {object} models.Role
+// @Failure 400 {object} helpers.ErrorData "Неизвестное право"
+// @Failure 409 {object} helpers.ErrorData "Роль с таким названием уже есть"
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/roles [post]
+func (h *RoleHandler) Create(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeRoleRequest(w, r)
+	if !ok {
+		return
+	}
+
+	role, err := h.service.Create(r.Context(), req)
+	if err != nil {
+		h.writeError(w, err, "Не удалось создать роль")
+		return
+	}
+	helpers.JSON(w, http.StatusCreated, role)
+}
+
+// Update
+// @Summary Update role (admin)
+// @Description Права роли заменяются переданными. Встроенные роли нельзя переименовать, права роли admin изменить нельзя
+// @Tags Admin — Roles
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Role ID"
+// @Param data body models.RoleRequest true "Роль и её права"
+// @Success 200 {object} models.Role
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData
+// @Failure 409 {object} helpers.ErrorData
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/roles/{id} [put]
+func (h *RoleHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+	req, ok := decodeRoleRequest(w, r)
+	if !ok {
+		return
+	}
+
+	role, err := h.service.Update(r.Context(), id, req)
+	if err != nil {
+		h.writeError(w, err, "Не удалось обновить роль")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, role)
+}
+
+// Delete
+// @Summary Delete role (admin)
+// @Description Удалить можно только созданную в админке роль, не назначенную пользователям
+// @Tags Admin — Roles
+// @Security Bearer
+// @Param id path int true "Role ID"
+// @Success 204
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData
+// @Failure 409 {object} helpers.ErrorData
+// @Failure 500 {object} helpers.ErrorData
+// @Router /admin/roles/{id} [delete]
+func (h *RoleHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		h.writeError(w, err, "Не удалось удалить роль")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
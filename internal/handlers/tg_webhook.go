@@ -1,60 +1,67 @@
 package handlers
 
 import (
+	"crypto/subtle"
 	"encoding/json"
-	"github.com/Ramcache/travel-backend/internal/services"
-	"go.uber.org/zap"
 	"net/http"
-	"strconv"
-	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/notifications"
+	"github.com/Ramcache/travel-backend/internal/services"
 )
 
+// TelegramSecretHeader — заголовок с секретом, заданным в setWebhook
+const TelegramSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
 type TelegramHandler struct {
-	orderService *services.OrderService
-	log          *zap.SugaredLogger
+	bot    *services.TelegramBotService
+	secret string
+	log    *zap.SugaredLogger
 }
 
-func NewTelegramHandler(orderService *services.OrderService, log *zap.SugaredLogger) *TelegramHandler {
-	return &TelegramHandler{orderService: orderService, log: log}
+func NewTelegramHandler(bot *services.TelegramBotService, secret string, log *zap.SugaredLogger) *TelegramHandler {
+	if secret == "" {
+		log.Warnw("TG_WEBHOOK_SECRET is empty: telegram webhooks will be rejected")
+	}
+	return &TelegramHandler{bot: bot, secret: secret, log: log}
 }
 
+// Webhook
+// @Summary Telegram bot webhook
+// @Description Обновления Bot API: нажатия кнопок под заказами в чате менеджеров и команды бота (/orders new, /order, /feedback, /stats today, /trip). Запрос должен содержать секрет в заголовке X-Telegram-Bot-Api-Secret-Token
+// @Tags Telegram
+// @Accept json
+// @Param X-Telegram-Bot-Api-Secret-Token header string true "Секрет вебхука"
+// @Success 200
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 403 {object} helpers.ErrorData
+// @Router /telegram/webhook [post]
 func (h *TelegramHandler) Webhook(w http.ResponseWriter, r *http.Request) {
-	var update struct {
-		CallbackQuery struct {
-			Data string `json:"data"`
-		} `json:"callback_query"`
+	got := r.Header.Get(TelegramSecretHeader)
+	if h.secret == "" || subtle.ConstantTimeCompare([]byte(got), []byte(h.secret)) != 1 {
+		h.log.Warnw("Telegram webhook с неверным секретом", "remote", r.RemoteAddr)
+		w.WriteHeader(http.StatusForbidden)
+		return
 	}
+
+	var update notifications.TelegramUpdate
 	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
 		h.log.Errorw("Ошибка парсинга Telegram update", "err", err)
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	if update.CallbackQuery.Data == "" {
-		w.WriteHeader(http.StatusOK)
-		return
-	}
-
-	parts := strings.Split(update.CallbackQuery.Data, "_")
-	if len(parts) != 2 {
-		w.WriteHeader(http.StatusOK)
-		return
-	}
-	action, idStr := parts[0], parts[1]
-	orderID, _ := strconv.Atoi(idStr)
-
-	var status string
-	switch action {
-	case "confirm":
-		status = "confirmed"
-	case "reject":
-		status = "rejected"
-	default:
-		status = "pending"
-	}
-
-	if err := h.orderService.UpdateStatus(r.Context(), orderID, status); err != nil {
-		h.log.Errorw("Ошибка обновления статуса заказа", "id", orderID, "status", status, "err", err)
+	// ошибки не возвращаются Telegram: иначе он будет повторять то же обновление
+	switch {
+	case update.CallbackQuery != nil:
+		if err := h.bot.HandleCallback(r.Context(), update.CallbackQuery); err != nil {
+			h.log.Errorw("Ошибка обработки нажатия кнопки Telegram", "update_id", update.UpdateID, "err", err)
+		}
+	case update.Message != nil:
+		if err := h.bot.HandleMessage(r.Context(), update.Message); err != nil {
+			h.log.Errorw("Ошибка обработки команды Telegram", "update_id", update.UpdateID, "err", err)
+		}
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -0,0 +1,40 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Ramcache/travel-backend/internal/handlers"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestTelegramHandler_Webhook_Secret(t *testing.T) {
+	cases := []struct {
+		name   string
+		secret string
+		header string
+		want   int
+	}{
+		{"no header", "s3cret", "", http.StatusForbidden},
+		{"wrong secret", "s3cret", "nope", http.StatusForbidden},
+		{"secret not configured", "", "", http.StatusForbidden},
+		// обновление без нажатия кнопки боту не передаётся
+		{"ok", "s3cret", "s3cret", http.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := handlers.NewTelegramHandler(nil, tc.secret, zaptest.NewLogger(t).Sugar())
+			req := httptest.NewRequest(http.MethodPost, "/telegram/webhook", strings.NewReader(`{"update_id":1}`))
+			if tc.header != "" {
+				req.Header.Set(handlers.TelegramSecretHeader, tc.header)
+			}
+			w := httptest.NewRecorder()
+			h.Webhook(w, req)
+			if w.Code != tc.want {
+				t.Fatalf("expected %d, got %d", tc.want, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/services"
+)
+
+type TravellerProfileHandler struct {
+	service *services.TravellerProfileService
+	log     *zap.SugaredLogger
+}
+
+func NewTravellerProfileHandler(service *services.TravellerProfileService, log *zap.SugaredLogger) *TravellerProfileHandler {
+	return &TravellerProfileHandler{service: service, log: log}
+}
+
+// writeError — общий маппинг ошибок сервиса профилей
+func (h *TravellerProfileHandler) writeError(w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, services.ErrProfileNotFound):
+		helpers.Error(w, http.StatusNotFound, "Профиль туриста не найден")
+	case errors.Is(err, services.ErrEncryptionUnavailable):
+		h.log.Errorw("Шифрование паспортных данных не настроено", "err", err)
+		helpers.Error(w, http.StatusServiceUnavailable, "Хранение паспортных данных временно недоступно")
+	case helpers.IsInvalidInput(err):
+		helpers.Error(w, http.StatusBadRequest, err.Error())
+	default:
+		h.log.Errorw(msg, "err", err)
+		helpers.Error(w, http.StatusInternalServerError, msg)
+	}
+}
+
+// List
+// @Summary My traveller profiles
+// @Description Сохранённые профили туристов текущего пользователя
+// @Tags Profile — Travellers
+// @Security Bearer
+// @Produce json
+// @Success 200 {array} models.TravellerProfile
+// @Failure 503 {object} helpers.ErrorData
+// @Router /profile/travellers [get]
+func (h *TravellerProfileHandler) List(w http.ResponseWriter, r *http.Request) {
+	uid := helpers.GetUserID(r.Context())
+
+	list, err := h.service.List(r.Context(), uid)
+	if err != nil {
+		h.writeError(w, err, "Не удалось получить профили туристов")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, list)
+}
+
+// Get
+// @Summary Get traveller profile
+// @Tags Profile — Travellers
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Profile ID"
+// @Success 200 {object} models.TravellerProfile
+// @Failure 404 {object} helpers.ErrorData
+// @Router /profile/travellers/{id} [get]
+func (h *TravellerProfileHandler) Get(w http.ResponseWriter, r *http.Request) {
+	uid := helpers.GetUserID(r.Context())
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	p, err := h.service.Get(r.Context(), uid, id)
+	if err != nil {
+		h.writeError(w, err, "Не удалось получить профиль туриста")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, p)
+}
+
+// Create
+// @Summary Create traveller profile
+// @Description Сохранить паспортные данные туриста (хранятся в зашифрованном виде)
+// @Tags Profile — Travellers
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param data body models.TravellerProfileRequest true "Данные туриста"
+// @Success 201 {object} models.TravellerProfile
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 503 {object} helpers.ErrorData
+// @Router /profile/travellers [post]
+func (h *TravellerProfileHandler) Create(w http.ResponseWriter, r *http.Request) {
+	uid := helpers.GetUserID(r.Context())
+
+	var req models.TravellerProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректное тело запроса")
+		return
+	}
+
+	p, err := h.service.Create(r.Context(), uid, req)
+	if err != nil {
+		h.writeError(w, err, "Не удалось сохранить профиль туриста")
+		return
+	}
+
+	h.log.Infow("Профиль туриста создан", "uid", uid, "id", p.ID)
+	helpers.JSON(w, http.StatusCreated, p)
+}
+
+// Update
+// @Summary Update traveller profile
+// @Tags Profile — Travellers
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "Profile ID"
+// @Param data body models.TravellerProfileRequest true "Данные туриста"
+// @Success 200 {object} models.TravellerProfile
+// @Failure 400 {object} helpers.ErrorData
+// @Failure 404 {object} helpers.ErrorData
+// @Router /profile/travellers/{id} [put]
+func (h *TravellerProfileHandler) Update(w http.ResponseWriter, r *http.Request) {
+	uid := helpers.GetUserID(r.Context())
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	var req models.TravellerProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректное тело запроса")
+		return
+	}
+
+	p, err := h.service.Update(r.Context(), uid, id, req)
+	if err != nil {
+		h.writeError(w, err, "Не удалось обновить профиль туриста")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, p)
+}
+
+// Delete
+// @Summary Delete traveller profile
+// @Tags Profile — Travellers
+// @Security Bearer
+// @Param id path int true "Profile ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} helpers.ErrorData
+// @Router /profile/travellers/{id} [delete]
+func (h *TravellerProfileHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	uid := helpers.GetUserID(r.Context())
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), uid, id); err != nil {
+		h.writeError(w, err, "Не удалось удалить профиль туриста")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AdminListByUser
+// @Summary User traveller profiles (admin)
+// @Description Профили туристов пользователя без паспортных данных
+// @Tags Admin — Travellers
+// @Security Bearer
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {array} models.TravellerProfileSummary
+// @Router /admin/users/{id}/travellers [get]
+func (h *TravellerProfileHandler) AdminListByUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	list, err := h.service.AdminListByUser(r.Context(), userID)
+	if err != nil {
+		h.writeError(w, err, "Не удалось получить профили туристов")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, list)
+}
+
+// AdminGet
+// @Summary Get decrypted traveller profile (admin)
+// @Description Расшифрованные паспортные данные. Каждый просмотр записывается в журнал аудита.
+// @Tags Admin — Travellers
+// @Security Bearer
+// @Produce json
+// @Param id path int true "Profile ID"
+// @Param reason query string false "Причина просмотра (попадает в аудит)"
+// @Success 200 {object} models.TravellerProfile
+// @Failure 404 {object} helpers.ErrorData
+// @Failure 503 {object} helpers.ErrorData
+// @Router /admin/travellers/{id} [get]
+func (h *TravellerProfileHandler) AdminGet(w http.ResponseWriter, r *http.Request) {
+	adminID := helpers.GetUserID(r.Context())
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный ID")
+		return
+	}
+
+	p, err := h.service.AdminGet(r.Context(), adminID, id, r.RemoteAddr, r.URL.Query().Get("reason"))
+	if err != nil {
+		h.writeError(w, err, "Не удалось получить профиль туриста")
+		return
+	}
+
+	h.log.Infow("Администратор просмотрел паспортные данные", "admin_id", adminID, "profile_id", id)
+	helpers.JSON(w, http.StatusOK, p)
+}
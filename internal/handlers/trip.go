@@ -16,6 +16,7 @@ import (
 	"github.com/Ramcache/travel-backend/internal/models"
 	"github.com/Ramcache/travel-backend/internal/repository"
 	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/Ramcache/travel-backend/internal/validators"
 )
 
 type TripHandler struct {
@@ -339,7 +340,7 @@ func (h *TripHandler) Popular(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param id path int true "Trip ID"
 // @Param data body models.BuyRequest true "Данные покупателя"
-// @Success 200 {object} map[string]string
+// @Success 200 {object} models.BuyResponse
 // @Failure 400 {object} helpers.ErrorData "Некорректные данные"
 // @Failure 404 {object} helpers.ErrorData "Тур не найден"
 // @Failure 500 {object} helpers.ErrorData "Ошибка при покупке тура"
@@ -351,41 +352,59 @@ func (h *TripHandler) Buy(w http.ResponseWriter, r *http.Request) {
 		helpers.Error(w, http.StatusBadRequest, "Некорректное тело запроса")
 		return
 	}
-	if err := h.service.Buy(r.Context(), id, req); err != nil {
-		if errors.Is(err, services.ErrTripNotFound) {
+	if err := validators.Validate.Struct(req); err != nil {
+		helpers.Error(w, http.StatusBadRequest, validators.TranslateValidationErrors(err))
+		return
+	}
+	resp, err := h.service.Buy(r.Context(), id, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTripNotFound):
 			helpers.Error(w, http.StatusNotFound, "Тур не найден")
-			return
+		case helpers.IsInvalidInput(err):
+			helpers.Error(w, http.StatusBadRequest, err.Error())
+		default:
+			h.log.Errorw("Ошибка при покупке тура", "trip_id", id, "err", err)
+			helpers.Error(w, http.StatusInternalServerError, "Ошибка при покупке тура")
 		}
-		helpers.Error(w, http.StatusInternalServerError, "Ошибка при покупке тура")
 		return
 	}
-	helpers.JSON(w, http.StatusOK, map[string]string{"status": "success"})
+	helpers.JSON(w, http.StatusOK, resp)
 }
 
 // BuyWithoutTrip
-// @Summary Buy trip
-// @Description Отправка заявки на покупку тура в Telegram
+// @Summary Buy without trip
+// @Description Заявка без привязки к туру (название, дата и цена задаются клиентом)
 // @Tags Public — Trips
 // @Accept json
 // @Produce json
-// @Param id path int true "Trip ID"
 // @Param data body models.BuyRequest true "Данные покупателя"
-// @Success 200 {object} map[string]string
+// @Success 200 {object} models.BuyResponse
 // @Failure 400 {object} helpers.ErrorData "Некорректные данные"
 // @Failure 404 {object} helpers.ErrorData "Тур не найден"
 // @Failure 500 {object} helpers.ErrorData "Ошибка при покупке тура"
-// @Router /trips/{id}/buy [post]
+// @Router /trips/buy [post]
 func (h *TripHandler) BuyWithoutTrip(w http.ResponseWriter, r *http.Request) {
 	var req models.BuyRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		helpers.Error(w, http.StatusBadRequest, "Некорректное тело запроса")
 		return
 	}
-	if err := h.service.BuyWithoutTrip(r.Context(), req); err != nil {
+	if err := validators.Validate.Struct(req); err != nil {
+		helpers.Error(w, http.StatusBadRequest, validators.TranslateValidationErrors(err))
+		return
+	}
+	resp, err := h.service.BuyWithoutTrip(r.Context(), req)
+	if err != nil {
+		if helpers.IsInvalidInput(err) {
+			helpers.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.log.Errorw("Ошибка при покупке без тура", "err", err)
 		helpers.Error(w, http.StatusInternalServerError, "Ошибка при покупке без тура")
 		return
 	}
-	helpers.JSON(w, http.StatusOK, map[string]string{"status": "success"})
+	helpers.JSON(w, http.StatusOK, resp)
 }
 
 // CreateTour — создаёт тур, отель и маршрут за один запрос
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -14,13 +15,19 @@ import (
 	"github.com/Ramcache/travel-backend/internal/repository"
 )
 
+// SessionRevoker закрывает все сессии пользователя
+type SessionRevoker interface {
+	RevokeSessions(ctx context.Context, userID int) error
+}
+
 type UserHandler struct {
-	repo repository.UserRepoI
-	log  *zap.SugaredLogger
+	repo     repository.UserRepoI
+	sessions SessionRevoker
+	log      *zap.SugaredLogger
 }
 
-func NewUserHandler(repo repository.UserRepoI, log *zap.SugaredLogger) *UserHandler {
-	return &UserHandler{repo: repo, log: log}
+func NewUserHandler(repo repository.UserRepoI, sessions SessionRevoker, log *zap.SugaredLogger) *UserHandler {
+	return &UserHandler{repo: repo, sessions: sessions, log: log}
 }
 
 // List
@@ -80,6 +87,7 @@ func (h *UserHandler) Get(w http.ResponseWriter, r *http.Request) {
 // @Param data body models.CreateUserRequest true "User data"
 // @Success 200 {object} models.User
 // @Failure 400 {object} helpers.ErrorData "Некорректное тело запроса"
+// @Failure 403 {object} helpers.ErrorData "Назначать роли можно только с правом roles.manage"
 // @Failure 500 {object} helpers.ErrorData "Ошибка при создании пользователя"
 // @Router /admin/users [post]
 func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -89,6 +97,10 @@ func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
 		helpers.Error(w, http.StatusBadRequest, "Некорректное тело запроса")
 		return
 	}
+	if req.RoleID != 0 && req.RoleID != models.RoleUser && !helpers.HasPermission(r.Context(), models.PermRolesManage) {
+		helpers.Error(w, http.StatusForbidden, "Назначать роли можно только с правом roles.manage")
+		return
+	}
 
 	hash, err := helpers.HashPassword(req.Password)
 	if err != nil {
@@ -105,6 +117,10 @@ func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.repo.Create(r.Context(), user); err != nil {
+		if status, _, _ := helpers.MapPgErr(err); status == http.StatusBadRequest {
+			helpers.Error(w, http.StatusBadRequest, "Роль не найдена")
+			return
+		}
 		h.log.Errorw("Ошибка создания пользователя", "err", err)
 		helpers.Error(w, http.StatusInternalServerError, "Не удалось создать пользователя")
 		return
@@ -124,7 +140,9 @@ func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
 // @Param data body models.UpdateUserRequest true "User update"
 // @Success 200 {object} models.User
 // @Failure 400 {object} helpers.ErrorData "Некорректное тело запроса"
+// @Failure 403 {object} helpers.ErrorData "Назначать роли и привязывать Telegram можно только с правом roles.manage"
 // @Failure 404 {object} helpers.ErrorData "Пользователь не найден"
+// @Failure 409 {object} helpers.ErrorData "Telegram уже привязан к другому пользователю"
 // @Failure 500 {object} helpers.ErrorData "Ошибка при обновлении пользователя"
 // @Router /admin/users/{id} [put]
 func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
@@ -151,15 +169,38 @@ func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
 	if req.FullName != nil {
 		user.FullName = *req.FullName
 	}
+	roleChanged := req.RoleID != nil && *req.RoleID != user.RoleID
+	if roleChanged && !helpers.HasPermission(r.Context(), models.PermRolesManage) {
+		helpers.Error(w, http.StatusForbidden, "Назначать роли можно только с правом roles.manage")
+		return
+	}
 	if req.RoleID != nil {
 		user.RoleID = *req.RoleID
 	}
+	// через бота действуют от имени владельца Telegram ID — привязать свой аккаунт к чужому
+	// профилю значит получить его права
+	telegramChanged := req.TelegramID != nil && !sameTelegramID(user.TelegramID, *req.TelegramID)
+	if telegramChanged && !helpers.HasPermission(r.Context(), models.PermRolesManage) {
+		helpers.Error(w, http.StatusForbidden, "Привязывать Telegram можно только с правом roles.manage")
+		return
+	}
+	if req.TelegramID != nil {
+		user.TelegramID = req.TelegramID
+		if *req.TelegramID == 0 {
+			user.TelegramID = nil
+		}
+	}
 
 	if err := h.repo.Update(r.Context(), user); err != nil {
+		status, _, _ := helpers.MapPgErr(err)
 		switch {
 		case errors.Is(err, repository.ErrNotFound):
 			h.log.Warnw("Пользователь не найден при обновлении", "id", id)
 			helpers.Error(w, http.StatusNotFound, "Пользователь не найден")
+		case status == http.StatusConflict:
+			helpers.Error(w, http.StatusConflict, "Этот Telegram уже привязан к другому пользователю")
+		case status == http.StatusBadRequest:
+			helpers.Error(w, http.StatusBadRequest, "Роль не найдена")
 		default:
 			h.log.Errorw("Ошибка обновления пользователя", "id", id, "err", err)
 			helpers.Error(w, http.StatusInternalServerError, "Не удалось обновить пользователя")
@@ -167,7 +208,16 @@ func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.log.Infow("Пользователь успешно обновлён", "id", id)
+	// с новой ролью — только после нового входа: старые токены больше не действуют
+	if roleChanged {
+		if err := h.sessions.RevokeSessions(r.Context(), id); err != nil {
+			h.log.Errorw("Ошибка отзыва сессий после смены роли", "id", id, "err", err)
+			helpers.Error(w, http.StatusInternalServerError, "Роль изменена, но не удалось завершить сессии пользователя")
+			return
+		}
+	}
+
+	h.log.Infow("Пользователь успешно обновлён", "id", id, "role_changed", roleChanged)
 	helpers.JSON(w, http.StatusOK, user)
 }
 
@@ -182,6 +232,7 @@ func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
 // @Router /admin/users/{id} [delete]
 func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	// сессии удаляются вместе с пользователем (ON DELETE CASCADE), его токены отклоняются сразу
 	err := h.repo.Delete(r.Context(), id)
 	switch {
 	case errors.Is(err, repository.ErrNotFound):
@@ -197,3 +248,11 @@ func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	h.log.Infow("Пользователь успешно удалён", "id", id)
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// sameTelegramID — запрос оставляет привязку как есть; 0 в запросе означает «отвязать»
+func sameTelegramID(current *int64, requested int64) bool {
+	if current == nil {
+		return requested == 0
+	}
+	return *current == requested
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/Ramcache/travel-backend/internal/validators"
+)
+
+type VerificationHandler struct {
+	service *services.VerificationService
+	log     *zap.SugaredLogger
+}
+
+func NewVerificationHandler(service *services.VerificationService, log *zap.SugaredLogger) *VerificationHandler {
+	return &VerificationHandler{service: service, log: log}
+}
+
+// VerifyEmail
+// @Summary Подтвердить email
+// @Description Код из письма, отправленного при регистрации или через /auth/email/resend. Вход не нужен.
+// @Tags System — Auth
+// @Accept json
+// @Param data body models.VerifyEmailRequest true "email и код"
+// @Success 204 "No Content"
+// @Failure 400 {object} helpers.ErrorData "Неверный или устаревший код"
+// @Failure 429 {object} helpers.ErrorData "Слишком много запросов"
+// @Failure 500 {object} helpers.ErrorData "Ошибка сервера"
+// @Router /auth/email/verify [post]
+func (h *VerificationHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req models.VerifyEmailRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	err := h.service.VerifyEmail(r.Context(), req.Email, req.Code)
+	if err != nil {
+		h.writeError(w, err, "Ошибка подтверждения email")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ResendEmail
+// @Summary Отправить код подтверждения email ещё раз
+// @Description Не чаще раза в минуту. Ответ одинаковый для зарегистрированных и незнакомых адресов.
+// @Tags System — Auth
+// @Accept json
+// @Param data body models.ResendVerificationRequest true "email"
+// @Success 204 "No Content"
+// @Failure 400 {object} helpers.ErrorData "Некорректный запрос"
+// @Failure 429 {object} helpers.ErrorData "Слишком много запросов"
+// @Failure 500 {object} helpers.ErrorData "Ошибка сервера"
+// @Router /auth/email/resend [post]
+func (h *VerificationHandler) ResendEmail(w http.ResponseWriter, r *http.Request) {
+	var req models.ResendVerificationRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	if err := h.service.ResendEmail(r.Context(), req.Email); err != nil {
+		h.writeError(w, err, "Ошибка отправки кода подтверждения email")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SendPhoneCode
+// @Summary Отправить SMS-код для подтверждения телефона
+// @Description Код уходит на телефон из профиля, не чаще раза в минуту.
+// @Tags System — Auth
+// @Security Bearer
+// @Success 204 "No Content"
+// @Failure 400 {object} helpers.ErrorData "В профиле нет телефона"
+// @Failure 409 {object} helpers.ErrorData "Телефон уже подтверждён"
+// @Failure 500 {object} helpers.ErrorData "Ошибка сервера"
+// @Router /profile/phone/send-code [post]
+func (h *VerificationHandler) SendPhoneCode(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.SendPhoneCode(r.Context(), helpers.GetUserID(r.Context())); err != nil {
+		h.writeError(w, err, "Ошибка отправки кода подтверждения телефона")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type phoneVerifiedResponse struct {
+	// сколько анонимных заказов с этим номером привязано к аккаунту
+	OrdersClaimed int `json:"orders_claimed"`
+}
+
+// VerifyPhone
+// @Summary Подтвердить телефон
+// @Description Код из SMS. После подтверждения к аккаунту привязываются анонимные заказы с этим номером.
+// @Tags System — Auth
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param data body models.VerifyPhoneRequest true "код"
+// @Success 200 {object} phoneVerifiedResponse
+// @Failure 400 {object} helpers.ErrorData "Неверный или устаревший код"
+// @Failure 409 {object} helpers.ErrorData "Телефон уже подтверждён"
+// @Failure 500 {object} helpers.ErrorData "Ошибка сервера"
+// @Router /profile/phone/verify [post]
+func (h *VerificationHandler) VerifyPhone(w http.ResponseWriter, r *http.Request) {
+	var req models.VerifyPhoneRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	n, err := h.service.VerifyPhone(r.Context(), helpers.GetUserID(r.Context()), req.Code)
+	if err != nil {
+		h.writeError(w, err, "Ошибка подтверждения телефона")
+		return
+	}
+	helpers.JSON(w, http.StatusOK, phoneVerifiedResponse{OrdersClaimed: n})
+}
+
+func (h *VerificationHandler) decode(w http.ResponseWriter, r *http.Request, req any) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		helpers.Error(w, http.StatusBadRequest, "Некорректный запрос")
+		return false
+	}
+	if err := validators.Validate.Struct(req); err != nil {
+		helpers.Error(w, http.StatusBadRequest, validators.TranslateValidationErrors(err))
+		return false
+	}
+	return true
+}
+
+func (h *VerificationHandler) writeError(w http.ResponseWriter, err error, logMsg string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidCode):
+		helpers.Error(w, http.StatusBadRequest, "Неверный или устаревший код, запросите новый")
+	case errors.Is(err, services.ErrAlreadyVerified):
+		helpers.Error(w, http.StatusConflict, "Уже подтверждено")
+	case errors.Is(err, services.ErrNotFound):
+		helpers.Error(w, http.StatusNotFound, "Пользователь не найден")
+	case helpers.IsInvalidInput(err):
+		helpers.Error(w, http.StatusBadRequest, err.Error())
+	default:
+		h.log.Errorw(logMsg, "err", err)
+		helpers.Error(w, http.StatusInternalServerError, "Ошибка сервера")
+	}
+}
@@ -1,6 +1,9 @@
 package helpers
 
-import "context"
+import (
+	"context"
+	"slices"
+)
 
 type ctxKey string
 
@@ -19,3 +22,28 @@ func GetUserID(ctx context.Context) int {
 	}
 	return 0
 }
+
+// PermissionsKey — права роли пользователя запроса ([]string)
+const PermissionsKey ctxKey = "permissions"
+
+// HasPermission — есть ли у пользователя запроса право permission
+func HasPermission(ctx context.Context, permission string) bool {
+	return slices.Contains(GetPermissions(ctx), permission)
+}
+
+func GetPermissions(ctx context.Context) []string {
+	if v, ok := ctx.Value(PermissionsKey).([]string); ok {
+		return v
+	}
+	return nil
+}
+
+// SessionIDKey — ID сессии входа, которой принадлежит access-токен запроса
+const SessionIDKey ctxKey = "session_id"
+
+func GetSessionID(ctx context.Context) int64 {
+	if v, ok := ctx.Value(SessionIDKey).(int64); ok {
+		return v
+	}
+	return 0
+}
@@ -0,0 +1,103 @@
+package helpers
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+)
+
+var (
+	ErrEmptyKey      = errors.New("encryption key is empty")
+	ErrInvalidCipher = errors.New("invalid ciphertext")
+)
+
+// cipherPrefix — версия формата, чтобы в будущем можно было сменить алгоритм/ключ
+const cipherPrefix = "v1:"
+
+// Cipher шифрует чувствительные поля (AES-256-GCM).
+// Ключ приложения приводится к 32 байтам через SHA-256.
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+func NewCipher(key string) (*Cipher, error) {
+	if strings.TrimSpace(key) == "" {
+		return nil, ErrEmptyKey
+	}
+	sum := sha256.Sum256([]byte(key))
+
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, fmt.Errorf("aes: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("gcm: %w", err)
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// Encrypt возвращает "v1:" + base64(nonce|ciphertext). Пустая строка не шифруется.
+func (c *Cipher) Encrypt(plain string) (string, error) {
+	if plain == "" {
+		return "", nil
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(plain), nil)
+	return cipherPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (c *Cipher) Decrypt(enc string) (string, error) {
+	if enc == "" {
+		return "", nil
+	}
+	if !strings.HasPrefix(enc, cipherPrefix) {
+		return "", ErrInvalidCipher
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(enc, cipherPrefix))
+	if err != nil {
+		return "", ErrInvalidCipher
+	}
+	ns := c.aead.NonceSize()
+	if len(raw) < ns {
+		return "", ErrInvalidCipher
+	}
+	plain, err := c.aead.Open(nil, raw[:ns], raw[ns:], nil)
+	if err != nil {
+		return "", ErrInvalidCipher
+	}
+	return string(plain), nil
+}
+
+// RandomToken — случайная строка из n байт в base64url без padding
+// (для ссылок и одноразовых токенов)
+func RandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", fmt.Errorf("random token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// RandomDigits — случайный код из n цифр (коды подтверждения по SMS и email)
+func RandomDigits(n int) (string, error) {
+	b := make([]byte, n)
+	for i := range b {
+		d, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", fmt.Errorf("random digits: %w", err)
+		}
+		b[i] = byte('0' + d.Int64())
+	}
+	return string(b), nil
+}
@@ -0,0 +1,86 @@
+package helpers_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+)
+
+func TestCipher_RoundTrip(t *testing.T) {
+	c, err := helpers.NewCipher("app-key")
+	if err != nil {
+		t.Fatalf("NewCipher error: %v", err)
+	}
+
+	enc, err := c.Encrypt("4510 123456")
+	if err != nil {
+		t.Fatalf("Encrypt error: %v", err)
+	}
+	if !strings.HasPrefix(enc, "v1:") || strings.Contains(enc, "123456") {
+		t.Fatalf("unexpected ciphertext: %q", enc)
+	}
+
+	enc2, _ := c.Encrypt("4510 123456")
+	if enc == enc2 {
+		t.Fatal("expected random nonce to produce different ciphertexts")
+	}
+
+	plain, err := c.Decrypt(enc)
+	if err != nil || plain != "4510 123456" {
+		t.Fatalf("Decrypt mismatch: %q, %v", plain, err)
+	}
+}
+
+func TestCipher_EmptyValues(t *testing.T) {
+	if _, err := helpers.NewCipher(""); !errors.Is(err, helpers.ErrEmptyKey) {
+		t.Fatalf("expected ErrEmptyKey, got %v", err)
+	}
+
+	c, _ := helpers.NewCipher("app-key")
+	if enc, _ := c.Encrypt(""); enc != "" {
+		t.Fatalf("expected empty ciphertext, got %q", enc)
+	}
+	if plain, _ := c.Decrypt(""); plain != "" {
+		t.Fatalf("expected empty plaintext, got %q", plain)
+	}
+}
+
+func TestCipher_WrongKey(t *testing.T) {
+	c1, _ := helpers.NewCipher("key-1")
+	c2, _ := helpers.NewCipher("key-2")
+
+	enc, _ := c1.Encrypt("secret")
+	if _, err := c2.Decrypt(enc); !errors.Is(err, helpers.ErrInvalidCipher) {
+		t.Fatalf("expected ErrInvalidCipher, got %v", err)
+	}
+	if _, err := c1.Decrypt("plain-text"); !errors.Is(err, helpers.ErrInvalidCipher) {
+		t.Fatalf("expected ErrInvalidCipher for unprefixed value, got %v", err)
+	}
+}
+
+func TestRandomToken(t *testing.T) {
+	a, err := helpers.RandomToken(24)
+	if err != nil {
+		t.Fatalf("RandomToken error: %v", err)
+	}
+	b, _ := helpers.RandomToken(24)
+
+	if len(a) != 32 || strings.Contains(a, "=") {
+		t.Fatalf("unexpected token: %q", a)
+	}
+	if a == b {
+		t.Fatal("expected different tokens")
+	}
+}
+
+func TestRandomDigits(t *testing.T) {
+	code, err := helpers.RandomDigits(6)
+	if err != nil {
+		t.Fatalf("RandomDigits error: %v", err)
+	}
+	if len(code) != 6 || strings.Trim(code, "0123456789") != "" {
+		t.Fatalf("unexpected code: %q", code)
+	}
+}
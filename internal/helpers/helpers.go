@@ -1,8 +1,39 @@
 package helpers
 
+import (
+	"strconv"
+	"strings"
+)
+
 func IfEmpty(s *string, def string) string {
 	if s == nil || *s == "" {
 		return def
 	}
 	return *s
 }
+
+// FormatPrice — сумма в рублях с разбивкой на разряды: 150000 → "150 000"
+func FormatPrice(price float64) string {
+	s := strconv.FormatInt(int64(price), 10)
+	n := len(s)
+	if n <= 3 {
+		return s
+	}
+
+	var out strings.Builder
+	mod := n % 3
+	if mod > 0 {
+		out.WriteString(s[:mod])
+		if n > mod {
+			out.WriteString(" ")
+		}
+	}
+
+	for i := mod; i < n; i += 3 {
+		out.WriteString(s[i : i+3])
+		if i+3 < n {
+			out.WriteString(" ")
+		}
+	}
+	return out.String()
+}
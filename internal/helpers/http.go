@@ -4,6 +4,7 @@ import (
 	"errors"
 	"github.com/go-playground/validator/v10"
 	"github.com/jackc/pgx/v5/pgconn"
+	"net"
 	"net/http"
 )
 
@@ -70,3 +71,13 @@ func MapPgErr(err error) (int, string, string) {
 	}
 	return http.StatusInternalServerError, "internal_error", ""
 }
+
+// ClientIP — адрес клиента без порта. За прокси RemoteAddr уже подменён
+// chi RealIP из X-Forwarded-For / X-Real-IP
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err == nil && host != "" {
+		return host
+	}
+	return r.RemoteAddr
+}
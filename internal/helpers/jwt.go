@@ -16,10 +16,24 @@ func GenerateJWT(secret string, userID int, fullName string, roleID int, ttl tim
 	return token.SignedString([]byte(secret))
 }
 
+// GenerateSessionJWT — access-токен сессии: sid — её ID, jti — ID самого токена.
+// Middleware пускает только с последним jti, выданным в незакрытой сессии
+func GenerateSessionJWT(secret string, userID int, fullName string, roleID int, sessionID int64, jti string, ttl time.Duration) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id":   userID,
+		"full_name": fullName,
+		"role_id":   roleID,
+		"sid":       sessionID,
+		"jti":       jti,
+		"exp":       time.Now().Add(ttl).Unix(),
+	})
+	return token.SignedString([]byte(secret))
+}
+
 func ParseJWT(secret, tokenStr string) (jwt.MapClaims, error) {
 	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
 		return []byte(secret), nil
-	})
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 	if err != nil {
 		return nil, err
 	}
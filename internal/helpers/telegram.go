@@ -7,8 +7,10 @@ import (
 	"fmt"
 	"github.com/Ramcache/travel-backend/internal/models"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/url"
+	"strconv"
 )
 
 var (
@@ -68,10 +70,19 @@ func NewTelegramClient(token, chatID string) *TelegramClient {
 }
 
 func (t *TelegramClient) SendMessage(text string) error {
+	return t.sendMessage(t.ChatID, text)
+}
+
+// SendMessageTo отправляет сообщение в указанный чат (например, клиенту)
+func (t *TelegramClient) SendMessageTo(chatID int64, text string) error {
+	return t.sendMessage(strconv.FormatInt(chatID, 10), text)
+}
+
+func (t *TelegramClient) sendMessage(chatID, text string) error {
 	apiURL := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", t.Token)
 
 	resp, err := httpPostForm(apiURL, url.Values{
-		"chat_id":    {t.ChatID},
+		"chat_id":    {chatID},
 		"text":       {text},
 		"parse_mode": {"HTML"},
 	})
@@ -118,3 +129,43 @@ func (t *TelegramClient) SendMessageWithButton(text, buttonText, buttonURL strin
 	}
 	return nil
 }
+
+// Enabled — задан ли токен бота
+func (t *TelegramClient) Enabled() bool {
+	return t != nil && t.Token != ""
+}
+
+// SendDocument отправляет файл в указанный чат (например, клиенту)
+func (t *TelegramClient) SendDocument(chatID int64, filename string, data []byte, caption string) error {
+	apiURL := fmt.Sprintf("https://api.telegram.org/bot%s/sendDocument", t.Token)
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	_ = w.WriteField("chat_id", strconv.FormatInt(chatID, 10))
+	if caption != "" {
+		_ = w.WriteField("caption", caption)
+		_ = w.WriteField("parse_mode", "HTML")
+	}
+	part, err := w.CreateFormFile("document", filename)
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	resp, err := httpPostJSON(apiURL, w.FormDataContentType(), &body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("telegram send document failed: %s, body: %s", resp.Status, string(b))
+	}
+	return nil
+}
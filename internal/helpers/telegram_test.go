@@ -3,6 +3,8 @@ package helpers
 import (
 	"errors"
 	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
 	"net/url"
 	"strings"
@@ -41,6 +43,22 @@ func TestSendMessageSuccess(t *testing.T) {
 	}
 }
 
+func TestSendMessageToChat(t *testing.T) {
+	client := NewTelegramClient("token", "chat")
+
+	httpPostForm = func(url string, data url.Values) (*http.Response, error) {
+		if data.Get("chat_id") != "123456789" {
+			t.Fatalf("unexpected chat id %s", data.Get("chat_id"))
+		}
+		return &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Body: io.NopCloser(strings.NewReader(""))}, nil
+	}
+	t.Cleanup(func() { httpPostForm = http.PostForm })
+
+	if err := client.SendMessageTo(123456789, "hello"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestSendMessageHTTPError(t *testing.T) {
 	client := NewTelegramClient("token", "chat")
 
@@ -130,3 +148,47 @@ func TestSendMessageWithButtonNon200(t *testing.T) {
 		t.Fatalf("expected telegram send failure, got %v", err)
 	}
 }
+
+func TestSendDocument(t *testing.T) {
+	client := NewTelegramClient("token", "chat")
+
+	httpPostJSON = func(url, contentType string, body io.Reader) (*http.Response, error) {
+		if !strings.HasSuffix(url, "/sendDocument") {
+			t.Fatalf("unexpected url %s", url)
+		}
+		_, params, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			t.Fatalf("parse content type: %v", err)
+		}
+		form, err := multipart.NewReader(body, params["boundary"]).ReadForm(1 << 20)
+		if err != nil {
+			t.Fatalf("read form: %v", err)
+		}
+		if form.Value["chat_id"][0] != "42" {
+			t.Fatalf("unexpected chat id %v", form.Value["chat_id"])
+		}
+		if fh := form.File["document"]; len(fh) != 1 || fh[0].Filename != "invoice-1.pdf" {
+			t.Fatalf("unexpected document %v", fh)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Body: io.NopCloser(strings.NewReader("{}"))}, nil
+	}
+	t.Cleanup(func() {
+		httpPostJSON = func(url, contentType string, body io.Reader) (*http.Response, error) {
+			return http.Post(url, contentType, body)
+		}
+	})
+
+	if err := client.SendDocument(42, "invoice-1.pdf", []byte("%PDF-"), "Счёт"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestTelegramEnabled(t *testing.T) {
+	var nilClient *TelegramClient
+	if nilClient.Enabled() || NewTelegramClient("", "chat").Enabled() {
+		t.Fatal("client without token must be disabled")
+	}
+	if !NewTelegramClient("token", "").Enabled() {
+		t.Fatal("client with token must be enabled")
+	}
+}
@@ -0,0 +1,67 @@
+package helpers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidTelegramInitData — initData мини-приложения не прошла проверку подписи или устарела
+var ErrInvalidTelegramInitData = errors.New("invalid telegram init data")
+
+// TelegramWebAppUserID проверяет initData мини-приложения Telegram подписью по токену бота
+// (https://core.telegram.org/bots/webapps#validating-data-received-via-the-mini-app)
+// и возвращает id пользователя — он же chat_id его личного чата с ботом.
+// initData старше maxAge не принимается, чтобы перехваченную строку нельзя было использовать вечно.
+func TelegramWebAppUserID(initData, botToken string, maxAge time.Duration, now time.Time) (int64, error) {
+	if initData == "" || botToken == "" {
+		return 0, ErrInvalidTelegramInitData
+	}
+	values, err := url.ParseQuery(initData)
+	if err != nil {
+		return 0, ErrInvalidTelegramInitData
+	}
+	hash, err := hex.DecodeString(values.Get("hash"))
+	if err != nil || len(hash) == 0 {
+		return 0, ErrInvalidTelegramInitData
+	}
+
+	pairs := make([]string, 0, len(values))
+	for key := range values {
+		if key != "hash" {
+			pairs = append(pairs, key+"="+values.Get(key))
+		}
+	}
+	sort.Strings(pairs)
+
+	secret := hmacSHA256([]byte("WebAppData"), []byte(botToken))
+	if !hmac.Equal(hash, hmacSHA256(secret, []byte(strings.Join(pairs, "\n")))) {
+		return 0, ErrInvalidTelegramInitData
+	}
+
+	authDate, err := strconv.ParseInt(values.Get("auth_date"), 10, 64)
+	if err != nil || now.Sub(time.Unix(authDate, 0)) > maxAge {
+		return 0, ErrInvalidTelegramInitData
+	}
+
+	var user struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(values.Get("user")), &user); err != nil || user.ID == 0 {
+		return 0, ErrInvalidTelegramInitData
+	}
+	return user.ID, nil
+}
+
+func hmacSHA256(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,55 @@
+package helpers
+
+import (
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// signInitData подписывает initData так же, как Telegram
+func signInitData(botToken string, values url.Values) string {
+	pairs := make([]string, 0, len(values))
+	for key := range values {
+		pairs = append(pairs, key+"="+values.Get(key))
+	}
+	sort.Strings(pairs)
+	secret := hmacSHA256([]byte("WebAppData"), []byte(botToken))
+	values.Set("hash", hex.EncodeToString(hmacSHA256(secret, []byte(strings.Join(pairs, "\n")))))
+	return values.Encode()
+}
+
+func TestTelegramWebAppUserID(t *testing.T) {
+	now := time.Unix(1760000000, 0)
+	values := url.Values{
+		"auth_date": {strconv.FormatInt(now.Add(-time.Minute).Unix(), 10)},
+		"query_id":  {"AAE"},
+		"user":      {`{"id":123456789,"first_name":"Иван"}`},
+	}
+	initData := signInitData("bot-token", values)
+
+	id, err := TelegramWebAppUserID(initData, "bot-token", time.Hour, now)
+	if err != nil || id != 123456789 {
+		t.Fatalf("expected user 123456789, got %d, %v", id, err)
+	}
+
+	forged := strings.Replace(initData, "123456789", "987654321", 1)
+	cases := map[string]struct {
+		initData, token string
+		now             time.Time
+	}{
+		"чужой токен бота":    {initData, "other-token", now},
+		"подменённый user":    {forged, "bot-token", now},
+		"устаревшая initData": {initData, "bot-token", now.Add(2 * time.Hour)},
+		"без подписи":         {"user=%7B%22id%22%3A1%7D&auth_date=1760000000", "bot-token", now},
+		"бот не настроен":     {initData, "", now},
+	}
+	for name, c := range cases {
+		if _, err := TelegramWebAppUserID(c.initData, c.token, time.Hour, c.now); err != ErrInvalidTelegramInitData {
+			t.Errorf("%s: expected ErrInvalidTelegramInitData, got %v", name, err)
+		}
+	}
+}
@@ -2,13 +2,23 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
 )
 
-func JWTAuth(secret string) func(http.Handler) http.Handler {
+var errInvalidToken = errors.New("invalid token")
+
+// SessionValidator проверяет, что сессия токена не закрыта, а сам токен — последний выданный в ней.
+// Возвращает владельца, его текущую роль и её права: роль в токене могла устареть
+type SessionValidator interface {
+	ValidateSession(ctx context.Context, sessionID int64, jti string) (*models.SessionPrincipal, error)
+}
+
+func JWTAuth(secret string, sessions SessionValidator) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			auth := r.Header.Get("Authorization")
@@ -16,37 +26,63 @@ func JWTAuth(secret string) func(http.Handler) http.Handler {
 				http.Error(w, "missing token", http.StatusUnauthorized)
 				return
 			}
-			tokenStr := strings.TrimPrefix(auth, "Bearer ")
 
-			claims, err := helpers.ParseJWT(secret, tokenStr)
+			ctx, err := authContext(r.Context(), secret, sessions, strings.TrimPrefix(auth, "Bearer "))
 			if err != nil {
 				http.Error(w, "invalid token", http.StatusUnauthorized)
 				return
 			}
 
-			rawUID, ok := claims["user_id"]
-			if !ok {
-				http.Error(w, "invalid token", http.StatusUnauthorized)
-				return
-			}
-			uidFloat, ok := rawUID.(float64)
-			if !ok {
-				http.Error(w, "invalid token", http.StatusUnauthorized)
-				return
-			}
+			next.ServeHTTP(w, r.WithContext(ctx))
 
-			rawRole := claims["role_id"]
-			roleFloat, ok := rawRole.(float64)
-			if !ok {
-				http.Error(w, "invalid token", http.StatusUnauthorized)
-				return
+		})
+	}
+}
+
+// OptionalJWTAuth — для публичных ручек: если передан валидный токен, кладёт user_id/role_id в контекст,
+// иначе пропускает запрос как анонимный.
+func OptionalJWTAuth(secret string, sessions SessionValidator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			auth := r.Header.Get("Authorization")
+			if strings.HasPrefix(auth, "Bearer ") {
+				if ctx, err := authContext(r.Context(), secret, sessions, strings.TrimPrefix(auth, "Bearer ")); err == nil {
+					r = r.WithContext(ctx)
+				}
 			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
 
-			ctx := context.WithValue(r.Context(), helpers.UserIDKey, int(uidFloat))
-			ctx = context.WithValue(ctx, helpers.RoleIDKey, int(roleFloat))
+func authContext(ctx context.Context, secret string, sessions SessionValidator, tokenStr string) (context.Context, error) {
+	claims, err := helpers.ParseJWT(secret, tokenStr)
+	if err != nil {
+		return nil, errInvalidToken
+	}
 
-			next.ServeHTTP(w, r.WithContext(ctx))
+	// токены без сессии (выданные до появления refresh) больше не принимаются
+	sidFloat, ok := claims["sid"].(float64)
+	if !ok {
+		return nil, errInvalidToken
+	}
+	jti, ok := claims["jti"].(string)
+	if !ok || jti == "" {
+		return nil, errInvalidToken
+	}
+	uidFloat, ok := claims["user_id"].(float64)
+	if !ok {
+		return nil, errInvalidToken
+	}
 
-		})
+	p, err := sessions.ValidateSession(ctx, int64(sidFloat), jti)
+	if err != nil || p.UserID != int(uidFloat) {
+		return nil, errInvalidToken
 	}
+
+	ctx = context.WithValue(ctx, helpers.UserIDKey, p.UserID)
+	ctx = context.WithValue(ctx, helpers.RoleIDKey, p.RoleID)
+	ctx = context.WithValue(ctx, helpers.PermissionsKey, p.Permissions)
+	ctx = context.WithValue(ctx, helpers.SessionIDKey, int64(sidFloat))
+	return ctx, nil
 }
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+)
+
+// RequirePermission пропускает запрос, только если у роли пользователя есть право permission.
+// Права кладёт в контекст JWTAuth, поэтому ставится после него
+func RequirePermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !helpers.HasPermission(r.Context(), permission) {
+				helpers.Error(w, http.StatusForbidden, "Недостаточно прав: нужно право "+permission)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
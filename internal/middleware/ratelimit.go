@@ -1,12 +1,13 @@
 package middleware
 
 import (
-	"net"
 	"net/http"
 	"sync"
 	"time"
 
 	"golang.org/x/time/rate"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
 )
 
 // IP-based limiter with TTL cleanup.
@@ -70,24 +71,10 @@ func (l *ipLimiter) cleanupLoop() {
 	}
 }
 
-func clientIP(r *http.Request) string {
-	// chi middleware RealIP sets RemoteAddr to the real client IP when possible.
-	// Still normalize it (RemoteAddr can be "IP:port").
-	host, _, err := net.SplitHostPort(r.RemoteAddr)
-	if err == nil && host != "" {
-		return host
-	}
-	// If it is already an IP without port
-	if net.ParseIP(r.RemoteAddr) != nil {
-		return r.RemoteAddr
-	}
-	return r.RemoteAddr
-}
-
 func RateLimit(l *ipLimiter) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			ip := clientIP(r)
+			ip := helpers.ClientIP(r)
 			lim := l.get(ip)
 
 			if !lim.Allow() {
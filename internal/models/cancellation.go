@@ -0,0 +1,94 @@
+package models
+
+import "time"
+
+// ======== Отмена заказа клиентом ========
+
+// Откуда пришла заявка на отмену
+const (
+	CancellationSourceProfile  = "profile"  // личный кабинет
+	CancellationSourceTracking = "tracking" // публичная ссылка на заказ
+)
+
+// Статусы заявки на отмену
+const (
+	CancellationPending    = "pending"
+	CancellationProcessing = "processing" // решение принимается: идут возвраты
+	CancellationApproved   = "approved"
+	CancellationRejected   = "rejected"
+)
+
+// TripCancellationRule — ступень штрафа: при отмене не позднее чем за DaysBefore дней
+// до начала тура удерживается PenaltyPercent от стоимости заказа
+type TripCancellationRule struct {
+	ID             int     `json:"id"`
+	TripID         int     `json:"trip_id"`
+	DaysBefore     int     `json:"days_before"`
+	PenaltyPercent float64 `json:"penalty_percent"`
+}
+
+type TripCancellationRuleRequest struct {
+	DaysBefore     int     `json:"days_before" example:"30"`
+	PenaltyPercent float64 `json:"penalty_percent" example:"25"`
+}
+
+// CancellationQuote — расчёт возврата при отмене заказа на текущую дату
+type CancellationQuote struct {
+	OrderID        int                    `json:"order_id"`
+	OrderStatus    string                 `json:"order_status"`
+	Cancellable    bool                   `json:"cancellable"`
+	TripStart      *time.Time             `json:"trip_start,omitempty"`
+	DaysBefore     *int                   `json:"days_before,omitempty"`
+	PaidAmount     float64                `json:"paid_amount"`
+	PenaltyPercent float64                `json:"penalty_percent"`
+	PenaltyAmount  float64                `json:"penalty_amount"`
+	RefundAmount   float64                `json:"refund_amount"`
+	Rules          []TripCancellationRule `json:"rules"`
+	// последняя заявка на отмену, если есть
+	Request *OrderCancellation `json:"request,omitempty"`
+}
+
+// OrderCancellation — заявка клиента на отмену заказа и решение по ней.
+// RefundAmount — сколько решено вернуть, RefundedAmount — сколько из этого вернули через платёжного провайдера.
+type OrderCancellation struct {
+	ID             int        `json:"id"`
+	OrderID        int        `json:"order_id"`
+	Source         string     `json:"source"`
+	Reason         *string    `json:"reason,omitempty"`
+	PreviousStatus string     `json:"previous_status"`
+	DaysBefore     *int       `json:"days_before,omitempty"`
+	PaidAmount     float64    `json:"paid_amount"`
+	PenaltyPercent float64    `json:"penalty_percent"`
+	PenaltyAmount  float64    `json:"penalty_amount"`
+	RefundAmount   float64    `json:"refund_amount"`
+	RefundedAmount float64    `json:"refunded_amount"`
+	Status         string     `json:"status"`
+	DecidedBy      *int       `json:"decided_by,omitempty"`
+	DecidedAt      *time.Time `json:"decided_at,omitempty"`
+	Comment        *string    `json:"comment,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// CancellationListItem — заявка на отмену в списке админки
+type CancellationListItem struct {
+	OrderCancellation
+	UserName   string   `json:"user_name"`
+	UserPhone  string   `json:"user_phone"`
+	TripID     *int     `json:"trip_id,omitempty"`
+	TripTitle  *string  `json:"trip_title,omitempty"`
+	TotalPrice *float64 `json:"total_price,omitempty"`
+}
+
+type CancelOrderRequest struct {
+	Reason string `json:"reason" validate:"omitempty,max=1000" example:"Изменились планы"`
+}
+
+// ApproveCancellationRequest — refund_amount необязателен: по умолчанию оплаченное минус штраф
+type ApproveCancellationRequest struct {
+	RefundAmount *float64 `json:"refund_amount,omitempty" example:"75000"`
+	Comment      string   `json:"comment,omitempty" example:"Возврат по правилам тура"`
+}
+
+type RejectCancellationRequest struct {
+	Comment string `json:"comment,omitempty" example:"Тур уже начался"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Customer — клиент, объединяющий заказы и заявки по нормализованному телефону
+type Customer struct {
+	ID         int       `json:"id"`
+	Phone      string    `json:"phone"`
+	Name       string    `json:"name"`
+	MergedInto *int      `json:"merged_into,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// CustomerSummary — клиент со сводкой по заказам; total_spent — сумма оплаченных и завершённых
+type CustomerSummary struct {
+	Customer
+	OrdersCount   int        `json:"orders_count"`
+	TotalSpent    float64    `json:"total_spent"`
+	LastContactAt *time.Time `json:"last_contact_at,omitempty"`
+}
+
+// Типы событий в таймлайне клиента
+const (
+	CustomerEventOrder    = "order"
+	CustomerEventFeedback = "feedback"
+	CustomerEventStatus   = "status"
+	CustomerEventNote     = "note"
+)
+
+type CustomerTimelineItem struct {
+	Type       string    `json:"type"`
+	At         time.Time `json:"at"`
+	OrderID    *int      `json:"order_id,omitempty"`
+	FeedbackID *int      `json:"feedback_id,omitempty"`
+	Status     *string   `json:"status,omitempty"`
+	Text       *string   `json:"text,omitempty"`
+	Amount     *float64  `json:"amount,omitempty"`
+}
+
+// MergeCustomersRequest — перенести заказы и заявки дубликата в текущего клиента
+type MergeCustomersRequest struct {
+	DuplicateID int `json:"duplicate_id" example:"42"`
+}
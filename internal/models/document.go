@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// DocumentTemplate — шаблон документа (text/template), редактируется в админке
+type DocumentTemplate struct {
+	Kind      string     `json:"kind"`
+	Body      string     `json:"body"`
+	IsDefault bool       `json:"is_default"`
+	UpdatedBy *int       `json:"updated_by,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+type DocumentTemplateRequest struct {
+	Body string `json:"body" example:"ДОГОВОР № {{.Number}} ..."`
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// OrderFilter — фильтры списка и выгрузки заказов; From/To — по created_at, To не включается
+type OrderFilter struct {
+	Status string
+	Phone  string
+	IsRead *bool
+	From   *time.Time
+	To     *time.Time
+
+	AssignedTo      *int
+	Unassigned      bool
+	Tag             string
+	OverdueFollowUp bool // next_call_at в прошлом, заказ не закрыт
+
+	UserID *int // заказы пользователя (личный кабинет)
+}
+
+type FeedbackFilter struct {
+	Phone  string
+	IsRead *bool
+	From   *time.Time
+	To     *time.Time
+}
+
+// OrderExportRow — строка выгрузки заказов с данными тура
+type OrderExportRow struct {
+	Order
+	TripTitle    *string
+	TripPrice    *float64
+	AssigneeName *string
+}
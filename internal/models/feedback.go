@@ -5,12 +5,16 @@ import "time"
 type Feedback struct {
 	ID        int       `json:"id"`
 	UserName  string    `json:"user_name"`
-	UserPhone string    `json:"user_phone"`
+	UserPhone string    `json:"user_phone"` // E.164
 	IsRead    bool      `json:"is_read"`
 	CreatedAt time.Time `json:"created_at"`
+
+	UserPhoneDisplay string `json:"user_phone_display,omitempty"`
+
+	CustomerID *int `json:"customer_id,omitempty"`
 }
 
 type FeedbackRequest struct {
 	UserName  string `json:"user_name"`
-	UserPhone string `json:"user_phone"`
+	UserPhone string `json:"user_phone" validate:"required,phone" example:"+7 928 123-45-67"`
 }
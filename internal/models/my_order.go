@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+// ======== Заказы в личном кабинете ========
+
+// MyOrder — заказ глазами клиента: без CRM-полей (менеджер, метки, заметки)
+type MyOrder struct {
+	ID         int              `json:"id"`
+	TripID     *int             `json:"trip_id,omitempty"`
+	TripTitle  *string          `json:"trip_title,omitempty"`
+	TripStart  *time.Time       `json:"trip_start_date,omitempty"`
+	TripEnd    *time.Time       `json:"trip_end_date,omitempty"`
+	Name       *string          `json:"name,omitempty"`
+	Date       *string          `json:"date,omitempty"`
+	Status     string           `json:"status"`
+	TotalPrice *float64         `json:"total_price,omitempty"`
+	Travellers []OrderTraveller `json:"travellers"`
+	CreatedAt  time.Time        `json:"created_at"`
+}
+
+// MyOrderDetails — карточка заказа: история статусов, документы и оплаты
+type MyOrderDetails struct {
+	MyOrder
+	Timeline  []OrderTimelineItem `json:"timeline"`
+	Documents []OrderDocument     `json:"documents"`
+	Payments  []Payment           `json:"payments"`
+	Schedule  *OrderSchedule      `json:"schedule,omitempty"`
+}
+
+// TrackedOrder — заказ по публичной ссылке: имя, телефон и туристы скрыты частично
+type TrackedOrder struct {
+	MyOrderDetails
+	UserName  string `json:"username"`
+	UserPhone string `json:"phone"`
+}
+
+// OrderTimelineItem — смена статуса без внутренних комментариев и имён сотрудников
+type OrderTimelineItem struct {
+	Status string    `json:"status"`
+	At     time.Time `json:"at"`
+}
+
+// OrderDocument — документ, доступный для скачивания
+type OrderDocument struct {
+	Kind  string `json:"kind"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+func NewMyOrder(o Order) MyOrder {
+	m := MyOrder{
+		ID:         o.ID,
+		Name:       o.Name,
+		Date:       o.Date,
+		Status:     o.Status,
+		TotalPrice: o.TotalPrice,
+		Travellers: o.Travellers,
+		CreatedAt:  o.CreatedAt,
+	}
+	if o.TripID.Valid {
+		id := int(o.TripID.Int32)
+		m.TripID = &id
+	}
+	if m.Travellers == nil {
+		m.Travellers = []OrderTraveller{}
+	}
+	return m
+}
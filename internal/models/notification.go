@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Статусы уведомления в outbox
+const (
+	NotificationPending = "pending"
+	NotificationSent    = "sent"
+	// NotificationDead — попытки исчерпаны, отправить можно только вручную
+	NotificationDead = "dead"
+)
+
+func IsValidNotificationStatus(s string) bool {
+	switch s {
+	case NotificationPending, NotificationSent, NotificationDead:
+		return true
+	}
+	return false
+}
+
+// OutboxNotification — готовое сообщение в очереди на отправку
+type OutboxNotification struct {
+	ID        int    `json:"id"`
+	EventType string `json:"event_type" example:"order.created"`
+	Channel   string `json:"channel" example:"telegram"`
+	Audience  string `json:"audience" example:"admin"`
+	// пусто — получатели канала по умолчанию (чат/адреса менеджеров)
+	Recipients    []string   `json:"recipients"`
+	Subject       string     `json:"subject"`
+	Body          string     `json:"body"`
+	LinkText      *string    `json:"link_text,omitempty"`
+	LinkURL       *string    `json:"link_url,omitempty"`
+	Status        string     `json:"status" example:"pending"`
+	Attempts      int        `json:"attempts"`
+	LastError     *string    `json:"last_error,omitempty"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	// кнопки бота под сообщением в Telegram
+	Actions []NotificationAction `json:"actions,omitempty"`
+}
+
+// NotificationAction — кнопка бота: подпись и callback_data
+type NotificationAction struct {
+	Text string `json:"text"`
+	Data string `json:"data"`
+}
+
+type NotificationFilter struct {
+	Status    string
+	EventType string
+	Channel   string
+}
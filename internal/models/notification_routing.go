@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// NotificationDestination — чат менеджеров в Telegram и правила, по которым туда идут уведомления
+type NotificationDestination struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name" example:"Умра, Махачкала"`
+	ChatID string `json:"chat_id" example:"-1001234567890"`
+	// тихие часы: уведомления копятся и уходят в QuietTo
+	QuietFrom *string            `json:"quiet_from,omitempty" example:"22:00"`
+	QuietTo   *string            `json:"quiet_to,omitempty" example:"08:00"`
+	Timezone  string             `json:"timezone" example:"Europe/Moscow"`
+	IsActive  bool               `json:"is_active"`
+	Rules     []NotificationRule `json:"rules"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+// NotificationRule — условия правила; пустое условие подходит под любое значение
+type NotificationRule struct {
+	ID            int      `json:"id"`
+	EventType     *string  `json:"event_type,omitempty" example:"order.created"`
+	TripType      *string  `json:"trip_type,omitempty" example:"umrah"`
+	DepartureCity *string  `json:"departure_city,omitempty" example:"Махачкала"`
+	MinAmount     *float64 `json:"min_amount,omitempty" example:"100000"`
+	MaxAmount     *float64 `json:"max_amount,omitempty"`
+}
+
+type NotificationDestinationRequest struct {
+	Name   string `json:"name" validate:"required,max=200"`
+	ChatID string `json:"chat_id" validate:"required,max=100"`
+	// "HH:MM"; задаются вместе или не задаются вовсе
+	QuietFrom *string `json:"quiet_from" example:"22:00"`
+	QuietTo   *string `json:"quiet_to" example:"08:00"`
+	// по умолчанию Europe/Moscow
+	Timezone string `json:"timezone" validate:"omitempty,max=64" example:"Europe/Moscow"`
+	// по умолчанию true
+	IsActive *bool                     `json:"is_active"`
+	Rules    []NotificationRuleRequest `json:"rules" validate:"required,min=1,dive"`
+}
+
+type NotificationRuleRequest struct {
+	EventType     *string  `json:"event_type" example:"order.created"`
+	TripType      *string  `json:"trip_type" validate:"omitempty,max=100" example:"umrah"`
+	DepartureCity *string  `json:"departure_city" validate:"omitempty,max=100" example:"Махачкала"`
+	MinAmount     *float64 `json:"min_amount" validate:"omitempty,gte=0"`
+	MaxAmount     *float64 `json:"max_amount" validate:"omitempty,gte=0"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// NotificationTemplate — текст уведомления для события, аудитории, канала и языка
+type NotificationTemplate struct {
+	ID        int       `json:"id"`
+	EventType string    `json:"event_type" example:"order.created"`
+	Audience  string    `json:"audience" example:"admin"`
+	Channel   string    `json:"channel" example:"telegram"`
+	Locale    string    `json:"locale" example:"ru"`
+	Subject   string    `json:"subject" example:"Новый заказ №{{.Order.ID}}"`
+	Body      string    `json:"body" example:"🛒 <b>Новый заказ!</b> {{.Order.UserName}}, {{price .Order.TotalPrice}} руб."`
+	LinkText  *string   `json:"link_text,omitempty" example:"Открыть тур"`
+	LinkURL   *string   `json:"link_url,omitempty" example:"{{.TripURL}}"`
+	IsActive  bool      `json:"is_active"`
+	UpdatedBy *int      `json:"updated_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type NotificationTemplateRequest struct {
+	EventType string `json:"event_type" validate:"required" example:"order.created"`
+	// admin (по умолчанию) или customer
+	Audience string `json:"audience" validate:"omitempty,oneof=admin customer" example:"admin"`
+	Channel  string `json:"channel" validate:"required,oneof=telegram email sms" example:"telegram"`
+	// пусто — язык по умолчанию (NOTIFY_LOCALE)
+	Locale   string  `json:"locale" validate:"omitempty,max=10" example:"ru"`
+	Subject  string  `json:"subject" validate:"max=500"`
+	Body     string  `json:"body" validate:"required,max=10000"`
+	LinkText *string `json:"link_text" validate:"omitempty,max=200"`
+	LinkURL  *string `json:"link_url" validate:"omitempty,max=1000"`
+	// по умолчанию true
+	IsActive *bool `json:"is_active"`
+}
+
+type NotificationTemplateFilter struct {
+	EventType string
+	Channel   string
+	Locale    string
+}
+
+// NotificationPreview — шаблон, отрендеренный на примере заказа
+type NotificationPreview struct {
+	Subject  string  `json:"subject"`
+	Body     string  `json:"body"`
+	LinkText *string `json:"link_text,omitempty"`
+	LinkURL  *string `json:"link_url,omitempty"`
+}
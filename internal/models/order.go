@@ -36,9 +36,65 @@ type Order struct {
 	Date      *string `json:"date,omitempty"`
 	Price     *string `json:"price,omitempty"`
 	UserName  string  `json:"username"`
-	UserPhone string  `json:"phone"`
+	UserPhone string  `json:"phone"` // E.164
+	// номер в привычном написании: +7 (928) 123-45-67
+	UserPhoneDisplay string `json:"phone_display,omitempty"`
+
+	TotalPrice *float64         `json:"total_price,omitempty"`
+	Travellers []OrderTraveller `json:"travellers,omitempty"`
+
+	// чат клиента в Telegram, если заявка пришла из мини-приложения
+	TelegramChatID *int64 `json:"telegram_chat_id,omitempty"`
 
 	Status    string    `json:"status"`
 	IsRead    bool      `json:"is_read"`
 	CreatedAt time.Time `json:"created_at"`
+
+	// CRM: ответственный менеджер, следующий звонок и метки
+	AssignedTo *int       `json:"assigned_to,omitempty"`
+	NextCallAt *time.Time `json:"next_call_at,omitempty"`
+	Tags       []string   `json:"tags"`
+
+	CustomerID *int `json:"customer_id,omitempty"`
+	// зарегистрированный пользователь, оформивший заказ
+	UserID *int `json:"user_id,omitempty"`
+	// секрет публичной ссылки /orders/track/{token}
+	TrackingToken string `json:"tracking_token,omitempty"`
+}
+
+// ======== Туристы заказа ========
+
+const (
+	TravellerAdult  = "adult"
+	TravellerChild  = "child"
+	TravellerInfant = "infant"
+)
+
+const (
+	GenderMale   = "male"
+	GenderFemale = "female"
+)
+
+type OrderTraveller struct {
+	ID            int        `json:"id"`
+	OrderID       int        `json:"order_id"`
+	ProfileID     *int       `json:"profile_id,omitempty"`
+	FullName      string     `json:"full_name"`
+	BirthDate     *time.Time `json:"birth_date,omitempty"`
+	Gender        *string    `json:"gender,omitempty"`
+	TravellerType string     `json:"traveller_type"`
+	Room          *string    `json:"room,omitempty"`
+	Price         *float64   `json:"price,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// TravellerRequest — турист в заявке на покупку
+type TravellerRequest struct {
+	// сохранённый профиль туриста (только для авторизованных пользователей)
+	ProfileID     *int   `json:"profile_id,omitempty" example:"3"`
+	FullName      string `json:"full_name" example:"Иванов Иван"`
+	BirthDate     string `json:"birth_date,omitempty" example:"1990-05-21"`
+	Gender        string `json:"gender,omitempty" example:"male"`
+	TravellerType string `json:"traveller_type" example:"adult"`
+	Room          string `json:"room,omitempty" example:"1"`
 }
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// OrderNote — внутренняя заметка менеджера по заказу, клиенту не видна
+type OrderNote struct {
+	ID         int       `json:"id"`
+	OrderID    int       `json:"order_id"`
+	AuthorID   *int      `json:"author_id,omitempty"`
+	AuthorName *string   `json:"author_name,omitempty"`
+	Body       string    `json:"body"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type OrderNoteRequest struct {
+	Body string `json:"body" example:"Перезвонить после 18:00, уточнить даты"`
+}
+
+// AssignOrderRequest — назначить менеджера; user_id=null снимает назначение
+type AssignOrderRequest struct {
+	UserID  *int   `json:"user_id" example:"3"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// FollowUpRequest — дата следующего звонка; null снимает напоминание
+type FollowUpRequest struct {
+	NextCallAt *time.Time `json:"next_call_at" example:"2025-10-20T10:00:00+03:00"`
+}
+
+type OrderTagsRequest struct {
+	Tags []string `json:"tags" example:"vip,повторный"`
+}
+
+// FollowUpReminder — заказ, по которому пора звонить
+type FollowUpReminder struct {
+	OrderID      int       `json:"order_id"`
+	UserName     string    `json:"username"`
+	UserPhone    string    `json:"phone"`
+	Status       string    `json:"status"`
+	NextCallAt   time.Time `json:"next_call_at"`
+	AssignedTo   *int      `json:"assigned_to,omitempty"`
+	AssigneeName *string   `json:"assignee_name,omitempty"`
+}
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// ======== Жизненный цикл заказа ========
+//
+// new → confirmed → paid → completed
+// new → rejected | cancelled
+// confirmed → cancelled
+// paid → refunded
+// new | confirmed | paid → cancellation_requested → cancelled | refunded
+// (при отказе в отмене заказ возвращается в прежний статус — RestoreStatus)
+
+const (
+	OrderStatusNew       = "new"
+	OrderStatusConfirmed = "confirmed"
+	OrderStatusPaid      = "paid"
+	OrderStatusCompleted = "completed"
+	OrderStatusCancelled = "cancelled"
+	OrderStatusRejected  = "rejected"
+	OrderStatusRefunded  = "refunded"
+
+	// клиент запросил отмену, ждёт решения менеджера
+	OrderStatusCancellationRequested = "cancellation_requested"
+)
+
+var orderTransitions = map[string][]string{
+	OrderStatusNew:                   {OrderStatusConfirmed, OrderStatusRejected, OrderStatusCancelled, OrderStatusCancellationRequested},
+	OrderStatusConfirmed:             {OrderStatusPaid, OrderStatusCancelled, OrderStatusCancellationRequested},
+	OrderStatusPaid:                  {OrderStatusCompleted, OrderStatusRefunded, OrderStatusCancellationRequested},
+	OrderStatusCancellationRequested: {OrderStatusCancelled, OrderStatusRefunded},
+	OrderStatusCompleted:             {},
+	OrderStatusCancelled:             {},
+	OrderStatusRejected:              {},
+	OrderStatusRefunded:              {},
+}
+
+// IsValidOrderStatus — статус входит в жизненный цикл
+func IsValidOrderStatus(status string) bool {
+	_, ok := orderTransitions[status]
+	return ok
+}
+
+// CanTransitionOrder — допустим ли переход from → to
+func CanTransitionOrder(from, to string) bool {
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CanRestoreOrder — можно ли вернуть заказ из cancellation_requested в статус до заявки
+// (менеджер отклонил отмену). Обычным переходом это не делается, чтобы заказ
+// нельзя было «вернуть» в произвольный статус из админки или Telegram.
+func CanRestoreOrder(from, to string) bool {
+	return from == OrderStatusCancellationRequested && CanTransitionOrder(to, OrderStatusCancellationRequested)
+}
+
+// CanSetOrderStatus — можно ли перевести заказ вручную (админка, кнопки бота). В cancellation_requested
+// и из него заказ переходит только через заявку на отмену: иначе у заказа не будет заявки
+// или она останется нерассмотренной
+func CanSetOrderStatus(from, to string) bool {
+	return from != OrderStatusCancellationRequested && to != OrderStatusCancellationRequested && CanTransitionOrder(from, to)
+}
+
+// NextOrderStatuses — куда можно перевести заказ из текущего статуса
+func NextOrderStatuses(from string) []string {
+	return orderTransitions[from]
+}
+
+// IsFinalOrderStatus — из статуса нет переходов, заказ закрыт
+func IsFinalOrderStatus(status string) bool {
+	next, ok := orderTransitions[status]
+	return ok && len(next) == 0
+}
+
+// FinalOrderStatuses — все закрытые статусы
+func FinalOrderStatuses() []string {
+	var list []string
+	for status, next := range orderTransitions {
+		if len(next) == 0 {
+			list = append(list, status)
+		}
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Типы записей в истории заказа
+const (
+	OrderEventStatus     = "status"
+	OrderEventAssignment = "assignment"
+)
+
+// Actor — кто изменил заказ: пользователь админки или внешний источник (telegram, system)
+type Actor struct {
+	ID   *int
+	Name string
+}
+
+// OrderStatusChange — запись истории заказа: смена статуса (event=status)
+// или переназначение менеджера (event=assignment, статус не меняется)
+type OrderStatusChange struct {
+	ID         int       `json:"id"`
+	OrderID    int       `json:"order_id"`
+	Event      string    `json:"event"`
+	FromStatus *string   `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	ActorID    *int      `json:"actor_id,omitempty"`
+	ActorName  *string   `json:"actor_name,omitempty"`
+	Comment    *string   `json:"comment,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	FromAssignee     *int    `json:"from_assignee,omitempty"`
+	FromAssigneeName *string `json:"from_assignee_name,omitempty"`
+	ToAssignee       *int    `json:"to_assignee,omitempty"`
+	ToAssigneeName   *string `json:"to_assignee_name,omitempty"`
+}
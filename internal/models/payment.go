@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// ======== Онлайн-оплата заказа ========
+
+type Payment struct {
+	ID              int       `json:"id"`
+	OrderID         int       `json:"order_id"`
+	Provider        string    `json:"provider"`
+	ExternalID      *string   `json:"external_id,omitempty"`
+	Amount          float64   `json:"amount"`
+	RefundedAmount  float64   `json:"refunded_amount"`
+	Currency        string    `json:"currency"`
+	Status          string    `json:"status"` // pending/succeeded/canceled/refunded
+	ConfirmationURL *string   `json:"confirmation_url,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Статусы возврата по платежу
+const (
+	RefundPending   = "pending"
+	RefundSucceeded = "succeeded"
+	RefundFailed    = "failed"
+)
+
+// PaymentRefund — возврат по платежу. Пока провайдер не ответил, возврат в статусе pending,
+// и повтор идёт с тем же ключом идемпотентности
+type PaymentRefund struct {
+	ID         int       `json:"id"`
+	PaymentID  int       `json:"payment_id"`
+	Amount     float64   `json:"amount"`
+	Status     string    `json:"status"` // pending/succeeded/failed
+	ExternalID *string   `json:"external_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// CreatePaymentRequest — amount необязателен: по умолчанию неоплаченный остаток заказа
+type CreatePaymentRequest struct {
+	Amount    *float64 `json:"amount,omitempty" example:"50000"`
+	ReturnURL string   `json:"return_url,omitempty" example:"https://example.com/orders/paid"`
+}
+
+// RefundPaymentRequest — amount необязателен: по умолчанию возвращается весь остаток платежа
+type RefundPaymentRequest struct {
+	Amount  *float64 `json:"amount,omitempty" example:"10000"`
+	Comment string   `json:"comment,omitempty" example:"Отказ клиента"`
+}
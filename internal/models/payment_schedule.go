@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// ======== График платежей (задаток + остаток) ========
+
+// Точка отсчёта срока платежа
+const (
+	DueAnchorBooking         = "booking"          // дата бронирования (создания заказа)
+	DueAnchorStartDate       = "start_date"       // дата начала тура
+	DueAnchorBookingDeadline = "booking_deadline" // дедлайн бронирования тура
+)
+
+// TripPaymentRule — один платёж в графике тура.
+// Percent и Amount взаимоисключающие; если не задано ни то, ни другое — платёж на остаток.
+type TripPaymentRule struct {
+	ID            int      `json:"id"`
+	TripID        int      `json:"trip_id"`
+	Seq           int      `json:"seq"`
+	Title         string   `json:"title"`
+	Percent       *float64 `json:"percent,omitempty"`
+	Amount        *float64 `json:"amount,omitempty"`
+	DueAnchor     string   `json:"due_anchor"`
+	DueOffsetDays int      `json:"due_offset_days"`
+}
+
+type TripPaymentRuleRequest struct {
+	Title         string   `json:"title" example:"Задаток"`
+	Percent       *float64 `json:"percent,omitempty" example:"30"`
+	Amount        *float64 `json:"amount,omitempty"`
+	DueAnchor     string   `json:"due_anchor" example:"booking"`
+	DueOffsetDays int      `json:"due_offset_days" example:"3"`
+}
+
+type OrderInstallment struct {
+	ID         int        `json:"id"`
+	OrderID    int        `json:"order_id"`
+	Seq        int        `json:"seq"`
+	Title      string     `json:"title"`
+	Amount     float64    `json:"amount"`
+	PaidAmount float64    `json:"paid_amount"`
+	DueDate    time.Time  `json:"due_date"`
+	PaidAt     *time.Time `json:"paid_at,omitempty"`
+	RemindedAt *time.Time `json:"reminded_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Outstanding — неоплаченная часть платежа
+func (i OrderInstallment) Outstanding() float64 {
+	if i.PaidAmount >= i.Amount {
+		return 0
+	}
+	return i.Amount - i.PaidAmount
+}
+
+type OrderSchedule struct {
+	OrderID      int                `json:"order_id"`
+	Total        float64            `json:"total"`
+	Paid         float64            `json:"paid"`
+	Outstanding  float64            `json:"outstanding"`
+	Installments []OrderInstallment `json:"installments"`
+}
+
+// OverdueInstallment — просроченный платёж с данными заказа для админки
+type OverdueInstallment struct {
+	OrderInstallment
+	Outstanding float64 `json:"outstanding"`
+	UserName    string  `json:"user_name"`
+	UserPhone   string  `json:"user_phone"`
+	OrderStatus string  `json:"order_status"`
+	TripID      *int    `json:"trip_id,omitempty"`
+	TripTitle   *string `json:"trip_title,omitempty"`
+}
@@ -0,0 +1,24 @@
+package models
+
+// PhoneRecord — номер телефона из заявки, заказа или карточки клиента
+type PhoneRecord struct {
+	ID      int
+	Phone   string
+	Display string
+}
+
+// PhoneNormalizeStats — итог приведения номеров одной таблицы
+type PhoneNormalizeStats struct {
+	Checked int `json:"checked"`
+	Updated int `json:"updated"`
+	Invalid int `json:"invalid"`
+	Merged  int `json:"merged,omitempty"`
+}
+
+// PhoneNormalizeResult — итог команды `phones normalize`
+type PhoneNormalizeResult struct {
+	DryRun    bool                `json:"dry_run"`
+	Orders    PhoneNormalizeStats `json:"orders"`
+	Feedbacks PhoneNormalizeStats `json:"feedbacks"`
+	Customers PhoneNormalizeStats `json:"customers"`
+}
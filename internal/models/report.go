@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+const (
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// Digest — сводка за период для владельца: заказы, заявки, просмотры и ближайшие вылеты
+type Digest struct {
+	Period string `json:"period" example:"daily"`
+	// From включительно, To не включительно
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+
+	OrdersTotal    int             `json:"orders_total" example:"14"`
+	OrdersByStatus []KV            `json:"orders_by_status"`
+	FeedbackCount  int             `json:"feedback_count" example:"5"`
+	TopTrips       []TripActivity  `json:"top_trips"`
+	Departures     []TripDeparture `json:"departures"`
+}
+
+// TripActivity — просмотры и заказы тура за период
+type TripActivity struct {
+	TripID int    `json:"trip_id" example:"12"`
+	Title  string `json:"title" example:"Умра — 10 дней"`
+	Views  int    `json:"views" example:"340"`
+	Orders int    `json:"orders" example:"6"`
+	// Conversion — заказы на 100 просмотров; nil — просмотров не было
+	Conversion *float64 `json:"conversion,omitempty" example:"1.76"`
+}
+
+// TripDeparture — ближайший вылет и сколько на него забронировано
+type TripDeparture struct {
+	TripID     int       `json:"trip_id" example:"12"`
+	Title      string    `json:"title" example:"Умра — 10 дней"`
+	StartDate  time.Time `json:"start_date"`
+	Bookings   int       `json:"bookings" example:"9"`
+	Travellers int       `json:"travellers" example:"21"`
+}
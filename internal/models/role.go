@@ -1,6 +1,50 @@
 package models
 
+// Role — роль пользователя и её права. Встроенные роли (IsSystem) нельзя удалить,
+// а права роли admin нельзя изменить: у неё всегда все права
 type Role struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
+	ID          int      `json:"id"`
+	Name        string   `json:"name" example:"editor"`
+	Description string   `json:"description" example:"Новости и медиа"`
+	IsSystem    bool     `json:"is_system"`
+	Permissions []string `json:"permissions" example:"news.write,media.write"`
+	// сколько пользователей с этой ролью
+	UsersCount int `json:"users_count"`
 }
+
+// Permission — право из справочника permissions
+type Permission struct {
+	Code        string `json:"code" example:"orders.write"`
+	Description string `json:"description" example:"Обработка заказов: статусы, заметки, назначение, отмены"`
+}
+
+type RoleRequest struct {
+	Name        string   `json:"name" validate:"required,max=50" example:"editor"`
+	Description string   `json:"description" validate:"max=200" example:"Новости и медиа"`
+	Permissions []string `json:"permissions" validate:"dive,required" example:"news.write,media.write"`
+}
+
+// Права из таблицы permissions; новые права добавляются миграцией вместе с выдачей роли admin
+const (
+	PermUsersRead           = "users.read"
+	PermUsersWrite          = "users.write"
+	PermRolesManage         = "roles.manage"
+	PermTravellersRead      = "travellers.read"
+	PermTripsRead           = "trips.read"
+	PermTripsWrite          = "trips.write"
+	PermTripsDelete         = "trips.delete"
+	PermNewsWrite           = "news.write"
+	PermMediaWrite          = "media.write"
+	PermOrdersRead          = "orders.read"
+	PermOrdersWrite         = "orders.write"
+	PermOrdersDelete        = "orders.delete"
+	PermPaymentsWrite       = "payments.write"
+	PermCustomersRead       = "customers.read"
+	PermCustomersWrite      = "customers.write"
+	PermFeedbackRead        = "feedback.read"
+	PermFeedbackWrite       = "feedback.write"
+	PermStatsRead           = "stats.read"
+	PermNotificationsManage = "notifications.manage"
+	PermDocumentsManage     = "documents.manage"
+	PermCachePurge          = "cache.purge"
+)
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Session — вход пользователя: живёт, пока обновляется refresh-токен и не отозвана
+type Session struct {
+	ID         int64      `json:"id"`
+	UserID     int        `json:"user_id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// SessionPrincipal — владелец открытой сессии, его текущая роль и права этой роли
+type SessionPrincipal struct {
+	UserID      int
+	RoleID      int
+	Permissions []string
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// Виды счётчиков неудачных входов
+const (
+	LoginByEmail = "email"
+	LoginByIP    = "ip"
+)
+
+// LoginLock — email или IP, с которых вход временно закрыт после неудачных попыток
+type LoginLock struct {
+	Kind          string    `json:"kind" example:"email"`
+	Key           string    `json:"key" example:"admin@example.com"`
+	Failures      int       `json:"failures" example:"5"`
+	LastFailureAt time.Time `json:"last_failure_at"`
+	LockedUntil   time.Time `json:"locked_until"`
+}
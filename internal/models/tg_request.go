@@ -5,5 +5,19 @@ type BuyRequest struct {
 	Date      string `json:"date"`
 	Price     string `json:"price"`
 	UserName  string `json:"username"`
-	UserPhone string `json:"phone"`
+	UserPhone string `json:"phone" validate:"required,phone" example:"8 (928) 123-45-67"`
+
+	Travellers []TravellerRequest `json:"travellers,omitempty"`
+
+	// initData Telegram мини-приложения (window.Telegram.WebApp.initData), если заявка отправлена из него.
+	// chat_id клиента берётся только из неё после проверки подписи: туда уходят документы по заказу
+	TelegramInitData string `json:"telegram_init_data,omitempty" example:"query_id=AAE...&user=%7B%22id%22%3A123456789%7D&auth_date=1760000000&hash=..."`
+}
+
+// BuyResponse — принятая заявка; по tracking_url клиент следит за заказом без входа
+type BuyResponse struct {
+	Status        string `json:"status" example:"success"`
+	OrderID       int    `json:"order_id" example:"42"`
+	TrackingToken string `json:"tracking_token" example:"Jx3k0c9Qm2Vv7bL1pZs8TqYwRn4uHe6A"`
+	TrackingURL   string `json:"tracking_url" example:"https://example.com/api/v1/orders/track/Jx3k0c9Qm2Vv7bL1pZs8TqYwRn4uHe6A"`
 }
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// ======== Профиль туриста (паспортные данные) ========
+
+// TravellerProfile — расшифрованный профиль туриста, принадлежит зарегистрированному пользователю
+type TravellerProfile struct {
+	ID             int        `json:"id"`
+	UserID         int        `json:"user_id"`
+	FullName       string     `json:"full_name"`
+	Gender         *string    `json:"gender,omitempty"`
+	BirthDate      *time.Time `json:"birth_date,omitempty"`
+	PassportNumber string     `json:"passport_number"`
+	PassportExpiry *time.Time `json:"passport_expiry,omitempty"`
+	Nationality    string     `json:"nationality"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// TravellerProfileRecord — строка traveller_profiles, чувствительные поля зашифрованы
+type TravellerProfileRecord struct {
+	ID                int
+	UserID            int
+	FullName          string
+	Gender            *string
+	BirthDateEnc      string
+	PassportNumberEnc string
+	PassportExpiryEnc string
+	NationalityEnc    string
+	PassportLast4     string
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// TravellerProfileSummary — профиль без чувствительных данных (для списков в админке)
+type TravellerProfileSummary struct {
+	ID            int    `json:"id"`
+	UserID        int    `json:"user_id"`
+	FullName      string `json:"full_name"`
+	PassportLast4 string `json:"passport_last4"`
+}
+
+type TravellerProfileRequest struct {
+	FullName       string `json:"full_name" example:"Иванов Иван"`
+	Gender         string `json:"gender,omitempty" example:"male"`
+	BirthDate      string `json:"birth_date,omitempty" example:"1990-05-21"`
+	PassportNumber string `json:"passport_number" example:"75 1234567"`
+	PassportExpiry string `json:"passport_expiry,omitempty" example:"2030-01-01"`
+	Nationality    string `json:"nationality,omitempty" example:"RU"`
+}
+
+// ======== Аудит ========
+
+type AuditEntry struct {
+	ID        int       `json:"id"`
+	ActorID   *int      `json:"actor_id,omitempty"`
+	Action    string    `json:"action"`
+	Entity    string    `json:"entity"`
+	EntityID  *int      `json:"entity_id,omitempty"`
+	Details   *string   `json:"details,omitempty"`
+	IP        *string   `json:"ip,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
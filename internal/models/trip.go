@@ -13,6 +13,8 @@ type Trip struct {
 	Season          string              `json:"season"`
 	Price           float64             `json:"price"`
 	FinalPrice      float64             `json:"final_price"`
+	ChildPrice      *float64            `json:"child_price,omitempty"`
+	InfantPrice     *float64            `json:"infant_price,omitempty"`
 	DiscountPercent int                 `json:"discount_percent"`
 	Currency        string              `json:"currency"`
 	Main            bool                `json:"main"`
@@ -55,6 +57,8 @@ type CreateTripRequest struct {
 	TripType        string        `json:"trip_type"`
 	Season          string        `json:"season"`
 	Price           float64       `json:"price"`
+	ChildPrice      *float64      `json:"child_price,omitempty"`
+	InfantPrice     *float64      `json:"infant_price,omitempty"`
 	DiscountPercent int           `json:"discount_percent"`
 	Currency        string        `json:"currency"`
 	Main            bool          `json:"main"`
@@ -74,6 +78,8 @@ type UpdateTripRequest struct {
 	TripType        *string       `json:"trip_type,omitempty"`
 	Season          *string       `json:"season,omitempty"`
 	Price           *float64      `json:"price,omitempty"`
+	ChildPrice      *float64      `json:"child_price,omitempty"`
+	InfantPrice     *float64      `json:"infant_price,omitempty"`
 	DiscountPercent *int          `json:"discount_percent,omitempty"`
 	Currency        *string       `json:"currency,omitempty"`
 	Main            *bool         `json:"main,omitempty"`
@@ -132,3 +138,24 @@ func (t *Trip) CalculateFinalPrice() {
 		t.FinalPrice = t.Price
 	}
 }
+
+// PriceFor — цена места для типа туриста с учётом скидки.
+// Если детская цена не задана, ребёнок едет по взрослой цене; младенец без цены — бесплатно.
+func (t *Trip) PriceFor(travellerType string) float64 {
+	base := t.Price
+	switch travellerType {
+	case TravellerChild:
+		if t.ChildPrice != nil {
+			base = *t.ChildPrice
+		}
+	case TravellerInfant:
+		if t.InfantPrice == nil {
+			return 0
+		}
+		base = *t.InfantPrice
+	}
+	if t.DiscountPercent > 0 {
+		return base * (100 - float64(t.DiscountPercent)) / 100
+	}
+	return base
+}
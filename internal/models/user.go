@@ -2,6 +2,13 @@ package models
 
 import "time"
 
+// Встроенные роли из таблицы roles; остальные роли создаются в админке
+const (
+	RoleUser    = 1
+	RoleAdmin   = 2
+	RoleManager = 3
+)
+
 type User struct {
 	ID        int       `json:"id"`
 	Email     string    `json:"email"`
@@ -11,6 +18,19 @@ type User struct {
 	RoleID    int       `json:"role_id"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// когда email подтверждён кодом из письма; nil — не подтверждён
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+
+	// телефон в E.164; по подтверждённому номеру к аккаунту привязываются анонимные заказы
+	Phone           *string    `json:"phone,omitempty"`
+	PhoneVerifiedAt *time.Time `json:"phone_verified_at,omitempty"`
+
+	// Telegram user ID сотрудника — от его имени работают кнопки бота в чате менеджеров
+	TelegramID *int64 `json:"telegram_id,omitempty"`
+
+	// права роли; заполняются в профиле текущего пользователя и для сотрудника в боте
+	Permissions []string `json:"permissions,omitempty" example:"orders.read,orders.write"`
 }
 
 type RegisterRequest struct {
@@ -22,10 +42,52 @@ type RegisterRequest struct {
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required"`
+
+	// IP клиента для защиты от подбора пароля; заполняет хендлер
+	IP string `json:"-" swaggerignore:"true"`
 }
 
+// AuthResponse — короткий access-токен и refresh-токен для его обновления (POST /auth/refresh)
 type AuthResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	// через сколько секунд истечёт access-токен
+	ExpiresIn int `json:"expires_in" example:"900"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	// токен из ссылки в письме
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=6"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=6"`
+}
+
+// Что подтверждается кодом (verification_codes.kind)
+const (
+	VerifyEmail = "email"
+	VerifyPhone = "phone"
+)
+
+// VerifyEmailRequest — код из письма; вход не нужен: без подтверждения он может быть закрыт
+type VerifyEmailRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Code  string `json:"code" validate:"required,len=6,numeric" example:"123456"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type VerifyPhoneRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric" example:"123456"`
 }
 
 type CreateUserRequest struct {
@@ -38,9 +100,13 @@ type CreateUserRequest struct {
 type UpdateUserRequest struct {
 	FullName *string `json:"full_name,omitempty"`
 	RoleID   *int    `json:"role_id,omitempty"`
+	// Telegram user ID для кнопок бота; 0 — отвязать
+	TelegramID *int64 `json:"telegram_id,omitempty" example:"123456789"`
 }
 
 type UpdateProfileRequest struct {
 	FullName *string `json:"full_name,omitempty"`
 	Avatar   *string `json:"avatar,omitempty"`
+	// пустая строка удаляет номер; новый номер нужно подтвердить заново
+	Phone *string `json:"phone,omitempty" example:"+7 928 123-45-67"`
 }
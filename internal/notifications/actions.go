@@ -0,0 +1,56 @@
+package notifications
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+)
+
+// Действия кнопок бота; callback_data — "<действие>_<id заказа>"
+const (
+	ActionConfirm = "confirm"
+	ActionReject  = "reject"
+	ActionAssign  = "assign"
+)
+
+// Action — кнопка бота под сообщением в Telegram
+type Action struct {
+	Text string
+	Data string
+}
+
+// OrderActions — кнопки для заказа в текущем состоянии: подтвердить и отклонить
+// можно только новый заказ, взять себе — ещё никому не назначенный
+func OrderActions(o *models.Order) []Action {
+	var list []Action
+	if o.Status == models.OrderStatusNew {
+		list = append(list,
+			Action{Text: "✅ Подтвердить", Data: fmt.Sprintf("%s_%d", ActionConfirm, o.ID)},
+			Action{Text: "❌ Отклонить", Data: fmt.Sprintf("%s_%d", ActionReject, o.ID)},
+		)
+	}
+	if o.AssignedTo == nil && !models.IsFinalOrderStatus(o.Status) {
+		list = append(list, Action{Text: "🙋 Взять себе", Data: fmt.Sprintf("%s_%d", ActionAssign, o.ID)})
+	}
+	return list
+}
+
+// ParseAction разбирает callback_data кнопки заказа
+func ParseAction(data string) (action string, orderID int, err error) {
+	action, id, ok := strings.Cut(data, "_")
+	if !ok {
+		return "", 0, fmt.Errorf("callback %q: expected action_id", data)
+	}
+	switch action {
+	case ActionConfirm, ActionReject, ActionAssign:
+	default:
+		return "", 0, fmt.Errorf("callback %q: unknown action %q", data, action)
+	}
+	orderID, err = strconv.Atoi(id)
+	if err != nil || orderID <= 0 {
+		return "", 0, fmt.Errorf("callback %q: invalid order id", data)
+	}
+	return action, orderID, nil
+}
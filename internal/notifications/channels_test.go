@@ -0,0 +1,123 @@
+package notifications_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Ramcache/travel-backend/internal/notifications"
+	"github.com/Ramcache/travel-backend/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTelegram_Send(t *testing.T) {
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/botTOKEN/sendMessage", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	tg := notifications.NewTelegram(notifications.TelegramConfig{Token: "TOKEN", ChatID: "-100", BaseURL: srv.URL})
+	err := tg.Send(context.Background(), notifications.Message{
+		Text: "<b>hi</b>",
+		Link: &notifications.Link{Text: "Открыть", URL: "https://example.com"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "-100", got["chat_id"])
+	assert.Equal(t, "HTML", got["parse_mode"])
+	assert.Contains(t, got, "reply_markup")
+}
+
+func TestTelegram_Send_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"ok":false}`, http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	tg := notifications.NewTelegram(notifications.TelegramConfig{Token: "T", ChatID: "1", BaseURL: srv.URL})
+	assert.Error(t, tg.Send(context.Background(), notifications.Message{Text: "x"}))
+
+	noChat := notifications.NewTelegram(notifications.TelegramConfig{Token: "T", BaseURL: srv.URL})
+	assert.ErrorIs(t, noChat.Send(context.Background(), notifications.Message{Text: "x"}), notifications.ErrNoRecipients)
+}
+
+func TestTelegram_Send_Actions(t *testing.T) {
+	bot := testutil.NewFakeBotAPI(t)
+	tg := notifications.NewTelegram(notifications.TelegramConfig{Token: "T", ChatID: "-100", BaseURL: bot.URL})
+
+	err := tg.Send(context.Background(), notifications.Message{
+		Text: "Новый заказ",
+		Link: &notifications.Link{Text: "Открыть", URL: "https://example.com"},
+		Actions: []notifications.Action{
+			{Text: "Подтвердить", Data: "confirm_1"},
+			{Text: "Отклонить", Data: "reject_1"},
+			{Text: "Взять себе", Data: "assign_1"},
+		},
+	})
+	require.NoError(t, err)
+
+	calls := bot.Calls("sendMessage")
+	require.Len(t, calls, 1)
+	rows := calls[0].Payload["reply_markup"].(map[string]any)["inline_keyboard"].([]any)
+	// кнопки по две в ряд, ссылка — отдельным рядом
+	require.Len(t, rows, 3)
+	assert.Len(t, rows[0], 2)
+	assert.Equal(t, "assign_1", rows[1].([]any)[0].(map[string]any)["callback_data"])
+	assert.Equal(t, "https://example.com", rows[2].([]any)[0].(map[string]any)["url"])
+}
+
+func TestTelegram_AnswerAndEdit(t *testing.T) {
+	bot := testutil.NewFakeBotAPI(t)
+	tg := notifications.NewTelegram(notifications.TelegramConfig{Token: "T", BaseURL: bot.URL})
+	ctx := context.Background()
+
+	require.NoError(t, tg.AnswerCallback(ctx, "cb1", "Готово", true))
+	require.NoError(t, tg.EditMessage(ctx, -100, 77, notifications.Message{Text: "Подтверждён"}))
+
+	answer := bot.Calls("answerCallbackQuery")
+	require.Len(t, answer, 1)
+	assert.Equal(t, "cb1", answer[0].Payload["callback_query_id"])
+	assert.Equal(t, true, answer[0].Payload["show_alert"])
+
+	edit := bot.Calls("editMessageText")
+	require.Len(t, edit, 1)
+	assert.EqualValues(t, 77, edit[0].Payload["message_id"])
+	// кнопок больше нет — клавиатура очищается
+	assert.Empty(t, edit[0].Payload["reply_markup"].(map[string]any)["inline_keyboard"])
+}
+
+func TestTelegram_EditMessage_NotModified(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"ok":false,"description":"Bad Request: message is not modified"}`, http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	tg := notifications.NewTelegram(notifications.TelegramConfig{Token: "T", BaseURL: srv.URL})
+	assert.NoError(t, tg.EditMessage(context.Background(), 1, 2, notifications.Message{Text: "x"}))
+}
+
+func TestSMS_Send(t *testing.T) {
+	var got []map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		got = append(got, body)
+	}))
+	defer srv.Close()
+
+	sms := notifications.NewSMS(notifications.SMSConfig{URL: srv.URL, Token: "secret", To: []string{"+7900", "+7901"}})
+	err := sms.Send(context.Background(), notifications.Message{
+		Text: "Заявка принята",
+		Link: &notifications.Link{URL: "https://t.example/tok"},
+	})
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "+7901", got[1]["to"])
+	assert.Equal(t, "Заявка принята https://t.example/tok", got[0]["text"])
+}
@@ -0,0 +1,92 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	// To — адреса менеджеров, получатели по умолчанию
+	To []string
+}
+
+// Email — отправка писем через SMTP (STARTTLS, если сервер его поддерживает)
+type Email struct {
+	cfg SMTPConfig
+	// send переопределяется в тестах
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+func NewEmail(cfg SMTPConfig) *Email {
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	return &Email{cfg: cfg, send: smtp.SendMail}
+}
+
+func (e *Email) Channel() string { return ChannelEmail }
+
+func (e *Email) Send(_ context.Context, msg Message) error {
+	to := msg.To
+	if len(to) == 0 {
+		to = e.cfg.To
+	}
+	if len(to) == 0 {
+		return ErrNoRecipients
+	}
+
+	var auth smtp.Auth
+	if e.cfg.Username != "" {
+		auth = smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, e.cfg.Host)
+	}
+
+	body, err := e.build(to, msg)
+	if err != nil {
+		return err
+	}
+	addr := net.JoinHostPort(e.cfg.Host, strconv.Itoa(e.cfg.Port))
+	if err := e.send(addr, auth, e.cfg.From, to, body); err != nil {
+		return fmt.Errorf("smtp send: %w", err)
+	}
+	return nil
+}
+
+// build собирает письмо: HTML в quoted-printable, тема в MIME encoded-word
+func (e *Email) build(to []string, msg Message) ([]byte, error) {
+	text := strings.ReplaceAll(msg.Text, "\n", "<br>\n")
+	if msg.Link != nil {
+		text += fmt.Sprintf("<br>\n<br>\n<a href=\"%s\">%s</a>", html.EscapeString(msg.Link.URL), html.EscapeString(msg.Link.Text))
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", e.cfg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	qp := quotedprintable.NewWriter(&b)
+	if _, err := qp.Write([]byte(text)); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
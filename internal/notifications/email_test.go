@@ -0,0 +1,42 @@
+package notifications
+
+import (
+	"context"
+	"net/smtp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmail_Send(t *testing.T) {
+	e := NewEmail(SMTPConfig{Host: "smtp.example.com", Username: "u", Password: "p", From: "bot@example.com", To: []string{"a@example.com"}})
+
+	var (
+		gotAddr string
+		gotTo   []string
+		gotMsg  string
+	)
+	e.send = func(addr string, _ smtp.Auth, _ string, to []string, msg []byte) error {
+		gotAddr, gotTo, gotMsg = addr, to, string(msg)
+		return nil
+	}
+
+	err := e.Send(context.Background(), Message{
+		To:      []string{"client@example.com"},
+		Subject: "Заявка №1 принята",
+		Text:    "строка 1\nстрока 2",
+		Link:    &Link{Text: "Открыть", URL: "https://example.com/?a=1&b=2"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "smtp.example.com:587", gotAddr)
+	assert.Equal(t, []string{"client@example.com"}, gotTo)
+	assert.Contains(t, gotMsg, "Subject: =?utf-8?q?")
+	assert.Contains(t, gotMsg, "Content-Type: text/html; charset=UTF-8")
+	assert.Contains(t, gotMsg, "&amp;b=3D2")
+}
+
+func TestEmail_Send_NoRecipients(t *testing.T) {
+	e := NewEmail(SMTPConfig{Host: "smtp.example.com"})
+	assert.ErrorIs(t, e.Send(context.Background(), Message{Text: "x"}), ErrNoRecipients)
+}
@@ -0,0 +1,223 @@
+package notifications
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+)
+
+// EventType — тип события; используется в маршрутах (NOTIFY_ROUTES)
+type EventType string
+
+const (
+	EventOrderCreated          EventType = "order.created"
+	EventOrderStatusChanged    EventType = "order.status_changed"
+	EventFeedbackReceived      EventType = "feedback.received"
+	EventDigest                EventType = "report.digest"
+	EventPasswordReset         EventType = "auth.password_reset"
+	EventVerification          EventType = "auth.verification"
+	EventAccountLocked         EventType = "auth.account_locked"
+	EventCancellationRequested EventType = "order.cancellation_requested"
+	EventPaymentReminder       EventType = "payment.reminder"
+	EventFollowUpReminder      EventType = "order.follow_up"
+)
+
+// EventTypes — все известные типы событий
+var EventTypes = []EventType{EventOrderCreated, EventOrderStatusChanged, EventFeedbackReceived, EventDigest, EventPasswordReset, EventVerification, EventAccountLocked,
+	EventCancellationRequested, EventPaymentReminder, EventFollowUpReminder}
+
+func IsValidEventType(t EventType) bool {
+	for _, known := range EventTypes {
+		if known == t {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCustomerOnly — события с секретами (ссылка на сброс пароля, код подтверждения):
+// уходят только самому пользователю, менеджерам их не рендерим
+func IsCustomerOnly(t EventType) bool {
+	return t == EventPasswordReset || t == EventVerification
+}
+
+// HasSecret — в тексте события одноразовый секрет (ссылка на сброс пароля, код подтверждения):
+// кто его прочтёт, тот войдёт в чужой аккаунт или подтвердит чужой адрес.
+// Такие уведомления не показываются в админке и не отправляются повторно
+func HasSecret(t EventType) bool {
+	return t == EventPasswordReset || t == EventVerification
+}
+
+// Event — событие для отправки уведомлений
+type Event interface {
+	Type() EventType
+	// Customer — контакты клиента для маршрутов с аудиторией customer
+	Customer() Contact
+}
+
+// Contact — куда можно написать клиенту; пустые поля означают, что канал недоступен
+type Contact struct {
+	Name           string
+	Phone          string
+	Email          string
+	TelegramChatID string
+}
+
+// Address — адрес клиента в канале или "", если писать некуда
+func (c Contact) Address(channel string) string {
+	switch channel {
+	case ChannelTelegram:
+		return c.TelegramChatID
+	case ChannelEmail:
+		return c.Email
+	case ChannelSMS:
+		return c.Phone
+	}
+	return ""
+}
+
+func orderContact(o *models.Order) Contact {
+	c := Contact{Name: o.UserName, Phone: o.UserPhone}
+	if o.TelegramChatID != nil {
+		c.TelegramChatID = strconv.FormatInt(*o.TelegramChatID, 10)
+	}
+	return c
+}
+
+// OrderCreated — новый заказ с сайта или из мини-приложения
+type OrderCreated struct {
+	Order *models.Order
+	// Trip — nil для заявки без тура
+	Trip *models.Trip
+	At   time.Time
+}
+
+func (e OrderCreated) Type() EventType   { return EventOrderCreated }
+func (e OrderCreated) Customer() Contact { return orderContact(e.Order) }
+
+// OrderStatusChanged — заказ перешёл в новый статус
+type OrderStatusChanged struct {
+	Order   *models.Order
+	From    string
+	To      string
+	Actor   string
+	Comment string
+	At      time.Time
+}
+
+func (e OrderStatusChanged) Type() EventType   { return EventOrderStatusChanged }
+func (e OrderStatusChanged) Customer() Contact { return orderContact(e.Order) }
+
+// FeedbackReceived — заявка на консультацию
+type FeedbackReceived struct {
+	Feedback *models.Feedback
+	At       time.Time
+}
+
+func (e FeedbackReceived) Type() EventType { return EventFeedbackReceived }
+func (e FeedbackReceived) Customer() Contact {
+	return Contact{Name: e.Feedback.UserName, Phone: e.Feedback.UserPhone}
+}
+
+// DigestReady — сводка для владельца за прошедший день или неделю
+type DigestReady struct {
+	Digest *models.Digest
+	At     time.Time
+}
+
+func (e DigestReady) Type() EventType   { return EventDigest }
+func (e DigestReady) Customer() Contact { return Contact{} }
+
+// PasswordResetRequested — пользователь запросил сброс пароля. Ссылка уходит только ему:
+// сообщения менеджерам для этого события не рендерятся
+type PasswordResetRequested struct {
+	User *models.User
+	// ResetURL — ссылка с одноразовым токеном
+	ResetURL  string
+	ExpiresAt time.Time
+	At        time.Time
+}
+
+func (e PasswordResetRequested) Type() EventType { return EventPasswordReset }
+
+// Customer — email пользователя и телефон, только если он подтверждён:
+// ссылку на сброс нельзя слать на чужой номер
+func (e PasswordResetRequested) Customer() Contact {
+	c := Contact{Name: e.User.FullName, Email: e.User.Email}
+	if e.User.Phone != nil && e.User.PhoneVerifiedAt != nil {
+		c.Phone = *e.User.Phone
+	}
+	return c
+}
+
+// VerificationRequested — код подтверждения email или телефона пользователя.
+// Код уходит только на подтверждаемый адрес
+type VerificationRequested struct {
+	User *models.User
+	// Kind — models.VerifyEmail или models.VerifyPhone
+	Kind      string
+	Code      string
+	ExpiresAt time.Time
+	At        time.Time
+}
+
+func (e VerificationRequested) Type() EventType { return EventVerification }
+
+func (e VerificationRequested) Customer() Contact {
+	c := Contact{Name: e.User.FullName}
+	switch e.Kind {
+	case models.VerifyEmail:
+		c.Email = e.User.Email
+	case models.VerifyPhone:
+		if e.User.Phone != nil {
+			c.Phone = *e.User.Phone
+		}
+	}
+	return c
+}
+
+// AccountLocked — вход в аккаунт сотрудника закрыт после серии неудачных попыток:
+// похоже на подбор пароля. Только для менеджеров
+type AccountLocked struct {
+	User *models.User
+	// IP последней неудачной попытки
+	IP          string
+	Failures    int
+	LockedUntil time.Time
+	At          time.Time
+}
+
+func (e AccountLocked) Type() EventType   { return EventAccountLocked }
+func (e AccountLocked) Customer() Contact { return Contact{} }
+
+// CancellationRequested — клиент попросил отменить заказ; менеджеру — расчёт штрафа и возврата
+type CancellationRequested struct {
+	Order *models.Order
+	// Trip — nil для заявки без тура
+	Trip         *models.Trip
+	Cancellation *models.OrderCancellation
+	At           time.Time
+}
+
+func (e CancellationRequested) Type() EventType   { return EventCancellationRequested }
+func (e CancellationRequested) Customer() Contact { return orderContact(e.Order) }
+
+// PaymentsDue — сводка платежей по графику, срок которых подходит или уже прошёл.
+// Только для менеджеров
+type PaymentsDue struct {
+	Installments []models.OverdueInstallment
+	At           time.Time
+}
+
+func (e PaymentsDue) Type() EventType   { return EventPaymentReminder }
+func (e PaymentsDue) Customer() Contact { return Contact{} }
+
+// FollowUpsDue — заказы, по которым пора перезвонить клиенту. Только для менеджеров
+type FollowUpsDue struct {
+	Reminders []models.FollowUpReminder
+	At        time.Time
+}
+
+func (e FollowUpsDue) Type() EventType   { return EventFollowUpReminder }
+func (e FollowUpsDue) Customer() Contact { return Contact{} }
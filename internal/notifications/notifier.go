@@ -0,0 +1,51 @@
+// Package notifications — уведомления о событиях (новый заказ, смена статуса,
+// заявка на консультацию) через подключаемые каналы: Telegram, email, SMS.
+// Какие события в какие каналы уходят, задаётся маршрутами (см. ParseRoutes).
+package notifications
+
+import (
+	"context"
+	"errors"
+)
+
+// Каналы доставки
+const (
+	ChannelTelegram = "telegram"
+	ChannelEmail    = "email"
+	ChannelSMS      = "sms"
+)
+
+// Кому адресовано уведомление
+const (
+	AudienceAdmin    = "admin"    // менеджеры: получатели канала по умолчанию
+	AudienceCustomer = "customer" // клиент: контакты из события
+)
+
+var (
+	ErrNoRecipients         = errors.New("no recipients")
+	ErrChannelNotConfigured = errors.New("notification channel is not configured")
+)
+
+// Notifier — канал доставки уведомлений
+type Notifier interface {
+	Channel() string
+	Send(ctx context.Context, msg Message) error
+}
+
+// Message — готовое к отправке уведомление. Формат Text зависит от канала:
+// HTML для Telegram и email, обычный текст для SMS.
+type Message struct {
+	// To — chat_id, email или телефон; пусто — получатели канала по умолчанию (менеджеры)
+	To      []string
+	Subject string
+	Text    string
+	// Link — кнопка под сообщением в Telegram, ссылка в конце письма или SMS
+	Link *Link
+	// Actions — кнопки бота под сообщением; есть только в Telegram, другие каналы их не показывают
+	Actions []Action
+}
+
+type Link struct {
+	Text string
+	URL  string
+}
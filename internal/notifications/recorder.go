@@ -0,0 +1,39 @@
+package notifications
+
+import (
+	"context"
+	"sync"
+)
+
+// Recorder — канал для тестов: запоминает отправленные сообщения.
+// Если задан Err, Send возвращает его и ничего не запоминает.
+type Recorder struct {
+	channel string
+	Err     error
+
+	mu   sync.Mutex
+	sent []Message
+}
+
+func NewRecorder(channel string) *Recorder {
+	return &Recorder{channel: channel}
+}
+
+func (r *Recorder) Channel() string { return r.channel }
+
+func (r *Recorder) Send(_ context.Context, msg Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return r.Err
+	}
+	r.sent = append(r.sent, msg)
+	return nil
+}
+
+// Sent — копия отправленных сообщений
+func (r *Recorder) Sent() []Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Message(nil), r.sent...)
+}
@@ -0,0 +1,480 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+)
+
+// Renderer превращает событие в сообщение для канала и аудитории
+type Renderer interface {
+	Render(ctx context.Context, ev Event, channel, audience string) (Message, error)
+}
+
+// Links — ссылки, которые подставляются в уведомления
+type Links struct {
+	// TrackingURL — начало публичной ссылки на заказ, к нему дописывается токен
+	TrackingURL string
+	// FrontendURL — сайт, для кнопки «Открыть тур»
+	FrontendURL string
+	// AdminURL — админка, для кнопки «Открыть заказы»
+	AdminURL string
+}
+
+func (l Links) Tracking(token string) string {
+	return strings.TrimRight(l.TrackingURL, "/") + "/" + token
+}
+
+func (l Links) Trip(id int) string {
+	return fmt.Sprintf("%s/api/v1/trips/%d", strings.TrimRight(l.FrontendURL, "/"), id)
+}
+
+// DefaultRenderer — встроенные тексты уведомлений
+type DefaultRenderer struct {
+	Links Links
+}
+
+func NewDefaultRenderer(links Links) *DefaultRenderer {
+	return &DefaultRenderer{Links: links}
+}
+
+func (r *DefaultRenderer) Render(_ context.Context, ev Event, channel, audience string) (Message, error) {
+	// SMS — короткий текст без разметки, остальные каналы понимают HTML
+	plain := channel == ChannelSMS
+
+	switch e := ev.(type) {
+	case OrderCreated:
+		if audience == AudienceCustomer {
+			return r.orderCreatedCustomer(e, plain), nil
+		}
+		return r.orderCreatedAdmin(e, plain), nil
+	case OrderStatusChanged:
+		if audience == AudienceCustomer {
+			return r.statusChangedCustomer(e, plain), nil
+		}
+		return r.statusChangedAdmin(e, plain), nil
+	case FeedbackReceived:
+		if audience == AudienceCustomer {
+			return Message{}, fmt.Errorf("%s: no customer message", ev.Type())
+		}
+		return r.feedbackAdmin(e, plain), nil
+	case DigestReady:
+		if audience == AudienceCustomer {
+			return Message{}, fmt.Errorf("%s: no customer message", ev.Type())
+		}
+		return r.digestAdmin(e, plain), nil
+	case PasswordResetRequested:
+		if audience != AudienceCustomer {
+			return Message{}, fmt.Errorf("%s: no admin message", ev.Type())
+		}
+		return r.passwordResetCustomer(e, plain), nil
+	case VerificationRequested:
+		if audience != AudienceCustomer {
+			return Message{}, fmt.Errorf("%s: no admin message", ev.Type())
+		}
+		return r.verificationCustomer(e, plain), nil
+	case AccountLocked:
+		if audience == AudienceCustomer {
+			return Message{}, fmt.Errorf("%s: no customer message", ev.Type())
+		}
+		return r.accountLockedAdmin(e, plain), nil
+	case CancellationRequested:
+		if audience == AudienceCustomer {
+			return Message{}, fmt.Errorf("%s: no customer message", ev.Type())
+		}
+		return r.cancellationAdmin(e, plain), nil
+	case PaymentsDue:
+		if audience == AudienceCustomer {
+			return Message{}, fmt.Errorf("%s: no customer message", ev.Type())
+		}
+		return r.paymentsDueAdmin(e, plain), nil
+	case FollowUpsDue:
+		if audience == AudienceCustomer {
+			return Message{}, fmt.Errorf("%s: no customer message", ev.Type())
+		}
+		return r.followUpsDueAdmin(e, plain), nil
+	}
+	return Message{}, fmt.Errorf("unsupported event %s", ev.Type())
+}
+
+func (r *DefaultRenderer) orderCreatedAdmin(e OrderCreated, plain bool) Message {
+	o := e.Order
+	msg := Message{Subject: fmt.Sprintf("Новый заказ №%d", o.ID)}
+	if plain {
+		msg.Text = fmt.Sprintf("Новый заказ №%d: %s, %s", o.ID, o.UserName, phoneText(o.UserPhone, o.UserPhoneDisplay))
+		return msg
+	}
+
+	var b strings.Builder
+	b.WriteString("🛒 <b>Новый заказ!</b>\n\n")
+	if e.Trip != nil {
+		price := "—"
+		if o.TotalPrice != nil {
+			price = helpers.FormatPrice(*o.TotalPrice)
+		}
+		fmt.Fprintf(&b, "📅 <b>Дата:</b> %s\n", eventTime(e.At).Format("02.01.2006 15:04"))
+		fmt.Fprintf(&b, "👤 <b>Имя:</b> %s\n", html.EscapeString(o.UserName))
+		fmt.Fprintf(&b, "📞 <b>Телефон:</b> %s\n\n", phoneHTML(o.UserPhone, o.UserPhoneDisplay))
+		fmt.Fprintf(&b, "🌍 <b>Тур:</b> %s\n", html.EscapeString(e.Trip.Title))
+		fmt.Fprintf(&b, "💰 <b>Цена:</b> %s руб.", price)
+		msg.Link = &Link{Text: "Открыть тур", URL: r.Links.Trip(e.Trip.ID)}
+	} else {
+		fmt.Fprintf(&b, "🏖️ <b>Тур:</b> %s\n", html.EscapeString(helpers.IfEmpty(o.Name, "—")))
+		fmt.Fprintf(&b, "📅 <b>Дата поездки:</b> %s\n", html.EscapeString(helpers.IfEmpty(o.Date, "—")))
+		fmt.Fprintf(&b, "💰 <b>Цена:</b> %s\n\n", html.EscapeString(helpers.IfEmpty(o.Price, "—")))
+		fmt.Fprintf(&b, "👤 <b>Имя:</b> %s\n", html.EscapeString(o.UserName))
+		fmt.Fprintf(&b, "📞 <b>Телефон:</b> %s\n", phoneHTML(o.UserPhone, o.UserPhoneDisplay))
+		fmt.Fprintf(&b, "🕒 <b>Создан:</b> %s", eventTime(e.At).Format("02.01.2006 15:04"))
+		if r.Links.AdminURL != "" {
+			msg.Link = &Link{Text: "Открыть заказы", URL: r.Links.AdminURL}
+		}
+	}
+	b.WriteString(formatTravellers(o.Travellers))
+	fmt.Fprintf(&b, "\n\n🔗 <a href=\"%s\">Ссылка для клиента</a>", r.Links.Tracking(o.TrackingToken))
+
+	msg.Text = b.String()
+	return msg
+}
+
+func (r *DefaultRenderer) orderCreatedCustomer(e OrderCreated, plain bool) Message {
+	o := e.Order
+	link := r.Links.Tracking(o.TrackingToken)
+	msg := Message{Subject: fmt.Sprintf("Заявка №%d принята", o.ID)}
+	if plain {
+		msg.Text = fmt.Sprintf("Заявка №%d принята. Статус заказа: %s", o.ID, link)
+		return msg
+	}
+	msg.Text = fmt.Sprintf("✅ Заявка №%d принята. Мы свяжемся с вами в ближайшее время.\n\n"+
+		"Статус заказа: <a href=\"%s\">%s</a>", o.ID, link, link)
+	return msg
+}
+
+func (r *DefaultRenderer) statusChangedAdmin(e OrderStatusChanged, plain bool) Message {
+	o := e.Order
+	msg := Message{Subject: fmt.Sprintf("Заказ №%d: %s", o.ID, StatusTitle(e.To))}
+	if plain {
+		msg.Text = fmt.Sprintf("Заказ №%d: %s → %s", o.ID, StatusTitle(e.From), StatusTitle(e.To))
+		return msg
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "🔄 <b>Заказ №%d</b>: %s → <b>%s</b>\n", o.ID, StatusTitle(e.From), StatusTitle(e.To))
+	fmt.Fprintf(&b, "👤 %s, %s", html.EscapeString(o.UserName), phoneHTML(o.UserPhone, o.UserPhoneDisplay))
+	if e.Actor != "" {
+		fmt.Fprintf(&b, "\n✍️ %s", html.EscapeString(e.Actor))
+	}
+	if e.Comment != "" {
+		fmt.Fprintf(&b, "\n💬 %s", html.EscapeString(e.Comment))
+	}
+	msg.Text = b.String()
+	return msg
+}
+
+func (r *DefaultRenderer) statusChangedCustomer(e OrderStatusChanged, plain bool) Message {
+	o := e.Order
+	link := r.Links.Tracking(o.TrackingToken)
+	msg := Message{Subject: fmt.Sprintf("Заявка №%d: %s", o.ID, StatusTitle(e.To))}
+	if plain {
+		msg.Text = fmt.Sprintf("Заявка №%d: %s. Подробнее: %s", o.ID, StatusTitle(e.To), link)
+		return msg
+	}
+	msg.Text = fmt.Sprintf("ℹ️ Статус заявки №%d: <b>%s</b>\n\nПодробнее: <a href=\"%s\">%s</a>",
+		o.ID, StatusTitle(e.To), link, link)
+	return msg
+}
+
+func (r *DefaultRenderer) feedbackAdmin(e FeedbackReceived, plain bool) Message {
+	fb := e.Feedback
+	msg := Message{Subject: "Новая заявка на консультацию"}
+	if plain {
+		msg.Text = fmt.Sprintf("Заявка на консультацию: %s, %s", fb.UserName, phoneText(fb.UserPhone, fb.UserPhoneDisplay))
+		return msg
+	}
+	msg.Text = fmt.Sprintf(
+		"💬 <b>Новая заявка на консультацию!</b>\n\n"+
+			"📅 <b>Дата:</b> %s\n"+
+			"👤 <b>Имя:</b> %s\n"+
+			"📞 <b>Телефон:</b> %s",
+		eventTime(e.At).Format("02.01.2006 15:04"),
+		html.EscapeString(fb.UserName),
+		phoneHTML(fb.UserPhone, fb.UserPhoneDisplay),
+	)
+	return msg
+}
+
+// DigestTitle — «за 01.10.2025» или «за неделю 22.09–28.09.2025»
+func DigestTitle(d *models.Digest) string {
+	last := d.To.AddDate(0, 0, -1)
+	if d.Period == models.DigestWeekly {
+		return fmt.Sprintf("за неделю %s–%s", d.From.Format("02.01"), last.Format("02.01.2006"))
+	}
+	return "за " + d.From.Format("02.01.2006")
+}
+
+func (r *DefaultRenderer) digestAdmin(e DigestReady, plain bool) Message {
+	d := e.Digest
+	title := DigestTitle(d)
+	msg := Message{Subject: "Сводка " + title}
+	if plain {
+		msg.Text = fmt.Sprintf("Сводка %s: заказов %d, заявок на консультацию %d", title, d.OrdersTotal, d.FeedbackCount)
+		return msg
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "📊 <b>Сводка %s</b>\n\n", title)
+	fmt.Fprintf(&b, "🛒 <b>Заказов:</b> %d", d.OrdersTotal)
+	for _, kv := range d.OrdersByStatus {
+		fmt.Fprintf(&b, "\n• %s: %d", html.EscapeString(StatusTitle(kv.Key)), kv.Count)
+	}
+	fmt.Fprintf(&b, "\n💬 <b>Заявок на консультацию:</b> %d", d.FeedbackCount)
+
+	if len(d.TopTrips) > 0 {
+		b.WriteString("\n\n👀 <b>Туры: просмотры → заказы</b>")
+		for i, t := range d.TopTrips {
+			fmt.Fprintf(&b, "\n%d. %s — %d → %d", i+1, html.EscapeString(t.Title), t.Views, t.Orders)
+			if t.Conversion != nil {
+				fmt.Fprintf(&b, " (%.1f%%)", *t.Conversion)
+			}
+		}
+	}
+
+	if len(d.Departures) > 0 {
+		b.WriteString("\n\n✈️ <b>Ближайшие вылеты</b>")
+		for _, t := range d.Departures {
+			fmt.Fprintf(&b, "\n• %s %s — броней: %d, туристов: %d",
+				t.StartDate.Format("02.01"), html.EscapeString(t.Title), t.Bookings, t.Travellers)
+		}
+	}
+
+	msg.Text = b.String()
+	if r.Links.AdminURL != "" {
+		msg.Link = &Link{Text: "Открыть админку", URL: r.Links.AdminURL}
+	}
+	return msg
+}
+
+func (r *DefaultRenderer) passwordResetCustomer(e PasswordResetRequested, plain bool) Message {
+	until := e.ExpiresAt.Format("02.01.2006 15:04")
+	msg := Message{Subject: "Восстановление пароля"}
+	if plain {
+		msg.Text = fmt.Sprintf("Ссылка для смены пароля (до %s): %s", until, e.ResetURL)
+		return msg
+	}
+	msg.Text = fmt.Sprintf("🔑 Вы запросили смену пароля.\n\n"+
+		"Чтобы задать новый пароль, перейдите по ссылке: <a href=\"%s\">%s</a>\n"+
+		"Ссылка действует до %s и только один раз.\n\n"+
+		"Если вы не запрашивали смену пароля, просто проигнорируйте это сообщение.",
+		e.ResetURL, e.ResetURL, until)
+	return msg
+}
+
+func (r *DefaultRenderer) verificationCustomer(e VerificationRequested, plain bool) Message {
+	minutes := int(e.ExpiresAt.Sub(eventTime(e.At)).Round(time.Minute).Minutes())
+	msg := Message{Subject: "Код подтверждения: " + e.Code}
+	if plain {
+		msg.Text = fmt.Sprintf("Код подтверждения: %s. Действует %d мин. Никому его не сообщайте.", e.Code, minutes)
+		return msg
+	}
+	what := "email"
+	if e.Kind == models.VerifyPhone {
+		what = "номер телефона"
+	}
+	msg.Text = fmt.Sprintf("✉️ Ваш код, чтобы подтвердить %s: <b>%s</b>\n\n"+
+		"Код действует %d мин. Никому его не сообщайте.", what, e.Code, minutes)
+	return msg
+}
+
+func (r *DefaultRenderer) accountLockedAdmin(e AccountLocked, plain bool) Message {
+	until := e.LockedUntil.Format("02.01.2006 15:04")
+	msg := Message{Subject: "Подбор пароля: вход заблокирован для " + e.User.Email}
+	if plain {
+		msg.Text = fmt.Sprintf("Подбор пароля: %s, %d неудачных попыток, IP %s. Вход закрыт до %s",
+			e.User.Email, e.Failures, e.IP, until)
+		return msg
+	}
+	msg.Text = fmt.Sprintf("🚨 <b>Подбор пароля к аккаунту сотрудника</b>\n\n"+
+		"👤 <b>Аккаунт:</b> %s (%s)\n"+
+		"❌ <b>Неудачных попыток:</b> %d\n"+
+		"🌐 <b>IP последней попытки:</b> %s\n"+
+		"🔒 <b>Вход закрыт до:</b> %s\n\n"+
+		"Если это был не сотрудник, смените ему пароль.",
+		html.EscapeString(e.User.Email), html.EscapeString(e.User.FullName), e.Failures, html.EscapeString(e.IP), until)
+	if r.Links.AdminURL != "" {
+		msg.Link = &Link{Text: "Открыть админку", URL: r.Links.AdminURL}
+	}
+	return msg
+}
+
+func (r *DefaultRenderer) cancellationAdmin(e CancellationRequested, plain bool) Message {
+	o, c := e.Order, e.Cancellation
+	msg := Message{Subject: fmt.Sprintf("Запрос на отмену заказа №%d", o.ID)}
+	if plain {
+		msg.Text = fmt.Sprintf("Запрос на отмену заказа №%d: %s, %s. К возврату %s руб.",
+			o.ID, o.UserName, phoneText(o.UserPhone, o.UserPhoneDisplay), helpers.FormatPrice(c.RefundAmount))
+		return msg
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "❌ <b>Запрос на отмену заказа #%d</b>\n\n", o.ID)
+	fmt.Fprintf(&b, "👤 %s\n", html.EscapeString(o.UserName))
+	if o.UserPhoneDisplay != "" {
+		fmt.Fprintf(&b, "📞 %s\n", phoneHTML(o.UserPhone, o.UserPhoneDisplay))
+	}
+	if e.Trip != nil {
+		fmt.Fprintf(&b, "🧳 %s (старт %s)\n", html.EscapeString(e.Trip.Title), e.Trip.StartDate.Format("02.01.2006"))
+	}
+	if c.DaysBefore != nil {
+		fmt.Fprintf(&b, "📅 До начала: %d дн.\n", *c.DaysBefore)
+	}
+	fmt.Fprintf(&b, "\n💳 Оплачено: %.2f\n", c.PaidAmount)
+	fmt.Fprintf(&b, "⚖️ Штраф: %.2f (%g%%)\n", c.PenaltyAmount, c.PenaltyPercent)
+	fmt.Fprintf(&b, "💸 К возврату: %.2f", c.RefundAmount)
+	if c.Reason != nil {
+		fmt.Fprintf(&b, "\n\n💬 %s", html.EscapeString(*c.Reason))
+	}
+	msg.Text = b.String()
+	if r.Links.AdminURL != "" {
+		msg.Link = &Link{Text: "Открыть заказы", URL: r.Links.AdminURL}
+	}
+	return msg
+}
+
+func (r *DefaultRenderer) paymentsDueAdmin(e PaymentsDue, plain bool) Message {
+	msg := Message{Subject: fmt.Sprintf("Платежи по графику: %d", len(e.Installments))}
+	today := startOfDay(eventTime(e.At))
+
+	var b strings.Builder
+	if plain {
+		b.WriteString("Платежи по графику:")
+	} else {
+		b.WriteString("⏰ <b>Платежи по графику</b>")
+	}
+	for _, in := range e.Installments {
+		due := in.DueDate.Format("02.01.2006")
+		if startOfDay(in.DueDate).Before(today) {
+			due += " ❗️просрочен"
+		}
+		if plain {
+			fmt.Fprintf(&b, "\n№%d %s: %s руб. до %s", in.OrderID, in.UserName, helpers.FormatPrice(in.Outstanding), due)
+			continue
+		}
+		trip := "—"
+		if in.TripTitle != nil {
+			trip = html.EscapeString(*in.TripTitle)
+		}
+		fmt.Fprintf(&b, "\n\n📄 <b>Заказ №%d</b> — %s\n👤 %s, %s\n💳 %s: %s руб. (срок %s)",
+			in.OrderID, trip,
+			html.EscapeString(in.UserName), phoneHTML(in.UserPhone, ""),
+			html.EscapeString(in.Title), helpers.FormatPrice(in.Outstanding), due,
+		)
+	}
+	msg.Text = b.String()
+	return msg
+}
+
+func (r *DefaultRenderer) followUpsDueAdmin(e FollowUpsDue, plain bool) Message {
+	msg := Message{Subject: fmt.Sprintf("Пора перезвонить: %d", len(e.Reminders))}
+
+	var b strings.Builder
+	if plain {
+		b.WriteString("Пора перезвонить:")
+	} else {
+		b.WriteString("📞 <b>Пора перезвонить</b>")
+	}
+	for _, f := range e.Reminders {
+		manager := "не назначен"
+		if f.AssigneeName != nil {
+			manager = *f.AssigneeName
+		}
+		if plain {
+			fmt.Fprintf(&b, "\n№%d %s, %s в %s", f.OrderID, f.UserName, f.UserPhone, f.NextCallAt.Format("02.01.2006 15:04"))
+			continue
+		}
+		fmt.Fprintf(&b, "\n\n📄 <b>Заказ №%d</b> (%s)\n👤 %s, %s\n🕑 %s, менеджер: %s",
+			f.OrderID, html.EscapeString(StatusTitle(f.Status)),
+			html.EscapeString(f.UserName), phoneHTML(f.UserPhone, ""),
+			f.NextCallAt.Format("02.01.2006 15:04"), html.EscapeString(manager),
+		)
+	}
+	msg.Text = b.String()
+	if r.Links.AdminURL != "" {
+		msg.Link = &Link{Text: "Открыть заказы", URL: r.Links.AdminURL}
+	}
+	return msg
+}
+
+var statusTitles = map[string]string{
+	models.OrderStatusNew:                   "новая",
+	models.OrderStatusConfirmed:             "подтверждена",
+	models.OrderStatusPaid:                  "оплачена",
+	models.OrderStatusCancellationRequested: "запрошена отмена",
+	models.OrderStatusCompleted:             "завершена",
+	models.OrderStatusCancelled:             "отменена",
+	models.OrderStatusRejected:              "отклонена",
+	models.OrderStatusRefunded:              "деньги возвращены",
+}
+
+// StatusTitle — статус заказа по-русски для клиента
+func StatusTitle(status string) string {
+	if title, ok := statusTitles[status]; ok {
+		return title
+	}
+	return status
+}
+
+var travellerTypeTitles = map[string]string{
+	models.TravellerAdult:  "взрослый",
+	models.TravellerChild:  "ребёнок",
+	models.TravellerInfant: "младенец",
+}
+
+// formatTravellers — блок со списком туристов для уведомления
+func formatTravellers(travellers []models.OrderTraveller) string {
+	if len(travellers) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n\n👥 <b>Туристы (%d):</b>", len(travellers))
+	for i, t := range travellers {
+		fmt.Fprintf(&b, "\n%d. %s — %s", i+1, html.EscapeString(t.FullName), travellerTypeTitles[t.TravellerType])
+		if t.BirthDate != nil {
+			fmt.Fprintf(&b, ", %s", t.BirthDate.Format("02.01.2006"))
+		}
+		if t.Room != nil {
+			fmt.Fprintf(&b, ", номер %s", html.EscapeString(*t.Room))
+		}
+	}
+	return b.String()
+}
+
+// phoneHTML — кликабельный номер: href в E.164, текст в привычном виде
+func phoneHTML(e164, display string) string {
+	return fmt.Sprintf("<a href=\"tel:%s\">%s</a>", html.EscapeString(e164), html.EscapeString(phoneText(e164, display)))
+}
+
+func phoneText(e164, display string) string {
+	if display != "" {
+		return display
+	}
+	return e164
+}
+
+// startOfDay — полночь того же дня в часовом поясе t
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// eventTime — время события; не заданное — текущее
+func eventTime(t time.Time) time.Time {
+	if t.IsZero() {
+		return time.Now()
+	}
+	return t
+}
@@ -0,0 +1,213 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultRoutes — как до появления маршрутов: менеджерам в Telegram о заказах и заявках,
+// клиенту из мини-приложения — ссылка на заказ в его чат; сводки — в Telegram;
+// ссылка на сброс пароля — пользователю на email; код подтверждения — на подтверждаемый адрес;
+// о подборе пароля к аккаунту сотрудника, заявках на отмену и напоминания о платежах и звонках — в Telegram
+const DefaultRoutes = "order.created=telegram,telegram:customer;feedback.received=telegram;report.digest=telegram;" +
+	"auth.password_reset=email:customer;auth.verification=email:customer,sms:customer;auth.account_locked=telegram;" +
+	"order.cancellation_requested=telegram;payment.reminder=telegram;order.follow_up=telegram"
+
+// Target — канал и аудитория, куда уходит событие
+type Target struct {
+	Channel  string
+	Audience string
+}
+
+func (t Target) String() string {
+	if t.Audience == AudienceCustomer {
+		return t.Channel + ":" + AudienceCustomer
+	}
+	return t.Channel
+}
+
+// Routes — куда отправлять каждое событие
+type Routes map[EventType][]Target
+
+// ParseRoutes разбирает маршруты вида
+// "order.created=telegram,email,sms:customer;feedback.received=telegram".
+// Канал без суффикса — менеджерам (получатели канала по умолчанию), с ":customer" — клиенту.
+func ParseRoutes(s string) (Routes, error) {
+	routes := Routes{}
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, targets, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("route %q: expected event=channels", part)
+		}
+		event := EventType(strings.TrimSpace(name))
+		if !IsValidEventType(event) {
+			return nil, fmt.Errorf("route %q: unknown event %q", part, event)
+		}
+
+		list := []Target{}
+		for _, raw := range strings.Split(targets, ",") {
+			raw = strings.TrimSpace(raw)
+			if raw == "" {
+				continue
+			}
+			channel, audience, _ := strings.Cut(raw, ":")
+			switch audience {
+			case "", AudienceAdmin:
+				audience = AudienceAdmin
+			case AudienceCustomer:
+			default:
+				return nil, fmt.Errorf("route %q: unknown audience %q", part, audience)
+			}
+			list = append(list, Target{Channel: channel, Audience: audience})
+		}
+		routes[event] = list
+	}
+	return routes, nil
+}
+
+// Router рассылает событие по каналам согласно маршрутам.
+// Методы безопасно вызывать у nil — уведомления просто не отправляются.
+type Router struct {
+	notifiers map[string]Notifier
+	routes    Routes
+	renderer  Renderer
+	rules     RuleStore
+	log       *zap.SugaredLogger
+}
+
+func NewRouter(routes Routes, renderer Renderer, log *zap.SugaredLogger, notifiers ...Notifier) *Router {
+	r := &Router{
+		notifiers: make(map[string]Notifier, len(notifiers)),
+		routes:    routes,
+		renderer:  renderer,
+		log:       log,
+	}
+	for _, n := range notifiers {
+		r.notifiers[n.Channel()] = n
+	}
+	for event, targets := range routes {
+		for _, t := range targets {
+			if _, ok := r.notifiers[t.Channel]; !ok {
+				log.Warnw("notification channel is not configured, route skipped", "event", event, "target", t.String())
+			}
+		}
+	}
+	return r
+}
+
+// UseRules включает правила маршрутизации по чатам: сообщения менеджерам в Telegram
+// уходят в чаты подошедших правил, а если ни одно не подошло — в чат по умолчанию
+func (r *Router) UseRules(store RuleStore) *Router {
+	r.rules = store
+	return r
+}
+
+// Delivery — сообщение, готовое к отправке по одному маршруту
+type Delivery struct {
+	Event   EventType
+	Target  Target
+	Message Message
+	// NotBefore — не отправлять раньше (тихие часы чата); нулевое — сразу
+	NotBefore time.Time
+}
+
+// Deliveries рендерит событие для всех маршрутов с настроенными каналами.
+// Маршруты клиенту, для которых у клиента нет контакта, и маршруты,
+// которые не удалось отрендерить (пишется в лог), пропускаются.
+func (r *Router) Deliveries(ctx context.Context, ev Event) []Delivery {
+	if r == nil {
+		return nil
+	}
+
+	var list []Delivery
+	for _, t := range r.routes[ev.Type()] {
+		if _, ok := r.notifiers[t.Channel]; !ok {
+			continue
+		}
+
+		msg, err := r.renderer.Render(ctx, ev, t.Channel, t.Audience)
+		if err != nil {
+			// ошибка шаблона не должна срывать заказ — маршрут пропускается
+			r.log.Errorw("Ошибка подготовки уведомления", "event", ev.Type(), "target", t.String(), "err", err)
+			continue
+		}
+		if t.Audience == AudienceCustomer {
+			addr := ev.Customer().Address(t.Channel)
+			if addr == "" {
+				// клиент не оставил контакт для этого канала
+				continue
+			}
+			msg.To = []string{addr}
+		}
+		if t.Channel == ChannelTelegram && t.Audience == AudienceAdmin {
+			if oc, ok := ev.(OrderCreated); ok {
+				// менеджеры разбирают заказ прямо из чата
+				msg.Actions = OrderActions(oc.Order)
+			}
+			if chats := r.destinations(ctx, ev); len(chats) > 0 {
+				now := time.Now()
+				for _, dest := range chats {
+					d := Delivery{Event: ev.Type(), Target: t, Message: msg}
+					d.Message.To = []string{dest.ChatID}
+					if at := dest.Quiet.Until(now); at.After(now) {
+						d.NotBefore = at
+					}
+					list = append(list, d)
+				}
+				continue
+			}
+		}
+		list = append(list, Delivery{Event: ev.Type(), Target: t, Message: msg})
+	}
+	return list
+}
+
+// destinations — чаты, подходящие под событие по правилам; при ошибке загрузки
+// правил сообщение уходит в чат по умолчанию, чтобы не потерять его
+func (r *Router) destinations(ctx context.Context, ev Event) []Destination {
+	if r.rules == nil {
+		return nil
+	}
+	rules, err := r.rules.Rules(ctx)
+	if err != nil {
+		r.log.Errorw("Ошибка загрузки правил маршрутизации, отправка в чат по умолчанию", "event", ev.Type(), "err", err)
+		return nil
+	}
+	return MatchDestinations(rules, FactsOf(ev))
+}
+
+// Deliver отправляет одно сообщение в его канал
+func (r *Router) Deliver(ctx context.Context, d Delivery) error {
+	if r == nil {
+		return nil
+	}
+	n, ok := r.notifiers[d.Target.Channel]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrChannelNotConfigured, d.Target.Channel)
+	}
+	return n.Send(ctx, d.Message)
+}
+
+// Notify сразу отправляет событие по всем маршрутам, минуя outbox (и тихие часы).
+// Ошибка одного канала не мешает остальным; возвращаются все ошибки вместе.
+func (r *Router) Notify(ctx context.Context, ev Event) error {
+	var errs []error
+	for _, d := range r.Deliveries(ctx, ev) {
+		if err := r.Deliver(ctx, d); err != nil {
+			r.log.Errorw("Ошибка отправки уведомления", "event", d.Event, "target", d.Target.String(), "err", err)
+			errs = append(errs, fmt.Errorf("%s: %w", d.Target, err))
+			continue
+		}
+		r.log.Debugw("notification_sent", "event", d.Event, "target", d.Target.String())
+	}
+	return errors.Join(errs...)
+}
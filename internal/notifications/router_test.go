@@ -0,0 +1,149 @@
+package notifications_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/notifications"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestParseRoutes(t *testing.T) {
+	routes, err := notifications.ParseRoutes(" order.created = telegram, email ,sms:customer ; feedback.received=telegram;")
+	require.NoError(t, err)
+	assert.Equal(t, []notifications.Target{
+		{Channel: notifications.ChannelTelegram, Audience: notifications.AudienceAdmin},
+		{Channel: notifications.ChannelEmail, Audience: notifications.AudienceAdmin},
+		{Channel: notifications.ChannelSMS, Audience: notifications.AudienceCustomer},
+	}, routes[notifications.EventOrderCreated])
+	assert.Len(t, routes[notifications.EventFeedbackReceived], 1)
+
+	_, err = notifications.ParseRoutes(notifications.DefaultRoutes)
+	assert.NoError(t, err)
+
+	for _, bad := range []string{"order.created", "order.deleted=telegram", "order.created=telegram:boss"} {
+		_, err := notifications.ParseRoutes(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func newOrderCreated() notifications.OrderCreated {
+	chat := int64(42)
+	return notifications.OrderCreated{
+		Order: &models.Order{
+			ID:             7,
+			Status:         models.OrderStatusNew,
+			UserName:       "Иван <script>",
+			UserPhone:      "+79990000000",
+			TrackingToken:  "tok",
+			TelegramChatID: &chat,
+		},
+		At: time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestRouter_Notify_Routes(t *testing.T) {
+	routes, err := notifications.ParseRoutes("order.created=telegram,sms:customer,email:customer")
+	require.NoError(t, err)
+
+	tg := notifications.NewRecorder(notifications.ChannelTelegram)
+	sms := notifications.NewRecorder(notifications.ChannelSMS)
+	email := notifications.NewRecorder(notifications.ChannelEmail)
+	renderer := notifications.NewDefaultRenderer(notifications.Links{TrackingURL: "https://t.example/track/"})
+	r := notifications.NewRouter(routes, renderer, zaptest.NewLogger(t).Sugar(), tg, sms, email)
+
+	require.NoError(t, r.Notify(context.Background(), newOrderCreated()))
+
+	// менеджерам — получатели канала по умолчанию, имя экранировано
+	require.Len(t, tg.Sent(), 1)
+	assert.Empty(t, tg.Sent()[0].To)
+	assert.Contains(t, tg.Sent()[0].Text, "Иван &lt;script&gt;")
+	assert.Contains(t, tg.Sent()[0].Text, "https://t.example/track/tok")
+	// под новым заказом — кнопки бота
+	assert.Equal(t, []string{"confirm_7", "reject_7", "assign_7"}, actionData(tg.Sent()[0].Actions))
+
+	// клиенту — на его номер, без разметки
+	require.Len(t, sms.Sent(), 1)
+	assert.Equal(t, []string{"+79990000000"}, sms.Sent()[0].To)
+	assert.NotContains(t, sms.Sent()[0].Text, "<")
+	assert.Empty(t, sms.Sent()[0].Actions)
+
+	// email клиент не оставил — маршрут пропускается
+	assert.Empty(t, email.Sent())
+}
+
+func TestRouter_Notify_ChannelErrorDoesNotStopOthers(t *testing.T) {
+	routes, err := notifications.ParseRoutes("order.created=telegram,sms,email")
+	require.NoError(t, err)
+
+	tg := notifications.NewRecorder(notifications.ChannelTelegram)
+	tg.Err = errors.New("boom")
+	sms := notifications.NewRecorder(notifications.ChannelSMS)
+	// email не настроен — маршрут тихо пропускается
+	r := notifications.NewRouter(routes, notifications.NewDefaultRenderer(notifications.Links{}), zaptest.NewLogger(t).Sugar(), tg, sms)
+
+	err = r.Notify(context.Background(), newOrderCreated())
+	assert.ErrorContains(t, err, "boom")
+	assert.Len(t, sms.Sent(), 1)
+}
+
+func actionData(list []notifications.Action) []string {
+	var data []string
+	for _, a := range list {
+		data = append(data, a.Data)
+	}
+	return data
+}
+
+func TestOrderActions(t *testing.T) {
+	manager := 3
+	assert.Equal(t, []string{"confirm_5", "reject_5", "assign_5"},
+		actionData(notifications.OrderActions(&models.Order{ID: 5, Status: models.OrderStatusNew})))
+	assert.Equal(t, []string{"confirm_5", "reject_5"},
+		actionData(notifications.OrderActions(&models.Order{ID: 5, Status: models.OrderStatusNew, AssignedTo: &manager})))
+	assert.Equal(t, []string{"assign_5"},
+		actionData(notifications.OrderActions(&models.Order{ID: 5, Status: models.OrderStatusConfirmed})))
+	assert.Empty(t, notifications.OrderActions(&models.Order{ID: 5, Status: models.OrderStatusRejected}))
+}
+
+func TestParseAction(t *testing.T) {
+	action, id, err := notifications.ParseAction("assign_42")
+	require.NoError(t, err)
+	assert.Equal(t, notifications.ActionAssign, action)
+	assert.Equal(t, 42, id)
+
+	for _, bad := range []string{"", "confirm", "delete_1", "confirm_x", "reject_-1"} {
+		_, _, err := notifications.ParseAction(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestRouter_NilIsNoop(t *testing.T) {
+	var r *notifications.Router
+	assert.NoError(t, r.Notify(context.Background(), newOrderCreated()))
+}
+
+func TestDefaultRenderer_StatusChanged(t *testing.T) {
+	renderer := notifications.NewDefaultRenderer(notifications.Links{TrackingURL: "https://t.example/track"})
+	ev := notifications.OrderStatusChanged{
+		Order: &models.Order{ID: 3, UserName: "Анна", TrackingToken: "abc"},
+		From:  models.OrderStatusNew,
+		To:    models.OrderStatusConfirmed,
+		Actor: "Менеджер",
+	}
+
+	admin, err := renderer.Render(context.Background(), ev, notifications.ChannelTelegram, notifications.AudienceAdmin)
+	require.NoError(t, err)
+	assert.Contains(t, admin.Text, "Менеджер")
+
+	customer, err := renderer.Render(context.Background(), ev, notifications.ChannelEmail, notifications.AudienceCustomer)
+	require.NoError(t, err)
+	assert.Contains(t, customer.Text, "https://t.example/track/abc")
+	assert.NotContains(t, customer.Text, "Менеджер")
+	assert.NotEmpty(t, customer.Subject)
+}
@@ -0,0 +1,175 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Destination — чат менеджеров в Telegram, куда уходят уведомления по правилам
+type Destination struct {
+	ID     int
+	Name   string
+	ChatID string
+	Quiet  QuietHours
+}
+
+// QuietHours — время, когда в чат ничего не отправляется: сообщения ждут конца тихих часов.
+// From и To — смещение от полуночи в Location; From == To — тихих часов нет.
+// From > To — тихие часы переходят через полночь (22:00–08:00).
+type QuietHours struct {
+	From     time.Duration
+	To       time.Duration
+	Location *time.Location
+}
+
+// ParseQuietHours разбирает "22:00", "08:00" и часовой пояс; пустые from и to — тихих часов нет
+func ParseQuietHours(from, to, tz string) (QuietHours, error) {
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return QuietHours{}, fmt.Errorf("unknown timezone %q", tz)
+	}
+	q := QuietHours{Location: loc}
+	if from == "" && to == "" {
+		return q, nil
+	}
+	if q.From, err = parseClock(from); err != nil {
+		return QuietHours{}, err
+	}
+	if q.To, err = parseClock(to); err != nil {
+		return QuietHours{}, err
+	}
+	return q, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func (q QuietHours) Enabled() bool { return q.From != q.To }
+
+// Until — когда можно отправить сообщение, готовое в момент t:
+// сам t вне тихих часов, иначе их ближайший конец
+func (q QuietHours) Until(t time.Time) time.Time {
+	if !q.Enabled() {
+		return t
+	}
+	loc := q.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	local := t.In(loc)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	now := local.Sub(midnight)
+	end := func(day int) time.Time {
+		return time.Date(local.Year(), local.Month(), local.Day()+day, 0, 0, 0, 0, loc).Add(q.To)
+	}
+
+	if q.From < q.To {
+		if now >= q.From && now < q.To {
+			return end(0)
+		}
+		return t
+	}
+	// через полночь: вечер — до утра следующего дня, раннее утро — до утра сегодня
+	switch {
+	case now >= q.From:
+		return end(1)
+	case now < q.To:
+		return end(0)
+	}
+	return t
+}
+
+// Rule — правило маршрутизации: события, подходящие под все заданные условия,
+// уходят в Destination. Пустое условие подходит под любое значение.
+type Rule struct {
+	EventType     EventType
+	TripType      string
+	DepartureCity string
+	// MinAmount и MaxAmount — границы суммы заказа включительно
+	MinAmount   *float64
+	MaxAmount   *float64
+	Destination Destination
+}
+
+// Facts — по чему правила выбирают события
+type Facts struct {
+	Event         EventType
+	TripType      string
+	DepartureCity string
+	// Amount — сумма заказа; nil — у события нет суммы
+	Amount *float64
+}
+
+// FactsOf — тип события, тур и сумма заказа. У смены статуса тура в событии нет,
+// поэтому правила с типом тура или городом под неё не подходят. Сводки напоминаний
+// о платежах и звонках охватывают много заказов и выбираются только по типу события.
+func FactsOf(ev Event) Facts {
+	f := Facts{Event: ev.Type()}
+	switch e := ev.(type) {
+	case OrderCreated:
+		f.Amount = e.Order.TotalPrice
+		if e.Trip != nil {
+			f.TripType = e.Trip.TripType
+			f.DepartureCity = e.Trip.DepartureCity
+		}
+	case OrderStatusChanged:
+		f.Amount = e.Order.TotalPrice
+	case CancellationRequested:
+		f.Amount = e.Order.TotalPrice
+		if e.Trip != nil {
+			f.TripType = e.Trip.TripType
+			f.DepartureCity = e.Trip.DepartureCity
+		}
+	}
+	return f
+}
+
+func (r Rule) Matches(f Facts) bool {
+	if r.EventType != "" && r.EventType != f.Event {
+		return false
+	}
+	if r.TripType != "" && !strings.EqualFold(r.TripType, f.TripType) {
+		return false
+	}
+	if r.DepartureCity != "" && !strings.EqualFold(r.DepartureCity, f.DepartureCity) {
+		return false
+	}
+	if r.MinAmount != nil || r.MaxAmount != nil {
+		if f.Amount == nil {
+			return false
+		}
+		if r.MinAmount != nil && *f.Amount < *r.MinAmount {
+			return false
+		}
+		if r.MaxAmount != nil && *f.Amount > *r.MaxAmount {
+			return false
+		}
+	}
+	return true
+}
+
+// RuleStore — правила маршрутизации по чатам (меняются из админки)
+type RuleStore interface {
+	Rules(ctx context.Context) ([]Rule, error)
+}
+
+// MatchDestinations — чаты всех подошедших правил, каждый по одному разу
+func MatchDestinations(rules []Rule, f Facts) []Destination {
+	var list []Destination
+	seen := map[int]bool{}
+	for _, r := range rules {
+		if !r.Matches(f) || seen[r.Destination.ID] {
+			continue
+		}
+		seen[r.Destination.ID] = true
+		list = append(list, r.Destination)
+	}
+	return list
+}
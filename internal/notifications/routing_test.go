@@ -0,0 +1,200 @@
+package notifications_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/notifications"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestQuietHours_Until(t *testing.T) {
+	msk, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+	at := func(day, hour, min int) time.Time { return time.Date(2025, 10, day, hour, min, 0, 0, msk) }
+
+	overnight, err := notifications.ParseQuietHours("22:00", "08:00", "Europe/Moscow")
+	require.NoError(t, err)
+	daytime, err := notifications.ParseQuietHours("13:00", "14:30", "Europe/Moscow")
+	require.NoError(t, err)
+	off, err := notifications.ParseQuietHours("", "", "Europe/Moscow")
+	require.NoError(t, err)
+
+	cases := []struct {
+		name  string
+		quiet notifications.QuietHours
+		now   time.Time
+		want  time.Time
+	}{
+		{"вечер — до утра", overnight, at(1, 23, 15), at(2, 8, 0)},
+		{"ночь — до утра", overnight, at(2, 3, 0), at(2, 8, 0)},
+		{"начало тихих часов", overnight, at(1, 22, 0), at(2, 8, 0)},
+		{"конец тихих часов", overnight, at(2, 8, 0), at(2, 8, 0)},
+		{"днём — сразу", overnight, at(1, 12, 0), at(1, 12, 0)},
+		{"обед", daytime, at(1, 13, 40), at(1, 14, 30)},
+		{"после обеда", daytime, at(1, 15, 0), at(1, 15, 0)},
+		{"без тихих часов", off, at(1, 23, 0), at(1, 23, 0)},
+		// момент в UTC переводится в часовой пояс чата: 20:00 UTC — 23:00 МСК
+		{"другой часовой пояс", overnight, time.Date(2025, 10, 1, 20, 0, 0, 0, time.UTC), at(2, 8, 0)},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.True(t, c.want.Equal(c.quiet.Until(c.now)), "got %s", c.quiet.Until(c.now))
+		})
+	}
+}
+
+func TestParseQuietHours_Invalid(t *testing.T) {
+	for _, c := range [][3]string{
+		{"22:00", "08:00", "Mars/Olympus"},
+		{"25:00", "08:00", "Europe/Moscow"},
+		{"22:00", "8", "Europe/Moscow"},
+	} {
+		_, err := notifications.ParseQuietHours(c[0], c[1], c[2])
+		assert.Error(t, err, c)
+	}
+}
+
+func amount(v float64) *float64 { return &v }
+
+func TestRule_Matches(t *testing.T) {
+	facts := notifications.Facts{
+		Event:         notifications.EventOrderCreated,
+		TripType:      "umrah",
+		DepartureCity: "Махачкала",
+		Amount:        amount(150000),
+	}
+
+	cases := []struct {
+		name string
+		rule notifications.Rule
+		want bool
+	}{
+		{"пустое правило", notifications.Rule{}, true},
+		{"событие", notifications.Rule{EventType: notifications.EventOrderCreated}, true},
+		{"другое событие", notifications.Rule{EventType: notifications.EventFeedbackReceived}, false},
+		{"тип и город без учёта регистра", notifications.Rule{TripType: "UMRAH", DepartureCity: "махачкала"}, true},
+		{"другой город", notifications.Rule{DepartureCity: "Грозный"}, false},
+		{"сумма в границах включительно", notifications.Rule{MinAmount: amount(150000), MaxAmount: amount(150000)}, true},
+		{"сумма меньше", notifications.Rule{MinAmount: amount(200000)}, false},
+		{"сумма больше", notifications.Rule{MaxAmount: amount(100000)}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.want, c.rule.Matches(facts))
+		})
+	}
+
+	// у события без суммы правило с суммой не срабатывает
+	assert.False(t, notifications.Rule{MinAmount: amount(0)}.Matches(notifications.Facts{Event: notifications.EventFeedbackReceived}))
+}
+
+type fakeRules struct {
+	rules []notifications.Rule
+	err   error
+}
+
+func (f fakeRules) Rules(context.Context) ([]notifications.Rule, error) { return f.rules, f.err }
+
+func routedOrder() notifications.OrderCreated {
+	ev := newOrderCreated()
+	ev.Order.TotalPrice = amount(300000)
+	ev.Trip = &models.Trip{TripType: "umrah", DepartureCity: "Махачкала"}
+	return ev
+}
+
+func TestRouter_Deliveries_Rules(t *testing.T) {
+	routes, err := notifications.ParseRoutes("order.created=telegram,sms:customer")
+	require.NoError(t, err)
+
+	// тихие часы круглые сутки, кроме последней минуты перед полуночью по UTC
+	quiet := notifications.QuietHours{From: 0, To: 23*time.Hour + 59*time.Minute, Location: time.UTC}
+	umrah := notifications.Destination{ID: 1, ChatID: "-100"}
+	vip := notifications.Destination{ID: 2, ChatID: "-200", Quiet: quiet}
+	hajj := notifications.Destination{ID: 3, ChatID: "-300"}
+	rules := fakeRules{rules: []notifications.Rule{
+		{TripType: "umrah", Destination: umrah},
+		{DepartureCity: "Махачкала", Destination: umrah},
+		{MinAmount: amount(250000), Destination: vip},
+		{TripType: "hajj", Destination: hajj},
+	}}
+
+	renderer := notifications.NewDefaultRenderer(notifications.Links{})
+	r := notifications.NewRouter(routes, renderer, zaptest.NewLogger(t).Sugar(),
+		notifications.NewRecorder(notifications.ChannelTelegram), notifications.NewRecorder(notifications.ChannelSMS),
+	).UseRules(rules)
+
+	list := r.Deliveries(context.Background(), routedOrder())
+	require.Len(t, list, 3)
+
+	// в каждый подошедший чат — по одному сообщению с кнопками
+	assert.Equal(t, []string{"-100"}, list[0].Message.To)
+	assert.Zero(t, list[0].NotBefore)
+	assert.Equal(t, []string{"-200"}, list[1].Message.To)
+	assert.Len(t, list[1].Message.Actions, 3)
+	now := time.Now().UTC()
+	if now.Hour() != 23 || now.Minute() != 59 {
+		want := time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 0, 0, time.UTC)
+		assert.True(t, want.Equal(list[1].NotBefore), "got %s", list[1].NotBefore)
+	}
+
+	// правила касаются только чатов менеджеров
+	assert.Equal(t, notifications.ChannelSMS, list[2].Target.Channel)
+	assert.Zero(t, list[2].NotBefore)
+}
+
+func TestRouter_Deliveries_RulesFallback(t *testing.T) {
+	routes, err := notifications.ParseRoutes("order.created=telegram")
+	require.NoError(t, err)
+	renderer := notifications.NewDefaultRenderer(notifications.Links{})
+
+	for name, store := range map[string]fakeRules{
+		"ни одно правило не подошло": {rules: []notifications.Rule{{TripType: "hajj", Destination: notifications.Destination{ID: 1, ChatID: "-100"}}}},
+		"правила не загрузились":     {err: errors.New("db down")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := notifications.NewRouter(routes, renderer, zaptest.NewLogger(t).Sugar(),
+				notifications.NewRecorder(notifications.ChannelTelegram)).UseRules(store)
+
+			list := r.Deliveries(context.Background(), routedOrder())
+			require.Len(t, list, 1)
+			// получатели не заданы — канал шлёт в чат по умолчанию
+			assert.Empty(t, list[0].Message.To)
+		})
+	}
+}
+
+func TestRouter_Deliveries_RulesForCancellationsAndReminders(t *testing.T) {
+	routes, err := notifications.ParseRoutes("order.cancellation_requested=telegram;payment.reminder=telegram")
+	require.NoError(t, err)
+
+	quiet := notifications.QuietHours{From: 0, To: 23*time.Hour + 59*time.Minute, Location: time.UTC}
+	umrah := notifications.Destination{ID: 1, ChatID: "-100"}
+	finance := notifications.Destination{ID: 2, ChatID: "-200", Quiet: quiet}
+	rules := fakeRules{rules: []notifications.Rule{
+		{EventType: notifications.EventCancellationRequested, TripType: "umrah", Destination: umrah},
+		{EventType: notifications.EventPaymentReminder, Destination: finance},
+	}}
+	r := notifications.NewRouter(routes, notifications.NewDefaultRenderer(notifications.Links{}), zaptest.NewLogger(t).Sugar(),
+		notifications.NewRecorder(notifications.ChannelTelegram)).UseRules(rules)
+
+	cancellation := notifications.SampleEvent(notifications.EventCancellationRequested).(notifications.CancellationRequested)
+	cancellation.Trip.TripType = "umrah"
+	list := r.Deliveries(context.Background(), cancellation)
+	require.Len(t, list, 1)
+	assert.Equal(t, []string{"-100"}, list[0].Message.To)
+
+	list = r.Deliveries(context.Background(), notifications.SampleEvent(notifications.EventPaymentReminder))
+	require.Len(t, list, 1)
+	assert.Equal(t, []string{"-200"}, list[0].Message.To)
+	// сводка ждёт конца тихих часов чата
+	now := time.Now().UTC()
+	if now.Hour() != 23 || now.Minute() != 59 {
+		assert.True(t, list[0].NotBefore.After(now))
+	}
+}
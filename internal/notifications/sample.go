@@ -0,0 +1,141 @@
+package notifications
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+)
+
+// SampleEvent — событие с выдуманным заказом для предпросмотра шаблонов
+func SampleEvent(t EventType) Event {
+	at := time.Date(2025, 3, 14, 12, 30, 0, 0, time.Local)
+	total := 285000.0
+	chatID := int64(123456789)
+	birth := time.Date(1985, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	trip := &models.Trip{
+		ID:            12,
+		Title:         "Умра — 10 дней",
+		DepartureCity: "Москва",
+		Price:         150000,
+		FinalPrice:    142500,
+		Currency:      "RUB",
+		StartDate:     time.Date(2025, 4, 10, 0, 0, 0, 0, time.UTC),
+		EndDate:       time.Date(2025, 4, 20, 0, 0, 0, 0, time.UTC),
+	}
+	order := &models.Order{
+		ID:               1024,
+		TripID:           models.NullInt32{NullInt32: sql.NullInt32{Int32: int32(trip.ID), Valid: true}},
+		UserName:         "Иван Петров",
+		UserPhone:        "+79991234567",
+		UserPhoneDisplay: "+7 999 123-45-67",
+		TotalPrice:       &total,
+		Status:           models.OrderStatusNew,
+		TelegramChatID:   &chatID,
+		TrackingToken:    "sample-tracking-token",
+		CreatedAt:        at,
+		Travellers: []models.OrderTraveller{
+			{FullName: "Иван Петров", TravellerType: models.TravellerAdult, BirthDate: &birth},
+			{FullName: "Мария Петрова", TravellerType: models.TravellerAdult},
+		},
+	}
+
+	switch t {
+	case EventOrderStatusChanged:
+		order.Status = models.OrderStatusConfirmed
+		return OrderStatusChanged{
+			Order: order, From: models.OrderStatusNew, To: models.OrderStatusConfirmed,
+			Actor: "Менеджер", Comment: "Созвонились, ждём задаток", At: at,
+		}
+	case EventFeedbackReceived:
+		return FeedbackReceived{
+			Feedback: &models.Feedback{ID: 77, UserName: order.UserName, UserPhone: order.UserPhone, UserPhoneDisplay: order.UserPhoneDisplay, CreatedAt: at},
+			At:       at,
+		}
+	case EventDigest:
+		conversion := 1.8
+		from := time.Date(at.Year(), at.Month(), at.Day()-1, 0, 0, 0, 0, time.Local)
+		return DigestReady{
+			Digest: &models.Digest{
+				Period:         models.DigestDaily,
+				From:           from,
+				To:             from.AddDate(0, 0, 1),
+				OrdersTotal:    6,
+				OrdersByStatus: []models.KV{{Key: models.OrderStatusNew, Count: 4}, {Key: models.OrderStatusConfirmed, Count: 2}},
+				FeedbackCount:  3,
+				TopTrips:       []models.TripActivity{{TripID: trip.ID, Title: trip.Title, Views: 340, Orders: 6, Conversion: &conversion}},
+				Departures:     []models.TripDeparture{{TripID: trip.ID, Title: trip.Title, StartDate: trip.StartDate, Bookings: 9, Travellers: 21}},
+			},
+			At: at,
+		}
+	case EventVerification:
+		return VerificationRequested{
+			User:      &models.User{ID: 5, Email: "ivan@example.com", FullName: order.UserName},
+			Kind:      models.VerifyEmail,
+			Code:      "123456",
+			ExpiresAt: at.Add(15 * time.Minute),
+			At:        at,
+		}
+	case EventAccountLocked:
+		return AccountLocked{
+			User:        &models.User{ID: 2, Email: "admin@example.com", FullName: "Администратор", RoleID: models.RoleAdmin},
+			IP:          "203.0.113.7",
+			Failures:    5,
+			LockedUntil: at.Add(15 * time.Minute),
+			At:          at,
+		}
+	case EventCancellationRequested:
+		days := 27
+		reason := "Не успеваем оформить визу"
+		order.Status = models.OrderStatusPaid
+		return CancellationRequested{
+			Order: order,
+			Trip:  trip,
+			Cancellation: &models.OrderCancellation{
+				ID: 31, OrderID: order.ID, Source: models.CancellationSourceProfile, Reason: &reason,
+				PreviousStatus: models.OrderStatusPaid, DaysBefore: &days,
+				PaidAmount: total, PenaltyPercent: 10, PenaltyAmount: 28500, RefundAmount: 256500,
+				Status: models.CancellationPending, CreatedAt: at,
+			},
+			At: at,
+		}
+	case EventPaymentReminder:
+		return PaymentsDue{
+			Installments: []models.OverdueInstallment{{
+				OrderInstallment: models.OrderInstallment{
+					ID: 41, OrderID: order.ID, Seq: 2, Title: "Остаток", Amount: 200000, PaidAmount: 50000,
+					DueDate: time.Date(2025, 3, 17, 0, 0, 0, 0, time.UTC),
+				},
+				Outstanding: 150000,
+				UserName:    order.UserName,
+				UserPhone:   order.UserPhone,
+				OrderStatus: models.OrderStatusConfirmed,
+				TripID:      &trip.ID,
+				TripTitle:   &trip.Title,
+			}},
+			At: at,
+		}
+	case EventFollowUpReminder:
+		manager := "Марьям"
+		return FollowUpsDue{
+			Reminders: []models.FollowUpReminder{{
+				OrderID:      order.ID,
+				UserName:     order.UserName,
+				UserPhone:    order.UserPhone,
+				Status:       models.OrderStatusNew,
+				NextCallAt:   at.Add(-30 * time.Minute),
+				AssigneeName: &manager,
+			}},
+			At: at,
+		}
+	case EventPasswordReset:
+		return PasswordResetRequested{
+			User:      &models.User{ID: 5, Email: "ivan@example.com", FullName: order.UserName},
+			ResetURL:  "https://example.com/reset-password?token=sample-reset-token",
+			ExpiresAt: at.Add(time.Hour),
+			At:        at,
+		}
+	}
+	return OrderCreated{Order: order, Trip: trip, At: at}
+}
@@ -0,0 +1,84 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+type SMSConfig struct {
+	// URL шлюза: принимает POST {"to": "+7999...", "text": "..."}
+	URL string
+	// Token — передаётся как Authorization: Bearer, если задан
+	Token string
+	// To — телефоны менеджеров в E.164, получатели по умолчанию
+	To []string
+}
+
+// SMS — отправка через HTTP-шлюз провайдера SMS
+type SMS struct {
+	http *http.Client
+	cfg  SMSConfig
+}
+
+func NewSMS(cfg SMSConfig) *SMS {
+	return &SMS{
+		http: &http.Client{Timeout: 10 * time.Second},
+		cfg:  cfg,
+	}
+}
+
+func (s *SMS) Channel() string { return ChannelSMS }
+
+func (s *SMS) Send(ctx context.Context, msg Message) error {
+	to := msg.To
+	if len(to) == 0 {
+		to = s.cfg.To
+	}
+	if len(to) == 0 {
+		return ErrNoRecipients
+	}
+
+	text := msg.Text
+	if msg.Link != nil {
+		text += " " + msg.Link.URL
+	}
+	for _, phone := range to {
+		if err := s.post(ctx, phone, text); err != nil {
+			return fmt.Errorf("sms %s: %w", phone, err)
+		}
+	}
+	return nil
+}
+
+func (s *SMS) post(ctx context.Context, phone, text string) error {
+	body, err := json.Marshal(map[string]string{"to": phone, "text": text})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.cfg.Token)
+	}
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("sms gateway failed: %s, body: %s", resp.Status, string(b))
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const TelegramDefaultURL = "https://api.telegram.org"
+
+type TelegramConfig struct {
+	Token string
+	// ChatID — чат менеджеров, получатель по умолчанию
+	ChatID string
+	// BaseURL переопределяется в тестах адресом локальной заглушки
+	BaseURL string
+}
+
+// Telegram — отправка через Bot API (sendMessage, HTML-разметка)
+type Telegram struct {
+	http *http.Client
+	cfg  TelegramConfig
+}
+
+func NewTelegram(cfg TelegramConfig) *Telegram {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = TelegramDefaultURL
+	}
+	return &Telegram{
+		http: &http.Client{Timeout: 10 * time.Second},
+		cfg:  cfg,
+	}
+}
+
+func (t *Telegram) Channel() string { return ChannelTelegram }
+
+func (t *Telegram) Send(ctx context.Context, msg Message) error {
+	to := msg.To
+	if len(to) == 0 && t.cfg.ChatID != "" {
+		to = []string{t.cfg.ChatID}
+	}
+	if len(to) == 0 {
+		return ErrNoRecipients
+	}
+
+	for _, chatID := range to {
+		payload := map[string]any{
+			"chat_id":    chatID,
+			"text":       msg.Text,
+			"parse_mode": "HTML",
+		}
+		if kb := keyboard(msg); kb != nil {
+			payload["reply_markup"] = kb
+		}
+		if err := t.call(ctx, "sendMessage", payload); err != nil {
+			return fmt.Errorf("telegram chat %s: %w", chatID, err)
+		}
+	}
+	return nil
+}
+
+// AnswerCallback отвечает на нажатие кнопки: text — всплывающая подсказка,
+// alert — показать её окном, которое нужно закрыть
+func (t *Telegram) AnswerCallback(ctx context.Context, callbackID, text string, alert bool) error {
+	payload := map[string]any{"callback_query_id": callbackID}
+	if text != "" {
+		payload["text"] = text
+		payload["show_alert"] = alert
+	}
+	return t.call(ctx, "answerCallbackQuery", payload)
+}
+
+// EditMessage заменяет текст и кнопки уже отправленного сообщения
+func (t *Telegram) EditMessage(ctx context.Context, chatID int64, messageID int, msg Message) error {
+	payload := map[string]any{
+		"chat_id":    chatID,
+		"message_id": messageID,
+		"text":       msg.Text,
+		"parse_mode": "HTML",
+		// без reply_markup Telegram оставил бы старые кнопки
+		"reply_markup": map[string]any{"inline_keyboard": [][]map[string]string{}},
+	}
+	if kb := keyboard(msg); kb != nil {
+		payload["reply_markup"] = kb
+	}
+	err := t.call(ctx, "editMessageText", payload)
+	if err != nil && strings.Contains(err.Error(), "message is not modified") {
+		// повторное нажатие той же кнопки — сообщение уже в нужном виде
+		return nil
+	}
+	return err
+}
+
+// SetWebhook регистрирует адрес, на который Telegram присылает обновления;
+// secret приходит в заголовке X-Telegram-Bot-Api-Secret-Token каждого запроса
+func (t *Telegram) SetWebhook(ctx context.Context, url, secret string, allowedUpdates []string) error {
+	payload := map[string]any{
+		"url":             url,
+		"secret_token":    secret,
+		"allowed_updates": allowedUpdates,
+	}
+	return t.call(ctx, "setWebhook", payload)
+}
+
+// keyboard — кнопки бота по две в ряд, ссылка отдельным рядом внизу
+func keyboard(msg Message) map[string]any {
+	var rows [][]map[string]string
+	var row []map[string]string
+	for _, a := range msg.Actions {
+		row = append(row, map[string]string{"text": a.Text, "callback_data": a.Data})
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if row != nil {
+		rows = append(rows, row)
+	}
+	if msg.Link != nil {
+		rows = append(rows, []map[string]string{{"text": msg.Link.Text, "url": msg.Link.URL}})
+	}
+	if rows == nil {
+		return nil
+	}
+	return map[string]any{"inline_keyboard": rows}
+}
+
+func (t *Telegram) call(ctx context.Context, method string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/bot%s/%s", strings.TrimRight(t.cfg.BaseURL, "/"), t.cfg.Token, method)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("telegram %s failed: %s, body: %s", method, resp.Status, string(b))
+	}
+	return nil
+}
+
+// TelegramUpdate — входящее обновление Bot API (нужные боту поля)
+type TelegramUpdate struct {
+	UpdateID      int                    `json:"update_id"`
+	Message       *TelegramMessage       `json:"message,omitempty"`
+	CallbackQuery *TelegramCallbackQuery `json:"callback_query,omitempty"`
+}
+
+// TelegramCallbackQuery — нажатие кнопки бота
+type TelegramCallbackQuery struct {
+	ID      string           `json:"id"`
+	From    TelegramUser     `json:"from"`
+	Message *TelegramMessage `json:"message,omitempty"`
+	Data    string           `json:"data"`
+}
+
+type TelegramUser struct {
+	ID        int64  `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name,omitempty"`
+	Username  string `json:"username,omitempty"`
+}
+
+type TelegramMessage struct {
+	MessageID int           `json:"message_id"`
+	From      *TelegramUser `json:"from,omitempty"`
+	Chat      TelegramChat  `json:"chat"`
+	Text      string        `json:"text,omitempty"`
+}
+
+type TelegramChat struct {
+	ID   int64  `json:"id"`
+	Type string `json:"type"`
+}
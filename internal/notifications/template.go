@@ -0,0 +1,243 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	htmltemplate "html/template"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"go.uber.org/zap"
+)
+
+// DefaultLocale — язык шаблонов, если другой не настроен
+const DefaultLocale = "ru"
+
+// TemplateData — переменные, доступные в шаблонах уведомлений.
+// Поля, не относящиеся к событию, пустые: у feedback.received нет .Order и .Trip,
+// у заказа без тура нет .Trip, .Digest есть только у report.digest,
+// .ResetURL — только у auth.password_reset, .Code — у auth.verification, .User и .IP — у auth.account_locked,
+// .ExpiresAt — у всех трёх; .Cancellation — у order.cancellation_requested, .Installments — у payment.reminder,
+// .FollowUps — у order.follow_up.
+type TemplateData struct {
+	Event EventType
+	At    time.Time
+
+	Order    *models.Order
+	Trip     *models.Trip
+	Feedback *models.Feedback
+	Digest   *models.Digest
+	Customer Contact
+
+	// order.status_changed: коды статусов и их названия по-русски, кто и с каким комментарием
+	From      string
+	To        string
+	FromTitle string
+	ToTitle   string
+	Actor     string
+	Comment   string
+
+	// auth.password_reset: ссылка с одноразовым токеном; auth.verification: код и что он подтверждает (email, phone).
+	// ExpiresAt — до какого времени действуют ссылка или код, у auth.account_locked — блокировка входа
+	ResetURL  string
+	Code      string
+	Kind      string
+	ExpiresAt time.Time
+
+	// auth.account_locked: чей аккаунт, IP последней попытки и сколько их было
+	User     *models.User
+	IP       string
+	Failures int
+
+	// order.cancellation_requested: заявка с расчётом штрафа и суммы к возврату
+	Cancellation *models.OrderCancellation
+	// payment.reminder: платежи, срок которых подходит или прошёл; order.follow_up: заказы, по которым пора звонить
+	Installments []models.OverdueInstallment
+	FollowUps    []models.FollowUpReminder
+
+	TrackingURL string
+	TripURL     string
+	AdminURL    string
+}
+
+// NewTemplateData собирает переменные шаблона из события
+func NewTemplateData(ev Event, links Links) TemplateData {
+	d := TemplateData{Event: ev.Type(), Customer: ev.Customer(), AdminURL: links.AdminURL}
+
+	switch e := ev.(type) {
+	case OrderCreated:
+		d.At, d.Order, d.Trip = eventTime(e.At), e.Order, e.Trip
+	case OrderStatusChanged:
+		d.At, d.Order = eventTime(e.At), e.Order
+		d.From, d.To = e.From, e.To
+		d.FromTitle, d.ToTitle = StatusTitle(e.From), StatusTitle(e.To)
+		d.Actor, d.Comment = e.Actor, e.Comment
+	case FeedbackReceived:
+		d.At, d.Feedback = eventTime(e.At), e.Feedback
+	case DigestReady:
+		d.At, d.Digest = eventTime(e.At), e.Digest
+	case PasswordResetRequested:
+		d.At, d.ResetURL, d.ExpiresAt = eventTime(e.At), e.ResetURL, e.ExpiresAt
+	case VerificationRequested:
+		d.At, d.Code, d.Kind, d.ExpiresAt = eventTime(e.At), e.Code, e.Kind, e.ExpiresAt
+	case AccountLocked:
+		d.At, d.User, d.IP, d.Failures, d.ExpiresAt = eventTime(e.At), e.User, e.IP, e.Failures, e.LockedUntil
+	case CancellationRequested:
+		d.At, d.Order, d.Trip, d.Cancellation = eventTime(e.At), e.Order, e.Trip, e.Cancellation
+	case PaymentsDue:
+		d.At, d.Installments = eventTime(e.At), e.Installments
+	case FollowUpsDue:
+		d.At, d.FollowUps = eventTime(e.At), e.Reminders
+	}
+	if d.Order != nil {
+		d.TrackingURL = links.Tracking(d.Order.TrackingToken)
+	}
+	if d.Trip != nil {
+		d.TripURL = links.Trip(d.Trip.ID)
+	}
+	return d
+}
+
+// templateFuncs — функции шаблонов: price, datetime, date, status
+var templateFuncs = map[string]any{
+	"price": func(v any) string {
+		switch p := v.(type) {
+		case float64:
+			return helpers.FormatPrice(p)
+		case *float64:
+			if p != nil {
+				return helpers.FormatPrice(*p)
+			}
+		}
+		return "—"
+	},
+	"datetime": func(v any) string { return formatTemplateTime(v, "02.01.2006 15:04") },
+	"date":     func(v any) string { return formatTemplateTime(v, "02.01.2006") },
+	"status":   StatusTitle,
+}
+
+func formatTemplateTime(v any, layout string) string {
+	switch t := v.(type) {
+	case time.Time:
+		return t.Format(layout)
+	case *time.Time:
+		if t != nil {
+			return t.Format(layout)
+		}
+	}
+	return "—"
+}
+
+// Template — текст уведомления на Go template. Тело для Telegram и email — html/template
+// (значения экранируются), для SMS — text/template. Тема и кнопка — text/template.
+type Template struct {
+	Subject  string
+	Body     string
+	LinkText string
+	LinkURL  string
+}
+
+// Render подставляет данные в шаблон для канала
+func (t Template) Render(channel string, data TemplateData) (Message, error) {
+	var (
+		msg Message
+		err error
+	)
+	if msg.Subject, err = execText("subject", t.Subject, data); err != nil {
+		return Message{}, err
+	}
+	if channel == ChannelSMS {
+		msg.Text, err = execText("body", t.Body, data)
+	} else {
+		msg.Text, err = execHTML("body", t.Body, data)
+	}
+	if err != nil {
+		return Message{}, err
+	}
+
+	url, err := execText("link_url", t.LinkURL, data)
+	if err != nil {
+		return Message{}, err
+	}
+	if url = strings.TrimSpace(url); url != "" {
+		text, err := execText("link_text", t.LinkText, data)
+		if err != nil {
+			return Message{}, err
+		}
+		if text = strings.TrimSpace(text); text == "" {
+			text = url
+		}
+		msg.Link = &Link{Text: text, URL: url}
+	}
+	return msg, nil
+}
+
+func execText(name, src string, data TemplateData) (string, error) {
+	if src == "" {
+		return "", nil
+	}
+	tpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(src)
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	if err := tpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func execHTML(name, src string, data TemplateData) (string, error) {
+	tpl, err := htmltemplate.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(src)
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	if err := tpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// TemplateStore ищет активный шаблон; nil без ошибки — шаблона нет
+type TemplateStore interface {
+	FindTemplate(ctx context.Context, event EventType, audience, channel, locale string) (*Template, error)
+}
+
+// TemplateRenderer берёт тексты из хранилища шаблонов, а если шаблона нет
+// или он сломан — из встроенного рендерера
+type TemplateRenderer struct {
+	store    TemplateStore
+	fallback Renderer
+	links    Links
+	locale   string
+	log      *zap.SugaredLogger
+}
+
+func NewTemplateRenderer(store TemplateStore, fallback Renderer, links Links, locale string, log *zap.SugaredLogger) *TemplateRenderer {
+	if locale == "" {
+		locale = DefaultLocale
+	}
+	return &TemplateRenderer{store: store, fallback: fallback, links: links, locale: locale, log: log}
+}
+
+func (r *TemplateRenderer) Render(ctx context.Context, ev Event, channel, audience string) (Message, error) {
+	t, err := r.store.FindTemplate(ctx, ev.Type(), audience, channel, r.locale)
+	if err != nil {
+		r.log.Errorw("Ошибка загрузки шаблона уведомления", "event", ev.Type(), "channel", channel, "err", err)
+		return r.fallback.Render(ctx, ev, channel, audience)
+	}
+	if t == nil {
+		return r.fallback.Render(ctx, ev, channel, audience)
+	}
+
+	msg, err := t.Render(channel, NewTemplateData(ev, r.links))
+	if err != nil {
+		r.log.Errorw("Ошибка в шаблоне уведомления, используется встроенный текст", "event", ev.Type(), "channel", channel, "audience", audience, "err", err)
+		return r.fallback.Render(ctx, ev, channel, audience)
+	}
+	return msg, nil
+}
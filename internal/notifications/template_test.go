@@ -0,0 +1,109 @@
+package notifications_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/notifications"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+var testLinks = notifications.Links{TrackingURL: "https://t.example/track", FrontendURL: "https://site.example"}
+
+func TestTemplate_Render(t *testing.T) {
+	tpl := notifications.Template{
+		Subject:  "Заказ №{{.Order.ID}}",
+		Body:     "<b>{{.Order.UserName}}</b>, {{price .Order.TotalPrice}} руб., {{datetime .At}}",
+		LinkText: "Открыть {{.Trip.Title}}",
+		LinkURL:  "{{.TripURL}}",
+	}
+	ev := notifications.SampleEvent(notifications.EventOrderCreated).(notifications.OrderCreated)
+	ev.Order.UserName = "Иван <script>"
+	data := notifications.NewTemplateData(ev, testLinks)
+
+	msg, err := tpl.Render(notifications.ChannelTelegram, data)
+	require.NoError(t, err)
+	assert.Equal(t, "Заказ №1024", msg.Subject)
+	assert.Contains(t, msg.Text, "<b>Иван &lt;script&gt;</b>, 285 000 руб., 14.03.2025 12:30")
+	require.NotNil(t, msg.Link)
+	assert.Equal(t, "https://site.example/api/v1/trips/12", msg.Link.URL)
+	assert.Equal(t, "Открыть Умра — 10 дней", msg.Link.Text)
+
+	// в SMS значения не экранируются
+	msg, err = tpl.Render(notifications.ChannelSMS, data)
+	require.NoError(t, err)
+	assert.Contains(t, msg.Text, "Иван <script>")
+}
+
+func TestTemplate_Render_Errors(t *testing.T) {
+	data := notifications.NewTemplateData(notifications.SampleEvent(notifications.EventFeedbackReceived), testLinks)
+
+	_, err := notifications.Template{Body: "{{.Order.ID"}.Render(notifications.ChannelTelegram, data)
+	assert.Error(t, err, "syntax error")
+
+	_, err = notifications.Template{Body: "{{.Order.ID}}"}.Render(notifications.ChannelTelegram, data)
+	assert.Error(t, err, "feedback has no order")
+
+	_, err = notifications.Template{Body: "{{.Nope}}"}.Render(notifications.ChannelSMS, data)
+	assert.Error(t, err, "unknown field")
+}
+
+type stubStore struct {
+	tpl *notifications.Template
+	err error
+}
+
+func (s stubStore) FindTemplate(context.Context, notifications.EventType, string, string, string) (*notifications.Template, error) {
+	return s.tpl, s.err
+}
+
+func TestTemplateRenderer_FallsBackToDefaults(t *testing.T) {
+	ev := notifications.SampleEvent(notifications.EventOrderStatusChanged)
+	fallback := notifications.NewDefaultRenderer(testLinks)
+	want, err := fallback.Render(context.Background(), ev, notifications.ChannelTelegram, notifications.AudienceCustomer)
+	require.NoError(t, err)
+
+	for name, store := range map[string]stubStore{
+		"no template": {},
+		"broken":      {tpl: &notifications.Template{Body: "{{.Feedback.UserName}}"}},
+		"store error": {err: errors.New("db down")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := notifications.NewTemplateRenderer(store, fallback, testLinks, "", zaptest.NewLogger(t).Sugar())
+			got, err := r.Render(context.Background(), ev, notifications.ChannelTelegram, notifications.AudienceCustomer)
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestTemplateRenderer_UsesTemplate(t *testing.T) {
+	store := stubStore{tpl: &notifications.Template{Body: "Заявка {{.Order.ID}}: {{.ToTitle}} {{.TrackingURL}}"}}
+	r := notifications.NewTemplateRenderer(store, notifications.NewDefaultRenderer(testLinks), testLinks, "", zaptest.NewLogger(t).Sugar())
+
+	ev := notifications.SampleEvent(notifications.EventOrderStatusChanged)
+	msg, err := r.Render(context.Background(), ev, notifications.ChannelSMS, notifications.AudienceCustomer)
+	require.NoError(t, err)
+	assert.Equal(t, "Заявка 1024: "+notifications.StatusTitle(models.OrderStatusConfirmed)+" https://t.example/track/sample-tracking-token", msg.Text)
+}
+
+func TestTemplate_Render_Reminders(t *testing.T) {
+	tpl := notifications.Template{Body: "{{range .Installments}}№{{.OrderID}} {{.UserName}}: {{price .Outstanding}} до {{date .DueDate}}{{end}}"}
+	msg, err := tpl.Render(notifications.ChannelSMS, notifications.NewTemplateData(notifications.SampleEvent(notifications.EventPaymentReminder), testLinks))
+	require.NoError(t, err)
+	assert.Equal(t, "№1024 Иван Петров: 150 000 до 17.03.2025", msg.Text)
+
+	tpl = notifications.Template{Body: "{{range .FollowUps}}№{{.OrderID}} {{datetime .NextCallAt}} {{.AssigneeName}}{{end}}"}
+	msg, err = tpl.Render(notifications.ChannelSMS, notifications.NewTemplateData(notifications.SampleEvent(notifications.EventFollowUpReminder), testLinks))
+	require.NoError(t, err)
+	assert.Equal(t, "№1024 14.03.2025 12:00 Марьям", msg.Text)
+
+	tpl = notifications.Template{Body: "№{{.Order.ID}}: штраф {{price .Cancellation.PenaltyAmount}}, к возврату {{price .Cancellation.RefundAmount}}"}
+	msg, err = tpl.Render(notifications.ChannelSMS, notifications.NewTemplateData(notifications.SampleEvent(notifications.EventCancellationRequested), testLinks))
+	require.NoError(t, err)
+	assert.Equal(t, "№1024: штраф 28 500, к возврату 256 500", msg.Text)
+}
@@ -0,0 +1,133 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+const FakeSignatureHeader = "X-Fake-Signature"
+
+// FakeProvider — провайдер для разработки и тестов: платежи хранятся в памяти,
+// вебхуки подписываются общим секретом и формируются через FakeProvider.Webhook
+type FakeProvider struct {
+	secret string
+
+	mu       sync.Mutex
+	seq      int
+	payments map[string]*fakePayment
+	byKey    map[string]string
+}
+
+type fakePayment struct {
+	amount   float64
+	refunded float64
+	status   string
+}
+
+type fakeWebhook struct {
+	EventID   string  `json:"event_id"`
+	PaymentID string  `json:"payment_id"`
+	Status    string  `json:"status"`
+	Amount    float64 `json:"amount"`
+	Currency  string  `json:"currency"`
+}
+
+func NewFakeProvider(secret string) *FakeProvider {
+	return &FakeProvider{
+		secret:   secret,
+		payments: make(map[string]*fakePayment),
+		byKey:    make(map[string]string),
+	}
+}
+
+func (f *FakeProvider) Name() string { return "fake" }
+
+func (f *FakeProvider) Create(_ context.Context, req CreateRequest) (*Payment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id, ok := f.byKey[req.IdempotencyKey]
+	if !ok || req.IdempotencyKey == "" {
+		f.seq++
+		id = fmt.Sprintf("fake_%d", f.seq)
+		f.payments[id] = &fakePayment{amount: req.Amount, status: StatusPending}
+		if req.IdempotencyKey != "" {
+			f.byKey[req.IdempotencyKey] = id
+		}
+	}
+
+	confirmation := "https://pay.example.invalid/" + id
+	if req.ReturnURL != "" {
+		confirmation = req.ReturnURL + "?payment=" + url.QueryEscape(id)
+	}
+	return &Payment{ExternalID: id, Status: f.payments[id].status, ConfirmationURL: confirmation}, nil
+}
+
+func (f *FakeProvider) ParseWebhook(header http.Header, body []byte) (*Event, error) {
+	if err := VerifySignature(f.secret, header.Get(FakeSignatureHeader), body); err != nil {
+		return nil, err
+	}
+
+	var wh fakeWebhook
+	if err := json.Unmarshal(body, &wh); err != nil {
+		return nil, fmt.Errorf("decode fake webhook: %w", err)
+	}
+	if wh.EventID == "" || wh.PaymentID == "" {
+		return nil, ErrUnsupportedEvent
+	}
+
+	f.mu.Lock()
+	if p, ok := f.payments[wh.PaymentID]; ok {
+		p.status = wh.Status
+	}
+	f.mu.Unlock()
+
+	return &Event{
+		ID:         wh.EventID,
+		ExternalID: wh.PaymentID,
+		Status:     wh.Status,
+		Amount:     wh.Amount,
+		Currency:   wh.Currency,
+	}, nil
+}
+
+func (f *FakeProvider) Refund(_ context.Context, req RefundRequest) (*Refund, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p, ok := f.payments[req.ExternalID]
+	if !ok {
+		return nil, ErrPaymentNotFound
+	}
+	if p.status != StatusSucceeded && p.status != StatusRefunded {
+		return nil, fmt.Errorf("fake refund: payment %s is %s: %w", req.ExternalID, p.status, ErrRefundRejected)
+	}
+	if p.refunded+req.Amount > p.amount+0.005 {
+		return nil, fmt.Errorf("fake refund: amount exceeds payment: %w", ErrRefundRejected)
+	}
+
+	p.refunded += req.Amount
+	if p.refunded+0.005 >= p.amount {
+		p.status = StatusRefunded
+	}
+	return &Refund{ExternalID: req.ExternalID + "_refund", Status: StatusSucceeded}, nil
+}
+
+// Webhook формирует подписанное уведомление о смене статуса платежа —
+// так в тестах и на dev-стенде имитируется ответ провайдера
+func (f *FakeProvider) Webhook(eventID, paymentID, status string, amount float64) (http.Header, []byte) {
+	body, _ := json.Marshal(fakeWebhook{
+		EventID:   eventID,
+		PaymentID: paymentID,
+		Status:    status,
+		Amount:    amount,
+		Currency:  "RUB",
+	})
+	header := http.Header{}
+	header.Set(FakeSignatureHeader, Sign(f.secret, body))
+	return header, body
+}
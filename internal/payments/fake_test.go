@@ -0,0 +1,44 @@
+package payments_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Ramcache/travel-backend/internal/payments"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeProvider_Flow(t *testing.T) {
+	f := payments.NewFakeProvider("s")
+	ctx := context.Background()
+
+	p, err := f.Create(ctx, payments.CreateRequest{Amount: 100, IdempotencyKey: "k1"})
+	require.NoError(t, err)
+	again, err := f.Create(ctx, payments.CreateRequest{Amount: 100, IdempotencyKey: "k1"})
+	require.NoError(t, err)
+	assert.Equal(t, p.ExternalID, again.ExternalID, "idempotency key must return the same payment")
+
+	// возврат до оплаты невозможен
+	_, err = f.Refund(ctx, payments.RefundRequest{ExternalID: p.ExternalID, Amount: 10})
+	assert.Error(t, err)
+
+	h, body := f.Webhook("ev1", p.ExternalID, payments.StatusSucceeded, 100)
+	ev, err := f.ParseWebhook(h, body)
+	require.NoError(t, err)
+	assert.Equal(t, "ev1", ev.ID)
+	assert.Equal(t, payments.StatusSucceeded, ev.Status)
+
+	_, err = f.Refund(ctx, payments.RefundRequest{ExternalID: p.ExternalID, Amount: 100})
+	require.NoError(t, err)
+	_, err = f.Refund(ctx, payments.RefundRequest{ExternalID: p.ExternalID, Amount: 1})
+	assert.Error(t, err)
+}
+
+func TestFakeProvider_BadSignature(t *testing.T) {
+	f := payments.NewFakeProvider("s")
+	h, body := f.Webhook("ev1", "fake_1", payments.StatusSucceeded, 100)
+	h.Set(payments.FakeSignatureHeader, "deadbeef")
+	_, err := f.ParseWebhook(h, body)
+	assert.ErrorIs(t, err, payments.ErrInvalidSignature)
+}
@@ -0,0 +1,94 @@
+// Package payments — абстракция платёжных провайдеров (создание платежа,
+// разбор вебхука, возврат) и их реализации.
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+)
+
+// Статусы платежа, общие для всех провайдеров
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusCanceled  = "canceled"
+	StatusRefunded  = "refunded"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrUnsupportedEvent = errors.New("unsupported webhook event")
+	ErrPaymentNotFound  = errors.New("payment not found at provider")
+	// ErrRefundRejected — провайдер окончательно отказал в возврате; повтор с тем же ключом не поможет
+	ErrRefundRejected = errors.New("refund rejected by provider")
+)
+
+// Provider — платёжный провайдер в модели «редирект на оплату + вебхук»
+type Provider interface {
+	Name() string
+	// Create регистрирует платёж у провайдера и возвращает ссылку на оплату.
+	// IdempotencyKey гарантирует, что повторный вызов не создаст второй платёж.
+	Create(ctx context.Context, req CreateRequest) (*Payment, error)
+	// ParseWebhook проверяет подпись уведомления и приводит его к общему виду
+	ParseWebhook(header http.Header, body []byte) (*Event, error)
+	Refund(ctx context.Context, req RefundRequest) (*Refund, error)
+}
+
+type CreateRequest struct {
+	OrderID        int
+	Amount         float64
+	Currency       string
+	Description    string
+	ReturnURL      string
+	IdempotencyKey string
+}
+
+type Payment struct {
+	ExternalID      string
+	Status          string
+	ConfirmationURL string
+}
+
+// Event — уведомление провайдера об изменении статуса платежа.
+// ID уникален в рамках провайдера и используется для дедупликации.
+type Event struct {
+	ID         string
+	ExternalID string
+	Status     string
+	Amount     float64
+	Currency   string
+}
+
+type RefundRequest struct {
+	ExternalID     string
+	Amount         float64
+	Currency       string
+	IdempotencyKey string
+}
+
+type Refund struct {
+	ExternalID string
+	Status     string
+}
+
+// Sign — HMAC-SHA256 тела вебхука в hex
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature сравнивает подпись за постоянное время; пустой секрет всегда отклоняется
+func VerifySignature(secret, signature string, body []byte) error {
+	if secret == "" || signature == "" {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(Sign(secret, body)), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
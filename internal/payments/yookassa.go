@@ -0,0 +1,205 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	YooKassaDefaultURL      = "https://api.yookassa.ru/v3"
+	YooKassaSignatureHeader = "X-Webhook-Signature"
+)
+
+type YooKassaConfig struct {
+	ShopID    string
+	SecretKey string
+	// BaseURL переопределяется в тестах адресом локальной заглушки
+	BaseURL string
+	// WebhookSecret — общий секрет для HMAC-подписи уведомлений (проксируются через наш шлюз)
+	WebhookSecret string
+}
+
+// YooKassa — адаптер API в стиле ЮKassa: redirect-подтверждение и уведомления payment.*/refund.*
+type YooKassa struct {
+	http *http.Client
+	cfg  YooKassaConfig
+}
+
+func NewYooKassa(cfg YooKassaConfig) *YooKassa {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = YooKassaDefaultURL
+	}
+	return &YooKassa{
+		http: &http.Client{Timeout: 20 * time.Second},
+		cfg:  cfg,
+	}
+}
+
+func (y *YooKassa) Name() string { return "yookassa" }
+
+type ykAmount struct {
+	Value    string `json:"value"`
+	Currency string `json:"currency"`
+}
+
+type ykPayment struct {
+	ID           string   `json:"id"`
+	Status       string   `json:"status"`
+	Amount       ykAmount `json:"amount"`
+	PaymentID    string   `json:"payment_id,omitempty"`
+	Confirmation struct {
+		Type            string `json:"type"`
+		ConfirmationURL string `json:"confirmation_url"`
+	} `json:"confirmation"`
+}
+
+type ykError struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
+func (y *YooKassa) Create(ctx context.Context, req CreateRequest) (*Payment, error) {
+	body := map[string]any{
+		"amount":  ykAmount{Value: formatAmount(req.Amount), Currency: currencyOrDefault(req.Currency)},
+		"capture": true,
+		"confirmation": map[string]string{
+			"type":       "redirect",
+			"return_url": req.ReturnURL,
+		},
+		"description": req.Description,
+		"metadata":    map[string]string{"order_id": strconv.Itoa(req.OrderID)},
+	}
+
+	var resp ykPayment
+	if err := y.do(ctx, "/payments", req.IdempotencyKey, body, &resp); err != nil {
+		return nil, err
+	}
+
+	return &Payment{
+		ExternalID:      resp.ID,
+		Status:          mapYooKassaStatus(resp.Status),
+		ConfirmationURL: resp.Confirmation.ConfirmationURL,
+	}, nil
+}
+
+func (y *YooKassa) Refund(ctx context.Context, req RefundRequest) (*Refund, error) {
+	body := map[string]any{
+		"payment_id": req.ExternalID,
+		"amount":     ykAmount{Value: formatAmount(req.Amount), Currency: currencyOrDefault(req.Currency)},
+	}
+
+	var resp ykPayment
+	if err := y.do(ctx, "/refunds", req.IdempotencyKey, body, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Status == "canceled" {
+		return nil, fmt.Errorf("yookassa refund %s canceled: %w", resp.ID, ErrRefundRejected)
+	}
+
+	return &Refund{ExternalID: resp.ID, Status: StatusSucceeded}, nil
+}
+
+func (y *YooKassa) ParseWebhook(header http.Header, body []byte) (*Event, error) {
+	if err := VerifySignature(y.cfg.WebhookSecret, header.Get(YooKassaSignatureHeader), body); err != nil {
+		return nil, err
+	}
+
+	var n struct {
+		Type   string    `json:"type"`
+		Event  string    `json:"event"`
+		Object ykPayment `json:"object"`
+	}
+	if err := json.Unmarshal(body, &n); err != nil {
+		return nil, fmt.Errorf("decode yookassa notification: %w", err)
+	}
+
+	ev := &Event{
+		ID:         n.Event + ":" + n.Object.ID,
+		ExternalID: n.Object.ID,
+		Currency:   n.Object.Amount.Currency,
+	}
+	ev.Amount, _ = strconv.ParseFloat(n.Object.Amount.Value, 64)
+
+	switch n.Event {
+	case "payment.succeeded":
+		ev.Status = StatusSucceeded
+	case "payment.canceled":
+		ev.Status = StatusCanceled
+	case "refund.succeeded":
+		// в уведомлении о возврате объект — сам возврат, платёж указан в payment_id
+		ev.ExternalID = n.Object.PaymentID
+		ev.Status = StatusRefunded
+	default:
+		return nil, ErrUnsupportedEvent
+	}
+	if ev.ExternalID == "" {
+		return nil, ErrUnsupportedEvent
+	}
+	return ev, nil
+}
+
+func (y *YooKassa) do(ctx context.Context, path, idempotencyKey string, body any, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, y.cfg.BaseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(y.cfg.ShopID, y.cfg.SecretKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotence-Key", idempotencyKey)
+
+	resp, err := y.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("yookassa request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("yookassa read body: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		var e ykError
+		_ = json.Unmarshal(raw, &e)
+		return fmt.Errorf("yookassa %s: status=%d code=%s: %s", path, resp.StatusCode, e.Code, e.Description)
+	}
+
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("yookassa decode response: %w", err)
+	}
+	return nil
+}
+
+func mapYooKassaStatus(s string) string {
+	switch s {
+	case "succeeded":
+		return StatusSucceeded
+	case "canceled":
+		return StatusCanceled
+	default:
+		// pending и waiting_for_capture для нас одинаковы — деньги ещё не списаны
+		return StatusPending
+	}
+}
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func currencyOrDefault(c string) string {
+	if c == "" {
+		return "RUB"
+	}
+	return c
+}
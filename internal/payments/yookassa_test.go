@@ -0,0 +1,118 @@
+package payments_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Ramcache/travel-backend/internal/payments"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newYooKassaStub(t *testing.T, handler http.HandlerFunc) *payments.YooKassa {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return payments.NewYooKassa(payments.YooKassaConfig{
+		ShopID:        "shop",
+		SecretKey:     "secret",
+		BaseURL:       srv.URL,
+		WebhookSecret: "whsec",
+	})
+}
+
+func TestYooKassa_Create(t *testing.T) {
+	yk := newYooKassaStub(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/payments", r.URL.Path)
+		assert.Equal(t, "order-5-payment-1", r.Header.Get("Idempotence-Key"))
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "shop", user)
+		assert.Equal(t, "secret", pass)
+
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, map[string]any{"value": "15000.50", "currency": "RUB"}, body["amount"])
+		assert.Equal(t, map[string]any{"order_id": "5"}, body["metadata"])
+
+		_, _ = w.Write([]byte(`{"id":"2c1","status":"pending","confirmation":{"type":"redirect","confirmation_url":"https://pay/2c1"}}`))
+	})
+
+	p, err := yk.Create(context.Background(), payments.CreateRequest{
+		OrderID:        5,
+		Amount:         15000.5,
+		ReturnURL:      "https://site/return",
+		IdempotencyKey: "order-5-payment-1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "2c1", p.ExternalID)
+	assert.Equal(t, payments.StatusPending, p.Status)
+	assert.Equal(t, "https://pay/2c1", p.ConfirmationURL)
+}
+
+func TestYooKassa_CreateError(t *testing.T) {
+	yk := newYooKassaStub(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"type":"error","code":"invalid_request","description":"bad amount"}`))
+	})
+
+	_, err := yk.Create(context.Background(), payments.CreateRequest{Amount: 1, IdempotencyKey: "k"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bad amount")
+}
+
+func TestYooKassa_Refund(t *testing.T) {
+	yk := newYooKassaStub(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/refunds", r.URL.Path)
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "2c1", body["payment_id"])
+		_, _ = w.Write([]byte(`{"id":"rf1","status":"succeeded","payment_id":"2c1"}`))
+	})
+
+	rf, err := yk.Refund(context.Background(), payments.RefundRequest{ExternalID: "2c1", Amount: 100, IdempotencyKey: "k"})
+	require.NoError(t, err)
+	assert.Equal(t, "rf1", rf.ExternalID)
+}
+
+func TestYooKassa_ParseWebhook(t *testing.T) {
+	yk := payments.NewYooKassa(payments.YooKassaConfig{WebhookSecret: "whsec"})
+	body := []byte(`{"type":"notification","event":"payment.succeeded","object":{"id":"2c1","status":"succeeded","amount":{"value":"100.00","currency":"RUB"}}}`)
+
+	h := http.Header{}
+	h.Set(payments.YooKassaSignatureHeader, payments.Sign("whsec", body))
+	ev, err := yk.ParseWebhook(h, body)
+	require.NoError(t, err)
+	assert.Equal(t, "payment.succeeded:2c1", ev.ID)
+	assert.Equal(t, "2c1", ev.ExternalID)
+	assert.Equal(t, payments.StatusSucceeded, ev.Status)
+	assert.Equal(t, 100.0, ev.Amount)
+
+	h.Set(payments.YooKassaSignatureHeader, payments.Sign("other", body))
+	_, err = yk.ParseWebhook(h, body)
+	assert.ErrorIs(t, err, payments.ErrInvalidSignature)
+
+	refund := []byte(`{"type":"notification","event":"refund.succeeded","object":{"id":"rf1","payment_id":"2c1","amount":{"value":"50.00","currency":"RUB"}}}`)
+	h.Set(payments.YooKassaSignatureHeader, payments.Sign("whsec", refund))
+	ev, err = yk.ParseWebhook(h, refund)
+	require.NoError(t, err)
+	assert.Equal(t, "2c1", ev.ExternalID)
+	assert.Equal(t, payments.StatusRefunded, ev.Status)
+
+	other := []byte(`{"event":"payout.succeeded","object":{"id":"x"}}`)
+	h.Set(payments.YooKassaSignatureHeader, payments.Sign("whsec", other))
+	_, err = yk.ParseWebhook(h, other)
+	assert.ErrorIs(t, err, payments.ErrUnsupportedEvent)
+}
+
+func TestYooKassa_ParseWebhook_NoSecret(t *testing.T) {
+	yk := payments.NewYooKassa(payments.YooKassaConfig{})
+	body := []byte(`{"event":"payment.succeeded","object":{"id":"2c1"}}`)
+	h := http.Header{}
+	h.Set(payments.YooKassaSignatureHeader, payments.Sign("", body))
+	_, err := yk.ParseWebhook(h, body)
+	assert.ErrorIs(t, err, payments.ErrInvalidSignature)
+}
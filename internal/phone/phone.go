@@ -0,0 +1,219 @@
+// Package phone разбирает телефонные номера в E.164 с учётом страны по умолчанию.
+// Полные правила есть для RU, KZ и SA; прочие международные номера принимаются
+// в виде «+код страны и номер» длиной 8–15 цифр.
+package phone
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	CountryRU = "RU"
+	CountryKZ = "KZ"
+	CountrySA = "SA"
+)
+
+var ErrInvalid = errors.New("invalid phone number")
+
+// Number — разобранный номер: E164 хранится и сравнивается, Display показывается людям
+type Number struct {
+	E164    string `json:"e164"`
+	Display string `json:"display"`
+	Country string `json:"country,omitempty"`
+}
+
+var defaultCountry atomic.Value
+
+func init() {
+	defaultCountry.Store(CountryRU)
+}
+
+// SetDefaultCountry задаёт страну для номеров без кода страны
+func SetDefaultCountry(country string) error {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	switch country {
+	case CountryRU, CountryKZ, CountrySA:
+		defaultCountry.Store(country)
+		return nil
+	default:
+		return fmt.Errorf("unsupported default phone country %q", country)
+	}
+}
+
+func DefaultCountry() string {
+	return defaultCountry.Load().(string)
+}
+
+// Parse разбирает номер; номер без «+» трактуется как национальный для country
+func Parse(raw, country string) (Number, error) {
+	digits, international := extractDigits(raw)
+	if digits == "" {
+		return Number{}, ErrInvalid
+	}
+
+	if international {
+		return parseInternational(digits)
+	}
+	if n, ok := parseNational(digits, country); ok {
+		return n, nil
+	}
+	// «79281234567» или «966501234567» без плюса; произвольные коды стран
+	// без «+» не угадываем — 8 (128)... не должен превратиться в японский номер
+	if strings.HasPrefix(digits, "7") || strings.HasPrefix(digits, "966") {
+		return parseInternational(digits)
+	}
+	return Number{}, ErrInvalid
+}
+
+// Normalize — E.164 для страны по умолчанию; пустая строка, если номер не разобрать
+func Normalize(raw string) string {
+	n, err := Parse(raw, DefaultCountry())
+	if err != nil {
+		return ""
+	}
+	return n.E164
+}
+
+// SearchDigits готовит фрагмент номера для поиска по E.164 (без «+»):
+// ведущая национальная 8 (RU, KZ) или 0 (SA) заменяется кодом страны
+func SearchDigits(q string) string {
+	digits, international := extractDigits(q)
+	if digits == "" || international {
+		return digits
+	}
+	switch country := DefaultCountry(); {
+	case (country == CountryRU || country == CountryKZ) && digits[0] == '8' && len(digits) > 1:
+		return "7" + digits[1:]
+	case country == CountrySA && digits[0] == '0' && len(digits) > 1:
+		return "966" + digits[1:]
+	}
+	return digits
+}
+
+// Mask скрывает номер для публичных страниц: видны код страны и две последние
+// цифры, «+7 (***) ***-**-67». Неразборчивый номер скрывается целиком
+func Mask(raw string) string {
+	n, err := Parse(raw, DefaultCountry())
+	if err != nil {
+		return "***"
+	}
+
+	code := "+"
+	switch n.Country {
+	case CountryRU, CountryKZ:
+		code = "+7"
+	case CountrySA:
+		code = "+966"
+	}
+
+	total := len(n.E164) - len(code)
+	var b strings.Builder
+	b.WriteString(code)
+	seen := 0
+	for _, r := range n.Display[len(code):] {
+		if r >= '0' && r <= '9' {
+			seen++
+			if seen <= total-2 {
+				r = '*'
+			}
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func extractDigits(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	international := strings.HasPrefix(raw, "+")
+
+	var b strings.Builder
+	for _, r := range raw {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	digits := b.String()
+	if !international && strings.HasPrefix(digits, "00") {
+		return digits[2:], true
+	}
+	return digits, international
+}
+
+func parseNational(digits, country string) (Number, bool) {
+	switch country {
+	case CountryRU, CountryKZ:
+		nat := digits
+		if len(digits) == 11 && (digits[0] == '8' || digits[0] == '7') {
+			nat = digits[1:]
+		}
+		if len(nat) != 10 {
+			return Number{}, false
+		}
+		return parseSeven(nat)
+	case CountrySA:
+		nat := digits
+		if len(digits) == 10 && digits[0] == '0' {
+			nat = digits[1:]
+		}
+		return parseSaudi(nat)
+	}
+	return Number{}, false
+}
+
+func parseInternational(digits string) (Number, error) {
+	switch {
+	case strings.HasPrefix(digits, "7"):
+		if n, ok := parseSeven(digits[1:]); ok {
+			return n, nil
+		}
+		return Number{}, ErrInvalid
+	case strings.HasPrefix(digits, "966"):
+		if n, ok := parseSaudi(digits[3:]); ok {
+			return n, nil
+		}
+		return Number{}, ErrInvalid
+	}
+
+	if len(digits) < 8 || len(digits) > 15 || digits[0] == '0' {
+		return Number{}, ErrInvalid
+	}
+	return Number{E164: "+" + digits, Display: "+" + digits}, nil
+}
+
+// parseSeven — зона +7: Казахстан на 6 и 7, Россия на 3, 4, 8, 9
+func parseSeven(nat string) (Number, bool) {
+	if len(nat) != 10 {
+		return Number{}, false
+	}
+
+	var country string
+	switch nat[0] {
+	case '3', '4', '8', '9':
+		country = CountryRU
+	case '6', '7':
+		country = CountryKZ
+	default:
+		return Number{}, false
+	}
+
+	return Number{
+		E164:    "+7" + nat,
+		Display: fmt.Sprintf("+7 (%s) %s-%s-%s", nat[:3], nat[3:6], nat[6:8], nat[8:]),
+		Country: country,
+	}, true
+}
+
+// parseSaudi — 9 цифр после кода: мобильные на 5, городские на 1
+func parseSaudi(nat string) (Number, bool) {
+	if len(nat) != 9 || (nat[0] != '5' && nat[0] != '1') {
+		return Number{}, false
+	}
+	return Number{
+		E164:    "+966" + nat,
+		Display: fmt.Sprintf("+966 %s %s %s", nat[:2], nat[2:5], nat[5:]),
+		Country: CountrySA,
+	}, true
+}
@@ -0,0 +1,63 @@
+package phone_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Ramcache/travel-backend/internal/phone"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		raw, country, e164, display, detected string
+	}{
+		{"8 (928) 123-45-67", phone.CountryRU, "+79281234567", "+7 (928) 123-45-67", phone.CountryRU},
+		{"+7928 1234567", phone.CountryRU, "+79281234567", "+7 (928) 123-45-67", phone.CountryRU},
+		{"9281234567", phone.CountryRU, "+79281234567", "+7 (928) 123-45-67", phone.CountryRU},
+		{"87011234567", phone.CountryRU, "+77011234567", "+7 (701) 123-45-67", phone.CountryKZ},
+		{"8 701 123 45 67", phone.CountryKZ, "+77011234567", "+7 (701) 123-45-67", phone.CountryKZ},
+		{"050 123 4567", phone.CountrySA, "+966501234567", "+966 50 123 4567", phone.CountrySA},
+		{"501234567", phone.CountrySA, "+966501234567", "+966 50 123 4567", phone.CountrySA},
+		{"00966 50 123 4567", phone.CountryRU, "+966501234567", "+966 50 123 4567", phone.CountrySA},
+		{"79281234567", phone.CountrySA, "+79281234567", "+7 (928) 123-45-67", phone.CountryRU},
+		{"+49 30 1234567", phone.CountryRU, "+49301234567", "+49301234567", ""},
+	}
+	for _, c := range cases {
+		n, err := phone.Parse(c.raw, c.country)
+		require.NoError(t, err, c.raw)
+		assert.Equal(t, c.e164, n.E164, c.raw)
+		assert.Equal(t, c.display, n.Display, c.raw)
+		assert.Equal(t, c.detected, n.Country, c.raw)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, raw := range []string{"", "abc", "12345", "8 (128) 123-45-67", "+7 999 12", "0501234567", "+966 60 123 4567"} {
+		_, err := phone.Parse(raw, phone.CountryRU)
+		assert.ErrorIs(t, err, phone.ErrInvalid, raw)
+	}
+}
+
+func TestSearchDigits(t *testing.T) {
+	require.NoError(t, phone.SetDefaultCountry("ru"))
+	assert.Equal(t, "7928", phone.SearchDigits("8 (928"))
+	assert.Equal(t, "92812", phone.SearchDigits("928-12"))
+	assert.Equal(t, "966", phone.SearchDigits("+966"))
+	assert.Equal(t, "", phone.SearchDigits("Иван"))
+
+	require.NoError(t, phone.SetDefaultCountry(phone.CountrySA))
+	defer phone.SetDefaultCountry(phone.CountryRU)
+	assert.Equal(t, "96650", phone.SearchDigits("050"))
+	assert.Equal(t, "+966501234567", phone.Normalize("0501234567"))
+
+	assert.Error(t, phone.SetDefaultCountry("US"))
+}
+
+func TestMask(t *testing.T) {
+	assert.Equal(t, "+7 (***) ***-**-67", phone.Mask("+79281234567"))
+	assert.Equal(t, "+966 ** *** **67", phone.Mask("+966501234567"))
+	assert.Equal(t, "+**********89", phone.Mask("+441234567889"))
+	assert.Equal(t, "***", phone.Mask("123"))
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+)
+
+type AuditRepo struct {
+	db DB
+}
+
+func NewAuditRepo(db DB) *AuditRepo {
+	return &AuditRepo{db: db}
+}
+
+const auditFields = `
+	id, actor_id, action, entity, entity_id, details, ip, created_at
+`
+
+func (r *AuditRepo) Create(ctx context.Context, e *models.AuditEntry) error {
+	query := `INSERT INTO audit_log (actor_id, action, entity, entity_id, details, ip)
+	          VALUES ($1, $2, $3, $4, $5, $6)
+	          RETURNING id, created_at`
+	return r.db.QueryRow(ctx, query,
+		e.ActorID, e.Action, e.Entity, e.EntityID, e.Details, e.IP,
+	).Scan(&e.ID, &e.CreatedAt)
+}
+
+// List — журнал аудита с фильтром по сущности (entity/entity_id необязательны)
+func (r *AuditRepo) List(ctx context.Context, entity string, entityID, limit, offset int) ([]models.AuditEntry, error) {
+	filters := "1=1"
+	args := []any{}
+	if entity != "" {
+		args = append(args, entity)
+		filters += fmt.Sprintf(" AND entity = $%d", len(args))
+	}
+	if entityID > 0 {
+		args = append(args, entityID)
+		filters += fmt.Sprintf(" AND entity_id = $%d", len(args))
+	}
+	args = append(args, limit, offset)
+
+	query := `SELECT ` + auditFields + `
+	          FROM audit_log
+	          WHERE ` + filters + `
+	          ORDER BY created_at DESC
+	          LIMIT $` + fmt.Sprint(len(args)-1) + ` OFFSET $` + fmt.Sprint(len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.AuditEntry
+	for rows.Next() {
+		var e models.AuditEntry
+		if err := rows.Scan(&e.ID, &e.ActorID, &e.Action, &e.Entity, &e.EntityID, &e.Details, &e.IP, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
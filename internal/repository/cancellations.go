@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+type CancellationRepo struct {
+	db DB
+}
+
+func NewCancellationRepo(db DB) *CancellationRepo {
+	return &CancellationRepo{db: db}
+}
+
+// Tx выполняет fn в транзакции; репозитории внутри получают tx через WithTx
+func (r *CancellationRepo) Tx(ctx context.Context, fn func(tx DB) error) error {
+	return InTx(ctx, r.db, fn)
+}
+
+// WithTx — тот же репозиторий поверх транзакции
+func (r *CancellationRepo) WithTx(tx DB) *CancellationRepo {
+	return &CancellationRepo{db: tx}
+}
+
+const cancellationFields = `
+	c.id, c.order_id, c.source, c.reason, c.previous_status, c.days_before,
+	c.paid_amount::float8, c.penalty_percent::float8, c.penalty_amount::float8,
+	c.refund_amount::float8, c.refunded_amount::float8, c.status,
+	c.decided_by, c.decided_at, c.comment, c.created_at
+`
+
+func scanCancellation(row pgx.Row, extra ...any) (*models.OrderCancellation, error) {
+	var c models.OrderCancellation
+	dest := append([]any{
+		&c.ID, &c.OrderID, &c.Source, &c.Reason, &c.PreviousStatus, &c.DaysBefore,
+		&c.PaidAmount, &c.PenaltyPercent, &c.PenaltyAmount,
+		&c.RefundAmount, &c.RefundedAmount, &c.Status,
+		&c.DecidedBy, &c.DecidedAt, &c.Comment, &c.CreatedAt,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// ---- правила тура ----
+
+func (r *CancellationRepo) ListRules(ctx context.Context, tripID int) ([]models.TripCancellationRule, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, trip_id, days_before, penalty_percent::float8
+		FROM trip_cancellation_rules
+		WHERE trip_id=$1
+		ORDER BY days_before DESC`, tripID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.TripCancellationRule
+	for rows.Next() {
+		var rule models.TripCancellationRule
+		if err := rows.Scan(&rule.ID, &rule.TripID, &rule.DaysBefore, &rule.PenaltyPercent); err != nil {
+			return nil, err
+		}
+		list = append(list, rule)
+	}
+	return list, rows.Err()
+}
+
+// ReplaceRules заменяет штрафы тура целиком
+func (r *CancellationRepo) ReplaceRules(ctx context.Context, tripID int, rules []models.TripCancellationRule) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM trip_cancellation_rules WHERE trip_id=$1`, tripID); err != nil {
+		return err
+	}
+	for i := range rules {
+		rule := &rules[i]
+		rule.TripID = tripID
+		err := r.db.QueryRow(ctx, `
+			INSERT INTO trip_cancellation_rules (trip_id, days_before, penalty_percent)
+			VALUES ($1, $2, $3)
+			RETURNING id`,
+			tripID, rule.DaysBefore, rule.PenaltyPercent,
+		).Scan(&rule.ID)
+		if err != nil {
+			return fmt.Errorf("insert cancellation rule %d: %w", rule.DaysBefore, err)
+		}
+	}
+	return nil
+}
+
+// ---- заявки на отмену ----
+
+func (r *CancellationRepo) Create(ctx context.Context, c *models.OrderCancellation) error {
+	return r.db.QueryRow(ctx, `
+		INSERT INTO order_cancellations (order_id, source, reason, previous_status, days_before,
+		                                 paid_amount, penalty_percent, penalty_amount, refund_amount)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, status, created_at`,
+		c.OrderID, c.Source, c.Reason, c.PreviousStatus, c.DaysBefore,
+		c.PaidAmount, c.PenaltyPercent, c.PenaltyAmount, c.RefundAmount,
+	).Scan(&c.ID, &c.Status, &c.CreatedAt)
+}
+
+// GetLatest — последняя заявка по заказу
+func (r *CancellationRepo) GetLatest(ctx context.Context, orderID int) (*models.OrderCancellation, error) {
+	c, err := scanCancellation(r.db.QueryRow(ctx, `
+		SELECT `+cancellationFields+`
+		FROM order_cancellations c
+		WHERE c.order_id=$1
+		ORDER BY c.created_at DESC, c.id DESC
+		LIMIT 1`, orderID))
+	if err != nil {
+		return nil, mapNotFound(err)
+	}
+	return c, nil
+}
+
+// Claim забирает заявку в processing перед решением; false — её уже обрабатывает другой запрос.
+// Заявку, зависшую в processing дольше 15 минут (упал процесс), можно забрать заново.
+func (r *CancellationRepo) Claim(ctx context.Context, id int) (bool, error) {
+	cmd, err := r.db.Exec(ctx, `
+		UPDATE order_cancellations
+		SET status='processing', claimed_at=now()
+		WHERE id=$1
+		  AND (status='pending' OR (status='processing' AND claimed_at < now() - interval '15 minutes'))`, id)
+	if err != nil {
+		return false, err
+	}
+	return cmd.RowsAffected() > 0, nil
+}
+
+// Release возвращает забранную заявку в pending, если решение не удалось довести до конца
+func (r *CancellationRepo) Release(ctx context.Context, id int) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE order_cancellations
+		SET status='pending', claimed_at=NULL
+		WHERE id=$1 AND status='processing'`, id)
+	return err
+}
+
+// Decide фиксирует решение по заявке, забранной через Claim
+func (r *CancellationRepo) Decide(ctx context.Context, c *models.OrderCancellation) error {
+	err := r.db.QueryRow(ctx, `
+		UPDATE order_cancellations
+		SET status=$2, refund_amount=$3, refunded_amount=$4, decided_by=$5, comment=$6, decided_at=now()
+		WHERE id=$1 AND status='processing'
+		RETURNING decided_at`,
+		c.ID, c.Status, c.RefundAmount, c.RefundedAmount, c.DecidedBy, c.Comment,
+	).Scan(&c.DecidedAt)
+	return mapNotFound(err)
+}
+
+// Count — количество заявок; status == "" — все заявки
+func (r *CancellationRepo) Count(ctx context.Context, status string) (int, error) {
+	var total int
+	err := r.db.QueryRow(ctx, `
+		SELECT count(*) FROM order_cancellations c
+		WHERE ($1 = '' OR c.status = $1)`, status).Scan(&total)
+	return total, err
+}
+
+// List — заявки с данными заказа, новые сверху; status == "" — все заявки
+func (r *CancellationRepo) List(ctx context.Context, status string, limit, offset int) ([]models.CancellationListItem, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+cancellationFields+`, o.user_name, o.user_phone, o.trip_id, t.title, o.total_price::float8
+		FROM order_cancellations c
+		JOIN orders o ON o.id = c.order_id
+		LEFT JOIN trips t ON t.id = o.trip_id
+		WHERE ($1 = '' OR c.status = $1)
+		ORDER BY c.created_at DESC, c.id DESC
+		LIMIT $2 OFFSET $3`, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.CancellationListItem
+	for rows.Next() {
+		var item models.CancellationListItem
+		c, err := scanCancellation(rows, &item.UserName, &item.UserPhone, &item.TripID, &item.TripTitle, &item.TotalPrice)
+		if err != nil {
+			return nil, err
+		}
+		item.OrderCancellation = *c
+		list = append(list, item)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/phone"
+)
+
+type CustomerRepo struct {
+	db DB
+}
+
+func NewCustomerRepo(db DB) *CustomerRepo {
+	return &CustomerRepo{db: db}
+}
+
+// linkCustomer находит или создаёт клиента по телефону и возвращает id основной
+// записи (с учётом слияний). nil — номер не разобрать, привязывать не к чему
+func linkCustomer(ctx context.Context, db DB, rawPhone, name string) (*int, error) {
+	normalized := phone.Normalize(rawPhone)
+	if normalized == "" {
+		return nil, nil
+	}
+
+	var id int
+	err := db.QueryRow(ctx, `
+		INSERT INTO customers (phone, name) VALUES ($1, $2)
+		ON CONFLICT (phone) DO UPDATE
+		SET name = CASE WHEN customers.name = '' THEN EXCLUDED.name ELSE customers.name END
+		RETURNING COALESCE(merged_into, id)`,
+		normalized, name,
+	).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("link customer: %w", err)
+	}
+	return &id, nil
+}
+
+// в total_spent идут оплаченные и завершённые заказы
+const customerSummarySelect = `
+	SELECT c.id, c.phone, c.name, c.merged_into, c.created_at,
+	       COALESCE(o.cnt, 0), COALESCE(o.spent, 0), GREATEST(o.last_at, f.last_at)
+	FROM customers c
+	LEFT JOIN LATERAL (
+	    SELECT COUNT(*) AS cnt,
+	           SUM(total_price) FILTER (WHERE status IN ('` + models.OrderStatusPaid + `', '` + models.OrderStatusCompleted + `')) AS spent,
+	           MAX(created_at) AS last_at
+	    FROM orders WHERE customer_id = c.id
+	) o ON true
+	LEFT JOIN LATERAL (
+	    SELECT MAX(created_at) AS last_at FROM feedbacks WHERE customer_id = c.id
+	) f ON true
+`
+
+func scanCustomerSummary(row interface{ Scan(dest ...any) error }) (models.CustomerSummary, error) {
+	var c models.CustomerSummary
+	err := row.Scan(&c.ID, &c.Phone, &c.Name, &c.MergedInto, &c.CreatedAt,
+		&c.OrdersCount, &c.TotalSpent, &c.LastContactAt)
+	return c, err
+}
+
+// buildCustomerFilters — поиск по имени или цифрам телефона, слитые дубликаты скрыты
+func buildCustomerFilters(q string) (string, []any) {
+	filters := "c.merged_into IS NULL"
+	var args []any
+	if q == "" {
+		return filters, args
+	}
+
+	args = append(args, "%"+q+"%")
+	cond := fmt.Sprintf("c.name ILIKE $%d", len(args))
+	if digits := phone.SearchDigits(q); digits != "" {
+		args = append(args, "%"+digits+"%")
+		cond += fmt.Sprintf(" OR c.phone LIKE $%d", len(args))
+	}
+	return filters + " AND (" + cond + ")", args
+}
+
+func (r *CustomerRepo) Count(ctx context.Context, q string) (int, error) {
+	where, args := buildCustomerFilters(q)
+	query := `SELECT COUNT(*) FROM customers c WHERE ` + where
+
+	var total int
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&total); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (r *CustomerRepo) List(ctx context.Context, q string, limit, offset int) ([]models.CustomerSummary, error) {
+	where, args := buildCustomerFilters(q)
+	args = append(args, limit, offset)
+
+	query := customerSummarySelect + `
+	WHERE ` + where + `
+	ORDER BY GREATEST(o.last_at, f.last_at) DESC NULLS LAST, c.id DESC
+	LIMIT $` + fmt.Sprint(len(args)-1) + ` OFFSET $` + fmt.Sprint(len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.CustomerSummary
+	for rows.Next() {
+		c, err := scanCustomerSummary(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
+
+// GetByID возвращает клиента, в том числе слитого (merged_into заполнен)
+func (r *CustomerRepo) GetByID(ctx context.Context, id int) (*models.CustomerSummary, error) {
+	c, err := scanCustomerSummary(r.db.QueryRow(ctx, customerSummarySelect+` WHERE c.id = $1`, id))
+	if err != nil {
+		return nil, mapNotFound(err)
+	}
+	return &c, nil
+}
+
+// Timeline — заказы, заявки, смены статусов и заметки клиента, новые сверху
+func (r *CustomerRepo) Timeline(ctx context.Context, id int) ([]models.CustomerTimelineItem, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT 'order', o.created_at, o.id, NULL::int, o.status, COALESCE(t.title, o.name), o.total_price
+		FROM orders o
+		LEFT JOIN trips t ON t.id = o.trip_id
+		WHERE o.customer_id = $1
+		UNION ALL
+		SELECT 'feedback', f.created_at, NULL, f.id, NULL, f.user_name, NULL
+		FROM feedbacks f
+		WHERE f.customer_id = $1
+		UNION ALL
+		SELECT 'status', h.created_at, h.order_id, NULL, h.to_status, h.comment, NULL
+		FROM order_status_history h
+		JOIN orders o ON o.id = h.order_id
+		WHERE o.customer_id = $1 AND h.event = 'status' AND h.from_status IS NOT NULL
+		UNION ALL
+		SELECT 'note', n.created_at, n.order_id, NULL, NULL, n.body, NULL
+		FROM order_notes n
+		JOIN orders o ON o.id = n.order_id
+		WHERE o.customer_id = $1
+		ORDER BY 2 DESC`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.CustomerTimelineItem
+	for rows.Next() {
+		var it models.CustomerTimelineItem
+		if err := rows.Scan(&it.Type, &it.At, &it.OrderID, &it.FeedbackID, &it.Status, &it.Text, &it.Amount); err != nil {
+			return nil, err
+		}
+		list = append(list, it)
+	}
+	return list, rows.Err()
+}
+
+// Merge переносит заказы и заявки дубликата в основную запись. Все шаги — одна транзакция:
+// слияние не остаётся сделанным наполовину
+func (r *CustomerRepo) Merge(ctx context.Context, targetID, duplicateID int) error {
+	steps := []string{
+		`UPDATE orders SET customer_id = $1 WHERE customer_id = $2`,
+		`UPDATE feedbacks SET customer_id = $1 WHERE customer_id = $2`,
+		// ранее слитые в дубликат теперь указывают на основную запись
+		`UPDATE customers SET merged_into = $1 WHERE merged_into = $2 OR id = $2`,
+		`UPDATE customers SET name = (SELECT name FROM customers WHERE id = $2)
+		 WHERE id = $1 AND name = ''`,
+	}
+	return InTx(ctx, r.db, func(tx DB) error {
+		for _, q := range steps {
+			if _, err := tx.Exec(ctx, q, targetID, duplicateID); err != nil {
+				return fmt.Errorf("merge customers: %w", err)
+			}
+		}
+		return nil
+	})
+}
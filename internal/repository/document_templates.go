@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+)
+
+type DocumentTemplateRepo struct {
+	db DB
+}
+
+func NewDocumentTemplateRepo(db DB) *DocumentTemplateRepo {
+	return &DocumentTemplateRepo{db: db}
+}
+
+func (r *DocumentTemplateRepo) Get(ctx context.Context, kind string) (*models.DocumentTemplate, error) {
+	var t models.DocumentTemplate
+	err := r.db.QueryRow(ctx, `SELECT kind, body, updated_by, updated_at FROM document_templates WHERE kind=$1`, kind).
+		Scan(&t.Kind, &t.Body, &t.UpdatedBy, &t.UpdatedAt)
+	if err != nil {
+		return nil, mapNotFound(err)
+	}
+	return &t, nil
+}
+
+func (r *DocumentTemplateRepo) Upsert(ctx context.Context, t *models.DocumentTemplate) error {
+	return r.db.QueryRow(ctx, `
+		INSERT INTO document_templates (kind, body, updated_by, updated_at)
+		VALUES ($1, $2, $3, now())
+		ON CONFLICT (kind) DO UPDATE
+		SET body = EXCLUDED.body, updated_by = EXCLUDED.updated_by, updated_at = now()
+		RETURNING updated_at`,
+		t.Kind, t.Body, t.UpdatedBy,
+	).Scan(&t.UpdatedAt)
+}
+
+// Delete — сброс к шаблону по умолчанию
+func (r *DocumentTemplateRepo) Delete(ctx context.Context, kind string) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM document_templates WHERE kind=$1`, kind)
+	return err
+}
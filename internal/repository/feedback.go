@@ -6,57 +6,83 @@ import (
 
 	"github.com/Ramcache/travel-backend/internal/models"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type FeedbackRepo struct {
-	db *pgxpool.Pool
+	db DB
 }
 
-func NewFeedbackRepo(db *pgxpool.Pool) *FeedbackRepo {
+func NewFeedbackRepo(db DB) *FeedbackRepo {
 	return &FeedbackRepo{db: db}
 }
 
+// Tx выполняет fn в транзакции; репозитории внутри получают tx через WithTx
+func (r *FeedbackRepo) Tx(ctx context.Context, fn func(tx DB) error) error {
+	return InTx(ctx, r.db, fn)
+}
+
+// WithTx — тот же репозиторий поверх транзакции
+func (r *FeedbackRepo) WithTx(tx DB) *FeedbackRepo {
+	return &FeedbackRepo{db: tx}
+}
+
 // общий SELECT список
 const feedbackFields = `
-	id, user_name, user_phone, is_read, created_at
+	id, user_name, user_phone, is_read, created_at, customer_id, user_phone_display
 `
 
 // приватный сканер
 func scanFeedback(row pgx.Row) (models.Feedback, error) {
 	var f models.Feedback
-	err := row.Scan(&f.ID, &f.UserName, &f.UserPhone, &f.IsRead, &f.CreatedAt)
+	err := row.Scan(&f.ID, &f.UserName, &f.UserPhone, &f.IsRead, &f.CreatedAt, &f.CustomerID, &f.UserPhoneDisplay)
 	return f, err
 }
 
 // buildFeedbackFilters собирает WHERE + args
-func buildFeedbackFilters(phone string, isRead *bool) (string, []any) {
+func buildFeedbackFilters(f models.FeedbackFilter) (string, []any) {
 	filters := "1=1"
 	args := []any{}
 	i := 1
 
-	if phone != "" {
+	if f.Phone != "" {
 		filters += fmt.Sprintf(" AND user_phone ILIKE $%d", i)
-		args = append(args, "%"+phone+"%")
+		args = append(args, phoneSearchPattern(f.Phone))
 		i++
 	}
-	if isRead != nil {
+	if f.IsRead != nil {
 		filters += fmt.Sprintf(" AND is_read = $%d", i)
-		args = append(args, *isRead)
+		args = append(args, *f.IsRead)
+		i++
+	}
+	if f.From != nil {
+		filters += fmt.Sprintf(" AND created_at >= $%d", i)
+		args = append(args, *f.From)
+		i++
+	}
+	if f.To != nil {
+		filters += fmt.Sprintf(" AND created_at < $%d", i)
+		args = append(args, *f.To)
 	}
 	return filters, args
 }
 
+// Create сохраняет заявку и привязывает её к клиенту по телефону
 func (r *FeedbackRepo) Create(ctx context.Context, f *models.Feedback) error {
-	query := `INSERT INTO feedbacks (user_name, user_phone)
-              VALUES ($1, $2)
+	customerID, err := linkCustomer(ctx, r.db, f.UserPhone, f.UserName)
+	if err != nil {
+		return err
+	}
+	f.CustomerID = customerID
+
+	query := `INSERT INTO feedbacks (user_name, user_phone, customer_id, user_phone_display)
+              VALUES ($1, $2, $3, $4)
               RETURNING id, created_at`
-	return r.db.QueryRow(ctx, query, f.UserName, f.UserPhone).
+	return r.db.QueryRow(ctx, query, f.UserName, f.UserPhone, f.CustomerID, f.UserPhoneDisplay).
 		Scan(&f.ID, &f.CreatedAt)
 }
 
 func (r *FeedbackRepo) Count(ctx context.Context, phone string, isRead *bool) (int, error) {
-	where, args := buildFeedbackFilters(phone, isRead)
+	where, args := buildFeedbackFilters(models.FeedbackFilter{Phone: phone, IsRead: isRead})
 	query := `SELECT COUNT(*) FROM feedbacks WHERE ` + where
 
 	var total int
@@ -67,7 +93,7 @@ func (r *FeedbackRepo) Count(ctx context.Context, phone string, isRead *bool) (i
 }
 
 func (r *FeedbackRepo) List(ctx context.Context, limit, offset int, phone string, isRead *bool) ([]models.Feedback, error) {
-	where, args := buildFeedbackFilters(phone, isRead)
+	where, args := buildFeedbackFilters(models.FeedbackFilter{Phone: phone, IsRead: isRead})
 	args = append(args, limit, offset)
 
 	query := `SELECT ` + feedbackFields + `
@@ -93,6 +119,32 @@ func (r *FeedbackRepo) List(ctx context.Context, limit, offset int, phone string
 	return list, rows.Err()
 }
 
+// Export построчно отдаёт заявки в fn, не собирая выборку в памяти
+func (r *FeedbackRepo) Export(ctx context.Context, f models.FeedbackFilter, fn func(models.Feedback) error) error {
+	where, args := buildFeedbackFilters(f)
+	query := `SELECT ` + feedbackFields + `
+              FROM feedbacks
+              WHERE ` + where + `
+              ORDER BY created_at DESC, id DESC`
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		fb, err := scanFeedback(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(fb); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
 func (r *FeedbackRepo) MarkAsRead(ctx context.Context, id int) error {
 	tag, err := r.db.Exec(ctx, `UPDATE feedbacks SET is_read = true WHERE id=$1`, id)
 	if err != nil {
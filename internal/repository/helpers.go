@@ -4,6 +4,8 @@ import (
 	"errors"
 
 	"github.com/jackc/pgx/v5"
+
+	"github.com/Ramcache/travel-backend/internal/phone"
 )
 
 var ErrNotFound = errors.New("record not found")
@@ -15,3 +17,29 @@ func mapNotFound(err error) error {
 	}
 	return err
 }
+
+type scanner interface{ Scan(dest ...any) error }
+
+type extraScanner struct {
+	row   scanner
+	extra []any
+}
+
+func (s extraScanner) Scan(dest ...any) error {
+	return s.row.Scan(append(dest, s.extra...)...)
+}
+
+// withExtra позволяет переиспользовать scanX для запросов с дополнительными
+// колонками в конце SELECT (например, из JOIN)
+func withExtra(row scanner, extra ...any) scanner {
+	return extraScanner{row: row, extra: extra}
+}
+
+// phoneSearchPattern — номера хранятся в E.164, поэтому ищем по цифрам:
+// «8 (928) 12» найдёт +792812... Запрос без цифр ищем как есть
+func phoneSearchPattern(q string) string {
+	if digits := phone.SearchDigits(q); digits != "" {
+		return "%" + digits + "%"
+	}
+	return "%" + q + "%"
+}
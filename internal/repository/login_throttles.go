@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+)
+
+// LoginThrottleRepo — счётчики неудачных входов по email и по IP
+type LoginThrottleRepo struct {
+	db DB
+}
+
+func NewLoginThrottleRepo(db DB) *LoginThrottleRepo {
+	return &LoginThrottleRepo{db: db}
+}
+
+// Tx выполняет fn в транзакции; репозитории внутри получают tx через WithTx
+func (r *LoginThrottleRepo) Tx(ctx context.Context, fn func(tx DB) error) error {
+	return InTx(ctx, r.db, fn)
+}
+
+// WithTx — тот же репозиторий поверх транзакции
+func (r *LoginThrottleRepo) WithTx(tx DB) *LoginThrottleRepo {
+	return &LoginThrottleRepo{db: tx}
+}
+
+// RetryAfter — сколько ждать до следующей попытки входа с этим email или IP; 0 — можно сейчас.
+// Ждать нужно до конца блокировки, а после freeAttempts неудач подряд — ещё и паузу
+// 1, 2, 4… секунды с последней неудачи, не больше maxDelay. Неудачи старше window не считаются
+func (r *LoginThrottleRepo) RetryAfter(ctx context.Context, email, ip string, freeAttempts int, maxDelay, window time.Duration) (time.Duration, error) {
+	var secs *float64
+	err := r.db.QueryRow(ctx, `
+		SELECT ceil(extract(epoch FROM max(until) - now()))
+		FROM (
+			SELECT GREATEST(
+				locked_until,
+				CASE WHEN failures >= $3
+					THEN last_failure_at + make_interval(secs => LEAST(power(2, failures - $3), $4))
+				END
+			) AS until
+			FROM login_throttles
+			WHERE ((kind = 'email' AND key = $1) OR (kind = 'ip' AND key = $2))
+			  AND last_failure_at > now() - make_interval(secs => $5)
+		) t
+		WHERE until > now()`,
+		email, ip, freeAttempts, maxDelay.Seconds(), window.Seconds(),
+	).Scan(&secs)
+	if err != nil || secs == nil {
+		return 0, err
+	}
+	return time.Duration(*secs) * time.Second, nil
+}
+
+// Fail засчитывает неудачу и возвращает, сколько их подряд. Если с прошлой неудачи
+// прошло больше window, счёт начинается заново
+func (r *LoginThrottleRepo) Fail(ctx context.Context, kind, key string, window time.Duration) (int, error) {
+	var failures int
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO login_throttles AS t (kind, key, failures, last_failure_at)
+		VALUES ($1, $2, 1, now())
+		ON CONFLICT (kind, key) DO UPDATE SET
+			failures = CASE WHEN t.last_failure_at > now() - make_interval(secs => $3) THEN t.failures + 1 ELSE 1 END,
+			last_failure_at = now(),
+			locked_until = NULL
+		RETURNING failures`,
+		kind, key, window.Seconds(),
+	).Scan(&failures)
+	return failures, err
+}
+
+// Lock закрывает вход с этого email или IP на duration
+func (r *LoginThrottleRepo) Lock(ctx context.Context, kind, key string, duration time.Duration) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE login_throttles SET locked_until = now() + make_interval(secs => $3)
+		WHERE kind = $1 AND key = $2`, kind, key, duration.Seconds())
+	return err
+}
+
+// Reset забывает неудачи: после успешного входа или когда блокировку снял администратор.
+// ErrNotFound — неудач не было
+func (r *LoginThrottleRepo) Reset(ctx context.Context, kind, key string) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM login_throttles WHERE kind = $1 AND key = $2`, kind, key)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Locked — действующие блокировки, сначала самые свежие
+func (r *LoginThrottleRepo) Locked(ctx context.Context) ([]models.LoginLock, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT kind, key, failures, last_failure_at, locked_until
+		FROM login_throttles
+		WHERE locked_until > now()
+		ORDER BY locked_until DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.LoginLock
+	for rows.Next() {
+		var l models.LoginLock
+		if err := rows.Scan(&l.Kind, &l.Key, &l.Failures, &l.LastFailureAt, &l.LockedUntil); err != nil {
+			return nil, err
+		}
+		list = append(list, l)
+	}
+	return list, rows.Err()
+}
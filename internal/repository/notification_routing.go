@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// NotificationRoutingRepo — чаты для уведомлений и их правила
+type NotificationRoutingRepo struct {
+	db DB
+}
+
+func NewNotificationRoutingRepo(db DB) *NotificationRoutingRepo {
+	return &NotificationRoutingRepo{db: db}
+}
+
+const notificationDestinationFields = `
+	id, name, chat_id, quiet_from, quiet_to, timezone, is_active, created_at, updated_at
+`
+
+func scanNotificationDestination(row pgx.Row) (*models.NotificationDestination, error) {
+	var d models.NotificationDestination
+	err := row.Scan(
+		&d.ID, &d.Name, &d.ChatID, &d.QuietFrom, &d.QuietTo, &d.Timezone, &d.IsActive, &d.CreatedAt, &d.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// List — все чаты с правилами
+func (r *NotificationRoutingRepo) List(ctx context.Context) ([]models.NotificationDestination, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+notificationDestinationFields+`
+		FROM notification_destinations
+		ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.NotificationDestination
+	var ids []int
+	for rows.Next() {
+		d, err := scanNotificationDestination(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *d)
+		ids = append(ids, d.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rules, err := r.rules(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		list[i].Rules = rules[list[i].ID]
+	}
+	return list, nil
+}
+
+func (r *NotificationRoutingRepo) GetByID(ctx context.Context, id int) (*models.NotificationDestination, error) {
+	d, err := scanNotificationDestination(r.db.QueryRow(ctx,
+		`SELECT `+notificationDestinationFields+` FROM notification_destinations WHERE id = $1`, id))
+	if err != nil {
+		return nil, mapNotFound(err)
+	}
+
+	rules, err := r.rules(ctx, []int{id})
+	if err != nil {
+		return nil, err
+	}
+	d.Rules = rules[id]
+	return d, nil
+}
+
+// rules — правила по списку чатов (destination_id → правила)
+func (r *NotificationRoutingRepo) rules(ctx context.Context, destinationIDs []int) (map[int][]models.NotificationRule, error) {
+	out := make(map[int][]models.NotificationRule, len(destinationIDs))
+	if len(destinationIDs) == 0 {
+		return out, nil
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id, destination_id, event_type, trip_type, departure_city, min_amount, max_amount
+		FROM notification_rules
+		WHERE destination_id = ANY($1)
+		ORDER BY destination_id, id`, destinationIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rule models.NotificationRule
+		var destinationID int
+		if err := rows.Scan(&rule.ID, &destinationID, &rule.EventType, &rule.TripType, &rule.DepartureCity, &rule.MinAmount, &rule.MaxAmount); err != nil {
+			return nil, err
+		}
+		out[destinationID] = append(out[destinationID], rule)
+	}
+	return out, rows.Err()
+}
+
+// Create сохраняет чат вместе с правилами
+func (r *NotificationRoutingRepo) Create(ctx context.Context, d *models.NotificationDestination) error {
+	return InTx(ctx, r.db, func(tx DB) error {
+		err := tx.QueryRow(ctx, `
+			INSERT INTO notification_destinations (name, chat_id, quiet_from, quiet_to, timezone, is_active)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id, created_at, updated_at`,
+			d.Name, d.ChatID, d.QuietFrom, d.QuietTo, d.Timezone, d.IsActive,
+		).Scan(&d.ID, &d.CreatedAt, &d.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		return insertNotificationRules(ctx, tx, d)
+	})
+}
+
+// Update заменяет чат и все его правила
+func (r *NotificationRoutingRepo) Update(ctx context.Context, d *models.NotificationDestination) error {
+	return InTx(ctx, r.db, func(tx DB) error {
+		err := tx.QueryRow(ctx, `
+			UPDATE notification_destinations
+			SET name = $2, chat_id = $3, quiet_from = $4, quiet_to = $5, timezone = $6, is_active = $7, updated_at = now()
+			WHERE id = $1
+			RETURNING created_at, updated_at`,
+			d.ID, d.Name, d.ChatID, d.QuietFrom, d.QuietTo, d.Timezone, d.IsActive,
+		).Scan(&d.CreatedAt, &d.UpdatedAt)
+		if err != nil {
+			return mapNotFound(err)
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM notification_rules WHERE destination_id = $1`, d.ID); err != nil {
+			return err
+		}
+		return insertNotificationRules(ctx, tx, d)
+	})
+}
+
+func insertNotificationRules(ctx context.Context, tx DB, d *models.NotificationDestination) error {
+	for i := range d.Rules {
+		rule := &d.Rules[i]
+		err := tx.QueryRow(ctx, `
+			INSERT INTO notification_rules (destination_id, event_type, trip_type, departure_city, min_amount, max_amount)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id`,
+			d.ID, rule.EventType, rule.TripType, rule.DepartureCity, rule.MinAmount, rule.MaxAmount,
+		).Scan(&rule.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete удаляет чат; правила удаляются каскадом
+func (r *NotificationRoutingRepo) Delete(ctx context.Context, id int) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM notification_destinations WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
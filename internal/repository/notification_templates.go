@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+type NotificationTemplateRepo struct {
+	db DB
+}
+
+func NewNotificationTemplateRepo(db DB) *NotificationTemplateRepo {
+	return &NotificationTemplateRepo{db: db}
+}
+
+const notificationTemplateFields = `
+	id, event_type, audience, channel, locale, subject, body, link_text, link_url,
+	is_active, updated_by, created_at, updated_at
+`
+
+func scanNotificationTemplate(row pgx.Row) (*models.NotificationTemplate, error) {
+	var t models.NotificationTemplate
+	err := row.Scan(
+		&t.ID, &t.EventType, &t.Audience, &t.Channel, &t.Locale, &t.Subject, &t.Body, &t.LinkText, &t.LinkURL,
+		&t.IsActive, &t.UpdatedBy, &t.CreatedAt, &t.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *NotificationTemplateRepo) List(ctx context.Context, f models.NotificationTemplateFilter) ([]models.NotificationTemplate, error) {
+	where := "1=1"
+	args := []any{}
+	if f.EventType != "" {
+		args = append(args, f.EventType)
+		where += fmt.Sprintf(" AND event_type = $%d", len(args))
+	}
+	if f.Channel != "" {
+		args = append(args, f.Channel)
+		where += fmt.Sprintf(" AND channel = $%d", len(args))
+	}
+	if f.Locale != "" {
+		args = append(args, f.Locale)
+		where += fmt.Sprintf(" AND locale = $%d", len(args))
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT `+notificationTemplateFields+`
+		FROM notification_templates
+		WHERE `+where+`
+		ORDER BY event_type, audience, channel, locale`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.NotificationTemplate
+	for rows.Next() {
+		t, err := scanNotificationTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *t)
+	}
+	return list, rows.Err()
+}
+
+func (r *NotificationTemplateRepo) GetByID(ctx context.Context, id int) (*models.NotificationTemplate, error) {
+	t, err := scanNotificationTemplate(r.db.QueryRow(ctx,
+		`SELECT `+notificationTemplateFields+` FROM notification_templates WHERE id = $1`, id))
+	return t, mapNotFound(err)
+}
+
+// GetByKey — шаблон для события, аудитории, канала и языка (в том числе выключенный)
+func (r *NotificationTemplateRepo) GetByKey(ctx context.Context, event, audience, channel, locale string) (*models.NotificationTemplate, error) {
+	t, err := scanNotificationTemplate(r.db.QueryRow(ctx, `
+		SELECT `+notificationTemplateFields+` FROM notification_templates
+		WHERE event_type = $1 AND audience = $2 AND channel = $3 AND locale = $4`,
+		event, audience, channel, locale))
+	return t, mapNotFound(err)
+}
+
+func (r *NotificationTemplateRepo) Create(ctx context.Context, t *models.NotificationTemplate) error {
+	return r.db.QueryRow(ctx, `
+		INSERT INTO notification_templates (event_type, audience, channel, locale, subject, body, link_text, link_url, is_active, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, updated_at`,
+		t.EventType, t.Audience, t.Channel, t.Locale, t.Subject, t.Body, t.LinkText, t.LinkURL, t.IsActive, t.UpdatedBy,
+	).Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt)
+}
+
+func (r *NotificationTemplateRepo) Update(ctx context.Context, t *models.NotificationTemplate) error {
+	err := r.db.QueryRow(ctx, `
+		UPDATE notification_templates
+		SET event_type = $2, audience = $3, channel = $4, locale = $5, subject = $6, body = $7,
+		    link_text = $8, link_url = $9, is_active = $10, updated_by = $11, updated_at = now()
+		WHERE id = $1
+		RETURNING created_at, updated_at`,
+		t.ID, t.EventType, t.Audience, t.Channel, t.Locale, t.Subject, t.Body, t.LinkText, t.LinkURL, t.IsActive, t.UpdatedBy,
+	).Scan(&t.CreatedAt, &t.UpdatedAt)
+	return mapNotFound(err)
+}
+
+func (r *NotificationTemplateRepo) Delete(ctx context.Context, id int) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM notification_templates WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
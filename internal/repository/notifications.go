@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// NotificationRepo — outbox исходящих уведомлений
+type NotificationRepo struct {
+	db DB
+}
+
+func NewNotificationRepo(db DB) *NotificationRepo {
+	return &NotificationRepo{db: db}
+}
+
+// WithTx — тот же репозиторий поверх транзакции
+func (r *NotificationRepo) WithTx(tx DB) *NotificationRepo {
+	return &NotificationRepo{db: tx}
+}
+
+const notificationFields = `
+	id, event_type, channel, audience, recipients, subject, body, link_text, link_url, actions,
+	status, attempts, last_error, next_attempt_at, sent_at, created_at, updated_at
+`
+
+func scanNotification(row pgx.Row) (*models.OutboxNotification, error) {
+	var n models.OutboxNotification
+	err := row.Scan(
+		&n.ID, &n.EventType, &n.Channel, &n.Audience, &n.Recipients, &n.Subject, &n.Body, &n.LinkText, &n.LinkURL, &n.Actions,
+		&n.Status, &n.Attempts, &n.LastError, &n.NextAttemptAt, &n.SentAt, &n.CreatedAt, &n.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+func (r *NotificationRepo) Create(ctx context.Context, n *models.OutboxNotification) error {
+	if n.Recipients == nil {
+		n.Recipients = []string{}
+	}
+	if n.Actions == nil {
+		n.Actions = []models.NotificationAction{}
+	}
+	// нулевое NextAttemptAt — отправить сразу; время пишется в UTC, а не в поясе чата
+	var next *time.Time
+	if !n.NextAttemptAt.IsZero() {
+		at := n.NextAttemptAt.UTC()
+		next = &at
+	}
+	return r.db.QueryRow(ctx, `
+		INSERT INTO notification_outbox (event_type, channel, audience, recipients, subject, body, link_text, link_url, actions, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, COALESCE($10, now()))
+		RETURNING id, status, next_attempt_at, created_at, updated_at`,
+		n.EventType, n.Channel, n.Audience, n.Recipients, n.Subject, n.Body, n.LinkText, n.LinkURL, n.Actions, next,
+	).Scan(&n.ID, &n.Status, &n.NextAttemptAt, &n.CreatedAt, &n.UpdatedAt)
+}
+
+// Claim забирает до limit уведомлений, которым пора уйти. Попытка засчитывается сразу,
+// а следующая назначается через lease: если процесс упадёт посреди отправки,
+// уведомление подберёт следующий проход. Параллельные диспетчеры не берут одни и те же строки.
+func (r *NotificationRepo) Claim(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxNotification, error) {
+	rows, err := r.db.Query(ctx, `
+		UPDATE notification_outbox
+		SET attempts = attempts + 1, next_attempt_at = now() + make_interval(secs => $2), updated_at = now()
+		WHERE id IN (
+			SELECT id FROM notification_outbox
+			WHERE status = 'pending' AND next_attempt_at <= now()
+			ORDER BY next_attempt_at, id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+notificationFields, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.OutboxNotification
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *n)
+	}
+	return list, rows.Err()
+}
+
+func (r *NotificationRepo) MarkSent(ctx context.Context, id int) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE notification_outbox SET status = 'sent', sent_at = now(), last_error = NULL, updated_at = now()
+		WHERE id = $1`, id)
+	return err
+}
+
+// MarkFailed записывает ошибку попытки; next == nil — попытки исчерпаны (dead letter)
+func (r *NotificationRepo) MarkFailed(ctx context.Context, id int, errText string, next *time.Time) error {
+	var err error
+	if next == nil {
+		_, err = r.db.Exec(ctx, `
+			UPDATE notification_outbox SET status = 'dead', last_error = $2, updated_at = now()
+			WHERE id = $1`, id, errText)
+	} else {
+		_, err = r.db.Exec(ctx, `
+			UPDATE notification_outbox SET last_error = $2, next_attempt_at = $3, updated_at = now()
+			WHERE id = $1`, id, errText, next.UTC())
+	}
+	return err
+}
+
+// Redact заменяет текст уведомления на body и стирает тему и ссылку
+func (r *NotificationRepo) Redact(ctx context.Context, id int, body string) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE notification_outbox SET subject = '', body = $2, link_url = NULL, updated_at = now()
+		WHERE id = $1`, id, body)
+	return err
+}
+
+// Resend ставит уведомление в очередь заново с обнулённым счётчиком попыток
+func (r *NotificationRepo) Resend(ctx context.Context, id int) (*models.OutboxNotification, error) {
+	n, err := scanNotification(r.db.QueryRow(ctx, `
+		UPDATE notification_outbox
+		SET status = 'pending', attempts = 0, next_attempt_at = now(), sent_at = NULL, updated_at = now()
+		WHERE id = $1
+		RETURNING `+notificationFields, id))
+	return n, mapNotFound(err)
+}
+
+func (r *NotificationRepo) GetByID(ctx context.Context, id int) (*models.OutboxNotification, error) {
+	n, err := scanNotification(r.db.QueryRow(ctx, `SELECT `+notificationFields+` FROM notification_outbox WHERE id = $1`, id))
+	return n, mapNotFound(err)
+}
+
+func buildNotificationFilters(f models.NotificationFilter) (string, []any) {
+	where := "1=1"
+	args := []any{}
+	if f.Status != "" {
+		args = append(args, f.Status)
+		where += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	if f.EventType != "" {
+		args = append(args, f.EventType)
+		where += fmt.Sprintf(" AND event_type = $%d", len(args))
+	}
+	if f.Channel != "" {
+		args = append(args, f.Channel)
+		where += fmt.Sprintf(" AND channel = $%d", len(args))
+	}
+	return where, args
+}
+
+func (r *NotificationRepo) Count(ctx context.Context, f models.NotificationFilter) (int, error) {
+	where, args := buildNotificationFilters(f)
+	var total int
+	err := r.db.QueryRow(ctx, `SELECT count(*) FROM notification_outbox WHERE `+where, args...).Scan(&total)
+	return total, err
+}
+
+// List — уведомления, новые сверху
+func (r *NotificationRepo) List(ctx context.Context, f models.NotificationFilter, limit, offset int) ([]models.OutboxNotification, error) {
+	where, args := buildNotificationFilters(f)
+	args = append(args, limit, offset)
+	rows, err := r.db.Query(ctx, `
+		SELECT `+notificationFields+`
+		FROM notification_outbox
+		WHERE `+where+`
+		ORDER BY created_at DESC, id DESC
+		LIMIT $`+fmt.Sprint(len(args)-1)+` OFFSET $`+fmt.Sprint(len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.OutboxNotification
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *n)
+	}
+	return list, rows.Err()
+}
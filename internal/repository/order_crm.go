@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+)
+
+// GetAssignment — текущий статус и ответственный менеджер заказа
+func (r *OrderRepo) GetAssignment(ctx context.Context, id int) (string, *int, error) {
+	var status string
+	var assignee *int
+	err := r.db.QueryRow(ctx, `SELECT status, assigned_to FROM orders WHERE id=$1`, id).Scan(&status, &assignee)
+	if err != nil {
+		return "", nil, mapNotFound(err)
+	}
+	return status, assignee, nil
+}
+
+// SetAssignee меняет менеджера, только если он не поменялся с момента чтения
+func (r *OrderRepo) SetAssignee(ctx context.Context, id int, from, to *int) error {
+	cmd, err := r.db.Exec(ctx,
+		`UPDATE orders SET assigned_to=$1 WHERE id=$2 AND assigned_to IS NOT DISTINCT FROM $3`, to, id, from)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// StaffName возвращает имя сотрудника — пользователя, чья роль может обрабатывать заказы;
+// ErrNotFound — нет такого сотрудника
+func (r *OrderRepo) StaffName(ctx context.Context, userID int) (string, error) {
+	var name string
+	err := r.db.QueryRow(ctx, `
+		SELECT u.full_name FROM users u
+		JOIN role_permissions rp ON rp.role_id = u.role_id AND rp.permission = $2
+		WHERE u.id = $1`,
+		userID, models.PermOrdersWrite).Scan(&name)
+	if err != nil {
+		return "", mapNotFound(err)
+	}
+	return name, nil
+}
+
+// SetNextCall ставит дату следующего звонка и сбрасывает отметку об отправленном напоминании
+func (r *OrderRepo) SetNextCall(ctx context.Context, id int, at *time.Time) error {
+	cmd, err := r.db.Exec(ctx,
+		`UPDATE orders SET next_call_at=$1, next_call_reminded_at=NULL WHERE id=$2`, at, id)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *OrderRepo) SetTags(ctx context.Context, id int, tags []string) error {
+	cmd, err := r.db.Exec(ctx, `UPDATE orders SET tags=$1 WHERE id=$2`, tags, id)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListFollowUpsDue — заказы с наступившим звонком, по которым ещё не напоминали
+func (r *OrderRepo) ListFollowUpsDue(ctx context.Context, now time.Time) ([]models.FollowUpReminder, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT o.id, o.user_name, o.user_phone, o.status, o.next_call_at, o.assigned_to, u.full_name
+		FROM orders o
+		LEFT JOIN users u ON u.id = o.assigned_to
+		WHERE o.next_call_at <= $1
+		  AND o.next_call_reminded_at IS NULL
+		  AND o.status <> ALL($2)
+		ORDER BY o.next_call_at, o.id`,
+		now, models.FinalOrderStatuses())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.FollowUpReminder
+	for rows.Next() {
+		var f models.FollowUpReminder
+		if err := rows.Scan(&f.OrderID, &f.UserName, &f.UserPhone, &f.Status, &f.NextCallAt,
+			&f.AssignedTo, &f.AssigneeName); err != nil {
+			return nil, err
+		}
+		list = append(list, f)
+	}
+	return list, rows.Err()
+}
+
+func (r *OrderRepo) MarkFollowUpReminded(ctx context.Context, ids []int, at time.Time) error {
+	_, err := r.db.Exec(ctx, `UPDATE orders SET next_call_reminded_at = $1 WHERE id = ANY($2)`, at, ids)
+	return err
+}
+
+// ======== Заметки ========
+
+func (r *OrderRepo) AddNote(ctx context.Context, n *models.OrderNote) error {
+	return r.db.QueryRow(ctx, `
+		INSERT INTO order_notes (order_id, author_id, body)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at`,
+		n.OrderID, n.AuthorID, n.Body,
+	).Scan(&n.ID, &n.CreatedAt)
+}
+
+func (r *OrderRepo) ListNotes(ctx context.Context, orderID int) ([]models.OrderNote, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT n.id, n.order_id, n.author_id, u.full_name, n.body, n.created_at
+		FROM order_notes n
+		LEFT JOIN users u ON u.id = n.author_id
+		WHERE n.order_id = $1
+		ORDER BY n.created_at DESC, n.id DESC`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.OrderNote
+	for rows.Next() {
+		var n models.OrderNote
+		if err := rows.Scan(&n.ID, &n.OrderID, &n.AuthorID, &n.AuthorName, &n.Body, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, n)
+	}
+	return list, rows.Err()
+}
+
+// DeleteNote удаляет заметку автора; чужую заметку удалить нельзя
+func (r *OrderRepo) DeleteNote(ctx context.Context, orderID, noteID, authorID int) error {
+	cmd, err := r.db.Exec(ctx, `DELETE FROM order_notes WHERE id=$1 AND order_id=$2 AND author_id=$3`,
+		noteID, orderID, authorID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
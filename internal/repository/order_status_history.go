@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+)
+
+func (r *OrderRepo) AddStatusHistory(ctx context.Context, h *models.OrderStatusChange) error {
+	if h.Event == "" {
+		h.Event = models.OrderEventStatus
+	}
+	query := `INSERT INTO order_status_history
+	              (order_id, from_status, to_status, actor_id, actor_name, comment, event, from_assignee, to_assignee)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	          RETURNING id, created_at`
+	return r.db.QueryRow(ctx, query,
+		h.OrderID, h.FromStatus, h.ToStatus, h.ActorID, h.ActorName, h.Comment, h.Event, h.FromAssignee, h.ToAssignee,
+	).Scan(&h.ID, &h.CreatedAt)
+}
+
+// ListStatusHistory — таймлайн заказа; имя администратора берём из users, если он ещё существует
+func (r *OrderRepo) ListStatusHistory(ctx context.Context, orderID int) ([]models.OrderStatusChange, error) {
+	query := `SELECT h.id, h.order_id, h.event, h.from_status, h.to_status, h.actor_id,
+	                 COALESCE(u.full_name, h.actor_name), h.comment, h.created_at,
+	                 h.from_assignee, fa.full_name, h.to_assignee, ta.full_name
+	          FROM order_status_history h
+	          LEFT JOIN users u ON u.id = h.actor_id
+	          LEFT JOIN users fa ON fa.id = h.from_assignee
+	          LEFT JOIN users ta ON ta.id = h.to_assignee
+	          WHERE h.order_id = $1
+	          ORDER BY h.created_at, h.id`
+
+	rows, err := r.db.Query(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.OrderStatusChange
+	for rows.Next() {
+		var h models.OrderStatusChange
+		if err := rows.Scan(&h.ID, &h.OrderID, &h.Event, &h.FromStatus, &h.ToStatus, &h.ActorID,
+			&h.ActorName, &h.Comment, &h.CreatedAt,
+			&h.FromAssignee, &h.FromAssigneeName, &h.ToAssignee, &h.ToAssigneeName); err != nil {
+			return nil, err
+		}
+		list = append(list, h)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+)
+
+const orderTravellerFields = `
+	id, order_id, profile_id, full_name, birth_date, gender, traveller_type, room, price, created_at
+`
+
+func scanOrderTraveller(row interface{ Scan(dest ...any) error }) (models.OrderTraveller, error) {
+	var t models.OrderTraveller
+	err := row.Scan(
+		&t.ID,
+		&t.OrderID,
+		&t.ProfileID,
+		&t.FullName,
+		&t.BirthDate,
+		&t.Gender,
+		&t.TravellerType,
+		&t.Room,
+		&t.Price,
+		&t.CreatedAt,
+	)
+	return t, err
+}
+
+func (r *OrderRepo) createTraveller(ctx context.Context, t *models.OrderTraveller) error {
+	query := `INSERT INTO order_travellers (order_id, profile_id, full_name, birth_date, gender, traveller_type, room, price)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	          RETURNING id, created_at`
+	return r.db.QueryRow(ctx, query,
+		t.OrderID,
+		t.ProfileID,
+		t.FullName,
+		t.BirthDate,
+		t.Gender,
+		t.TravellerType,
+		t.Room,
+		t.Price,
+	).Scan(&t.ID, &t.CreatedAt)
+}
+
+// ListTravellers возвращает туристов по списку заказов (order_id → туристы)
+func (r *OrderRepo) ListTravellers(ctx context.Context, orderIDs []int) (map[int][]models.OrderTraveller, error) {
+	out := make(map[int][]models.OrderTraveller, len(orderIDs))
+	if len(orderIDs) == 0 {
+		return out, nil
+	}
+
+	query := `SELECT ` + orderTravellerFields + `
+	          FROM order_travellers
+	          WHERE order_id = ANY($1)
+	          ORDER BY order_id, id`
+
+	rows, err := r.db.Query(ctx, query, orderIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		t, err := scanOrderTraveller(rows)
+		if err != nil {
+			return nil, err
+		}
+		out[t.OrderID] = append(out[t.OrderID], t)
+	}
+	return out, rows.Err()
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/Ramcache/travel-backend/internal/helpers"
 	"github.com/Ramcache/travel-backend/internal/models"
 )
 
@@ -16,8 +17,19 @@ func NewOrderRepo(db DB) *OrderRepo {
 	return &OrderRepo{db: db}
 }
 
+// Tx выполняет fn в транзакции; репозитории внутри получают tx через WithTx
+func (r *OrderRepo) Tx(ctx context.Context, fn func(tx DB) error) error {
+	return InTx(ctx, r.db, fn)
+}
+
+// WithTx — тот же репозиторий поверх транзакции
+func (r *OrderRepo) WithTx(tx DB) *OrderRepo {
+	return &OrderRepo{db: tx}
+}
+
 const orderFields = `
-	id, trip_id, name, date, price, user_name, user_phone, status, is_read, created_at
+	id, trip_id, name, date, price, user_name, user_phone, total_price, telegram_chat_id, status, is_read, created_at,
+	assigned_to, next_call_at, tags, customer_id, user_phone_display, user_id, tracking_token
 `
 
 // приватный сканер
@@ -33,9 +45,18 @@ func scanOrder(row interface{ Scan(dest ...any) error }) (models.Order, error) {
 		&price,
 		&o.UserName,
 		&o.UserPhone,
+		&o.TotalPrice,
+		&o.TelegramChatID,
 		&o.Status,
 		&o.IsRead,
 		&o.CreatedAt,
+		&o.AssignedTo,
+		&o.NextCallAt,
+		&o.Tags,
+		&o.CustomerID,
+		&o.UserPhoneDisplay,
+		&o.UserID,
+		&o.TrackingToken,
 	)
 	if err != nil {
 		return o, err
@@ -55,48 +76,148 @@ func scanOrder(row interface{ Scan(dest ...any) error }) (models.Order, error) {
 }
 
 // buildOrderFilters собирает WHERE + args
-func buildOrderFilters(status, phone string, isRead *bool) (string, []any) {
+func buildOrderFilters(f models.OrderFilter) (string, []any) {
 	filters := "1=1"
 	args := []any{}
 	i := 1
 
-	if status != "" {
+	if f.Status != "" {
 		filters += fmt.Sprintf(" AND status = $%d", i)
-		args = append(args, status)
+		args = append(args, f.Status)
 		i++
 	}
-	if phone != "" {
+	if f.Phone != "" {
 		filters += fmt.Sprintf(" AND user_phone ILIKE $%d", i)
-		args = append(args, "%"+phone+"%")
+		args = append(args, phoneSearchPattern(f.Phone))
 		i++
 	}
-	if isRead != nil {
+	if f.IsRead != nil {
 		filters += fmt.Sprintf(" AND is_read = $%d", i)
-		args = append(args, *isRead)
+		args = append(args, *f.IsRead)
+		i++
+	}
+	if f.From != nil {
+		filters += fmt.Sprintf(" AND created_at >= $%d", i)
+		args = append(args, *f.From)
+		i++
+	}
+	if f.To != nil {
+		filters += fmt.Sprintf(" AND created_at < $%d", i)
+		args = append(args, *f.To)
+		i++
+	}
+	if f.AssignedTo != nil {
+		filters += fmt.Sprintf(" AND assigned_to = $%d", i)
+		args = append(args, *f.AssignedTo)
+		i++
+	}
+	if f.Unassigned {
+		filters += " AND assigned_to IS NULL"
+	}
+	if f.Tag != "" {
+		filters += fmt.Sprintf(" AND $%d = ANY(tags)", i)
+		args = append(args, f.Tag)
+		i++
+	}
+	if f.UserID != nil {
+		filters += fmt.Sprintf(" AND user_id = $%d", i)
+		args = append(args, *f.UserID)
+		i++
+	}
+	if f.OverdueFollowUp {
+		filters += fmt.Sprintf(" AND next_call_at <= now() AND status <> ALL($%d)", i)
+		args = append(args, models.FinalOrderStatuses())
 	}
 	return filters, args
 }
 
+// Create сохраняет заказ вместе с туристами (если они переданы),
+// привязывает его к клиенту по телефону и выдаёт токен ссылки отслеживания
 func (r *OrderRepo) Create(ctx context.Context, o *models.Order) error {
-	query := `INSERT INTO orders (trip_id, name, date, price, user_name, user_phone, status)
-	          VALUES ($1, $2, $3, $4, $5, $6, $7)
+	customerID, err := linkCustomer(ctx, r.db, o.UserPhone, o.UserName)
+	if err != nil {
+		return err
+	}
+	o.CustomerID = customerID
+
+	if o.TrackingToken == "" {
+		if o.TrackingToken, err = helpers.RandomToken(24); err != nil {
+			return err
+		}
+	}
+
+	query := `INSERT INTO orders (trip_id, name, date, price, user_name, user_phone, total_price, telegram_chat_id, status, customer_id, user_phone_display, user_id, tracking_token)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 	          RETURNING id, created_at`
 
 	trip := sql.NullInt32{Int32: o.TripID.Int32, Valid: o.TripID.Valid}
 
-	return r.db.QueryRow(ctx, query,
+	err = r.db.QueryRow(ctx, query,
 		trip,
 		o.Name,
 		o.Date,
 		o.Price,
 		o.UserName,
 		o.UserPhone,
+		o.TotalPrice,
+		o.TelegramChatID,
 		o.Status,
+		o.CustomerID,
+		o.UserPhoneDisplay,
+		o.UserID,
+		o.TrackingToken,
 	).Scan(&o.ID, &o.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	for i := range o.Travellers {
+		o.Travellers[i].OrderID = o.ID
+		if err := r.createTraveller(ctx, &o.Travellers[i]); err != nil {
+			return fmt.Errorf("create traveller: %w", err)
+		}
+	}
+
+	system := "system"
+	return r.AddStatusHistory(ctx, &models.OrderStatusChange{
+		OrderID:   o.ID,
+		ToStatus:  o.Status,
+		ActorName: &system,
+	})
+}
+
+func (r *OrderRepo) GetByID(ctx context.Context, id int) (*models.Order, error) {
+	query := `SELECT ` + orderFields + ` FROM orders WHERE id=$1`
+	o, err := scanOrder(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		return nil, mapNotFound(err)
+	}
+
+	travellers, err := r.ListTravellers(ctx, []int{o.ID})
+	if err != nil {
+		return nil, err
+	}
+	o.Travellers = travellers[o.ID]
+	return &o, nil
 }
 
-func (r *OrderRepo) Count(ctx context.Context, status, phone string, isRead *bool) (int, error) {
-	where, args := buildOrderFilters(status, phone, isRead)
+func (r *OrderRepo) GetByTrackingToken(ctx context.Context, token string) (*models.Order, error) {
+	query := `SELECT ` + orderFields + ` FROM orders WHERE tracking_token=$1`
+	o, err := scanOrder(r.db.QueryRow(ctx, query, token))
+	if err != nil {
+		return nil, mapNotFound(err)
+	}
+
+	travellers, err := r.ListTravellers(ctx, []int{o.ID})
+	if err != nil {
+		return nil, err
+	}
+	o.Travellers = travellers[o.ID]
+	return &o, nil
+}
+
+func (r *OrderRepo) Count(ctx context.Context, f models.OrderFilter) (int, error) {
+	where, args := buildOrderFilters(f)
 	query := `SELECT COUNT(*) FROM orders WHERE ` + where
 
 	var total int
@@ -106,8 +227,8 @@ func (r *OrderRepo) Count(ctx context.Context, status, phone string, isRead *boo
 	return total, nil
 }
 
-func (r *OrderRepo) List(ctx context.Context, limit, offset int, status, phone string, isRead *bool) ([]models.Order, error) {
-	where, args := buildOrderFilters(status, phone, isRead)
+func (r *OrderRepo) List(ctx context.Context, limit, offset int, f models.OrderFilter) ([]models.Order, error) {
+	where, args := buildOrderFilters(f)
 	args = append(args, limit, offset)
 
 	query := `SELECT ` + orderFields + `
@@ -130,11 +251,82 @@ func (r *OrderRepo) List(ctx context.Context, limit, offset int, status, phone s
 		}
 		list = append(list, o)
 	}
-	return list, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// подтягиваем туристов одним запросом
+	ids := make([]int, 0, len(list))
+	for _, o := range list {
+		ids = append(ids, o.ID)
+	}
+	travellers, err := r.ListTravellers(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		list[i].Travellers = travellers[list[i].ID]
+	}
+	return list, nil
+}
+
+// Export построчно отдаёт заказы вместе с туром и менеджером в fn,
+// не собирая выборку в памяти
+func (r *OrderRepo) Export(ctx context.Context, f models.OrderFilter, fn func(models.OrderExportRow) error) error {
+	where, args := buildOrderFilters(f)
+	// колонки тура и менеджера переименованы, чтобы не конфликтовать с полями заказа в orderFields и фильтрах
+	query := `SELECT ` + orderFields + `, t.trip_title, t.trip_price, m.manager_name
+              FROM orders
+              LEFT JOIN (SELECT id AS trip_ref, title AS trip_title, price AS trip_price FROM trips) t
+                     ON t.trip_ref = orders.trip_id
+              LEFT JOIN (SELECT id AS manager_ref, full_name AS manager_name FROM users) m
+                     ON m.manager_ref = orders.assigned_to
+              WHERE ` + where + `
+              ORDER BY created_at DESC, id DESC`
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row models.OrderExportRow
+		o, err := scanOrder(withExtra(rows, &row.TripTitle, &row.TripPrice, &row.AssigneeName))
+		if err != nil {
+			return err
+		}
+		row.Order = o
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ClaimByPhone привязывает к пользователю анонимные заказы с этим телефоном (E.164)
+func (r *OrderRepo) ClaimByPhone(ctx context.Context, userID int, phone string) (int, error) {
+	cmd, err := r.db.Exec(ctx,
+		`UPDATE orders SET user_id = $1 WHERE user_id IS NULL AND user_phone = $2`,
+		userID, phone)
+	if err != nil {
+		return 0, fmt.Errorf("claim orders: %w", err)
+	}
+	return int(cmd.RowsAffected()), nil
+}
+
+func (r *OrderRepo) GetStatus(ctx context.Context, id int) (string, error) {
+	var status string
+	if err := r.db.QueryRow(ctx, `SELECT status FROM orders WHERE id=$1`, id).Scan(&status); err != nil {
+		return "", mapNotFound(err)
+	}
+	return status, nil
 }
 
-func (r *OrderRepo) UpdateStatus(ctx context.Context, id int, status string) error {
-	cmd, err := r.db.Exec(ctx, `UPDATE orders SET status=$1 WHERE id=$2`, status, id)
+// UpdateStatus меняет статус только если заказ всё ещё в статусе from
+// (защита от одновременного изменения из админки и Telegram)
+func (r *OrderRepo) UpdateStatus(ctx context.Context, id int, from, to string) error {
+	cmd, err := r.db.Exec(ctx, `UPDATE orders SET status=$1 WHERE id=$2 AND status=$3`, to, id, from)
 	if err != nil {
 		return err
 	}
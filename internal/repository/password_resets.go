@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// PasswordResetRepo — токены сброса пароля
+type PasswordResetRepo struct {
+	db DB
+}
+
+func NewPasswordResetRepo(db DB) *PasswordResetRepo {
+	return &PasswordResetRepo{db: db}
+}
+
+// Tx выполняет fn в транзакции; репозитории внутри получают tx через WithTx
+func (r *PasswordResetRepo) Tx(ctx context.Context, fn func(tx DB) error) error {
+	return InTx(ctx, r.db, fn)
+}
+
+// WithTx — тот же репозиторий поверх транзакции
+func (r *PasswordResetRepo) WithTx(tx DB) *PasswordResetRepo {
+	return &PasswordResetRepo{db: tx}
+}
+
+// Create выдаёт пользователю новый токен, прежние неиспользованные перестают действовать.
+// false — предыдущий токен выдан меньше cooldown назад, новый не создан
+func (r *PasswordResetRepo) Create(ctx context.Context, userID int, hash string, expiresAt time.Time, cooldown time.Duration) (bool, error) {
+	var recent bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM password_reset_tokens
+			WHERE user_id = $1 AND created_at > now() - make_interval(secs => $2)
+		)`, userID, cooldown.Seconds(),
+	).Scan(&recent)
+	if err != nil || recent {
+		return false, err
+	}
+
+	if _, err := r.db.Exec(ctx, `
+		UPDATE password_reset_tokens SET used_at = now()
+		WHERE user_id = $1 AND used_at IS NULL`, userID); err != nil {
+		return false, err
+	}
+	if _, err := r.db.Exec(ctx, `
+		INSERT INTO password_reset_tokens (user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3)`, userID, hash, expiresAt); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Consume гасит действующий токен и возвращает его владельца.
+// ErrNotFound — токена нет, он истёк или уже использован
+func (r *PasswordResetRepo) Consume(ctx context.Context, hash string) (int, error) {
+	var userID int
+	err := r.db.QueryRow(ctx, `
+		UPDATE password_reset_tokens SET used_at = now()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > now()
+		RETURNING user_id`, hash,
+	).Scan(&userID)
+	return userID, mapNotFound(err)
+}
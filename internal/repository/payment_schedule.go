@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+type PaymentScheduleRepo struct {
+	db DB
+}
+
+func NewPaymentScheduleRepo(db DB) *PaymentScheduleRepo {
+	return &PaymentScheduleRepo{db: db}
+}
+
+// Tx выполняет fn в транзакции; репозитории внутри получают tx через WithTx
+func (r *PaymentScheduleRepo) Tx(ctx context.Context, fn func(tx DB) error) error {
+	return InTx(ctx, r.db, fn)
+}
+
+// WithTx — тот же репозиторий поверх транзакции
+func (r *PaymentScheduleRepo) WithTx(tx DB) *PaymentScheduleRepo {
+	return &PaymentScheduleRepo{db: tx}
+}
+
+const installmentFields = `
+	i.id, i.order_id, i.seq, i.title, i.amount, i.paid_amount, i.due_date,
+	i.paid_at, i.reminded_at, i.created_at
+`
+
+func scanInstallment(row pgx.Row, extra ...any) (*models.OrderInstallment, error) {
+	var i models.OrderInstallment
+	dest := append([]any{
+		&i.ID, &i.OrderID, &i.Seq, &i.Title, &i.Amount, &i.PaidAmount, &i.DueDate,
+		&i.PaidAt, &i.RemindedAt, &i.CreatedAt,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+// ---- правила тура ----
+
+func (r *PaymentScheduleRepo) ListRules(ctx context.Context, tripID int) ([]models.TripPaymentRule, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, trip_id, seq, title, percent::float8, amount::float8, due_anchor, due_offset_days
+		FROM trip_payment_rules
+		WHERE trip_id=$1
+		ORDER BY seq`, tripID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.TripPaymentRule
+	for rows.Next() {
+		var rule models.TripPaymentRule
+		if err := rows.Scan(&rule.ID, &rule.TripID, &rule.Seq, &rule.Title, &rule.Percent, &rule.Amount,
+			&rule.DueAnchor, &rule.DueOffsetDays); err != nil {
+			return nil, err
+		}
+		list = append(list, rule)
+	}
+	return list, rows.Err()
+}
+
+// ReplaceRules заменяет график тура целиком
+func (r *PaymentScheduleRepo) ReplaceRules(ctx context.Context, tripID int, rules []models.TripPaymentRule) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM trip_payment_rules WHERE trip_id=$1`, tripID); err != nil {
+		return err
+	}
+	for i := range rules {
+		rule := &rules[i]
+		rule.TripID = tripID
+		err := r.db.QueryRow(ctx, `
+			INSERT INTO trip_payment_rules (trip_id, seq, title, percent, amount, due_anchor, due_offset_days)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id`,
+			tripID, rule.Seq, rule.Title, rule.Percent, rule.Amount, rule.DueAnchor, rule.DueOffsetDays,
+		).Scan(&rule.ID)
+		if err != nil {
+			return fmt.Errorf("insert payment rule %d: %w", rule.Seq, err)
+		}
+	}
+	return nil
+}
+
+// ---- график заказа ----
+
+func (r *PaymentScheduleRepo) ListInstallments(ctx context.Context, orderID int) ([]models.OrderInstallment, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+installmentFields+`
+		FROM order_installments i
+		WHERE i.order_id=$1
+		ORDER BY i.seq`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.OrderInstallment
+	for rows.Next() {
+		i, err := scanInstallment(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *i)
+	}
+	return list, rows.Err()
+}
+
+// ReplaceInstallments пересоздаёт график заказа
+func (r *PaymentScheduleRepo) ReplaceInstallments(ctx context.Context, orderID int, list []models.OrderInstallment) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM order_installments WHERE order_id=$1`, orderID); err != nil {
+		return err
+	}
+	for i := range list {
+		in := &list[i]
+		in.OrderID = orderID
+		err := r.db.QueryRow(ctx, `
+			INSERT INTO order_installments (order_id, seq, title, amount, due_date)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id, created_at`,
+			orderID, in.Seq, in.Title, in.Amount, in.DueDate,
+		).Scan(&in.ID, &in.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("insert installment %d: %w", in.Seq, err)
+		}
+	}
+	return nil
+}
+
+func (r *PaymentScheduleRepo) SetPaid(ctx context.Context, id int, paidAmount float64, paidAt *time.Time) error {
+	cmd, err := r.db.Exec(ctx, `UPDATE order_installments SET paid_amount=$1, paid_at=$2 WHERE id=$3`,
+		paidAmount, paidAt, id)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// активные заказы — просрочки по отменённым/возвращённым заказам не интересны
+const activeOrderStatuses = `('new', 'confirmed', 'paid')`
+
+// ListOverdue — неоплаченные платежи со сроком раньше asOf
+func (r *PaymentScheduleRepo) ListOverdue(ctx context.Context, asOf time.Time, limit, offset int) ([]models.OverdueInstallment, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+installmentFields+`,
+		       o.user_name, o.user_phone, o.status, o.trip_id, t.title
+		FROM order_installments i
+		JOIN orders o ON o.id = i.order_id
+		LEFT JOIN trips t ON t.id = o.trip_id
+		WHERE i.paid_amount < i.amount
+		  AND i.due_date < $1::date
+		  AND o.status IN `+activeOrderStatuses+`
+		ORDER BY i.due_date, i.id
+		LIMIT $2 OFFSET $3`, asOf, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanOverdue(rows)
+}
+
+// ListForReminder — платежи со сроком до until, о которых ещё не напоминали;
+// по просроченным напоминание повторяется не чаще раза в сутки
+func (r *PaymentScheduleRepo) ListForReminder(ctx context.Context, now, until time.Time) ([]models.OverdueInstallment, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+installmentFields+`,
+		       o.user_name, o.user_phone, o.status, o.trip_id, t.title
+		FROM order_installments i
+		JOIN orders o ON o.id = i.order_id
+		LEFT JOIN trips t ON t.id = o.trip_id
+		WHERE i.paid_amount < i.amount
+		  AND i.due_date <= $2::date
+		  AND o.status IN `+activeOrderStatuses+`
+		  AND (i.reminded_at IS NULL
+		       OR (i.due_date < $1::date AND i.reminded_at < $1 - interval '24 hours'))
+		ORDER BY i.due_date, i.id`, now, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanOverdue(rows)
+}
+
+func scanOverdue(rows pgx.Rows) ([]models.OverdueInstallment, error) {
+	var list []models.OverdueInstallment
+	for rows.Next() {
+		var o models.OverdueInstallment
+		i, err := scanInstallment(rows, &o.UserName, &o.UserPhone, &o.OrderStatus, &o.TripID, &o.TripTitle)
+		if err != nil {
+			return nil, err
+		}
+		o.OrderInstallment = *i
+		o.Outstanding = i.Outstanding()
+		list = append(list, o)
+	}
+	return list, rows.Err()
+}
+
+func (r *PaymentScheduleRepo) MarkReminded(ctx context.Context, ids []int, at time.Time) error {
+	_, err := r.db.Exec(ctx, `UPDATE order_installments SET reminded_at=$1 WHERE id = ANY($2)`, at, ids)
+	return err
+}
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+type PaymentRepo struct {
+	db DB
+}
+
+func NewPaymentRepo(db DB) *PaymentRepo {
+	return &PaymentRepo{db: db}
+}
+
+// Tx выполняет fn в транзакции; репозитории внутри получают tx через WithTx
+func (r *PaymentRepo) Tx(ctx context.Context, fn func(tx DB) error) error {
+	return InTx(ctx, r.db, fn)
+}
+
+// WithTx — тот же репозиторий поверх транзакции
+func (r *PaymentRepo) WithTx(tx DB) *PaymentRepo {
+	return &PaymentRepo{db: tx}
+}
+
+const paymentFields = `
+	id, order_id, provider, external_id, amount, refunded_amount, currency,
+	status, confirmation_url, created_at, updated_at
+`
+
+func scanPayment(row pgx.Row) (*models.Payment, error) {
+	var p models.Payment
+	if err := row.Scan(
+		&p.ID, &p.OrderID, &p.Provider, &p.ExternalID, &p.Amount, &p.RefundedAmount, &p.Currency,
+		&p.Status, &p.ConfirmationURL, &p.CreatedAt, &p.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *PaymentRepo) Create(ctx context.Context, p *models.Payment) error {
+	query := `INSERT INTO payments (order_id, provider, amount, currency, status)
+	          VALUES ($1, $2, $3, $4, $5)
+	          RETURNING id, created_at, updated_at`
+	return r.db.QueryRow(ctx, query,
+		p.OrderID, p.Provider, p.Amount, p.Currency, p.Status,
+	).Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt)
+}
+
+// SetExternal сохраняет идентификатор и ссылку на оплату, полученные от провайдера
+func (r *PaymentRepo) SetExternal(ctx context.Context, id int, externalID, confirmationURL, status string) error {
+	cmd, err := r.db.Exec(ctx, `
+		UPDATE payments
+		SET external_id=$1, confirmation_url=NULLIF($2, ''), status=$3, updated_at=now()
+		WHERE id=$4`, externalID, confirmationURL, status, id)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *PaymentRepo) GetByID(ctx context.Context, id int) (*models.Payment, error) {
+	row := r.db.QueryRow(ctx, `SELECT `+paymentFields+` FROM payments WHERE id=$1`, id)
+	p, err := scanPayment(row)
+	if err != nil {
+		return nil, mapNotFound(err)
+	}
+	return p, nil
+}
+
+// GetForUpdate — платёж с блокировкой строки до конца транзакции: вебхуки и возвраты
+// по одному платежу обрабатываются по очереди
+func (r *PaymentRepo) GetForUpdate(ctx context.Context, id int) (*models.Payment, error) {
+	row := r.db.QueryRow(ctx, `SELECT `+paymentFields+` FROM payments WHERE id=$1 FOR UPDATE`, id)
+	p, err := scanPayment(row)
+	if err != nil {
+		return nil, mapNotFound(err)
+	}
+	return p, nil
+}
+
+func (r *PaymentRepo) GetByExternalID(ctx context.Context, provider, externalID string) (*models.Payment, error) {
+	row := r.db.QueryRow(ctx, `SELECT `+paymentFields+` FROM payments WHERE provider=$1 AND external_id=$2`,
+		provider, externalID)
+	p, err := scanPayment(row)
+	if err != nil {
+		return nil, mapNotFound(err)
+	}
+	return p, nil
+}
+
+func (r *PaymentRepo) ListByOrder(ctx context.Context, orderID int) ([]models.Payment, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+paymentFields+` FROM payments WHERE order_id=$1 ORDER BY created_at, id`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.Payment
+	for rows.Next() {
+		p, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *p)
+	}
+	return list, rows.Err()
+}
+
+func (r *PaymentRepo) UpdateStatus(ctx context.Context, id int, status string) error {
+	cmd, err := r.db.Exec(ctx, `UPDATE payments SET status=$1, updated_at=now() WHERE id=$2`, status, id)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// AddRefund увеличивает сумму возврата; полностью возвращённый платёж получает статус refunded
+func (r *PaymentRepo) AddRefund(ctx context.Context, id int, amount float64) (*models.Payment, error) {
+	row := r.db.QueryRow(ctx, `
+		UPDATE payments
+		SET refunded_amount = refunded_amount + $1,
+		    status = CASE WHEN refunded_amount + $1 >= amount THEN 'refunded' ELSE status END,
+		    updated_at = now()
+		WHERE id=$2
+		RETURNING `+paymentFields, amount, id)
+	p, err := scanPayment(row)
+	if err != nil {
+		return nil, mapNotFound(err)
+	}
+	return p, nil
+}
+
+// CreateRefund создаёт возврат в статусе pending до запроса к провайдеру
+func (r *PaymentRepo) CreateRefund(ctx context.Context, rf *models.PaymentRefund) error {
+	return r.db.QueryRow(ctx, `
+		INSERT INTO payment_refunds (payment_id, amount)
+		VALUES ($1, $2)
+		RETURNING id, status, created_at, updated_at`,
+		rf.PaymentID, rf.Amount,
+	).Scan(&rf.ID, &rf.Status, &rf.CreatedAt, &rf.UpdatedAt)
+}
+
+// PendingRefund — незавершённый возврат по платежу
+func (r *PaymentRepo) PendingRefund(ctx context.Context, paymentID int) (*models.PaymentRefund, error) {
+	var rf models.PaymentRefund
+	err := r.db.QueryRow(ctx, `
+		SELECT id, payment_id, amount::float8, status, external_id, created_at, updated_at
+		FROM payment_refunds
+		WHERE payment_id=$1 AND status='pending'`, paymentID,
+	).Scan(&rf.ID, &rf.PaymentID, &rf.Amount, &rf.Status, &rf.ExternalID, &rf.CreatedAt, &rf.UpdatedAt)
+	if err != nil {
+		return nil, mapNotFound(err)
+	}
+	return &rf, nil
+}
+
+// FinishRefund переводит возврат из pending в status; false — его уже завершил параллельный повтор
+func (r *PaymentRepo) FinishRefund(ctx context.Context, id int, status string, externalID *string) (bool, error) {
+	cmd, err := r.db.Exec(ctx, `
+		UPDATE payment_refunds
+		SET status=$2, external_id=COALESCE($3, external_id), updated_at=now()
+		WHERE id=$1 AND status='pending'`, id, status, externalID)
+	if err != nil {
+		return false, err
+	}
+	return cmd.RowsAffected() > 0, nil
+}
+
+// PaidAmount — сколько по заказу оплачено за вычетом возвратов
+func (r *PaymentRepo) PaidAmount(ctx context.Context, orderID int) (float64, error) {
+	var sum float64
+	err := r.db.QueryRow(ctx, `
+		SELECT COALESCE(SUM(amount - refunded_amount), 0)::float8
+		FROM payments
+		WHERE order_id=$1 AND status IN ('succeeded', 'refunded')`, orderID).Scan(&sum)
+	return sum, err
+}
+
+// SaveEvent фиксирует вебхук; false — событие уже обрабатывалось
+func (r *PaymentRepo) SaveEvent(ctx context.Context, provider, eventID string, paymentID int, status string, payload []byte) (bool, error) {
+	cmd, err := r.db.Exec(ctx, `
+		INSERT INTO payment_events (provider, event_id, payment_id, status, payload)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (provider, event_id) DO NOTHING`,
+		provider, eventID, paymentID, status, payload)
+	if err != nil {
+		return false, err
+	}
+	return cmd.RowsAffected() > 0, nil
+}
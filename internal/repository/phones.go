@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+)
+
+// PhoneRepo — массовое приведение номеров в заказах, заявках и клиентах
+type PhoneRepo struct {
+	db DB
+}
+
+func NewPhoneRepo(db DB) *PhoneRepo {
+	return &PhoneRepo{db: db}
+}
+
+// таблицы с полями user_phone / user_phone_display
+var contactTables = map[string]bool{"orders": true, "feedbacks": true}
+
+func checkContactTable(table string) error {
+	if !contactTables[table] {
+		return fmt.Errorf("unknown contact table %q", table)
+	}
+	return nil
+}
+
+// ListContactPhones — очередная пачка номеров после afterID (keyset-пагинация)
+func (r *PhoneRepo) ListContactPhones(ctx context.Context, table string, afterID, limit int) ([]models.PhoneRecord, error) {
+	if err := checkContactTable(table); err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Query(ctx,
+		`SELECT id, COALESCE(user_phone, ''), user_phone_display FROM `+table+` WHERE id > $1 ORDER BY id LIMIT $2`,
+		afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list %s phones: %w", table, err)
+	}
+	return scanPhoneRecords(rows)
+}
+
+func (r *PhoneRepo) UpdateContactPhone(ctx context.Context, table string, id int, e164, display string) error {
+	if err := checkContactTable(table); err != nil {
+		return err
+	}
+	_, err := r.db.Exec(ctx,
+		`UPDATE `+table+` SET user_phone = $2, user_phone_display = $3 WHERE id = $1`,
+		id, e164, display)
+	if err != nil {
+		return fmt.Errorf("update %s phone: %w", table, err)
+	}
+	return nil
+}
+
+func (r *PhoneRepo) ListCustomerPhones(ctx context.Context, afterID, limit int) ([]models.PhoneRecord, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT id, phone, '' FROM customers WHERE id > $1 ORDER BY id LIMIT $2`,
+		afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list customer phones: %w", err)
+	}
+	return scanPhoneRecords(rows)
+}
+
+// CustomerByPhone — id основной записи клиента с таким номером, кроме exceptID
+func (r *PhoneRepo) CustomerByPhone(ctx context.Context, phone string, exceptID int) (int, error) {
+	var id int
+	err := r.db.QueryRow(ctx,
+		`SELECT COALESCE(merged_into, id) FROM customers WHERE phone = $1 AND id <> $2`,
+		phone, exceptID).Scan(&id)
+	if err != nil {
+		return 0, mapNotFound(err)
+	}
+	return id, nil
+}
+
+func (r *PhoneRepo) UpdateCustomerPhone(ctx context.Context, id int, phone string) error {
+	if _, err := r.db.Exec(ctx, `UPDATE customers SET phone = $2 WHERE id = $1`, id, phone); err != nil {
+		return fmt.Errorf("update customer phone: %w", err)
+	}
+	return nil
+}
+
+func scanPhoneRecords(rows pgx.Rows) ([]models.PhoneRecord, error) {
+	defer rows.Close()
+
+	var out []models.PhoneRecord
+	for rows.Next() {
+		var rec models.PhoneRecord
+		if err := rows.Scan(&rec.ID, &rec.Phone, &rec.Display); err != nil {
+			return nil, err
+		}
+		out = append(out, rec)
+	}
+	return out, rows.Err()
+}
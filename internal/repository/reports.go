@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+)
+
+// ReportRepo — выборки для сводок владельцу
+type ReportRepo struct {
+	db DB
+}
+
+func NewReportRepo(db DB) *ReportRepo {
+	return &ReportRepo{db: db}
+}
+
+// Tx выполняет fn в транзакции; репозитории внутри получают tx через WithTx
+func (r *ReportRepo) Tx(ctx context.Context, fn func(tx DB) error) error {
+	return InTx(ctx, r.db, fn)
+}
+
+// WithTx — тот же репозиторий поверх транзакции
+func (r *ReportRepo) WithTx(tx DB) *ReportRepo {
+	return &ReportRepo{db: tx}
+}
+
+// pgDate — календарный день для колонок DATE: границы периода уже в часовом поясе сводки
+func pgDate(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// OrdersByStatus — заказы, созданные в [from, to), по статусам
+func (r *ReportRepo) OrdersByStatus(ctx context.Context, from, to time.Time) ([]models.KV, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT status, COUNT(*)
+		FROM orders
+		WHERE created_at >= $1 AND created_at < $2
+		GROUP BY status
+		ORDER BY COUNT(*) DESC, status`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.KV
+	for rows.Next() {
+		var kv models.KV
+		if err := rows.Scan(&kv.Key, &kv.Count); err != nil {
+			return nil, err
+		}
+		list = append(list, kv)
+	}
+	return list, rows.Err()
+}
+
+// FeedbackCount — заявки на консультацию, поступившие в [from, to)
+func (r *ReportRepo) FeedbackCount(ctx context.Context, from, to time.Time) (int, error) {
+	var n int
+	err := r.db.QueryRow(ctx,
+		`SELECT COUNT(*) FROM feedbacks WHERE created_at >= $1 AND created_at < $2`, from, to).Scan(&n)
+	return n, err
+}
+
+// TripActivity — туры с просмотрами или заказами в [from, to), самые просматриваемые сначала
+func (r *ReportRepo) TripActivity(ctx context.Context, from, to time.Time, limit int) ([]models.TripActivity, error) {
+	rows, err := r.db.Query(ctx, `
+		WITH v AS (
+			SELECT trip_id, SUM(views) AS views
+			FROM trip_daily_views
+			WHERE day >= $3::date AND day < $4::date
+			GROUP BY trip_id
+		), o AS (
+			SELECT trip_id, COUNT(*) AS orders
+			FROM orders
+			WHERE trip_id IS NOT NULL AND created_at >= $1 AND created_at < $2
+			GROUP BY trip_id
+		)
+		SELECT t.id, t.title, COALESCE(v.views, 0)::int, COALESCE(o.orders, 0)::int
+		FROM trips t
+		LEFT JOIN v ON v.trip_id = t.id
+		LEFT JOIN o ON o.trip_id = t.id
+		WHERE v.trip_id IS NOT NULL OR o.trip_id IS NOT NULL
+		ORDER BY 3 DESC, 4 DESC, t.id
+		LIMIT $5`, from, to, pgDate(from), pgDate(to), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.TripActivity
+	for rows.Next() {
+		var a models.TripActivity
+		if err := rows.Scan(&a.TripID, &a.Title, &a.Views, &a.Orders); err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
+
+// Departures — активные туры с вылетом в [from, to) и брони на них без закрытых заказов
+// (отменённых, отклонённых, с возвратом)
+func (r *ReportRepo) Departures(ctx context.Context, from, to time.Time, limit int) ([]models.TripDeparture, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT t.id, t.title, t.start_date, COUNT(o.id)::int, COALESCE(SUM(tr.cnt), 0)::int
+		FROM trips t
+		LEFT JOIN orders o ON o.trip_id = t.id AND o.status <> ALL($3)
+		LEFT JOIN LATERAL (
+			SELECT COUNT(*) AS cnt FROM order_travellers WHERE order_id = o.id
+		) tr ON true
+		WHERE t.active = true AND t.start_date >= $1::date AND t.start_date < $2::date
+		GROUP BY t.id
+		ORDER BY t.start_date, t.id
+		LIMIT $4`,
+		pgDate(from), pgDate(to),
+		[]string{models.OrderStatusCancelled, models.OrderStatusRejected, models.OrderStatusRefunded},
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.TripDeparture
+	for rows.Next() {
+		var d models.TripDeparture
+		if err := rows.Scan(&d.TripID, &d.Title, &d.StartDate, &d.Bookings, &d.Travellers); err != nil {
+			return nil, err
+		}
+		list = append(list, d)
+	}
+	return list, rows.Err()
+}
+
+// ClaimDigest отмечает сводку за период отправленной; false — её уже отправили
+func (r *ReportRepo) ClaimDigest(ctx context.Context, period string, start time.Time) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+		INSERT INTO report_digest_runs (period, period_start) VALUES ($1, $2::date)
+		ON CONFLICT DO NOTHING`, period, pgDate(start))
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// RoleRepo — роли, справочник прав и права ролей
+type RoleRepo struct {
+	db DB
+}
+
+func NewRoleRepo(db DB) *RoleRepo {
+	return &RoleRepo{db: db}
+}
+
+const roleFields = `
+	r.id, r.name, r.description, r.is_system,
+	ARRAY(SELECT permission FROM role_permissions WHERE role_id = r.id ORDER BY permission),
+	(SELECT count(*) FROM users WHERE role_id = r.id)
+`
+
+func scanRole(row pgx.Row) (*models.Role, error) {
+	var role models.Role
+	if err := row.Scan(&role.ID, &role.Name, &role.Description, &role.IsSystem, &role.Permissions, &role.UsersCount); err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *RoleRepo) List(ctx context.Context) ([]models.Role, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+roleFields+` FROM roles r ORDER BY r.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.Role
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *role)
+	}
+	return list, rows.Err()
+}
+
+func (r *RoleRepo) GetByID(ctx context.Context, id int) (*models.Role, error) {
+	role, err := scanRole(r.db.QueryRow(ctx, `SELECT `+roleFields+` FROM roles r WHERE r.id = $1`, id))
+	if err != nil {
+		return nil, mapNotFound(err)
+	}
+	return role, nil
+}
+
+// Permissions — справочник прав
+func (r *RoleRepo) Permissions(ctx context.Context) ([]models.Permission, error) {
+	rows, err := r.db.Query(ctx, `SELECT code, description FROM permissions ORDER BY code`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.Permission
+	for rows.Next() {
+		var p models.Permission
+		if err := rows.Scan(&p.Code, &p.Description); err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	return list, rows.Err()
+}
+
+// Create сохраняет роль вместе с правами
+func (r *RoleRepo) Create(ctx context.Context, role *models.Role) error {
+	return InTx(ctx, r.db, func(tx DB) error {
+		err := tx.QueryRow(ctx, `
+			INSERT INTO roles (name, description) VALUES ($1, $2)
+			RETURNING id`, role.Name, role.Description,
+		).Scan(&role.ID)
+		if err != nil {
+			return err
+		}
+		return insertRolePermissions(ctx, tx, role)
+	})
+}
+
+// Update заменяет название, описание и все права роли
+func (r *RoleRepo) Update(ctx context.Context, role *models.Role) error {
+	return InTx(ctx, r.db, func(tx DB) error {
+		tag, err := tx.Exec(ctx, `UPDATE roles SET name = $2, description = $3 WHERE id = $1`,
+			role.ID, role.Name, role.Description)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrNotFound
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM role_permissions WHERE role_id = $1`, role.ID); err != nil {
+			return err
+		}
+		return insertRolePermissions(ctx, tx, role)
+	})
+}
+
+func insertRolePermissions(ctx context.Context, tx DB, role *models.Role) error {
+	if len(role.Permissions) == 0 {
+		return nil
+	}
+	_, err := tx.Exec(ctx, `
+		INSERT INTO role_permissions (role_id, permission)
+		SELECT $1, unnest($2::text[])`, role.ID, role.Permissions)
+	return err
+}
+
+// Delete удаляет роль; права удаляются каскадом. Роль, назначенную пользователям,
+// удалить не даст внешний ключ users.role_id
+func (r *RoleRepo) Delete(ctx context.Context, id int) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM roles WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
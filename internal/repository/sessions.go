@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+)
+
+// SessionRepo — сессии входа и их токены
+type SessionRepo struct {
+	db DB
+}
+
+func NewSessionRepo(db DB) *SessionRepo {
+	return &SessionRepo{db: db}
+}
+
+// Create открывает сессию с первым access-токеном (jti) и refresh-токеном (его хэш)
+func (r *SessionRepo) Create(ctx context.Context, s *models.Session, accessJTI, refreshHash string) error {
+	return r.db.QueryRow(ctx, `
+		INSERT INTO auth_sessions (user_id, access_jti, refresh_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, last_used_at`,
+		s.UserID, accessJTI, refreshHash, s.ExpiresAt,
+	).Scan(&s.ID, &s.CreatedAt, &s.LastUsedAt)
+}
+
+// GetByRefreshHash — сессия по действующему или предыдущему refresh-токену;
+// current=false — предъявлен уже использованный токен
+func (r *SessionRepo) GetByRefreshHash(ctx context.Context, hash string) (s *models.Session, current bool, err error) {
+	var out models.Session
+	err = r.db.QueryRow(ctx, `
+		SELECT id, user_id, created_at, last_used_at, expires_at, revoked_at, refresh_hash = $1
+		FROM auth_sessions
+		WHERE refresh_hash = $1 OR previous_refresh_hash = $1
+		LIMIT 1`, hash,
+	).Scan(&out.ID, &out.UserID, &out.CreatedAt, &out.LastUsedAt, &out.ExpiresAt, &out.RevokedAt, &current)
+	if err != nil {
+		return nil, false, mapNotFound(err)
+	}
+	return &out, current, nil
+}
+
+// Rotate меняет токены сессии и продлевает её. ErrNotFound — сессию уже обновили
+// этим же refresh-токеном или отозвали
+func (r *SessionRepo) Rotate(ctx context.Context, id int64, oldHash, newHash, accessJTI string, expiresAt time.Time) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE auth_sessions
+		SET previous_refresh_hash = refresh_hash, refresh_hash = $3, access_jti = $4,
+		    expires_at = $5, last_used_at = now()
+		WHERE id = $1 AND refresh_hash = $2 AND revoked_at IS NULL`,
+		id, oldHash, newHash, accessJTI, expiresAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Active — владелец, его текущая роль и её права, если access-токен jti — последний выданный
+// в сессии id и сессия не отозвана и не истекла
+func (r *SessionRepo) Active(ctx context.Context, id int64, accessJTI string) (*models.SessionPrincipal, error) {
+	var p models.SessionPrincipal
+	err := r.db.QueryRow(ctx, `
+		SELECT u.id, u.role_id,
+		       ARRAY(SELECT permission FROM role_permissions WHERE role_id = u.role_id ORDER BY permission)
+		FROM auth_sessions s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.id = $1 AND s.access_jti = $2 AND s.revoked_at IS NULL AND s.expires_at > now()`,
+		id, accessJTI,
+	).Scan(&p.UserID, &p.RoleID, &p.Permissions)
+	if err != nil {
+		return nil, mapNotFound(err)
+	}
+	return &p, nil
+}
+
+// Revoke завершает сессию пользователя
+func (r *SessionRepo) Revoke(ctx context.Context, id int64, userID int) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE auth_sessions SET revoked_at = now()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RevokeOthers завершает все сессии пользователя, кроме keepID
+func (r *SessionRepo) RevokeOthers(ctx context.Context, userID int, keepID int64) (int64, error) {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE auth_sessions SET revoked_at = now()
+		WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL`, userID, keepID)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// RevokeAll завершает все сессии пользователя; возвращает, сколько было открыто
+func (r *SessionRepo) RevokeAll(ctx context.Context, userID int) (int64, error) {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE auth_sessions SET revoked_at = now()
+		WHERE user_id = $1 AND revoked_at IS NULL`, userID)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -4,14 +4,13 @@ import (
 	"context"
 
 	"github.com/Ramcache/travel-backend/internal/models"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type StatsRepository struct {
-	db *pgxpool.Pool
+	db DB
 }
 
-func NewStatsRepository(db *pgxpool.Pool) *StatsRepository {
+func NewStatsRepository(db DB) *StatsRepository {
 	return &StatsRepository{db: db}
 }
 
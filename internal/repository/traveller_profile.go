@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+)
+
+type TravellerProfileRepo struct {
+	db DB
+}
+
+func NewTravellerProfileRepo(db DB) *TravellerProfileRepo {
+	return &TravellerProfileRepo{db: db}
+}
+
+const travellerProfileFields = `
+	id, user_id, full_name, gender,
+	birth_date_enc, passport_number_enc, passport_expiry_enc, nationality_enc, passport_last4,
+	created_at, updated_at
+`
+
+func scanTravellerProfile(row interface{ Scan(dest ...any) error }) (models.TravellerProfileRecord, error) {
+	var p models.TravellerProfileRecord
+	err := row.Scan(
+		&p.ID,
+		&p.UserID,
+		&p.FullName,
+		&p.Gender,
+		&p.BirthDateEnc,
+		&p.PassportNumberEnc,
+		&p.PassportExpiryEnc,
+		&p.NationalityEnc,
+		&p.PassportLast4,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	return p, err
+}
+
+func (r *TravellerProfileRepo) Create(ctx context.Context, p *models.TravellerProfileRecord) error {
+	query := `INSERT INTO traveller_profiles (user_id, full_name, gender,
+	              birth_date_enc, passport_number_enc, passport_expiry_enc, nationality_enc, passport_last4)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	          RETURNING id, created_at, updated_at`
+	return r.db.QueryRow(ctx, query,
+		p.UserID, p.FullName, p.Gender,
+		p.BirthDateEnc, p.PassportNumberEnc, p.PassportExpiryEnc, p.NationalityEnc, p.PassportLast4,
+	).Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt)
+}
+
+func (r *TravellerProfileRepo) GetByID(ctx context.Context, id int) (*models.TravellerProfileRecord, error) {
+	query := `SELECT ` + travellerProfileFields + ` FROM traveller_profiles WHERE id=$1`
+	p, err := scanTravellerProfile(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		return nil, mapNotFound(err)
+	}
+	return &p, nil
+}
+
+func (r *TravellerProfileRepo) ListByUser(ctx context.Context, userID int) ([]models.TravellerProfileRecord, error) {
+	query := `SELECT ` + travellerProfileFields + ` FROM traveller_profiles WHERE user_id=$1 ORDER BY id`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.TravellerProfileRecord
+	for rows.Next() {
+		p, err := scanTravellerProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	return list, rows.Err()
+}
+
+func (r *TravellerProfileRepo) Update(ctx context.Context, p *models.TravellerProfileRecord) error {
+	query := `UPDATE traveller_profiles
+	          SET full_name=$1, gender=$2, birth_date_enc=$3, passport_number_enc=$4,
+	              passport_expiry_enc=$5, nationality_enc=$6, passport_last4=$7, updated_at=now()
+	          WHERE id=$8 AND user_id=$9
+	          RETURNING updated_at`
+	err := r.db.QueryRow(ctx, query,
+		p.FullName, p.Gender, p.BirthDateEnc, p.PassportNumberEnc,
+		p.PassportExpiryEnc, p.NationalityEnc, p.PassportLast4,
+		p.ID, p.UserID,
+	).Scan(&p.UpdatedAt)
+	if err != nil {
+		return mapNotFound(err)
+	}
+	return nil
+}
+
+func (r *TravellerProfileRepo) Delete(ctx context.Context, id, userID int) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM traveller_profiles WHERE id=$1 AND user_id=$2`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 
@@ -27,7 +28,7 @@ type TripRepositoryI interface {
 	GetMain(ctx context.Context) (*models.Trip, error)
 	ResetMain(ctx context.Context, excludeID *int) error
 	Popular(ctx context.Context, limit int) ([]models.Trip, error)
-	IncrementViews(ctx context.Context, id int) error
+	IncrementViews(ctx context.Context, id int, day time.Time) error
 	IncrementBuys(ctx context.Context, id int) error
 	GetOptions(ctx context.Context, tripID int) ([]models.TripOptionResponse, error)
 }
@@ -35,7 +36,7 @@ type TripRepositoryI interface {
 // общий SELECT список
 const tripSelectFields = `
 	id, title, description, urls, departure_city, trip_type, season,
-	price, discount_percent, currency,
+	price, child_price, infant_price, discount_percent, currency,
 	start_date, end_date, booking_deadline, main, active,
 	views_count, buys_count, created_at, updated_at
 `
@@ -48,7 +49,7 @@ func scanTrip(row pgx.Row) (models.Trip, error) {
 	err := row.Scan(
 		&t.ID, &t.Title, &t.Description, &t.URLs, // 👈 urls TEXT[]
 		&t.DepartureCity, &t.TripType, &t.Season,
-		&t.Price, &t.DiscountPercent, &t.Currency,
+		&t.Price, &t.ChildPrice, &t.InfantPrice, &t.DiscountPercent, &t.Currency,
 		&t.StartDate, &t.EndDate, &t.BookingDeadline,
 		&t.Main, &t.Active,
 		&t.ViewsCount, &t.BuysCount,
@@ -184,13 +185,15 @@ func (r *TripRepository) Create(ctx context.Context, t *models.Trip) error {
 	return r.Db.QueryRow(ctx,
 		`INSERT INTO trips (title, description, urls, departure_city, trip_type, season,
                         price, discount_percent, currency,
-                        start_date, end_date, booking_deadline, main, active)
-     VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)
+                        start_date, end_date, booking_deadline, main, active,
+                        child_price, infant_price)
+     VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16)
      RETURNING id, views_count, buys_count, created_at, updated_at`,
 		t.Title, t.Description, t.URLs, // 👈 массив TEXT[]
 		t.DepartureCity, t.TripType, t.Season,
 		t.Price, t.DiscountPercent, t.Currency,
 		t.StartDate, t.EndDate, t.BookingDeadline, t.Main, t.Active,
+		t.ChildPrice, t.InfantPrice,
 	).Scan(&t.ID, &t.ViewsCount, &t.BuysCount, &t.CreatedAt, &t.UpdatedAt)
 }
 
@@ -199,14 +202,15 @@ func (r *TripRepository) Update(ctx context.Context, t *models.Trip) error {
 		`UPDATE trips
      SET title=$1, description=$2, urls=$3, departure_city=$4, trip_type=$5, season=$6,
          price=$7, discount_percent=$8, currency=$9,
-         start_date=$10, end_date=$11, booking_deadline=$12, main=$13, active=$14, updated_at=now()
-     WHERE id=$15
+         start_date=$10, end_date=$11, booking_deadline=$12, main=$13, active=$14,
+         child_price=$15, infant_price=$16, updated_at=now()
+     WHERE id=$17
      RETURNING views_count, buys_count, updated_at`,
 		t.Title, t.Description, t.URLs,
 		t.DepartureCity, t.TripType, t.Season,
 		t.Price, t.DiscountPercent, t.Currency,
 		t.StartDate, t.EndDate, t.BookingDeadline,
-		t.Main, t.Active, t.ID,
+		t.Main, t.Active, t.ChildPrice, t.InfantPrice, t.ID,
 	).Scan(&t.ViewsCount, &t.BuysCount, &t.UpdatedAt)
 
 	if err != nil {
@@ -215,6 +219,16 @@ func (r *TripRepository) Update(ctx context.Context, t *models.Trip) error {
 	return nil
 }
 
+// SetActive включает или выключает тур и возвращает его название
+func (r *TripRepository) SetActive(ctx context.Context, id int, active bool) (string, error) {
+	var title string
+	err := r.Db.QueryRow(ctx, `UPDATE trips SET active=$1, updated_at=now() WHERE id=$2 RETURNING title`, active, id).Scan(&title)
+	if err != nil {
+		return "", mapNotFound(err)
+	}
+	return title, nil
+}
+
 func (r *TripRepository) Delete(ctx context.Context, id int) error {
 	tag, err := r.Db.Exec(ctx, `DELETE FROM trips WHERE id=$1`, id)
 	if err != nil {
@@ -249,9 +263,16 @@ func (r *TripRepository) Popular(ctx context.Context, limit int) ([]models.Trip,
 	return r.queryTrips(ctx, query, limit)
 }
 
-func (r *TripRepository) IncrementViews(ctx context.Context, id int) error {
-	_, err := r.Db.Exec(ctx,
-		`UPDATE trips SET views_count = views_count + 1 WHERE id = $1`, id)
+// IncrementViews увеличивает счётчик просмотров тура и просмотры за day (для сводок).
+// day считается в часовом поясе сводок, а не в часовом поясе сессии БД
+func (r *TripRepository) IncrementViews(ctx context.Context, id int, day time.Time) error {
+	_, err := r.Db.Exec(ctx, `
+		WITH t AS (
+			UPDATE trips SET views_count = views_count + 1 WHERE id = $1 RETURNING id
+		)
+		INSERT INTO trip_daily_views (trip_id, day, views)
+		SELECT id, $2::date, 1 FROM t
+		ON CONFLICT (trip_id, day) DO UPDATE SET views = trip_daily_views.views + 1`, id, pgDate(day))
 	return err
 }
 
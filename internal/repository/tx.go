@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type txBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// InTx выполняет fn в транзакции: коммит, если fn вернула nil, иначе откат.
+// pgx.Tx тоже умеет Begin, так что вложенный вызов откроет savepoint.
+// Если db не умеет открывать транзакции (фейки в тестах), fn выполняется прямо на нём.
+func InTx(ctx context.Context, db DB, fn func(tx DB) error) error {
+	b, ok := db.(txBeginner)
+	if !ok {
+		return fn(db)
+	}
+
+	tx, err := b.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	// после Commit откат ничего не делает
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -4,14 +4,13 @@ import (
 	"context"
 
 	"github.com/Ramcache/travel-backend/internal/models"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type UserRepository struct {
-	db *pgxpool.Pool
+	db DB
 }
 
-func NewUserRepository(db *pgxpool.Pool) *UserRepository {
+func NewUserRepository(db DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
@@ -26,16 +25,18 @@ type UserRepoI interface {
 }
 
 const userFields = `
-	id, email, full_name, avatar, role_id, created_at, updated_at
+	id, email, full_name, avatar, role_id, created_at, updated_at, phone, phone_verified_at, telegram_id, email_verified_at
 `
 
 func scanUser(row interface{ Scan(dest ...any) error }, withPassword bool) (models.User, error) {
 	var u models.User
 	if withPassword {
-		err := row.Scan(&u.ID, &u.Email, &u.Password, &u.FullName, &u.Avatar, &u.RoleID, &u.CreatedAt, &u.UpdatedAt)
+		err := row.Scan(&u.ID, &u.Email, &u.Password, &u.FullName, &u.Avatar, &u.RoleID, &u.CreatedAt, &u.UpdatedAt,
+			&u.Phone, &u.PhoneVerifiedAt, &u.TelegramID, &u.EmailVerifiedAt)
 		return u, err
 	}
-	err := row.Scan(&u.ID, &u.Email, &u.FullName, &u.Avatar, &u.RoleID, &u.CreatedAt, &u.UpdatedAt)
+	err := row.Scan(&u.ID, &u.Email, &u.FullName, &u.Avatar, &u.RoleID, &u.CreatedAt, &u.UpdatedAt,
+		&u.Phone, &u.PhoneVerifiedAt, &u.TelegramID, &u.EmailVerifiedAt)
 	return u, err
 }
 
@@ -68,7 +69,8 @@ func (r *UserRepository) GetByID(ctx context.Context, id int) (*models.User, err
 }
 
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
-	query := `SELECT id, email, password, full_name, avatar, role_id, created_at, updated_at FROM users WHERE email=$1`
+	query := `SELECT id, email, password, full_name, avatar, role_id, created_at, updated_at, phone, phone_verified_at, telegram_id, email_verified_at
+              FROM users WHERE email=$1`
 	u, err := scanUser(r.db.QueryRow(ctx, query, email), true)
 	if err != nil {
 		return nil, mapNotFound(err)
@@ -86,19 +88,36 @@ func (r *UserRepository) Create(ctx context.Context, u *models.User) error {
 }
 
 func (r *UserRepository) Update(ctx context.Context, u *models.User) error {
+	// смена телефона снимает подтверждение
 	query := `UPDATE users
-              SET full_name=$1, avatar=$2, role_id=$3, updated_at=now()
+              SET full_name=$1, avatar=$2, role_id=$3, phone=$5, telegram_id=$6,
+                  phone_verified_at = CASE WHEN phone IS DISTINCT FROM $5 THEN NULL ELSE phone_verified_at END,
+                  updated_at=now()
               WHERE id=$4
-              RETURNING updated_at`
+              RETURNING updated_at, phone_verified_at`
 	err := r.db.QueryRow(ctx, query,
-		u.FullName, u.Avatar, u.RoleID, u.ID,
-	).Scan(&u.UpdatedAt)
+		u.FullName, u.Avatar, u.RoleID, u.ID, u.Phone, u.TelegramID,
+	).Scan(&u.UpdatedAt, &u.PhoneVerifiedAt)
 	if err != nil {
 		return mapNotFound(err)
 	}
 	return nil
 }
 
+// GetByTelegramID — сотрудник, привязавший этот Telegram-аккаунт, с правами его роли
+func (r *UserRepository) GetByTelegramID(ctx context.Context, telegramID int64) (*models.User, error) {
+	query := `SELECT ` + userFields + `,
+		ARRAY(SELECT permission FROM role_permissions WHERE role_id = users.role_id ORDER BY permission)
+		FROM users WHERE telegram_id=$1`
+	var u models.User
+	err := r.db.QueryRow(ctx, query, telegramID).Scan(&u.ID, &u.Email, &u.FullName, &u.Avatar, &u.RoleID, &u.CreatedAt, &u.UpdatedAt,
+		&u.Phone, &u.PhoneVerifiedAt, &u.TelegramID, &u.EmailVerifiedAt, &u.Permissions)
+	if err != nil {
+		return nil, mapNotFound(err)
+	}
+	return &u, nil
+}
+
 func (r *UserRepository) UpdatePassword(ctx context.Context, id int, password string) error {
 	query := `UPDATE users SET password=$1, updated_at=now() WHERE id=$2`
 	tag, err := r.db.Exec(ctx, query, password, id)
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+)
+
+// VerificationCode — действующий код подтверждения
+type VerificationCode struct {
+	ID       int64
+	Target   string
+	CodeHash string
+	// Attempts — сколько раз код вводили, включая текущую попытку
+	Attempts int
+}
+
+// VerificationRepo — коды подтверждения email и телефона
+type VerificationRepo struct {
+	db DB
+}
+
+func NewVerificationRepo(db DB) *VerificationRepo {
+	return &VerificationRepo{db: db}
+}
+
+// Tx выполняет fn в транзакции; репозитории внутри получают tx через WithTx
+func (r *VerificationRepo) Tx(ctx context.Context, fn func(tx DB) error) error {
+	return InTx(ctx, r.db, fn)
+}
+
+// WithTx — тот же репозиторий поверх транзакции
+func (r *VerificationRepo) WithTx(tx DB) *VerificationRepo {
+	return &VerificationRepo{db: tx}
+}
+
+// Create выдаёт новый код, прежние коды того же вида перестают действовать.
+// false — предыдущий код отправлен меньше cooldown назад, новый не создан
+func (r *VerificationRepo) Create(ctx context.Context, userID int, kind, target, hash string, expiresAt time.Time, cooldown time.Duration) (bool, error) {
+	var recent bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM verification_codes
+			WHERE user_id = $1 AND kind = $2 AND created_at > now() - make_interval(secs => $3)
+		)`, userID, kind, cooldown.Seconds(),
+	).Scan(&recent)
+	if err != nil || recent {
+		return false, err
+	}
+
+	if _, err := r.db.Exec(ctx, `
+		UPDATE verification_codes SET used_at = now()
+		WHERE user_id = $1 AND kind = $2 AND used_at IS NULL`, userID, kind); err != nil {
+		return false, err
+	}
+	if _, err := r.db.Exec(ctx, `
+		INSERT INTO verification_codes (user_id, kind, target, code_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5)`, userID, kind, target, hash, expiresAt); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Attempt засчитывает попытку ввода и возвращает действующий код.
+// ErrNotFound — кода нет, он истёк, использован или попытки исчерпаны
+func (r *VerificationRepo) Attempt(ctx context.Context, userID int, kind string, maxAttempts int) (*VerificationCode, error) {
+	var c VerificationCode
+	err := r.db.QueryRow(ctx, `
+		UPDATE verification_codes SET attempts = attempts + 1
+		WHERE id = (
+			SELECT id FROM verification_codes
+			WHERE user_id = $1 AND kind = $2 AND used_at IS NULL AND expires_at > now() AND attempts < $3
+			ORDER BY created_at DESC
+			LIMIT 1
+			FOR UPDATE
+		)
+		RETURNING id, target, code_hash, attempts`, userID, kind, maxAttempts,
+	).Scan(&c.ID, &c.Target, &c.CodeHash, &c.Attempts)
+	if err != nil {
+		return nil, mapNotFound(err)
+	}
+	return &c, nil
+}
+
+// Confirm гасит код и отмечает адрес пользователя подтверждённым.
+// ErrNotFound — адрес в профиле уже не тот, на который ушёл код
+func (r *VerificationRepo) Confirm(ctx context.Context, userID int, kind string, code *VerificationCode) error {
+	var column string
+	switch kind {
+	case models.VerifyEmail:
+		column = "email"
+	case models.VerifyPhone:
+		column = "phone"
+	default:
+		return fmt.Errorf("unknown verification kind %q", kind)
+	}
+
+	return InTx(ctx, r.db, func(tx DB) error {
+		if _, err := tx.Exec(ctx, `UPDATE verification_codes SET used_at = now() WHERE id = $1`, code.ID); err != nil {
+			return err
+		}
+		tag, err := tx.Exec(ctx, `
+			UPDATE users SET `+column+`_verified_at = now(), updated_at = now()
+			WHERE id = $1 AND `+column+` = $2`, userID, code.Target)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
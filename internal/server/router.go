@@ -18,6 +18,7 @@ import (
 
 	"github.com/Ramcache/travel-backend/internal/handlers"
 	"github.com/Ramcache/travel-backend/internal/middleware"
+	"github.com/Ramcache/travel-backend/internal/models"
 )
 
 func NewRouter(
@@ -39,7 +40,26 @@ func NewRouter(
 	dateHandler *handlers.DateHandler,
 	mediaHandler *handlers.MediaHandler,
 	cloudflareHandler *handlers.CloudflareHandler,
+	travellerProfileHandler *handlers.TravellerProfileHandler,
+	auditHandler *handlers.AuditHandler,
+	paymentHandler *handlers.PaymentHandler,
+	paymentScheduleHandler *handlers.PaymentScheduleHandler,
+	documentHandler *handlers.DocumentHandler,
+	orderCRMHandler *handlers.OrderCRMHandler,
+	customerHandler *handlers.CustomerHandler,
+	myOrderHandler *handlers.MyOrderHandler,
+	cancellationHandler *handlers.CancellationHandler,
+	notificationHandler *handlers.NotificationHandler,
+	notificationTemplateHandler *handlers.NotificationTemplateHandler,
+	telegramHandler *handlers.TelegramHandler,
+	notificationRoutingHandler *handlers.NotificationRoutingHandler,
+	reportHandler *handlers.ReportHandler,
+	passwordHandler *handlers.PasswordHandler,
+	verificationHandler *handlers.VerificationHandler,
+	roleHandler *handlers.RoleHandler,
+	loginLockHandler *handlers.LoginLockHandler,
 	jwtSecret string,
+	sessions middleware.SessionValidator,
 	log *zap.SugaredLogger,
 	db *pgxpool.Pool,
 ) http.Handler {
@@ -58,6 +78,9 @@ func NewRouter(
 	// Buy/feedback: 0.5 rps (~1 запрос в 2 секунды), burst 2
 	buyLimiter := middleware.NewIPLimiter(rate.Limit(0.5), 2, ttl)
 
+	// Отслеживание заказа по ссылке: 1 rps, burst 5
+	trackLimiter := middleware.NewIPLimiter(rate.Limit(1), 5, ttl)
+
 	// Admin upload/cleanup: 0.2 rps (~1 запрос в 5 секунд), burst 1
 	adminUploadLimiter := middleware.NewIPLimiter(rate.Limit(0.2), 1, ttl)
 
@@ -104,6 +127,11 @@ func NewRouter(
 			a.Use(middleware.RateLimit(authLimiter))
 			a.Post("/auth/register", authHandler.Register)
 			a.Post("/auth/login", authHandler.Login)
+			a.Post("/auth/refresh", authHandler.Refresh)
+			a.Post("/auth/password/forgot", passwordHandler.Forgot)
+			a.Post("/auth/password/reset", passwordHandler.Reset)
+			a.Post("/auth/email/verify", verificationHandler.VerifyEmail)
+			a.Post("/auth/email/resend", verificationHandler.ResendEmail)
 		})
 
 		api.Get("/date/today", dateHandler.Today)
@@ -139,84 +167,235 @@ func NewRouter(
 		// buy + feedback — отдельный лимит (обычно достаточно жёсткий)
 		api.Group(func(b chi.Router) {
 			b.Use(middleware.RateLimit(buyLimiter))
+			// токен необязателен: нужен для сохранённых профилей туристов
+			b.Use(middleware.OptionalJWTAuth(jwtSecret, sessions))
 			b.Post("/trips/{id}/buy", tripHandler.Buy)
 			b.Post("/trips/buy", tripHandler.BuyWithoutTrip)
 			b.Post("/feedback", feedbackHandler.Create)
 		})
 
+		// публичная ссылка на заказ для клиентов без аккаунта
+		api.Group(func(t chi.Router) {
+			t.Use(middleware.RateLimit(trackLimiter))
+			t.Get("/orders/track/{token}", myOrderHandler.Track)
+			t.Get("/orders/track/{token}/documents/{kind}.pdf", myOrderHandler.TrackDocument)
+			t.Get("/orders/track/{token}/cancellation", cancellationHandler.QuoteByToken)
+			t.Post("/orders/track/{token}/cancellation", cancellationHandler.RequestByToken)
+		})
+
+		// уведомления платёжного провайдера (проверка подписи внутри)
+		api.Post("/payments/webhook", paymentHandler.Webhook)
+		// кнопки бота в чате менеджеров; проверяется секрет вебхука
+		api.Post("/telegram/webhook", telegramHandler.Webhook)
+
 		// profile (требует JWT)
 		api.Group(func(pr chi.Router) {
-			pr.Use(middleware.JWTAuth(jwtSecret))
+			pr.Use(middleware.JWTAuth(jwtSecret, sessions))
 			pr.Get("/profile", profileHandler.Get)
 			pr.Put("/profile", profileHandler.Update)
+			// подбор текущего пароля — под тем же лимитом, что и вход
+			pr.With(middleware.RateLimit(authLimiter)).Put("/profile/password", passwordHandler.Change)
+			pr.With(middleware.RateLimit(authLimiter)).Post("/profile/phone/send-code", verificationHandler.SendPhoneCode)
+			pr.With(middleware.RateLimit(authLimiter)).Post("/profile/phone/verify", verificationHandler.VerifyPhone)
+			pr.Post("/auth/logout", authHandler.Logout)
+			pr.Post("/auth/logout-all", authHandler.LogoutAll)
+
+			pr.Get("/profile/travellers", travellerProfileHandler.List)
+			pr.Post("/profile/travellers", travellerProfileHandler.Create)
+			pr.Get("/profile/travellers/{id}", travellerProfileHandler.Get)
+			pr.Put("/profile/travellers/{id}", travellerProfileHandler.Update)
+			pr.Delete("/profile/travellers/{id}", travellerProfileHandler.Delete)
+
+			pr.Get("/profile/orders", myOrderHandler.List)
+			pr.Get("/profile/orders/{id}", myOrderHandler.Get)
+			pr.Get("/profile/orders/{id}/documents/{kind}.pdf", myOrderHandler.Document)
+			pr.Get("/profile/orders/{id}/cancellation", cancellationHandler.QuoteOwn)
+			pr.Post("/profile/orders/{id}/cancellation", cancellationHandler.RequestOwn)
 		})
 
-		// admin (JWT + роль 2)
+		// admin (JWT + право на каждую группу ручек)
 		api.Group(func(admin chi.Router) {
-			admin.Use(middleware.JWTAuth(jwtSecret))
-			admin.Use(middleware.RoleAuth(2))
-			admin.Post("/admin/cloudflare/purge-cache", cloudflareHandler.PurgeCache)
-
-			admin.Get("/admin/users", userHandler.List)
-			admin.Get("/admin/users/{id}", userHandler.Get)
-			admin.Post("/admin/users", userHandler.Create)
-			admin.Put("/admin/users/{id}", userHandler.Update)
-			admin.Delete("/admin/users/{id}", userHandler.Delete)
-
-			admin.Get("/admin/trips", tripHandler.List)
-			admin.Get("/admin/trips/{id}", tripHandler.Get)
-			admin.Post("/admin/trips", tripHandler.Create)
-			admin.Post("/admin/tours", tripHandler.CreateTour)
-			admin.Put("/admin/trips/{id}", tripHandler.Update)
-			admin.Delete("/admin/trips/{id}", tripHandler.Delete)
-			admin.Put("/admin/trips/{id}/full", tripHandler.UpdateTour)
-			admin.Get("/admin/trips/{id}/full", tripHandler.GetFull)
-
-			admin.Get("/admin/news", newsHandler.AdminList)
-			admin.Post("/admin/news", newsHandler.Create)
-			admin.Put("/admin/news/{id}", newsHandler.Update)
-			admin.Delete("/admin/news/{id}", newsHandler.Delete)
-
-			admin.Get("/admin/news/categories", categoryHandler.List)
-			admin.Get("/admin/news/categories/{id}", categoryHandler.Get)
-			admin.Post("/admin/news/categories", categoryHandler.Create)
-			admin.Put("/admin/news/categories/{id}", categoryHandler.Update)
-			admin.Delete("/admin/news/categories/{id}", categoryHandler.Delete)
-
-			admin.Get("/admin/stats", statsHandler.Get)
-
-			admin.Get("/admin/orders", orderHandler.List)
-			admin.Post("/admin/orders/{id}/status", orderHandler.UpdateStatus)
-			admin.Post("/admin/orders/{id}/read", orderHandler.MarkAsRead)
-			admin.Delete("/admin/orders/{id}", orderHandler.Delete)
-
-			admin.Get("/admin/feedbacks", feedbackHandler.List)
-			admin.Post("/admin/feedbacks/{id}/read", feedbackHandler.MarkAsRead)
-			admin.Delete("/admin/feedbacks/{id}", feedbackHandler.Delete)
-
-			// hotels CRUD
-			admin.Get("/admin/hotels", hotelHandler.List)
-			admin.Get("/admin/hotels/{id}", hotelHandler.Get)
-			admin.Post("/admin/hotels", hotelHandler.Create)
-			admin.Put("/admin/hotels/{id}", hotelHandler.Update)
-			admin.Delete("/admin/hotels/{id}", hotelHandler.Delete)
-			admin.Post("/admin/trips/{id}/hotels", hotelHandler.AttachHotelToTrip)
-
-			// routes CRUD
-			admin.Post("/admin/trips/{id}/routes/batch", tripRouteHandler.CreateBatch)
-			admin.Put("/admin/trips/{id}/routes/{route_id}", tripRouteHandler.Update)
-			admin.Delete("/admin/trips/{id}/routes/{route_id}", tripRouteHandler.Delete)
-
-			// upload/cleanup — отдельный строгий лимит
-			admin.Group(func(up chi.Router) {
-				up.Use(middleware.RateLimit(adminUploadLimiter))
-				up.Post("/admin/upload", mediaHandler.Upload)
-				up.Post("/admin/media/cleanup", mediaHandler.CleanupUnused)
-			})
-
-			// оставшиеся admin media endpoints (как у вас)
-			admin.Get("/admin/uploads", mediaHandler.ListUploads)
-			admin.Delete("/admin/upload", mediaHandler.DeleteUpload)
+			admin.Use(middleware.JWTAuth(jwtSecret, sessions))
+			// can — ручки, доступные с правом permission
+			can := func(permission string, fn func(r chi.Router)) {
+				admin.Group(func(g chi.Router) {
+					g.Use(middleware.RequirePermission(permission))
+					fn(g)
+				})
+			}
+
+			can(models.PermCachePurge, func(r chi.Router) {
+				r.Post("/admin/cloudflare/purge-cache", cloudflareHandler.PurgeCache)
+			})
+
+			can(models.PermUsersRead, func(r chi.Router) {
+				r.Get("/admin/users", userHandler.List)
+				r.Get("/admin/users/{id}", userHandler.Get)
+			})
+			// назначить роль можно только с roles.manage — проверяется в ручках
+			can(models.PermUsersWrite, func(r chi.Router) {
+				r.Post("/admin/users", userHandler.Create)
+				r.Put("/admin/users/{id}", userHandler.Update)
+				r.Delete("/admin/users/{id}", userHandler.Delete)
+				r.Get("/admin/login-locks", loginLockHandler.List)
+				r.Delete("/admin/login-locks", loginLockHandler.Unlock)
+			})
+			can(models.PermRolesManage, func(r chi.Router) {
+				r.Get("/admin/roles", roleHandler.List)
+				r.Post("/admin/roles", roleHandler.Create)
+				r.Get("/admin/roles/{id}", roleHandler.Get)
+				r.Put("/admin/roles/{id}", roleHandler.Update)
+				r.Delete("/admin/roles/{id}", roleHandler.Delete)
+				r.Get("/admin/permissions", roleHandler.Permissions)
+			})
+
+			// паспортные данные — только с записью в аудит
+			can(models.PermTravellersRead, func(r chi.Router) {
+				r.Get("/admin/users/{id}/travellers", travellerProfileHandler.AdminListByUser)
+				r.Get("/admin/travellers/{id}", travellerProfileHandler.AdminGet)
+				r.Get("/admin/audit", auditHandler.List)
+			})
+
+			can(models.PermTripsRead, func(r chi.Router) {
+				r.Get("/admin/trips", tripHandler.List)
+				r.Get("/admin/trips/{id}", tripHandler.Get)
+				r.Get("/admin/trips/{id}/full", tripHandler.GetFull)
+				r.Get("/admin/trips/{id}/payment-rules", paymentScheduleHandler.GetRules)
+				r.Get("/admin/trips/{id}/cancellation-rules", cancellationHandler.GetRules)
+				r.Get("/admin/hotels", hotelHandler.List)
+				r.Get("/admin/hotels/{id}", hotelHandler.Get)
+			})
+			can(models.PermTripsWrite, func(r chi.Router) {
+				r.Post("/admin/trips", tripHandler.Create)
+				r.Post("/admin/tours", tripHandler.CreateTour)
+				r.Put("/admin/trips/{id}", tripHandler.Update)
+				r.Put("/admin/trips/{id}/full", tripHandler.UpdateTour)
+
+				// график платежей: задаток + остаток
+				r.Put("/admin/trips/{id}/payment-rules", paymentScheduleHandler.SetRules)
+				// отмена заказов клиентами: штрафы тура
+				r.Put("/admin/trips/{id}/cancellation-rules", cancellationHandler.SetRules)
+
+				// hotels CRUD
+				r.Post("/admin/hotels", hotelHandler.Create)
+				r.Put("/admin/hotels/{id}", hotelHandler.Update)
+				r.Delete("/admin/hotels/{id}", hotelHandler.Delete)
+				r.Post("/admin/trips/{id}/hotels", hotelHandler.AttachHotelToTrip)
+
+				// routes CRUD
+				r.Post("/admin/trips/{id}/routes/batch", tripRouteHandler.CreateBatch)
+				r.Put("/admin/trips/{id}/routes/{route_id}", tripRouteHandler.Update)
+				r.Delete("/admin/trips/{id}/routes/{route_id}", tripRouteHandler.Delete)
+			})
+			can(models.PermTripsDelete, func(r chi.Router) {
+				r.Delete("/admin/trips/{id}", tripHandler.Delete)
+			})
+
+			can(models.PermNewsWrite, func(r chi.Router) {
+				r.Get("/admin/news", newsHandler.AdminList)
+				r.Post("/admin/news", newsHandler.Create)
+				r.Put("/admin/news/{id}", newsHandler.Update)
+				r.Delete("/admin/news/{id}", newsHandler.Delete)
+
+				r.Get("/admin/news/categories", categoryHandler.List)
+				r.Get("/admin/news/categories/{id}", categoryHandler.Get)
+				r.Post("/admin/news/categories", categoryHandler.Create)
+				r.Put("/admin/news/categories/{id}", categoryHandler.Update)
+				r.Delete("/admin/news/categories/{id}", categoryHandler.Delete)
+			})
+
+			can(models.PermStatsRead, func(r chi.Router) {
+				r.Get("/admin/stats", statsHandler.Get)
+				r.Get("/admin/reports/digest", reportHandler.Digest)
+			})
+
+			can(models.PermOrdersRead, func(r chi.Router) {
+				r.Get("/admin/orders", orderHandler.List)
+				r.Get("/admin/orders/export", orderHandler.Export)
+				r.Get("/admin/orders/{id}", orderHandler.Get)
+				r.Get("/admin/orders/{id}/history", orderHandler.History)
+				r.Get("/admin/orders/{id}/notes", orderCRMHandler.ListNotes)
+				r.Get("/admin/orders/{id}/payments", paymentHandler.ListByOrder)
+				r.Get("/admin/orders/{id}/schedule", paymentScheduleHandler.GetSchedule)
+				r.Get("/admin/installments/overdue", paymentScheduleHandler.Overdue)
+				r.Get("/admin/cancellations", cancellationHandler.List)
+				r.Get("/admin/orders/{id}/documents/{kind}.pdf", documentHandler.Download)
+			})
+			can(models.PermOrdersWrite, func(r chi.Router) {
+				r.Post("/admin/orders/{id}/status", orderHandler.UpdateStatus)
+				r.Post("/admin/orders/{id}/read", orderHandler.MarkAsRead)
+				r.Put("/admin/orders/{id}/assignee", orderCRMHandler.Assign)
+				r.Post("/admin/orders/{id}/notes", orderCRMHandler.AddNote)
+				r.Delete("/admin/orders/{id}/notes/{noteId}", orderCRMHandler.DeleteNote)
+				r.Put("/admin/orders/{id}/follow-up", orderCRMHandler.SetFollowUp)
+				r.Put("/admin/orders/{id}/tags", orderCRMHandler.SetTags)
+				r.Post("/admin/orders/{id}/schedule", paymentScheduleHandler.Regenerate)
+				// рассмотрение заявок клиентов на отмену
+				r.Post("/admin/orders/{id}/cancellation/approve", cancellationHandler.Approve)
+				r.Post("/admin/orders/{id}/cancellation/reject", cancellationHandler.Reject)
+				r.Post("/admin/orders/{id}/documents/{kind}/send", documentHandler.Send)
+			})
+			can(models.PermOrdersDelete, func(r chi.Router) {
+				r.Delete("/admin/orders/{id}", orderHandler.Delete)
+			})
+			can(models.PermPaymentsWrite, func(r chi.Router) {
+				r.Post("/admin/orders/{id}/payments", paymentHandler.Create)
+				r.Post("/admin/payments/{id}/refund", paymentHandler.Refund)
+			})
+
+			can(models.PermNotificationsManage, func(r chi.Router) {
+				r.Get("/admin/notifications", notificationHandler.List)
+				r.Get("/admin/notifications/{id}", notificationHandler.Get)
+				r.Post("/admin/notifications/{id}/resend", notificationHandler.Resend)
+				r.Get("/admin/notification-templates", notificationTemplateHandler.List)
+				r.Post("/admin/notification-templates", notificationTemplateHandler.Create)
+				r.Post("/admin/notification-templates/preview", notificationTemplateHandler.Preview)
+				r.Get("/admin/notification-templates/{id}", notificationTemplateHandler.Get)
+				r.Put("/admin/notification-templates/{id}", notificationTemplateHandler.Update)
+				r.Delete("/admin/notification-templates/{id}", notificationTemplateHandler.Delete)
+				r.Get("/admin/notification-destinations", notificationRoutingHandler.List)
+				r.Post("/admin/notification-destinations", notificationRoutingHandler.Create)
+				r.Get("/admin/notification-destinations/{id}", notificationRoutingHandler.Get)
+				r.Put("/admin/notification-destinations/{id}", notificationRoutingHandler.Update)
+				r.Delete("/admin/notification-destinations/{id}", notificationRoutingHandler.Delete)
+			})
+
+			can(models.PermDocumentsManage, func(r chi.Router) {
+				r.Get("/admin/documents/templates/contract", documentHandler.GetContractTemplate)
+				r.Put("/admin/documents/templates/contract", documentHandler.SetContractTemplate)
+				r.Delete("/admin/documents/templates/contract", documentHandler.ResetContractTemplate)
+			})
+
+			can(models.PermCustomersRead, func(r chi.Router) {
+				r.Get("/admin/customers", customerHandler.List)
+				r.Get("/admin/customers/{id}", customerHandler.Get)
+				r.Get("/admin/customers/{id}/timeline", customerHandler.Timeline)
+			})
+			can(models.PermCustomersWrite, func(r chi.Router) {
+				r.Post("/admin/customers/{id}/merge", customerHandler.Merge)
+			})
+
+			can(models.PermFeedbackRead, func(r chi.Router) {
+				r.Get("/admin/feedbacks", feedbackHandler.List)
+				r.Get("/admin/feedbacks/export", feedbackHandler.Export)
+			})
+			can(models.PermFeedbackWrite, func(r chi.Router) {
+				r.Post("/admin/feedbacks/{id}/read", feedbackHandler.MarkAsRead)
+				r.Delete("/admin/feedbacks/{id}", feedbackHandler.Delete)
+			})
+
+			can(models.PermMediaWrite, func(r chi.Router) {
+				// upload/cleanup — отдельный строгий лимит
+				r.Group(func(up chi.Router) {
+					up.Use(middleware.RateLimit(adminUploadLimiter))
+					up.Post("/admin/upload", mediaHandler.Upload)
+					up.Post("/admin/media/cleanup", mediaHandler.CleanupUnused)
+				})
+
+				// оставшиеся admin media endpoints (как у вас)
+				r.Get("/admin/uploads", mediaHandler.ListUploads)
+				r.Delete("/admin/upload", mediaHandler.DeleteUpload)
+			})
 		})
 	})
 
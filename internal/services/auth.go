@@ -2,6 +2,8 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"go.uber.org/zap"
 	"time"
@@ -15,22 +17,65 @@ var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrEmailTaken         = errors.New("email already registered")
 	ErrNotFound           = errors.New("user not found")
+
+	// ErrInvalidRefreshToken — refresh-токен неизвестен, истёк, отозван или уже использован
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrSessionNotFound     = errors.New("session not found")
 )
 
+// OrderClaimer привязывает к пользователю анонимные заказы с его телефоном
+type OrderClaimer interface {
+	ClaimByPhone(ctx context.Context, userID int, phone string) (int, error)
+}
+
+// TokenConfig — подпись и сроки токенов
+type TokenConfig struct {
+	Secret string
+	// AccessTTL — срок JWT, с которым ходят в API
+	AccessTTL time.Duration
+	// RefreshTTL — сколько сессия живёт без обновления
+	RefreshTTL time.Duration
+}
+
 type AuthService struct {
-	repo      repository.UserRepoI
-	jwtSecret string
-	jwtTTL    time.Duration
-	log       *zap.SugaredLogger
+	repo     repository.UserRepoI
+	orders   OrderClaimer
+	sessions *repository.SessionRepo
+	tokens   TokenConfig
+	log      *zap.SugaredLogger
+
+	verification *VerificationService
+	// requireVerifiedEmail — не пускать, пока email не подтверждён
+	requireVerifiedEmail bool
+
+	guard *LoginGuardService
+}
+
+func NewAuthService(repo repository.UserRepoI, orders OrderClaimer, sessions *repository.SessionRepo, tokens TokenConfig, log *zap.SugaredLogger) *AuthService {
+	return &AuthService{repo: repo, orders: orders, sessions: sessions, tokens: tokens, log: log}
 }
 
-func NewAuthService(repo repository.UserRepoI, jwtSecret string, jwtTTL time.Duration, log *zap.SugaredLogger) *AuthService {
-	return &AuthService{repo: repo, jwtSecret: jwtSecret, jwtTTL: jwtTTL, log: log}
+// UseVerification включает подтверждение email: код уходит при регистрации,
+// а с requireVerified вход закрыт, пока email не подтверждён
+func (s *AuthService) UseVerification(v *VerificationService, requireVerified bool) *AuthService {
+	s.verification = v
+	s.requireVerifiedEmail = requireVerified
+	return s
+}
+
+// UseLoginGuard включает защиту от подбора пароля: задержки и блокировку
+// после неудачных входов по email и по IP
+func (s *AuthService) UseLoginGuard(g *LoginGuardService) *AuthService {
+	s.guard = g
+	return s
 }
 
 type AuthServiceI interface {
 	Register(ctx context.Context, req models.RegisterRequest) (*models.User, error)
-	Login(ctx context.Context, req models.LoginRequest) (string, error)
+	Login(ctx context.Context, req models.LoginRequest) (*models.AuthResponse, error)
+	Refresh(ctx context.Context, refreshToken string) (*models.AuthResponse, error)
+	Logout(ctx context.Context, sessionID int64, userID int) error
+	LogoutAll(ctx context.Context, userID int) error
 	UpdateProfile(ctx context.Context, id int, req models.UpdateProfileRequest) (*models.User, error)
 	GetByID(ctx context.Context, id int) (*models.User, error)
 }
@@ -73,29 +118,206 @@ func (s *AuthService) Register(ctx context.Context, req models.RegisterRequest)
 	}
 
 	s.log.Infow("user_registered", "user_id", user.ID, "email", user.Email)
+
+	if s.verification != nil {
+		// письмо можно запросить повторно — регистрация от ошибки отправки не откатывается
+		if err := s.verification.SendEmailCode(ctx, user); err != nil {
+			s.log.Errorw("verification_email_failed", "user_id", user.ID, "err", err)
+		}
+	}
 	return user, nil
 }
 
-// Login — проверяет креды и выдаёт JWT
-func (s *AuthService) Login(ctx context.Context, req models.LoginRequest) (string, error) {
+// Login — проверяет креды, открывает сессию и выдаёт пару токенов
+func (s *AuthService) Login(ctx context.Context, req models.LoginRequest) (*models.AuthResponse, error) {
+	key := loginEmailKey(req.Email)
+	if s.guard != nil {
+		if err := s.guard.Check(ctx, key, req.IP); err != nil {
+			return nil, err
+		}
+	}
+
 	user, err := s.repo.GetByEmail(ctx, req.Email)
 	if err != nil {
 		s.log.Warnw("login_failed_user_not_found", "email", req.Email)
-		return "", ErrInvalidCredentials
+		if s.guard != nil {
+			s.guard.Failed(ctx, key, req.IP, nil)
+		}
+		return nil, ErrInvalidCredentials
 	}
 	if !helpers.CheckPassword(user.Password, req.Password) {
 		s.log.Warnw("login_failed_invalid_password", "email", req.Email)
-		return "", ErrInvalidCredentials
+		if s.guard != nil {
+			s.guard.Failed(ctx, key, req.IP, user)
+		}
+		return nil, ErrInvalidCredentials
+	}
+	if s.guard != nil {
+		s.guard.Succeeded(ctx, key)
+	}
+	if s.requireVerifiedEmail && user.EmailVerifiedAt == nil {
+		s.log.Warnw("login_failed_email_not_verified", "user_id", user.ID)
+		return nil, ErrEmailNotVerified
+	}
+
+	refresh, refreshHash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	jti, err := helpers.RandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	sess := &models.Session{UserID: user.ID, ExpiresAt: time.Now().Add(s.tokens.RefreshTTL)}
+	if err := s.sessions.Create(ctx, sess, jti, refreshHash); err != nil {
+		s.log.Errorw("session_create_failed", "user_id", user.ID, "err", err)
+		return nil, err
 	}
 
-	token, err := helpers.GenerateJWT(s.jwtSecret, user.ID, user.FullName, user.RoleID, s.jwtTTL)
+	resp, err := s.issue(user, sess.ID, jti, refresh)
+	if err != nil {
+		return nil, err
+	}
+
+	s.claimOrders(ctx, user)
+
+	s.log.Infow("user_login", "user_id", user.ID, "role_id", user.RoleID, "session_id", sess.ID)
+	return resp, nil
+}
+
+// Refresh меняет refresh-токен на новую пару; старый больше не действует.
+// Повторное предъявление уже обменянного токена значит, что его украли:
+// сессия закрывается целиком, и войти заново придётся обоим
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*models.AuthResponse, error) {
+	if refreshToken == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+	oldHash := hashToken(refreshToken)
+	sess, current, err := s.sessions.GetByRefreshHash(ctx, oldHash)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if sess.RevokedAt != nil || time.Now().After(sess.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if !current {
+		s.log.Warnw("Повторное использование refresh-токена, сессия закрыта", "user_id", sess.UserID, "session_id", sess.ID)
+		if err := s.sessions.Revoke(ctx, sess.ID, sess.UserID); err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.repo.GetByID(ctx, sess.UserID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	refresh, refreshHash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	jti, err := helpers.RandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	err = s.sessions.Rotate(ctx, sess.ID, oldHash, refreshHash, jti, time.Now().Add(s.tokens.RefreshTTL))
+	if errors.Is(err, repository.ErrNotFound) {
+		// параллельный запрос успел обменять этот же токен
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		s.log.Errorw("session_rotate_failed", "session_id", sess.ID, "err", err)
+		return nil, err
+	}
+
+	s.log.Infow("session_refreshed", "user_id", user.ID, "session_id", sess.ID)
+	return s.issue(user, sess.ID, jti, refresh)
+}
+
+// Logout закрывает сессию, из которой пришёл запрос
+func (s *AuthService) Logout(ctx context.Context, sessionID int64, userID int) error {
+	err := s.sessions.Revoke(ctx, sessionID, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		return err
+	}
+	s.log.Infow("user_logout", "user_id", userID, "session_id", sessionID)
+	return nil
+}
+
+// LogoutAll закрывает все сессии пользователя, включая текущую
+func (s *AuthService) LogoutAll(ctx context.Context, userID int) error {
+	return s.RevokeSessions(ctx, userID)
+}
+
+// RevokeSessions закрывает все сессии пользователя: его токены перестают
+// действовать на следующем же запросе (смена роли, выход со всех устройств)
+func (s *AuthService) RevokeSessions(ctx context.Context, userID int) error {
+	n, err := s.sessions.RevokeAll(ctx, userID)
+	if err != nil {
+		s.log.Errorw("sessions_revoke_failed", "user_id", userID, "err", err)
+		return err
+	}
+	s.log.Infow("sessions_revoked", "user_id", userID, "count", n)
+	return nil
+}
+
+// ValidateSession — для middleware: сессия открыта, токен в ней последний, пользователь существует.
+// Роль и её права берутся из базы, а не из токена: изменения действуют на следующий запрос
+func (s *AuthService) ValidateSession(ctx context.Context, sessionID int64, jti string) (*models.SessionPrincipal, error) {
+	return s.sessions.Active(ctx, sessionID, jti)
+}
+
+func (s *AuthService) issue(user *models.User, sessionID int64, jti, refresh string) (*models.AuthResponse, error) {
+	token, err := helpers.GenerateSessionJWT(s.tokens.Secret, user.ID, user.FullName, user.RoleID, sessionID, jti, s.tokens.AccessTTL)
 	if err != nil {
 		s.log.Errorw("jwt_generate_failed", "user_id", user.ID, "err", err)
-		return "", err
+		return nil, err
+	}
+	return &models.AuthResponse{
+		Token:        token,
+		RefreshToken: refresh,
+		ExpiresIn:    int(s.tokens.AccessTTL.Seconds()),
+	}, nil
+}
+
+// newRefreshToken — случайный токен для клиента и его хэш для базы: утечка таблицы не даёт входа
+func newRefreshToken() (token, hash string, err error) {
+	token, err = helpers.RandomToken(32)
+	if err != nil {
+		return "", "", err
 	}
+	return token, hashToken(token), nil
+}
 
-	s.log.Infow("user_login", "user_id", user.ID, "role_id", user.RoleID)
-	return token, nil
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// claimOrders забирает анонимные заказы с подтверждённым телефоном пользователя.
+// Ошибка не мешает входу — заказы привяжутся при следующем
+func (s *AuthService) claimOrders(ctx context.Context, user *models.User) {
+	if s.orders == nil || user.Phone == nil || user.PhoneVerifiedAt == nil {
+		return
+	}
+	n, err := s.orders.ClaimByPhone(ctx, user.ID, *user.Phone)
+	if err != nil {
+		s.log.Errorw("orders_claim_failed", "user_id", user.ID, "err", err)
+		return
+	}
+	if n > 0 {
+		s.log.Infow("orders_claimed", "user_id", user.ID, "count", n)
+	}
 }
 
 // UpdateProfile — обновляет профиль текущего пользователя
@@ -114,6 +336,17 @@ func (s *AuthService) UpdateProfile(ctx context.Context, id int, req models.Upda
 	if req.Avatar != nil {
 		u.Avatar = req.Avatar
 	}
+	if req.Phone != nil {
+		if *req.Phone == "" {
+			u.Phone = nil
+		} else {
+			num, err := parsePhone(*req.Phone)
+			if err != nil {
+				return nil, err
+			}
+			u.Phone = &num.E164
+		}
+	}
 
 	if err := s.repo.Update(ctx, u); err != nil {
 		return nil, err
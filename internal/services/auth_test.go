@@ -2,6 +2,8 @@ package services_test
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"testing"
 	"time"
 
@@ -9,8 +11,12 @@ import (
 	"github.com/Ramcache/travel-backend/internal/models"
 	"github.com/Ramcache/travel-backend/internal/repository"
 	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/Ramcache/travel-backend/internal/testutil"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zaptest"
 )
 
@@ -48,11 +54,28 @@ func (m *MockUserRepo) Delete(ctx context.Context, id int) error {
 	return m.Called(ctx, id).Error(0)
 }
 
+func newAuthService(t *testing.T, repo *MockUserRepo, orders services.OrderClaimer) (*services.AuthService, *testutil.MockDB) {
+	db := testutil.NewMockDB(t)
+	svc := services.NewAuthService(repo, orders, repository.NewSessionRepo(db), services.TokenConfig{
+		Secret:     "secret",
+		AccessTTL:  15 * time.Minute,
+		RefreshTTL: 24 * time.Hour,
+	}, zaptest.NewLogger(t).Sugar())
+	return svc, db
+}
+
+// expectSessionCreate — вход открывает сессию с ID id
+func expectSessionCreate(db *testutil.MockDB, id int64) {
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{id, db.Now(), db.Now()}), nil
+	})
+}
+
 // ---- Tests ----
 
 func TestAuthService_Register_InvalidInput(t *testing.T) {
 	repo := new(MockUserRepo)
-	svc := services.NewAuthService(repo, "secret", 24*time.Hour, zaptest.NewLogger(t).Sugar())
+	svc, _ := newAuthService(t, repo, nil)
 
 	u, err := svc.Register(context.Background(), models.RegisterRequest{})
 	assert.Nil(t, u)
@@ -61,7 +84,7 @@ func TestAuthService_Register_InvalidInput(t *testing.T) {
 
 func TestAuthService_Register_EmailTaken(t *testing.T) {
 	repo := new(MockUserRepo)
-	svc := services.NewAuthService(repo, "secret", 24*time.Hour, zaptest.NewLogger(t).Sugar())
+	svc, _ := newAuthService(t, repo, nil)
 
 	repo.On("GetByEmail", mock.Anything, "test@mail.com").
 		Return(&models.User{ID: 1}, nil)
@@ -75,7 +98,7 @@ func TestAuthService_Register_EmailTaken(t *testing.T) {
 
 func TestAuthService_Register_Success(t *testing.T) {
 	repo := new(MockUserRepo)
-	svc := services.NewAuthService(repo, "secret", 24*time.Hour, zaptest.NewLogger(t).Sugar())
+	svc, _ := newAuthService(t, repo, nil)
 
 	repo.On("GetByEmail", mock.Anything, "new@mail.com").
 		Return((*models.User)(nil), repository.ErrNotFound)
@@ -92,7 +115,7 @@ func TestAuthService_Register_Success(t *testing.T) {
 
 func TestAuthService_Login_UserNotFound(t *testing.T) {
 	repo := new(MockUserRepo)
-	svc := services.NewAuthService(repo, "secret", 24*time.Hour, zaptest.NewLogger(t).Sugar())
+	svc, _ := newAuthService(t, repo, nil)
 
 	repo.On("GetByEmail", mock.Anything, "a@b.com").
 		Return((*models.User)(nil), repository.ErrNotFound)
@@ -106,7 +129,7 @@ func TestAuthService_Login_UserNotFound(t *testing.T) {
 
 func TestAuthService_Login_InvalidPassword(t *testing.T) {
 	repo := new(MockUserRepo)
-	svc := services.NewAuthService(repo, "secret", 24*time.Hour, zaptest.NewLogger(t).Sugar())
+	svc, _ := newAuthService(t, repo, nil)
 
 	// bcrypt hash от "rightpass"
 	hash, _ := helpers.HashPassword("rightpass")
@@ -122,7 +145,8 @@ func TestAuthService_Login_InvalidPassword(t *testing.T) {
 
 func TestAuthService_Login_Success(t *testing.T) {
 	repo := new(MockUserRepo)
-	svc := services.NewAuthService(repo, "secret", 24*time.Hour, zaptest.NewLogger(t).Sugar())
+	svc, db := newAuthService(t, repo, nil)
+	expectSessionCreate(db, 5)
 
 	hash, _ := helpers.HashPassword("123456")
 	repo.On("GetByEmail", mock.Anything, "ok@mail.com").
@@ -137,7 +161,7 @@ func TestAuthService_Login_Success(t *testing.T) {
 
 func TestAuthService_UpdateProfile_Success(t *testing.T) {
 	repo := new(MockUserRepo)
-	svc := services.NewAuthService(repo, "secret", 24*time.Hour, zaptest.NewLogger(t).Sugar())
+	svc, _ := newAuthService(t, repo, nil)
 
 	old := &models.User{ID: 1, FullName: "Old"}
 	repo.On("GetByID", mock.Anything, 1).Return(old, nil)
@@ -152,7 +176,7 @@ func TestAuthService_UpdateProfile_Success(t *testing.T) {
 
 func TestAuthService_GetByID_NotFound(t *testing.T) {
 	repo := new(MockUserRepo)
-	svc := services.NewAuthService(repo, "secret", 24*time.Hour, zaptest.NewLogger(t).Sugar())
+	svc, _ := newAuthService(t, repo, nil)
 
 	repo.On("GetByID", mock.Anything, 99).Return((*models.User)(nil), repository.ErrNotFound)
 
@@ -160,3 +184,239 @@ func TestAuthService_GetByID_NotFound(t *testing.T) {
 	assert.Nil(t, u)
 	assert.Error(t, err)
 }
+
+type MockOrderClaimer struct{ mock.Mock }
+
+func (m *MockOrderClaimer) ClaimByPhone(ctx context.Context, userID int, phone string) (int, error) {
+	args := m.Called(ctx, userID, phone)
+	return args.Int(0), args.Error(1)
+}
+
+func TestAuthService_Login_ClaimsOrdersByVerifiedPhone(t *testing.T) {
+	repo := new(MockUserRepo)
+	orders := new(MockOrderClaimer)
+	svc, db := newAuthService(t, repo, orders)
+	expectSessionCreate(db, 5)
+	expectSessionCreate(db, 6)
+
+	hash, _ := helpers.HashPassword("123456")
+	phone := "+79281234567"
+	verified := time.Now()
+	repo.On("GetByEmail", mock.Anything, "ok@mail.com").
+		Return(&models.User{ID: 1, Password: hash, Phone: &phone, PhoneVerifiedAt: &verified}, nil)
+	repo.On("GetByEmail", mock.Anything, "new@mail.com").
+		Return(&models.User{ID: 2, Password: hash, Phone: &phone}, nil)
+	orders.On("ClaimByPhone", mock.Anything, 1, phone).Return(2, nil)
+
+	_, err := svc.Login(context.Background(), models.LoginRequest{Email: "ok@mail.com", Password: "123456"})
+	assert.NoError(t, err)
+
+	// неподтверждённый номер заказы не забирает
+	_, err = svc.Login(context.Background(), models.LoginRequest{Email: "new@mail.com", Password: "123456"})
+	assert.NoError(t, err)
+	orders.AssertNumberOfCalls(t, "ClaimByPhone", 1)
+}
+
+func TestAuthService_UpdateProfile_Phone(t *testing.T) {
+	repo := new(MockUserRepo)
+	svc, _ := newAuthService(t, repo, nil)
+
+	u := &models.User{ID: 1}
+	repo.On("GetByID", mock.Anything, 1).Return(u, nil)
+	repo.On("Update", mock.Anything, u).Return(nil)
+
+	raw := "8 (928) 123-45-67"
+	updated, err := svc.UpdateProfile(context.Background(), 1, models.UpdateProfileRequest{Phone: &raw})
+	assert.NoError(t, err)
+	assert.Equal(t, "+79281234567", *updated.Phone)
+
+	bad := "123"
+	_, err = svc.UpdateProfile(context.Background(), 1, models.UpdateProfileRequest{Phone: &bad})
+	assert.True(t, helpers.IsInvalidInput(err))
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// sessionRow — строка auth_sessions для GetByRefreshHash; current=false — токен уже обменяли
+func sessionRow(db *testutil.MockDB, expiresAt time.Time, revokedAt *time.Time, current bool) []any {
+	return []any{int64(5), 1, db.Now(), db.Now(), expiresAt, revokedAt, current}
+}
+
+func TestAuthService_Login_OpensSession(t *testing.T) {
+	repo := new(MockUserRepo)
+	svc, db := newAuthService(t, repo, nil)
+
+	hash, _ := helpers.HashPassword("123456")
+	repo.On("GetByEmail", mock.Anything, "ok@mail.com").
+		Return(&models.User{ID: 1, FullName: "Ок", RoleID: 2, Password: hash}, nil)
+
+	var stored []any
+	db.ExpectQueryRow(func(_ context.Context, sql string, args []any) (pgx.Row, error) {
+		assert.Contains(t, sql, "INSERT INTO auth_sessions")
+		stored = args
+		return testutil.NewSliceRow([]any{int64(5), db.Now(), db.Now()}), nil
+	})
+
+	resp, err := svc.Login(context.Background(), models.LoginRequest{Email: "ok@mail.com", Password: "123456"})
+	require.NoError(t, err)
+	db.Verify(t)
+	assert.Equal(t, 900, resp.ExpiresIn)
+
+	// в базе — только хэш refresh-токена
+	assert.Equal(t, 1, stored[0])
+	assert.Equal(t, sha256Hex(resp.RefreshToken), stored[2])
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), stored[3].(time.Time), time.Minute)
+
+	claims, err := helpers.ParseJWT("secret", resp.Token)
+	require.NoError(t, err)
+	assert.Equal(t, float64(5), claims["sid"])
+	assert.Equal(t, stored[1], claims["jti"])
+	assert.Equal(t, float64(2), claims["role_id"])
+}
+
+func TestAuthService_Refresh_Rotates(t *testing.T) {
+	repo := new(MockUserRepo)
+	svc, db := newAuthService(t, repo, nil)
+	repo.On("GetByID", mock.Anything, 1).Return(&models.User{ID: 1, RoleID: 1}, nil)
+
+	db.ExpectQueryRow(func(_ context.Context, _ string, args []any) (pgx.Row, error) {
+		assert.Equal(t, []any{sha256Hex("old-refresh")}, args)
+		return testutil.NewSliceRow(sessionRow(db, time.Now().Add(time.Hour), nil, true)), nil
+	})
+	var rotated []any
+	db.ExpectExec(func(_ context.Context, sql string, args []any) (pgconn.CommandTag, error) {
+		assert.Contains(t, sql, "previous_refresh_hash = refresh_hash")
+		rotated = args
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+
+	resp, err := svc.Refresh(context.Background(), "old-refresh")
+	require.NoError(t, err)
+	db.Verify(t)
+
+	assert.Equal(t, int64(5), rotated[0])
+	assert.Equal(t, sha256Hex("old-refresh"), rotated[1])
+	assert.Equal(t, sha256Hex(resp.RefreshToken), rotated[2])
+	assert.NotEqual(t, "old-refresh", resp.RefreshToken)
+
+	claims, err := helpers.ParseJWT("secret", resp.Token)
+	require.NoError(t, err)
+	assert.Equal(t, rotated[3], claims["jti"])
+}
+
+func TestAuthService_Refresh_ReuseRevokesSession(t *testing.T) {
+	repo := new(MockUserRepo)
+	svc, db := newAuthService(t, repo, nil)
+
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow(sessionRow(db, time.Now().Add(time.Hour), nil, false)), nil
+	})
+	db.ExpectExec(func(_ context.Context, sql string, args []any) (pgconn.CommandTag, error) {
+		assert.Contains(t, sql, "SET revoked_at = now()")
+		assert.Equal(t, []any{int64(5), 1}, args)
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+
+	resp, err := svc.Refresh(context.Background(), "stolen")
+	assert.Nil(t, resp)
+	assert.ErrorIs(t, err, services.ErrInvalidRefreshToken)
+	db.Verify(t)
+	repo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
+func TestAuthService_Refresh_Rejected(t *testing.T) {
+	revoked := time.Now().Add(-time.Minute)
+	for name, row := range map[string]func(*testutil.MockDB) pgx.Row{
+		"unknown": func(*testutil.MockDB) pgx.Row { return nil },
+		"expired": func(db *testutil.MockDB) pgx.Row {
+			return testutil.NewSliceRow(sessionRow(db, time.Now().Add(-time.Minute), nil, true))
+		},
+		"revoked": func(db *testutil.MockDB) pgx.Row {
+			return testutil.NewSliceRow(sessionRow(db, time.Now().Add(time.Hour), &revoked, true))
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			repo := new(MockUserRepo)
+			svc, db := newAuthService(t, repo, nil)
+			db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+				if r := row(db); r != nil {
+					return r, nil
+				}
+				return nil, pgx.ErrNoRows
+			})
+
+			_, err := svc.Refresh(context.Background(), "token")
+			assert.ErrorIs(t, err, services.ErrInvalidRefreshToken)
+			db.Verify(t)
+		})
+	}
+}
+
+func TestAuthService_Refresh_ConcurrentRotation(t *testing.T) {
+	repo := new(MockUserRepo)
+	svc, db := newAuthService(t, repo, nil)
+	repo.On("GetByID", mock.Anything, 1).Return(&models.User{ID: 1, RoleID: 1}, nil)
+
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow(sessionRow(db, time.Now().Add(time.Hour), nil, true)), nil
+	})
+	// тот же токен уже обменял параллельный запрос
+	db.ExpectExec(func(context.Context, string, []any) (pgconn.CommandTag, error) {
+		return pgconn.NewCommandTag("UPDATE 0"), nil
+	})
+
+	_, err := svc.Refresh(context.Background(), "token")
+	assert.ErrorIs(t, err, services.ErrInvalidRefreshToken)
+	db.Verify(t)
+}
+
+func TestAuthService_ValidateSession(t *testing.T) {
+	repo := new(MockUserRepo)
+	svc, db := newAuthService(t, repo, nil)
+
+	db.ExpectQueryRow(func(_ context.Context, sql string, args []any) (pgx.Row, error) {
+		assert.Contains(t, sql, "s.revoked_at IS NULL")
+		assert.Equal(t, []any{int64(5), "jti-1"}, args)
+		return testutil.NewSliceRow([]any{1, 2, []string{"orders.read", "orders.write"}}), nil
+	})
+	// сессия отозвана или пользователь удалён
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return nil, pgx.ErrNoRows
+	})
+
+	p, err := svc.ValidateSession(context.Background(), 5, "jti-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, p.UserID)
+	assert.Equal(t, 2, p.RoleID)
+	assert.Equal(t, []string{"orders.read", "orders.write"}, p.Permissions)
+
+	_, err = svc.ValidateSession(context.Background(), 5, "jti-1")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	db.Verify(t)
+}
+
+func TestAuthService_Logout(t *testing.T) {
+	repo := new(MockUserRepo)
+	svc, db := newAuthService(t, repo, nil)
+
+	db.ExpectExec(func(_ context.Context, _ string, args []any) (pgconn.CommandTag, error) {
+		assert.Equal(t, []any{int64(5), 1}, args)
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+	db.ExpectExec(func(context.Context, string, []any) (pgconn.CommandTag, error) {
+		return pgconn.NewCommandTag("UPDATE 0"), nil
+	})
+	db.ExpectExec(func(_ context.Context, sql string, args []any) (pgconn.CommandTag, error) {
+		assert.Contains(t, sql, "WHERE user_id = $1 AND revoked_at IS NULL")
+		assert.Equal(t, []any{1}, args)
+		return pgconn.NewCommandTag("UPDATE 3"), nil
+	})
+
+	require.NoError(t, svc.Logout(context.Background(), 5, 1))
+	assert.ErrorIs(t, svc.Logout(context.Background(), 5, 1), services.ErrSessionNotFound)
+	require.NoError(t, svc.LogoutAll(context.Background(), 1))
+	db.Verify(t)
+}
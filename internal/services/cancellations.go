@@ -0,0 +1,535 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/notifications"
+	"github.com/Ramcache/travel-backend/internal/payments"
+	"github.com/Ramcache/travel-backend/internal/repository"
+)
+
+var (
+	ErrCancellationNotAllowed = errors.New("order can not be cancelled in its current status")
+	ErrCancellationPending    = errors.New("cancellation already requested")
+	ErrCancellationNotFound   = errors.New("no pending cancellation request")
+	// ErrCancellationInProgress — по заявке уже принимается решение в другом запросе
+	ErrCancellationInProgress = errors.New("cancellation request is being processed")
+	ErrTripAlreadyStarted     = errors.New("trip has already started")
+)
+
+// CancellationService — отмена заказа клиентом: расчёт штрафа по правилам тура,
+// заявка на отмену и её рассмотрение менеджером с возвратом через платёжного провайдера
+type CancellationService struct {
+	repo        *repository.CancellationRepo
+	orderRepo   *repository.OrderRepo
+	orders      *OrderService
+	trips       repository.TripRepositoryI
+	paymentRepo *repository.PaymentRepo
+	payments    *PaymentService
+	notify      *NotificationService
+	log         *zap.SugaredLogger
+
+	now func() time.Time
+}
+
+func NewCancellationService(
+	repo *repository.CancellationRepo,
+	orderRepo *repository.OrderRepo,
+	orders *OrderService,
+	trips repository.TripRepositoryI,
+	paymentRepo *repository.PaymentRepo,
+	payments *PaymentService,
+	notify *NotificationService,
+	log *zap.SugaredLogger,
+) *CancellationService {
+	return &CancellationService{
+		repo:        repo,
+		orderRepo:   orderRepo,
+		orders:      orders,
+		trips:       trips,
+		paymentRepo: paymentRepo,
+		payments:    payments,
+		notify:      notify,
+		log:         log,
+		now:         time.Now,
+	}
+}
+
+// ---- правила тура ----
+
+func (s *CancellationService) Rules(ctx context.Context, tripID int) ([]models.TripCancellationRule, error) {
+	if _, err := s.getTrip(ctx, tripID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListRules(ctx, tripID)
+}
+
+func (s *CancellationService) SetRules(ctx context.Context, tripID int, reqs []models.TripCancellationRuleRequest) ([]models.TripCancellationRule, error) {
+	if _, err := s.getTrip(ctx, tripID); err != nil {
+		return nil, err
+	}
+	rules, err := BuildCancellationRules(reqs)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceRules(ctx, tripID, rules); err != nil {
+		return nil, err
+	}
+	s.log.Infow("trip_cancellation_rules_updated", "trip_id", tripID, "count", len(rules))
+	return rules, nil
+}
+
+// BuildCancellationRules проверяет ступени штрафа и сортирует их от дальней к ближней.
+// Пустой список — отмена без штрафа.
+func BuildCancellationRules(reqs []models.TripCancellationRuleRequest) ([]models.TripCancellationRule, error) {
+	rules := make([]models.TripCancellationRule, 0, len(reqs))
+	seen := make(map[int]bool, len(reqs))
+	for i, req := range reqs {
+		n := i + 1
+		if req.DaysBefore < 0 {
+			return nil, helpers.ErrInvalidInput(fmt.Sprintf("rule %d: days_before must not be negative", n))
+		}
+		if req.PenaltyPercent < 0 || req.PenaltyPercent > 100 {
+			return nil, helpers.ErrInvalidInput(fmt.Sprintf("rule %d: penalty_percent must be in [0, 100]", n))
+		}
+		if seen[req.DaysBefore] {
+			return nil, helpers.ErrInvalidInput(fmt.Sprintf("rule %d: duplicate days_before %d", n, req.DaysBefore))
+		}
+		seen[req.DaysBefore] = true
+		rules = append(rules, models.TripCancellationRule{
+			DaysBefore:     req.DaysBefore,
+			PenaltyPercent: req.PenaltyPercent,
+		})
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].DaysBefore > rules[j].DaysBefore })
+	return rules, nil
+}
+
+// CancellationPenalty — процент штрафа при отмене за daysBefore дней до начала тура.
+// Действует ступень с наибольшим days_before, не превышающим daysBefore.
+// Без правил штрафа нет; если отмена позже самой ближней ступени — удерживается всё.
+func CancellationPenalty(rules []models.TripCancellationRule, daysBefore int) float64 {
+	if len(rules) == 0 {
+		return 0
+	}
+	best := -1
+	for i, rule := range rules {
+		if rule.DaysBefore <= daysBefore && (best < 0 || rule.DaysBefore > rules[best].DaysBefore) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return 100
+	}
+	return rules[best].PenaltyPercent
+}
+
+// ---- клиент ----
+
+// QuoteOwn — расчёт возврата по заказу текущего пользователя
+func (s *CancellationService) QuoteOwn(ctx context.Context, userID, orderID int) (*models.CancellationQuote, error) {
+	order, err := s.getOwn(ctx, userID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return s.quoteWithRequest(ctx, order)
+}
+
+// QuoteByToken — расчёт возврата по публичной ссылке на заказ
+func (s *CancellationService) QuoteByToken(ctx context.Context, token string) (*models.CancellationQuote, error) {
+	order, err := s.getByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return s.quoteWithRequest(ctx, order)
+}
+
+// RequestOwn — заявка на отмену заказа из личного кабинета
+func (s *CancellationService) RequestOwn(ctx context.Context, userID, orderID int, reason string) (*models.CancellationQuote, error) {
+	order, err := s.getOwn(ctx, userID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return s.request(ctx, order, models.CancellationSourceProfile, reason, models.Actor{ID: &userID, Name: "client"})
+}
+
+// RequestByToken — заявка на отмену заказа по публичной ссылке
+func (s *CancellationService) RequestByToken(ctx context.Context, token, reason string) (*models.CancellationQuote, error) {
+	order, err := s.getByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return s.request(ctx, order, models.CancellationSourceTracking, reason, models.Actor{Name: "client"})
+}
+
+func (s *CancellationService) request(ctx context.Context, order *models.Order, source, reason string, actor models.Actor) (*models.CancellationQuote, error) {
+	if order.Status == models.OrderStatusCancellationRequested {
+		return nil, ErrCancellationPending
+	}
+	if !models.CanTransitionOrder(order.Status, models.OrderStatusCancellationRequested) {
+		return nil, ErrCancellationNotAllowed
+	}
+
+	q, trip, err := s.quote(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+	if q.DaysBefore != nil && *q.DaysBefore < 0 {
+		return nil, ErrTripAlreadyStarted
+	}
+
+	c := &models.OrderCancellation{
+		OrderID:        order.ID,
+		Source:         source,
+		PreviousStatus: order.Status,
+		DaysBefore:     q.DaysBefore,
+		PaidAmount:     q.PaidAmount,
+		PenaltyPercent: q.PenaltyPercent,
+		PenaltyAmount:  q.PenaltyAmount,
+		RefundAmount:   q.RefundAmount,
+	}
+	if r := strings.TrimSpace(reason); r != "" {
+		c.Reason = &r
+	}
+	comment := "Клиент запросил отмену"
+	if c.Reason != nil {
+		comment += ": " + *c.Reason
+	}
+	// заявка без смены статуса не нужна — менеджер её не увидит в заказе; поэтому одной транзакцией,
+	// и уведомление менеджерам — в той же транзакции через outbox
+	err = s.repo.Tx(ctx, func(tx repository.DB) error {
+		if err := s.repo.WithTx(tx).Create(ctx, c); err != nil {
+			return err
+		}
+		if err := s.orders.ChangeStatusTx(ctx, tx, order.ID, models.OrderStatusCancellationRequested, actor, comment); err != nil {
+			return err
+		}
+		return s.notify.Enqueue(ctx, tx, notifications.CancellationRequested{Order: order, Trip: trip, Cancellation: c, At: s.now()})
+	})
+	if errors.Is(err, ErrInvalidTransition) {
+		return nil, ErrCancellationNotAllowed
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.log.Infow("order_cancellation_requested", "order_id", order.ID, "source", source,
+		"penalty_percent", c.PenaltyPercent, "refund", c.RefundAmount)
+
+	q.OrderStatus = models.OrderStatusCancellationRequested
+	q.Cancellable = false
+	q.Request = c
+	return q, nil
+}
+
+// ---- админка ----
+
+type CancellationsWithTotal struct {
+	Total         int                           `json:"total"`
+	Cancellations []models.CancellationListItem `json:"cancellations"`
+}
+
+// List — заявки на отмену; status == "" — все
+func (s *CancellationService) List(ctx context.Context, status string, limit, offset int) (*CancellationsWithTotal, error) {
+	switch status {
+	case "", models.CancellationPending, models.CancellationProcessing, models.CancellationApproved, models.CancellationRejected:
+	default:
+		return nil, helpers.ErrInvalidInput(fmt.Sprintf("invalid status %q", status))
+	}
+
+	total, err := s.repo.Count(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.repo.List(ctx, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		list = []models.CancellationListItem{}
+	}
+	return &CancellationsWithTotal{Total: total, Cancellations: list}, nil
+}
+
+// Approve одобряет отмену: возвращает деньги через провайдера по успешным платежам заказа
+// и закрывает заказ (refunded, если оплаченного не осталось, иначе cancelled).
+// Сумма, которую не удалось вернуть через провайдера (оплата вне системы), возвращается вручную.
+// Заявка забирается до первого возврата: параллельное одобрение получит ErrCancellationInProgress.
+func (s *CancellationService) Approve(ctx context.Context, orderID int, req models.ApproveCancellationRequest, actor models.Actor) (_ *models.OrderCancellation, err error) {
+	c, err := s.claim(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer s.releaseOnError(ctx, c, &err)
+
+	paid, err := s.paymentRepo.PaidAmount(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	// штраф зафиксирован при подаче заявки; после частичного сбоя повтор вернёт только остаток
+	refund := roundMoney(paid - c.PenaltyAmount)
+	if req.RefundAmount != nil {
+		refund = roundMoney(*req.RefundAmount)
+		if refund < 0 || refund > paid+moneyEpsilon {
+			return nil, helpers.ErrInvalidInput(fmt.Sprintf("refund_amount must be between 0 and %.2f", paid))
+		}
+	}
+	if refund < 0 {
+		refund = 0
+	}
+
+	comment := strings.TrimSpace(req.Comment)
+	if comment == "" {
+		comment = fmt.Sprintf("Отмена по заявке клиента, возврат %.2f", refund)
+	}
+
+	refunded, err := s.refund(ctx, orderID, refund, actor, comment)
+	if err != nil {
+		return nil, err
+	}
+
+	// при полном возврате PaymentService уже перевёл заказ в refunded
+	status, err := s.orderRepo.GetStatus(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if status == models.OrderStatusCancellationRequested {
+		if err := s.orders.ChangeStatus(ctx, orderID, models.OrderStatusCancelled, actor, comment); err != nil {
+			return nil, err
+		}
+	}
+
+	c.Status = models.CancellationApproved
+	c.RefundAmount = refund
+	c.RefundedAmount = refunded
+	c.DecidedBy = actor.ID
+	c.Comment = &comment
+	if err := s.repo.Decide(ctx, c); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrCancellationNotFound
+		}
+		return nil, err
+	}
+
+	if manual := roundMoney(refund - refunded); manual > moneyEpsilon {
+		s.log.Warnw("cancellation_manual_refund_required", "order_id", orderID, "amount", manual)
+	}
+	s.log.Infow("order_cancellation_approved", "order_id", orderID, "refund", refund, "refunded", refunded)
+	return c, nil
+}
+
+// Reject отклоняет заявку и возвращает заказ в прежний статус
+func (s *CancellationService) Reject(ctx context.Context, orderID int, comment string, actor models.Actor) (_ *models.OrderCancellation, err error) {
+	c, err := s.claim(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer s.releaseOnError(ctx, c, &err)
+
+	comment = strings.TrimSpace(comment)
+	historyComment := "Отмена отклонена"
+	if comment != "" {
+		historyComment += ": " + comment
+	}
+	if err := s.orders.RestoreStatus(ctx, orderID, c.PreviousStatus, actor, historyComment); err != nil {
+		return nil, err
+	}
+
+	c.Status = models.CancellationRejected
+	c.RefundAmount = 0
+	c.DecidedBy = actor.ID
+	if comment != "" {
+		c.Comment = &comment
+	}
+	if err := s.repo.Decide(ctx, c); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrCancellationNotFound
+		}
+		return nil, err
+	}
+	s.log.Infow("order_cancellation_rejected", "order_id", orderID)
+	return c, nil
+}
+
+// refund возвращает amount через провайдера по успешным платежам заказа, начиная с последних.
+// Возвращает сумму, фактически возвращённую через провайдера.
+func (s *CancellationService) refund(ctx context.Context, orderID int, amount float64, actor models.Actor, comment string) (float64, error) {
+	if amount <= 0 {
+		return 0, nil
+	}
+	list, err := s.paymentRepo.ListByOrder(ctx, orderID)
+	if err != nil {
+		return 0, err
+	}
+
+	var refunded float64
+	for i := len(list) - 1; i >= 0; i-- {
+		left := roundMoney(amount - refunded)
+		if left <= 0 {
+			break
+		}
+		p := list[i]
+		if p.Status != payments.StatusSucceeded || p.ExternalID == nil {
+			continue
+		}
+		available := roundMoney(p.Amount - p.RefundedAmount)
+		if available <= 0 {
+			continue
+		}
+		value := min(left, available)
+		if _, err := s.payments.Refund(ctx, p.ID, &value, actor, comment); err != nil {
+			return refunded, fmt.Errorf("refund payment %d: %w", p.ID, err)
+		}
+		refunded = roundMoney(refunded + value)
+	}
+	return refunded, nil
+}
+
+// ---- расчёт ----
+
+func (s *CancellationService) quoteWithRequest(ctx context.Context, order *models.Order) (*models.CancellationQuote, error) {
+	q, _, err := s.quote(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+	last, err := s.repo.GetLatest(ctx, order.ID)
+	switch {
+	case err == nil:
+		q.Request = last
+	case !errors.Is(err, repository.ErrNotFound):
+		return nil, err
+	}
+	return q, nil
+}
+
+// quote считает штраф и возврат на сегодня. Штраф берётся от стоимости заказа
+// (или от оплаченного, если стоимость не рассчитана) и не превышает оплаченного.
+func (s *CancellationService) quote(ctx context.Context, order *models.Order) (*models.CancellationQuote, *models.Trip, error) {
+	q := &models.CancellationQuote{
+		OrderID:     order.ID,
+		OrderStatus: order.Status,
+		Cancellable: models.CanTransitionOrder(order.Status, models.OrderStatusCancellationRequested),
+		Rules:       []models.TripCancellationRule{},
+	}
+
+	paid, err := s.paymentRepo.PaidAmount(ctx, order.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	q.PaidAmount = roundMoney(paid)
+
+	var trip *models.Trip
+	if order.TripID.Valid {
+		trip, err = s.getTrip(ctx, int(order.TripID.Int32))
+		if err != nil && !errors.Is(err, ErrTripNotFound) {
+			return nil, nil, err
+		}
+	}
+	if trip != nil {
+		rules, err := s.repo.ListRules(ctx, trip.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if rules != nil {
+			q.Rules = rules
+		}
+
+		start := trip.StartDate
+		days := daysUntil(s.now(), start)
+		q.TripStart = &start
+		q.DaysBefore = &days
+		if days < 0 {
+			q.Cancellable = false
+		}
+		q.PenaltyPercent = CancellationPenalty(q.Rules, days)
+	}
+
+	base := q.PaidAmount
+	if order.TotalPrice != nil {
+		base = *order.TotalPrice
+	}
+	q.PenaltyAmount = min(roundMoney(base*q.PenaltyPercent/100), q.PaidAmount)
+	q.RefundAmount = roundMoney(q.PaidAmount - q.PenaltyAmount)
+	return q, trip, nil
+}
+
+// daysUntil — сколько календарных дней осталось от now до start (отрицательное — тур уже начался)
+func daysUntil(now, start time.Time) int {
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	to := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// ---- вспомогательное ----
+
+// claim забирает нерассмотренную заявку в processing: решение по ней принимает только один запрос
+func (s *CancellationService) claim(ctx context.Context, orderID int) (*models.OrderCancellation, error) {
+	c, err := s.getPending(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := s.repo.Claim(ctx, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrCancellationInProgress
+	}
+	c.Status = models.CancellationProcessing
+	return c, nil
+}
+
+// releaseOnError возвращает заявку в pending, если решение сорвалось, — повтор сможет её забрать
+func (s *CancellationService) releaseOnError(ctx context.Context, c *models.OrderCancellation, err *error) {
+	if *err == nil {
+		return
+	}
+	if rerr := s.repo.Release(context.WithoutCancel(ctx), c.ID); rerr != nil {
+		s.log.Errorw("order_cancellation_release_failed", "order_id", c.OrderID, "cancellation_id", c.ID, "err", rerr)
+	}
+}
+
+// getPending — последняя заявка по заказу, пока решение по ней не принято
+func (s *CancellationService) getPending(ctx context.Context, orderID int) (*models.OrderCancellation, error) {
+	if _, err := s.orders.GetByID(ctx, orderID); err != nil {
+		return nil, err
+	}
+	c, err := s.repo.GetLatest(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrCancellationNotFound
+		}
+		return nil, err
+	}
+	if c.Status != models.CancellationPending && c.Status != models.CancellationProcessing {
+		return nil, ErrCancellationNotFound
+	}
+	return c, nil
+}
+
+func (s *CancellationService) getOwn(ctx context.Context, userID, orderID int) (*models.Order, error) {
+	return findOwnOrder(ctx, s.orderRepo, userID, orderID)
+}
+
+func (s *CancellationService) getByToken(ctx context.Context, token string) (*models.Order, error) {
+	return findOrderByToken(ctx, s.orderRepo, token)
+}
+
+func (s *CancellationService) getTrip(ctx context.Context, tripID int) (*models.Trip, error) {
+	trip, err := s.trips.GetByID(ctx, tripID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrTripNotFound
+		}
+		return nil, err
+	}
+	return trip, nil
+}
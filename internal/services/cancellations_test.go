@@ -0,0 +1,331 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/payments"
+	"github.com/Ramcache/travel-backend/internal/repository"
+	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/Ramcache/travel-backend/internal/testutil"
+)
+
+func newCancellationService(db *testutil.MockDB, trips repository.TripRepositoryI, provider payments.Provider) *services.CancellationService {
+	log := zap.NewNop().Sugar()
+	orderRepo := repository.NewOrderRepo(db)
+	orders := services.NewOrderService(orderRepo, nil)
+	paymentRepo := repository.NewPaymentRepo(db)
+	schedules := services.NewPaymentScheduleService(repository.NewPaymentScheduleRepo(db), orderRepo, nil, nil, 0, log)
+	paymentService := services.NewPaymentService(paymentRepo, orders, schedules, provider, "", log)
+	return services.NewCancellationService(repository.NewCancellationRepo(db), orderRepo, orders, trips,
+		paymentRepo, paymentService, nil, log)
+}
+
+func pendingCancellationRow(id, orderID int, previous string, penalty float64) []any {
+	return []any{id, orderID, models.CancellationSourceProfile, nil, previous, nil,
+		1000.0, 50.0, penalty, 1000.0 - penalty, 0.0, models.CancellationPending,
+		nil, nil, nil, time.Now()}
+}
+
+// expectCancellationClaim — заявка забирается в processing перед решением
+func expectCancellationClaim(t *testing.T, db *testutil.MockDB, id int, ok bool) {
+	db.ExpectExec(func(_ context.Context, sql string, args []any) (pgconn.CommandTag, error) {
+		assert.Contains(t, sql, "status='processing'")
+		assert.Equal(t, []any{id}, args)
+		if !ok {
+			return pgconn.NewCommandTag("UPDATE 0"), nil
+		}
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+}
+
+func TestCancellationPenalty(t *testing.T) {
+	rules := []models.TripCancellationRule{
+		{DaysBefore: 30, PenaltyPercent: 0},
+		{DaysBefore: 14, PenaltyPercent: 25},
+		{DaysBefore: 3, PenaltyPercent: 50},
+	}
+
+	tests := []struct {
+		days int
+		want float64
+	}{
+		{days: 45, want: 0},
+		{days: 30, want: 0},
+		{days: 29, want: 25},
+		{days: 14, want: 25},
+		{days: 5, want: 50},
+		{days: 3, want: 50},
+		// позже самой ближней ступени — удерживается всё
+		{days: 1, want: 100},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, services.CancellationPenalty(rules, tt.days), "days=%d", tt.days)
+	}
+
+	assert.Equal(t, 0.0, services.CancellationPenalty(nil, 0), "no rules — no penalty")
+}
+
+func TestBuildCancellationRules(t *testing.T) {
+	rules, err := services.BuildCancellationRules([]models.TripCancellationRuleRequest{
+		{DaysBefore: 7, PenaltyPercent: 50},
+		{DaysBefore: 30, PenaltyPercent: 10},
+	})
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Equal(t, 30, rules[0].DaysBefore)
+	assert.Equal(t, 7, rules[1].DaysBefore)
+
+	_, err = services.BuildCancellationRules([]models.TripCancellationRuleRequest{{DaysBefore: 7, PenaltyPercent: 120}})
+	assert.True(t, helpers.IsInvalidInput(err))
+
+	_, err = services.BuildCancellationRules([]models.TripCancellationRuleRequest{
+		{DaysBefore: 7, PenaltyPercent: 10},
+		{DaysBefore: 7, PenaltyPercent: 20},
+	})
+	assert.True(t, helpers.IsInvalidInput(err))
+}
+
+func TestCancellationService_RequestOwn(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	trips := new(MockTripRepo)
+	svc := newCancellationService(db, trips, payments.NewFakeProvider("secret"))
+
+	start := time.Now().AddDate(0, 0, 20)
+	trips.On("GetByID", mock.Anything, 5).Return(&models.Trip{ID: 5, Title: "Умра", StartDate: start}, nil)
+
+	row := ownedOrderRow(7, 1, models.OrderStatusPaid, 1000)
+	row[1] = models.NullInt32{NullInt32: sql.NullInt32{Int32: 5, Valid: true}}
+	expectOrder(db, row)
+	// оплачено полностью
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{1000.0}), nil
+	})
+	// правила тура: за 20 дней действует ступень «14 дней — 30%»
+	db.ExpectQuery(func(_ context.Context, _ string, args []any) (pgx.Rows, error) {
+		assert.Equal(t, []any{5}, args)
+		return testutil.NewMockRows([][]any{
+			{1, 5, 30, 0.0},
+			{2, 5, 14, 30.0},
+		}), nil
+	})
+	db.ExpectQueryRow(func(_ context.Context, _ string, args []any) (pgx.Row, error) {
+		assert.Equal(t, 7, args[0])
+		assert.Equal(t, models.CancellationSourceProfile, args[1])
+		assert.Equal(t, "Заболел", *args[2].(*string))
+		assert.Equal(t, models.OrderStatusPaid, args[3])
+		assert.Equal(t, 20, *args[4].(*int))
+		assert.Equal(t, []any{1000.0, 30.0, 300.0, 700.0}, args[5:])
+		return testutil.NewSliceRow([]any{11, models.CancellationPending, time.Now()}), nil
+	})
+	// смена статуса paid → cancellation_requested
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{models.OrderStatusPaid}), nil
+	})
+	db.ExpectExec(func(_ context.Context, _ string, args []any) (pgconn.CommandTag, error) {
+		assert.Equal(t, []any{models.OrderStatusCancellationRequested, 7, models.OrderStatusPaid}, args)
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{1, time.Now()}), nil
+	})
+
+	q, err := svc.RequestOwn(context.Background(), 1, 7, " Заболел ")
+	require.NoError(t, err)
+	assert.Equal(t, models.OrderStatusCancellationRequested, q.OrderStatus)
+	assert.False(t, q.Cancellable)
+	assert.Equal(t, 300.0, q.PenaltyAmount)
+	assert.Equal(t, 700.0, q.RefundAmount)
+	require.NotNil(t, q.Request)
+	assert.Equal(t, 11, q.Request.ID)
+	db.Verify(t)
+	trips.AssertExpectations(t)
+}
+
+func TestCancellationService_RequestRejectsClosedOrder(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newCancellationService(db, nil, payments.NewFakeProvider("secret"))
+
+	expectOrder(db, ownedOrderRow(7, 1, models.OrderStatusCompleted, 1000))
+	_, err := svc.RequestOwn(context.Background(), 1, 7, "")
+	assert.ErrorIs(t, err, services.ErrCancellationNotAllowed)
+
+	expectOrder(db, ownedOrderRow(7, 1, models.OrderStatusCancellationRequested, 1000))
+	_, err = svc.RequestOwn(context.Background(), 1, 7, "")
+	assert.ErrorIs(t, err, services.ErrCancellationPending)
+	db.Verify(t)
+}
+
+func TestCancellationService_ApproveRefundsThroughProvider(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewMockDB(t)
+	provider := payments.NewFakeProvider("secret")
+	svc := newCancellationService(db, nil, provider)
+
+	created, err := provider.Create(ctx, payments.CreateRequest{Amount: 1000, IdempotencyKey: "order-7"})
+	require.NoError(t, err)
+	_, err = provider.ParseWebhook(provider.Webhook("ev-1", created.ExternalID, payments.StatusSucceeded, 1000))
+	require.NoError(t, err)
+
+	expectOrder(db, orderRow(7, models.OrderStatusCancellationRequested, 1000))
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow(pendingCancellationRow(11, 7, models.OrderStatusPaid, 300)), nil
+	})
+	expectCancellationClaim(t, db, 11, true)
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{1000.0}), nil
+	})
+	db.ExpectQuery(func(context.Context, string, []any) (pgx.Rows, error) {
+		return testutil.NewMockRows([][]any{paymentRow(3, 7, created.ExternalID, payments.StatusSucceeded, 1000)}), nil
+	})
+	// возврат 700 через провайдера
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow(paymentRow(3, 7, created.ExternalID, payments.StatusSucceeded, 1000)), nil
+	})
+	// возврат записывается в pending до запроса к провайдеру и проводится после ответа
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return nil, pgx.ErrNoRows
+	})
+	db.ExpectQueryRow(func(_ context.Context, _ string, args []any) (pgx.Row, error) {
+		assert.Equal(t, []any{3, 700.0}, args)
+		now := time.Now()
+		return testutil.NewSliceRow([]any{21, models.RefundPending, now, now}), nil
+	})
+	db.ExpectExec(func(_ context.Context, _ string, args []any) (pgconn.CommandTag, error) {
+		assert.Equal(t, []any{21, models.RefundSucceeded}, args[:2])
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+	db.ExpectQueryRow(func(_ context.Context, _ string, args []any) (pgx.Row, error) {
+		assert.Equal(t, []any{700.0, 3}, args)
+		row := paymentRow(3, 7, created.ExternalID, payments.StatusSucceeded, 1000)
+		row[5] = 700.0
+		return testutil.NewSliceRow(row), nil
+	})
+	db.ExpectQuery(func(context.Context, string, []any) (pgx.Rows, error) {
+		return testutil.NewMockRows(nil), nil
+	})
+	// у заказа остаётся удержанный штраф — статус не refunded
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{300.0}), nil
+	})
+	// закрываем заказ: cancellation_requested → cancelled
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{models.OrderStatusCancellationRequested}), nil
+	})
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{models.OrderStatusCancellationRequested}), nil
+	})
+	db.ExpectExec(func(_ context.Context, _ string, args []any) (pgconn.CommandTag, error) {
+		assert.Equal(t, []any{models.OrderStatusCancelled, 7, models.OrderStatusCancellationRequested}, args)
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{1, time.Now()}), nil
+	})
+	db.ExpectQueryRow(func(_ context.Context, _ string, args []any) (pgx.Row, error) {
+		assert.Equal(t, []any{11, models.CancellationApproved, 700.0, 700.0}, args[:4])
+		now := time.Now()
+		return testutil.NewSliceRow([]any{&now}), nil
+	})
+
+	uid := 2
+	c, err := svc.Approve(ctx, 7, models.ApproveCancellationRequest{}, models.Actor{ID: &uid})
+	require.NoError(t, err)
+	assert.Equal(t, models.CancellationApproved, c.Status)
+	assert.Equal(t, 700.0, c.RefundedAmount)
+	assert.Equal(t, &uid, c.DecidedBy)
+	db.Verify(t)
+}
+
+func TestCancellationService_ApproveTwiceRefundsOnce(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewMockDB(t)
+	provider := &refundKeys{Provider: payments.NewFakeProvider("secret")}
+	svc := newCancellationService(db, nil, provider)
+
+	// заявку уже забрал параллельный запрос — второй не трогает платежи
+	expectOrder(db, orderRow(7, models.OrderStatusCancellationRequested, 1000))
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		row := pendingCancellationRow(11, 7, models.OrderStatusPaid, 300)
+		row[11] = models.CancellationProcessing
+		return testutil.NewSliceRow(row), nil
+	})
+	expectCancellationClaim(t, db, 11, false)
+
+	_, err := svc.Approve(ctx, 7, models.ApproveCancellationRequest{}, models.Actor{})
+	assert.ErrorIs(t, err, services.ErrCancellationInProgress)
+	assert.Empty(t, provider.keys)
+	db.Verify(t)
+}
+
+func TestCancellationService_ApproveReleasesClaimOnFailure(t *testing.T) {
+	ctx := context.Background()
+	db := testutil.NewMockDB(t)
+	svc := newCancellationService(db, nil, payments.NewFakeProvider("secret"))
+
+	expectOrder(db, orderRow(7, models.OrderStatusCancellationRequested, 1000))
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow(pendingCancellationRow(11, 7, models.OrderStatusPaid, 300)), nil
+	})
+	expectCancellationClaim(t, db, 11, true)
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return nil, sql.ErrConnDone
+	})
+	// заявка возвращается в pending, чтобы одобрение можно было повторить
+	db.ExpectExec(func(_ context.Context, sql string, args []any) (pgconn.CommandTag, error) {
+		assert.Contains(t, sql, "SET status='pending'")
+		assert.Equal(t, []any{11}, args)
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+
+	_, err := svc.Approve(ctx, 7, models.ApproveCancellationRequest{}, models.Actor{})
+	assert.ErrorIs(t, err, sql.ErrConnDone)
+	db.Verify(t)
+}
+
+func TestCancellationService_RejectRestoresPreviousStatus(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newCancellationService(db, nil, payments.NewFakeProvider("secret"))
+
+	expectOrder(db, orderRow(7, models.OrderStatusCancellationRequested, 1000))
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow(pendingCancellationRow(11, 7, models.OrderStatusConfirmed, 0)), nil
+	})
+	expectCancellationClaim(t, db, 11, true)
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{models.OrderStatusCancellationRequested}), nil
+	})
+	db.ExpectExec(func(_ context.Context, _ string, args []any) (pgconn.CommandTag, error) {
+		assert.Equal(t, []any{models.OrderStatusConfirmed, 7, models.OrderStatusCancellationRequested}, args)
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{1, time.Now()}), nil
+	})
+	db.ExpectQueryRow(func(_ context.Context, _ string, args []any) (pgx.Row, error) {
+		assert.Equal(t, []any{11, models.CancellationRejected, 0.0, 0.0}, args[:4])
+		now := time.Now()
+		return testutil.NewSliceRow([]any{&now}), nil
+	})
+
+	c, err := svc.Reject(context.Background(), 7, "Тур состоится", models.Actor{})
+	require.NoError(t, err)
+	assert.Equal(t, models.CancellationRejected, c.Status)
+	db.Verify(t)
+
+	// обычной сменой статуса вернуть заказ из cancellation_requested нельзя
+	assert.False(t, models.CanTransitionOrder(models.OrderStatusCancellationRequested, models.OrderStatusConfirmed))
+	assert.True(t, models.CanRestoreOrder(models.OrderStatusCancellationRequested, models.OrderStatusConfirmed))
+	assert.False(t, models.CanRestoreOrder(models.OrderStatusCancellationRequested, models.OrderStatusCompleted))
+}
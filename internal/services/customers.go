@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/repository"
+)
+
+var ErrCustomerNotFound = errors.New("customer not found")
+
+type CustomerService struct {
+	repo *repository.CustomerRepo
+	log  *zap.SugaredLogger
+}
+
+type CustomersWithTotal struct {
+	Total     int                      `json:"total"`
+	Customers []models.CustomerSummary `json:"customers"`
+}
+
+func NewCustomerService(repo *repository.CustomerRepo, log *zap.SugaredLogger) *CustomerService {
+	return &CustomerService{repo: repo, log: log}
+}
+
+func (s *CustomerService) List(ctx context.Context, q string, limit, offset int) (*CustomersWithTotal, error) {
+	q = strings.TrimSpace(q)
+
+	total, err := s.repo.Count(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.repo.List(ctx, q, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		list = []models.CustomerSummary{}
+	}
+	return &CustomersWithTotal{Total: total, Customers: list}, nil
+}
+
+func (s *CustomerService) Get(ctx context.Context, id int) (*models.CustomerSummary, error) {
+	c, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrCustomerNotFound
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s *CustomerService) Timeline(ctx context.Context, id int) ([]models.CustomerTimelineItem, error) {
+	if _, err := s.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.Timeline(ctx, id)
+}
+
+// Merge сливает дубликат в клиента targetID и возвращает обновлённую сводку
+func (s *CustomerService) Merge(ctx context.Context, targetID, duplicateID int) (*models.CustomerSummary, error) {
+	if targetID == duplicateID {
+		return nil, helpers.ErrInvalidInput("cannot merge customer into itself")
+	}
+
+	target, err := s.Get(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if target.MergedInto != nil {
+		return nil, helpers.ErrInvalidInput("target customer is already merged")
+	}
+	dup, err := s.Get(ctx, duplicateID)
+	if err != nil {
+		return nil, err
+	}
+	if dup.MergedInto != nil && *dup.MergedInto != targetID {
+		return nil, helpers.ErrInvalidInput("duplicate is already merged into another customer")
+	}
+
+	if err := s.repo.Merge(ctx, targetID, duplicateID); err != nil {
+		return nil, err
+	}
+
+	s.log.Infow("customers_merged", "target_id", targetID, "duplicate_id", duplicateID)
+	return s.Get(ctx, targetID)
+}
@@ -0,0 +1,98 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/repository"
+	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/Ramcache/travel-backend/internal/testutil"
+)
+
+func newCustomerService(db *testutil.MockDB) *services.CustomerService {
+	return services.NewCustomerService(repository.NewCustomerRepo(db), zap.NewNop().Sugar())
+}
+
+func customerRow(id int, phone string, mergedInto *int, orders int, spent float64) []any {
+	return []any{id, phone, "Иван", mergedInto, time.Now(), orders, spent, nil}
+}
+
+func TestCustomerService_ListSearchesByPhoneDigits(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newCustomerService(db)
+
+	db.ExpectQueryRow(func(_ context.Context, query string, args []any) (pgx.Row, error) {
+		assert.Contains(t, query, "c.name ILIKE $1 OR c.phone LIKE $2")
+		assert.Equal(t, []any{"%8 999 123-45-67%", "%79991234567%"}, args)
+		return testutil.NewSliceRow([]any{1}), nil
+	})
+	db.ExpectQuery(func(_ context.Context, _ string, args []any) (pgx.Rows, error) {
+		assert.Equal(t, 20, args[2])
+		return testutil.NewMockRows([][]any{customerRow(1, "+79991234567", nil, 2, 150000)}), nil
+	})
+
+	res, err := svc.List(context.Background(), " 8 999 123-45-67 ", 20, 0)
+	require.NoError(t, err)
+	require.Len(t, res.Customers, 1)
+	assert.Equal(t, 150000.0, res.Customers[0].TotalSpent)
+	db.Verify(t)
+}
+
+func TestCustomerService_Merge(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newCustomerService(db)
+
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow(customerRow(1, "+79991234567", nil, 1, 0)), nil
+	})
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow(customerRow(2, "+79991234560", nil, 1, 0)), nil
+	})
+	for i := 0; i < 4; i++ {
+		db.ExpectExec(func(_ context.Context, _ string, args []any) (pgconn.CommandTag, error) {
+			assert.Equal(t, []any{1, 2}, args)
+			return pgconn.NewCommandTag("UPDATE 1"), nil
+		})
+	}
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow(customerRow(1, "+79991234567", nil, 2, 0)), nil
+	})
+
+	c, err := svc.Merge(context.Background(), 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, c.OrdersCount)
+	db.Verify(t)
+}
+
+func TestCustomerService_MergeValidation(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newCustomerService(db)
+
+	_, err := svc.Merge(context.Background(), 1, 1)
+	assert.True(t, helpers.IsInvalidInput(err))
+
+	other := 3
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow(customerRow(1, "+79991234567", nil, 1, 0)), nil
+	})
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow(customerRow(2, "+79991234560", &other, 0, 0)), nil
+	})
+	_, err = svc.Merge(context.Background(), 1, 2)
+	assert.True(t, helpers.IsInvalidInput(err))
+
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return nil, pgx.ErrNoRows
+	})
+	_, err = svc.Merge(context.Background(), 5, 2)
+	assert.ErrorIs(t, err, services.ErrCustomerNotFound)
+	db.Verify(t)
+}
@@ -0,0 +1,247 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/documents"
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/phone"
+	"github.com/Ramcache/travel-backend/internal/repository"
+)
+
+var (
+	ErrDocumentsUnavailable = errors.New("document generation is not configured")
+	ErrNoCustomerChat       = errors.New("order has no customer telegram chat")
+	ErrTelegramDisabled     = errors.New("telegram is not configured")
+)
+
+var documentTitles = map[string]string{
+	documents.KindInvoice:  "Счёт на оплату",
+	documents.KindVoucher:  "Туристический ваучер",
+	documents.KindContract: "Договор",
+}
+
+type DocumentService struct {
+	orders    *repository.OrderRepo
+	trips     repository.TripRepositoryI
+	hotels    repository.HotelRepositoryI
+	routes    repository.TripRouteRepository
+	schedules *repository.PaymentScheduleRepo
+	templates *repository.DocumentTemplateRepo
+	renderer  *documents.Renderer
+	company   documents.Company
+	telegram  *helpers.TelegramClient
+	log       *zap.SugaredLogger
+}
+
+func NewDocumentService(
+	orders *repository.OrderRepo,
+	trips repository.TripRepositoryI,
+	hotels repository.HotelRepositoryI,
+	routes repository.TripRouteRepository,
+	schedules *repository.PaymentScheduleRepo,
+	templates *repository.DocumentTemplateRepo,
+	renderer *documents.Renderer,
+	company documents.Company,
+	telegram *helpers.TelegramClient,
+	log *zap.SugaredLogger,
+) *DocumentService {
+	return &DocumentService{
+		orders:    orders,
+		trips:     trips,
+		hotels:    hotels,
+		routes:    routes,
+		schedules: schedules,
+		templates: templates,
+		renderer:  renderer,
+		company:   company,
+		telegram:  telegram,
+		log:       log,
+	}
+}
+
+// Available — настроены ли шрифты для PDF
+func (s *DocumentService) Available() bool {
+	return s.renderer.Available()
+}
+
+// Render формирует PDF документа по заказу; возвращает имя файла и содержимое
+func (s *DocumentService) Render(ctx context.Context, orderID int, kind string) (string, []byte, error) {
+	return s.render(ctx, orderID, kind, false)
+}
+
+// RenderMasked — документ для публичной ссылки на заказ: ссылку могли переслать,
+// поэтому ФИО и телефон заказчика и ФИО туристов скрыты частично, а даты рождения не выводятся
+func (s *DocumentService) RenderMasked(ctx context.Context, orderID int, kind string) (string, []byte, error) {
+	return s.render(ctx, orderID, kind, true)
+}
+
+func (s *DocumentService) render(ctx context.Context, orderID int, kind string, masked bool) (string, []byte, error) {
+	if !documents.IsValidKind(kind) {
+		return "", nil, helpers.ErrInvalidInput(fmt.Sprintf("unknown document kind %q", kind))
+	}
+
+	d, err := s.collect(ctx, orderID)
+	if err != nil {
+		return "", nil, err
+	}
+	if masked {
+		maskDocumentData(d)
+	}
+	if kind == documents.KindInvoice && d.Total() <= 0 {
+		return "", nil, helpers.ErrInvalidInput("order has no total price")
+	}
+
+	var contractText string
+	if kind == documents.KindContract {
+		tpl, err := s.ContractTemplate(ctx)
+		if err != nil {
+			return "", nil, err
+		}
+		if contractText, err = documents.RenderContractText(tpl.Body, *d); err != nil {
+			return "", nil, err
+		}
+	}
+
+	pdf, err := s.renderer.Render(kind, *d, contractText)
+	if err != nil {
+		if errors.Is(err, documents.ErrFontUnavailable) {
+			return "", nil, fmt.Errorf("%w: %v", ErrDocumentsUnavailable, err)
+		}
+		return "", nil, err
+	}
+	return documents.FileName(kind, orderID), pdf, nil
+}
+
+// SendToCustomer отправляет документ в Telegram-чат клиента
+func (s *DocumentService) SendToCustomer(ctx context.Context, orderID int, kind string) error {
+	if !s.telegram.Enabled() {
+		return ErrTelegramDisabled
+	}
+
+	order, err := s.getOrder(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if order.TelegramChatID == nil {
+		return ErrNoCustomerChat
+	}
+
+	name, pdf, err := s.Render(ctx, orderID, kind)
+	if err != nil {
+		return err
+	}
+
+	caption := fmt.Sprintf("%s по заказу №%d", documentTitles[kind], orderID)
+	if err := s.telegram.SendDocument(*order.TelegramChatID, name, pdf, caption); err != nil {
+		return err
+	}
+
+	s.log.Infow("document_sent", "order_id", orderID, "kind", kind)
+	return nil
+}
+
+// ContractTemplate — шаблон договора из БД или встроенный по умолчанию
+func (s *DocumentService) ContractTemplate(ctx context.Context) (*models.DocumentTemplate, error) {
+	tpl, err := s.templates.Get(ctx, documents.KindContract)
+	if errors.Is(err, repository.ErrNotFound) {
+		return &models.DocumentTemplate{
+			Kind:      documents.KindContract,
+			Body:      documents.DefaultContractTemplate,
+			IsDefault: true,
+		}, nil
+	}
+	return tpl, err
+}
+
+// SetContractTemplate сохраняет шаблон после пробной подстановки тестового заказа.
+// Пустой body — сброс к шаблону по умолчанию.
+func (s *DocumentService) SetContractTemplate(ctx context.Context, body string, actorID int) (*models.DocumentTemplate, error) {
+	if body == "" {
+		if err := s.templates.Delete(ctx, documents.KindContract); err != nil {
+			return nil, err
+		}
+		return s.ContractTemplate(ctx)
+	}
+
+	if _, err := documents.RenderContractText(body, documents.SampleData()); err != nil {
+		return nil, helpers.ErrInvalidInput(err.Error())
+	}
+
+	tpl := &models.DocumentTemplate{Kind: documents.KindContract, Body: body}
+	if actorID > 0 {
+		tpl.UpdatedBy = &actorID
+	}
+	if err := s.templates.Upsert(ctx, tpl); err != nil {
+		return nil, err
+	}
+
+	s.log.Infow("contract_template_updated", "actor_id", actorID)
+	return tpl, nil
+}
+
+// collect собирает данные заказа: тур, отели, маршрут и график платежей
+func (s *DocumentService) collect(ctx context.Context, orderID int) (*documents.Data, error) {
+	order, err := s.getOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &documents.Data{Date: time.Now(), Company: s.company, Order: *order}
+
+	if order.TripID.Valid {
+		tripID := int(order.TripID.Int32)
+		trip, err := s.trips.GetByID(ctx, tripID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+		if trip != nil {
+			d.Trip = trip
+			if d.Hotels, err = s.hotels.ListByTrip(ctx, tripID); err != nil {
+				return nil, fmt.Errorf("list trip hotels: %w", err)
+			}
+			if d.Routes, err = s.routes.ListByTrip(ctx, tripID); err != nil {
+				return nil, fmt.Errorf("list trip routes: %w", err)
+			}
+		}
+	}
+
+	if d.Installments, err = s.schedules.ListInstallments(ctx, orderID); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// maskDocumentData скрывает персональные данные так же, как публичная карточка заказа
+func maskDocumentData(d *documents.Data) {
+	o := &d.Order
+	o.UserName = maskName(o.UserName)
+	o.UserPhone = phone.Mask(o.UserPhone)
+	o.UserPhoneDisplay = o.UserPhone
+	o.TelegramChatID = nil
+
+	travellers := make([]models.OrderTraveller, len(o.Travellers))
+	for i, t := range o.Travellers {
+		t.FullName = maskName(t.FullName)
+		t.BirthDate, t.Gender, t.ProfileID = nil, nil, nil
+		travellers[i] = t
+	}
+	o.Travellers = travellers
+}
+
+func (s *DocumentService) getOrder(ctx context.Context, id int) (*models.Order, error) {
+	order, err := s.orders.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	return order, nil
+}
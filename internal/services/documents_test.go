@@ -0,0 +1,79 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/documents"
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/repository"
+	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/Ramcache/travel-backend/internal/testutil"
+)
+
+func newDocumentService(db *testutil.MockDB) *services.DocumentService {
+	return services.NewDocumentService(
+		repository.NewOrderRepo(db), nil, nil, nil,
+		repository.NewPaymentScheduleRepo(db),
+		repository.NewDocumentTemplateRepo(db),
+		documents.NewRenderer("", ""), documents.Company{},
+		nil, zap.NewNop().Sugar(),
+	)
+}
+
+func TestDocumentService_ContractTemplateFallback(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	db.ExpectQueryRow(func(_ context.Context, _ string, args []any) (pgx.Row, error) {
+		assert.Equal(t, documents.KindContract, args[0])
+		return nil, pgx.ErrNoRows
+	})
+
+	tpl, err := newDocumentService(db).ContractTemplate(context.Background())
+	require.NoError(t, err)
+	assert.True(t, tpl.IsDefault)
+	assert.Equal(t, documents.DefaultContractTemplate, tpl.Body)
+	db.Verify(t)
+}
+
+func TestDocumentService_SetContractTemplate(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newDocumentService(db)
+
+	_, err := svc.SetContractTemplate(context.Background(), "{{.Order.Missing}}", 1)
+	assert.True(t, helpers.IsInvalidInput(err))
+
+	now := time.Now()
+	db.ExpectQueryRow(func(_ context.Context, _ string, args []any) (pgx.Row, error) {
+		assert.Equal(t, "Договор №{{.Number}}", args[1])
+		return testutil.NewSliceRow([]any{&now}), nil
+	})
+	tpl, err := svc.SetContractTemplate(context.Background(), "Договор №{{.Number}}", 1)
+	require.NoError(t, err)
+	require.NotNil(t, tpl.UpdatedBy)
+	assert.Equal(t, 1, *tpl.UpdatedBy)
+
+	// пустой шаблон — сброс к встроенному
+	db.ExpectExec(func(context.Context, string, []any) (pgconn.CommandTag, error) {
+		return pgconn.NewCommandTag("DELETE 1"), nil
+	})
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return nil, pgx.ErrNoRows
+	})
+	tpl, err = svc.SetContractTemplate(context.Background(), "", 1)
+	require.NoError(t, err)
+	assert.True(t, tpl.IsDefault)
+	db.Verify(t)
+}
+
+func TestDocumentService_RenderUnknownKind(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	_, _, err := newDocumentService(db).Render(context.Background(), 1, "receipt")
+	assert.True(t, helpers.IsInvalidInput(err))
+}
@@ -2,53 +2,44 @@ package services
 
 import (
 	"context"
-	"fmt"
 	"time"
 
-	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/export"
 	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/notifications"
 	"github.com/Ramcache/travel-backend/internal/repository"
 	"go.uber.org/zap"
 )
 
 type FeedbackService struct {
-	repo     *repository.FeedbackRepo
-	telegram *helpers.TelegramClient
-	log      *zap.SugaredLogger
+	repo   *repository.FeedbackRepo
+	notify *NotificationService
+	log    *zap.SugaredLogger
 }
 
-func NewFeedbackService(repo *repository.FeedbackRepo, telegram *helpers.TelegramClient, log *zap.SugaredLogger) *FeedbackService {
-	return &FeedbackService{repo: repo, telegram: telegram, log: log}
+func NewFeedbackService(repo *repository.FeedbackRepo, notify *NotificationService, log *zap.SugaredLogger) *FeedbackService {
+	return &FeedbackService{repo: repo, notify: notify, log: log}
 }
 
 func (s *FeedbackService) Create(ctx context.Context, req models.FeedbackRequest) error {
-	fb := models.Feedback{
-		UserName:  req.UserName,
-		UserPhone: req.UserPhone,
-	}
-
-	if err := s.repo.Create(ctx, &fb); err != nil {
+	num, err := parsePhone(req.UserPhone)
+	if err != nil {
 		return err
 	}
 
-	msg := fmt.Sprintf(
-		"💬 <b>Новая заявка на консультацию!</b>\n\n"+
-			"📅 <b>Дата:</b> %s\n"+
-			"👤 <b>Имя:</b> %s\n"+
-			"📞 <b>Телефон:</b> <a href=\"tel:%s\">%s</a>",
-		time.Now().Format("02.01.2006 15:04"),
-		fb.UserName,
-		fb.UserPhone, fb.UserPhone,
-	)
+	fb := models.Feedback{
+		UserName:         req.UserName,
+		UserPhone:        num.E164,
+		UserPhoneDisplay: num.Display,
+	}
 
-	if s.telegram != nil {
-		if err := s.telegram.SendMessage(msg); err != nil {
-			s.log.Errorw("Ошибка отправки feedback в Telegram", "err", err)
+	// заявка и уведомление о ней — одной транзакцией, доставка из outbox
+	return s.repo.Tx(ctx, func(tx repository.DB) error {
+		if err := s.repo.WithTx(tx).Create(ctx, &fb); err != nil {
 			return err
 		}
-	}
-
-	return nil
+		return s.notify.Enqueue(ctx, tx, notifications.FeedbackReceived{Feedback: &fb, At: time.Now()})
+	})
 }
 
 type FeedbacksWithTotal struct {
@@ -73,6 +64,18 @@ func (s *FeedbackService) List(ctx context.Context, limit, offset int, phone str
 	}, nil
 }
 
+var feedbackExportColumns = []string{"ID", "Создана", "Имя", "Телефон", "Прочитана"}
+
+// Export пишет заявки по фильтру в w построчно
+func (s *FeedbackService) Export(ctx context.Context, f models.FeedbackFilter, w export.Writer) error {
+	if err := w.WriteHeader(feedbackExportColumns); err != nil {
+		return err
+	}
+	return s.repo.Export(ctx, f, func(fb models.Feedback) error {
+		return w.WriteRow([]any{fb.ID, fb.CreatedAt, fb.UserName, fb.UserPhone, fb.IsRead})
+	})
+}
+
 func (s *FeedbackService) MarkAsRead(ctx context.Context, id int) error {
 	return s.repo.MarkAsRead(ctx, id)
 }
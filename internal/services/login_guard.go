@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/notifications"
+	"github.com/Ramcache/travel-backend/internal/repository"
+)
+
+var (
+	// ErrLoginLocked — слишком много неудачных попыток, вход временно закрыт
+	ErrLoginLocked       = errors.New("too many failed login attempts")
+	ErrLoginLockNotFound = errors.New("login lock not found")
+)
+
+const (
+	// столько неудач подряд проходят без задержки, дальше пауза 1, 2, 4… секунды
+	loginFreeAttempts = 3
+	loginMaxDelay     = time.Minute
+)
+
+// LoginLockedError — вход закрыт; RetryAfter — через сколько можно попробовать снова.
+// errors.Is(err, ErrLoginLocked) для неё истинно
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrLoginLocked, e.RetryAfter)
+}
+
+func (e *LoginLockedError) Is(target error) bool { return target == ErrLoginLocked }
+
+// LoginGuardConfig — защита входа от подбора пароля
+type LoginGuardConfig struct {
+	// после стольких неудач подряд вход в аккаунт закрывается на Lockout
+	MaxFailures int
+	// то же для IP: с одного адреса перебирают разные аккаунты, поэтому порог выше
+	MaxFailuresPerIP int
+	// на сколько закрывается вход; неудачи старше этого забываются
+	Lockout time.Duration
+}
+
+// LoginGuardService считает неудачные входы по email и по IP. Счётчики лежат в базе,
+// поэтому блокировка переживает перезапуск и действует на все инстансы.
+// Когда закрывается вход в аккаунт сотрудника, менеджерам уходит auth.account_locked.
+type LoginGuardService struct {
+	repo          *repository.LoginThrottleRepo
+	notifications *NotificationService
+	cfg           LoginGuardConfig
+	log           *zap.SugaredLogger
+}
+
+func NewLoginGuardService(repo *repository.LoginThrottleRepo, notifications *NotificationService, cfg LoginGuardConfig, log *zap.SugaredLogger) *LoginGuardService {
+	if cfg.MaxFailures <= 0 {
+		cfg.MaxFailures = 5
+	}
+	if cfg.MaxFailuresPerIP <= 0 {
+		cfg.MaxFailuresPerIP = 20
+	}
+	if cfg.Lockout <= 0 {
+		cfg.Lockout = 15 * time.Minute
+	}
+	return &LoginGuardService{repo: repo, notifications: notifications, cfg: cfg, log: log}
+}
+
+// Check — можно ли сейчас проверять пароль для email с этого IP.
+// Вызывается до проверки пароля, иначе перебор продолжается и во время блокировки
+func (s *LoginGuardService) Check(ctx context.Context, email, ip string) error {
+	wait, err := s.repo.RetryAfter(ctx, email, ip, loginFreeAttempts, loginMaxDelay, s.cfg.Lockout)
+	if err != nil {
+		s.log.Errorw("login_throttle_check_failed", "email", email, "ip", ip, "err", err)
+		return err
+	}
+	if wait > 0 {
+		s.log.Warnw("login_throttled", "email", email, "ip", ip, "retry_after", wait)
+		return &LoginLockedError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// Failed засчитывает неудачный вход. user — владелец email, nil если такого нет:
+// незнакомые адреса тоже считаются, чтобы по ответам нельзя было отличить их от настоящих.
+// Ошибки только логируются — ответ на вход от них не меняется
+func (s *LoginGuardService) Failed(ctx context.Context, email, ip string, user *models.User) {
+	s.fail(ctx, models.LoginByEmail, email, s.cfg.MaxFailures, func(tx repository.DB, failures int) error {
+		if user == nil || user.RoleID == models.RoleUser {
+			return nil
+		}
+		// клиентов не тревожим, а вот подбор пароля к админке — повод для менеджеров
+		return s.notifications.Enqueue(ctx, tx, notifications.AccountLocked{
+			User:        user,
+			IP:          ip,
+			Failures:    failures,
+			LockedUntil: time.Now().Add(s.cfg.Lockout),
+			At:          time.Now(),
+		})
+	})
+	if ip != "" {
+		s.fail(ctx, models.LoginByIP, ip, s.cfg.MaxFailuresPerIP, nil)
+	}
+}
+
+// fail засчитывает неудачу и при достижении max закрывает вход; onLock выполняется в той же транзакции
+func (s *LoginGuardService) fail(ctx context.Context, kind, key string, max int, onLock func(tx repository.DB, failures int) error) {
+	err := s.repo.Tx(ctx, func(tx repository.DB) error {
+		repo := s.repo.WithTx(tx)
+		failures, err := repo.Fail(ctx, kind, key, s.cfg.Lockout)
+		if err != nil || failures < max {
+			return err
+		}
+		if err := repo.Lock(ctx, kind, key, s.cfg.Lockout); err != nil {
+			return err
+		}
+		s.log.Warnw("Вход заблокирован после неудачных попыток", "kind", kind, "key", key, "failures", failures, "until", time.Now().Add(s.cfg.Lockout))
+		if onLock == nil {
+			return nil
+		}
+		return onLock(tx, failures)
+	})
+	if err != nil {
+		s.log.Errorw("login_throttle_fail_failed", "kind", kind, "key", key, "err", err)
+	}
+}
+
+// Succeeded сбрасывает счётчик email после успешного входа. Счётчик IP не трогаем:
+// удачный вход в свой аккаунт не должен обнулять перебор чужих с того же адреса
+func (s *LoginGuardService) Succeeded(ctx context.Context, email string) {
+	err := s.repo.Reset(ctx, models.LoginByEmail, email)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		s.log.Errorw("login_throttle_reset_failed", "email", email, "err", err)
+	}
+}
+
+// Locks — действующие блокировки для админки
+func (s *LoginGuardService) Locks(ctx context.Context) ([]models.LoginLock, error) {
+	list, err := s.repo.Locked(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		list = []models.LoginLock{}
+	}
+	return list, nil
+}
+
+// Unlock снимает блокировку и забывает неудачи — вход открывается сразу, без задержек
+func (s *LoginGuardService) Unlock(ctx context.Context, kind, key string) error {
+	if kind != models.LoginByEmail && kind != models.LoginByIP {
+		return helpers.ErrInvalidInput(fmt.Sprintf("invalid kind %q", kind))
+	}
+	if kind == models.LoginByEmail {
+		key = loginEmailKey(key)
+	}
+	if key == "" {
+		return helpers.ErrInvalidInput("email or ip is required")
+	}
+
+	err := s.repo.Reset(ctx, kind, key)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrLoginLockNotFound
+	}
+	if err != nil {
+		return err
+	}
+	s.log.Infow("login_unlocked", "kind", kind, "key", key)
+	return nil
+}
+
+// loginEmailKey — ключ счётчика: Admin@Example.com и admin@example.com — один аккаунт
+func loginEmailKey(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
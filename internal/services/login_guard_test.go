@@ -0,0 +1,169 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/notifications"
+	"github.com/Ramcache/travel-backend/internal/repository"
+	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/Ramcache/travel-backend/internal/testutil"
+)
+
+func newLoginGuard(t *testing.T, db *testutil.MockDB) *services.LoginGuardService {
+	t.Helper()
+	notify := newNotificationService(t, db, "auth.account_locked=telegram", notifications.NewRecorder(notifications.ChannelTelegram))
+	return services.NewLoginGuardService(repository.NewLoginThrottleRepo(db), notify,
+		services.LoginGuardConfig{MaxFailures: 5, MaxFailuresPerIP: 20, Lockout: 15 * time.Minute}, zaptest.NewLogger(t).Sugar())
+}
+
+// expectRetryAfter — сколько секунд ждать до следующей попытки; nil — можно входить
+func expectRetryAfter(db *testutil.MockDB, secs *float64) {
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{secs}), nil
+	})
+}
+
+// expectFail — неудача засчитана, всего их failures
+func expectFail(t *testing.T, db *testutil.MockDB, kind, key string, failures int) {
+	db.ExpectQueryRow(func(_ context.Context, sql string, args []any) (pgx.Row, error) {
+		assert.Contains(t, sql, "INSERT INTO login_throttles")
+		assert.Equal(t, []any{kind, key, 900.0}, args)
+		return testutil.NewSliceRow([]any{failures}), nil
+	})
+}
+
+func TestAuthService_Login_Throttled(t *testing.T) {
+	repo := new(MockUserRepo)
+	svc, db := newAuthService(t, repo, nil)
+	svc.UseLoginGuard(newLoginGuard(t, db))
+
+	wait := 42.0
+	db.ExpectQueryRow(func(_ context.Context, sql string, args []any) (pgx.Row, error) {
+		assert.Contains(t, sql, "FROM login_throttles")
+		// email без учёта регистра; 3 попытки без задержки, пауза до минуты, окно 15 минут
+		assert.Equal(t, []any{"admin@example.com", "10.0.0.1", 3, 60.0, 900.0}, args)
+		return testutil.NewSliceRow([]any{&wait}), nil
+	})
+
+	resp, err := svc.Login(context.Background(), models.LoginRequest{Email: " Admin@Example.com", Password: "guess", IP: "10.0.0.1"})
+	assert.Nil(t, resp)
+	assert.ErrorIs(t, err, services.ErrLoginLocked)
+	var locked *services.LoginLockedError
+	require.ErrorAs(t, err, &locked)
+	assert.Equal(t, 42*time.Second, locked.RetryAfter)
+	// пароль не проверяется, пока вход закрыт
+	repo.AssertNotCalled(t, "GetByEmail", mock.Anything, mock.Anything)
+	db.Verify(t)
+}
+
+func TestAuthService_Login_LocksAccount(t *testing.T) {
+	hash, _ := helpers.HashPassword("rightpass")
+	tests := []struct {
+		name  string
+		role  int
+		alert bool
+	}{
+		{"staff", models.RoleAdmin, true},
+		// клиентов блокируем молча
+		{"customer", models.RoleUser, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockUserRepo)
+			svc, db := newAuthService(t, repo, nil)
+			svc.UseLoginGuard(newLoginGuard(t, db))
+			repo.On("GetByEmail", mock.Anything, "admin@example.com").
+				Return(&models.User{ID: 3, Email: "admin@example.com", Password: hash, RoleID: tt.role}, nil)
+
+			expectRetryAfter(db, nil)
+			expectFail(t, db, models.LoginByEmail, "admin@example.com", 5)
+			db.ExpectExec(func(_ context.Context, sql string, args []any) (pgconn.CommandTag, error) {
+				assert.Contains(t, sql, "locked_until = now()")
+				assert.Equal(t, []any{models.LoginByEmail, "admin@example.com", 900.0}, args)
+				return pgconn.NewCommandTag("UPDATE 1"), nil
+			})
+			if tt.alert {
+				db.ExpectQueryRow(func(_ context.Context, _ string, args []any) (pgx.Row, error) {
+					assert.Equal(t, string(notifications.EventAccountLocked), args[0])
+					assert.Equal(t, notifications.ChannelTelegram, args[1])
+					assert.Contains(t, args[4], "admin@example.com")
+					assert.Contains(t, args[5], "10.0.0.1")
+					return testutil.NewSliceRow([]any{1, models.NotificationPending, time.Now(), time.Now(), time.Now()}), nil
+				})
+			}
+			// порог для IP ещё не достигнут
+			expectFail(t, db, models.LoginByIP, "10.0.0.1", 6)
+
+			_, err := svc.Login(context.Background(), models.LoginRequest{Email: "admin@example.com", Password: "wrong", IP: "10.0.0.1"})
+			assert.ErrorIs(t, err, services.ErrInvalidCredentials)
+			db.Verify(t)
+		})
+	}
+}
+
+func TestAuthService_Login_UnknownEmailCounted(t *testing.T) {
+	repo := new(MockUserRepo)
+	svc, db := newAuthService(t, repo, nil)
+	svc.UseLoginGuard(newLoginGuard(t, db))
+	repo.On("GetByEmail", mock.Anything, "nobody@example.com").Return(nil, repository.ErrNotFound)
+
+	expectRetryAfter(db, nil)
+	expectFail(t, db, models.LoginByEmail, "nobody@example.com", 1)
+	expectFail(t, db, models.LoginByIP, "10.0.0.1", 1)
+
+	_, err := svc.Login(context.Background(), models.LoginRequest{Email: "nobody@example.com", Password: "x", IP: "10.0.0.1"})
+	assert.ErrorIs(t, err, services.ErrInvalidCredentials)
+	db.Verify(t)
+}
+
+func TestAuthService_Login_SuccessResetsFailures(t *testing.T) {
+	repo := new(MockUserRepo)
+	svc, db := newAuthService(t, repo, nil)
+	svc.UseLoginGuard(newLoginGuard(t, db))
+
+	hash, _ := helpers.HashPassword("123456")
+	repo.On("GetByEmail", mock.Anything, "ok@mail.com").
+		Return(&models.User{ID: 1, Email: "ok@mail.com", Password: hash}, nil)
+	expectRetryAfter(db, nil)
+	db.ExpectExec(func(_ context.Context, sql string, args []any) (pgconn.CommandTag, error) {
+		assert.Contains(t, sql, "DELETE FROM login_throttles")
+		// счётчик IP остаётся
+		assert.Equal(t, []any{models.LoginByEmail, "ok@mail.com"}, args)
+		return pgconn.NewCommandTag("DELETE 1"), nil
+	})
+	expectSessionCreate(db, 5)
+
+	resp, err := svc.Login(context.Background(), models.LoginRequest{Email: "ok@mail.com", Password: "123456", IP: "10.0.0.1"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.Token)
+	db.Verify(t)
+}
+
+func TestLoginGuardService_Unlock(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newLoginGuard(t, db)
+
+	db.ExpectExec(func(_ context.Context, _ string, args []any) (pgconn.CommandTag, error) {
+		assert.Equal(t, []any{models.LoginByEmail, "admin@example.com"}, args)
+		return pgconn.NewCommandTag("DELETE 1"), nil
+	})
+	db.ExpectExec(func(context.Context, string, []any) (pgconn.CommandTag, error) {
+		return pgconn.NewCommandTag("DELETE 0"), nil
+	})
+
+	require.NoError(t, svc.Unlock(context.Background(), models.LoginByEmail, "Admin@Example.com"))
+	assert.ErrorIs(t, svc.Unlock(context.Background(), models.LoginByIP, "10.0.0.9"), services.ErrLoginLockNotFound)
+	assert.True(t, helpers.IsInvalidInput(svc.Unlock(context.Background(), models.LoginByIP, "")))
+	db.Verify(t)
+}
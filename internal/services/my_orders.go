@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/documents"
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/phone"
+	"github.com/Ramcache/travel-backend/internal/repository"
+)
+
+// ErrDocumentNotReady — документ ещё не положен клиенту по статусу заказа
+var ErrDocumentNotReady = errors.New("document is not available for this order yet")
+
+// MyOrderService — «Мои бронирования» в профиле и публичная ссылка отслеживания заказа
+type MyOrderService struct {
+	orders    *repository.OrderRepo
+	trips     repository.TripRepositoryI
+	payments  *repository.PaymentRepo
+	schedules *PaymentScheduleService
+	documents *DocumentService
+	log       *zap.SugaredLogger
+}
+
+type MyOrdersWithTotal struct {
+	Total  int              `json:"total"`
+	Orders []models.MyOrder `json:"orders"`
+}
+
+func NewMyOrderService(
+	orders *repository.OrderRepo,
+	trips repository.TripRepositoryI,
+	payments *repository.PaymentRepo,
+	schedules *PaymentScheduleService,
+	documents *DocumentService,
+	log *zap.SugaredLogger,
+) *MyOrderService {
+	return &MyOrderService{
+		orders:    orders,
+		trips:     trips,
+		payments:  payments,
+		schedules: schedules,
+		documents: documents,
+		log:       log,
+	}
+}
+
+func (s *MyOrderService) List(ctx context.Context, userID, limit, offset int) (*MyOrdersWithTotal, error) {
+	f := models.OrderFilter{UserID: &userID}
+
+	total, err := s.orders.Count(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.orders.List(ctx, limit, offset, f)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &MyOrdersWithTotal{Total: total, Orders: make([]models.MyOrder, 0, len(list))}
+	trips := map[int]*models.Trip{}
+	for _, o := range list {
+		m := models.NewMyOrder(o)
+		if m.TripID != nil {
+			if _, ok := trips[*m.TripID]; !ok {
+				trips[*m.TripID] = s.trip(ctx, *m.TripID)
+			}
+			setTrip(&m, trips[*m.TripID])
+		}
+		res.Orders = append(res.Orders, m)
+	}
+	return res, nil
+}
+
+// Get — карточка заказа; чужой заказ неотличим от несуществующего
+func (s *MyOrderService) Get(ctx context.Context, userID, orderID int) (*models.MyOrderDetails, error) {
+	order, err := s.getOwn(ctx, userID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return s.details(ctx, order, fmt.Sprintf("/api/v1/profile/orders/%d/documents/", order.ID))
+}
+
+// Track — заказ по публичной ссылке без входа; ФИО и телефон скрыты частично
+func (s *MyOrderService) Track(ctx context.Context, token string) (*models.TrackedOrder, error) {
+	order, err := s.getByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	d, err := s.details(ctx, order, "/api/v1/orders/track/"+token+"/documents/")
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range d.Travellers {
+		t := &d.Travellers[i]
+		t.FullName = maskName(t.FullName)
+		t.BirthDate, t.Gender, t.ProfileID = nil, nil, nil
+	}
+	for i := range d.Payments {
+		d.Payments[i].ExternalID = nil
+	}
+	return &models.TrackedOrder{
+		MyOrderDetails: *d,
+		UserName:       maskName(order.UserName),
+		UserPhone:      phone.Mask(order.UserPhone),
+	}, nil
+}
+
+// TrackDocument — PDF документа по публичной ссылке; персональные данные в нём скрыты, как в Track
+func (s *MyOrderService) TrackDocument(ctx context.Context, token, kind string) (string, []byte, error) {
+	order, err := s.getByToken(ctx, token)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := checkDocument(order, kind); err != nil {
+		return "", nil, err
+	}
+	return s.documents.RenderMasked(ctx, order.ID, kind)
+}
+
+// details собирает карточку: docPrefix — начало ссылок на PDF, к нему дописывается {kind}.pdf
+func (s *MyOrderService) details(ctx context.Context, order *models.Order, docPrefix string) (*models.MyOrderDetails, error) {
+	d := &models.MyOrderDetails{MyOrder: models.NewMyOrder(*order)}
+	if d.TripID != nil {
+		setTrip(&d.MyOrder, s.trip(ctx, *d.TripID))
+	}
+
+	history, err := s.orders.ListStatusHistory(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	d.Timeline = []models.OrderTimelineItem{}
+	for _, h := range history {
+		if h.Event == models.OrderEventStatus {
+			d.Timeline = append(d.Timeline, models.OrderTimelineItem{Status: h.ToStatus, At: h.CreatedAt})
+		}
+	}
+
+	if d.Payments, err = s.payments.ListByOrder(ctx, order.ID); err != nil {
+		return nil, err
+	}
+	if d.Payments == nil {
+		d.Payments = []models.Payment{}
+	}
+
+	if d.Schedule, err = s.schedules.Schedule(ctx, order.ID); err != nil {
+		return nil, err
+	}
+
+	d.Documents = s.availableDocuments(order, docPrefix)
+	return d, nil
+}
+
+// Document формирует PDF документа по своему заказу
+func (s *MyOrderService) Document(ctx context.Context, userID, orderID int, kind string) (string, []byte, error) {
+	order, err := s.getOwn(ctx, userID, orderID)
+	if err != nil {
+		return "", nil, err
+	}
+	return s.document(ctx, order, kind)
+}
+
+func (s *MyOrderService) document(ctx context.Context, order *models.Order, kind string) (string, []byte, error) {
+	if err := checkDocument(order, kind); err != nil {
+		return "", nil, err
+	}
+	return s.documents.Render(ctx, order.ID, kind)
+}
+
+func checkDocument(order *models.Order, kind string) error {
+	if !documents.IsValidKind(kind) {
+		return helpers.ErrInvalidInput(fmt.Sprintf("unknown document kind %q", kind))
+	}
+	if !documentAvailable(order, kind) {
+		return ErrDocumentNotReady
+	}
+	return nil
+}
+
+// счёт — пока заказ в работе и есть сумма; договор — после подтверждения; ваучер — после оплаты
+func documentAvailable(o *models.Order, kind string) bool {
+	switch kind {
+	case documents.KindInvoice:
+		return o.TotalPrice != nil && *o.TotalPrice > 0 &&
+			(o.Status == models.OrderStatusNew || o.Status == models.OrderStatusConfirmed)
+	case documents.KindContract:
+		return o.Status == models.OrderStatusConfirmed || o.Status == models.OrderStatusPaid ||
+			o.Status == models.OrderStatusCompleted
+	case documents.KindVoucher:
+		return o.Status == models.OrderStatusPaid || o.Status == models.OrderStatusCompleted
+	}
+	return false
+}
+
+func (s *MyOrderService) availableDocuments(o *models.Order, prefix string) []models.OrderDocument {
+	list := []models.OrderDocument{}
+	if !s.documents.Available() {
+		return list
+	}
+	for _, kind := range []string{documents.KindInvoice, documents.KindContract, documents.KindVoucher} {
+		if documentAvailable(o, kind) {
+			list = append(list, models.OrderDocument{
+				Kind:  kind,
+				Title: documentTitles[kind],
+				URL:   prefix + kind + ".pdf",
+			})
+		}
+	}
+	return list
+}
+
+func (s *MyOrderService) getOwn(ctx context.Context, userID, orderID int) (*models.Order, error) {
+	return findOwnOrder(ctx, s.orders, userID, orderID)
+}
+
+// findOwnOrder — заказ пользователя; чужой или анонимный заказ не виден (ErrOrderNotFound)
+func findOwnOrder(ctx context.Context, orders *repository.OrderRepo, userID, orderID int) (*models.Order, error) {
+	order, err := orders.GetByID(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	if order.UserID == nil || *order.UserID != userID {
+		return nil, ErrOrderNotFound
+	}
+	return order, nil
+}
+
+func (s *MyOrderService) getByToken(ctx context.Context, token string) (*models.Order, error) {
+	return findOrderByToken(ctx, s.orders, token)
+}
+
+// findOrderByToken — заказ по токену публичной ссылки
+func findOrderByToken(ctx context.Context, orders *repository.OrderRepo, token string) (*models.Order, error) {
+	if token == "" {
+		return nil, ErrOrderNotFound
+	}
+	order, err := orders.GetByTrackingToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	return order, nil
+}
+
+// trip — тур заказа; удалённый тур не ломает карточку
+func (s *MyOrderService) trip(ctx context.Context, tripID int) *models.Trip {
+	trip, err := s.trips.GetByID(ctx, tripID)
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			s.log.Warnw("Не удалось загрузить тур заказа", "trip_id", tripID, "err", err)
+		}
+		return nil
+	}
+	return trip
+}
+
+func setTrip(m *models.MyOrder, trip *models.Trip) {
+	if trip == nil {
+		return
+	}
+	m.TripTitle = &trip.Title
+	m.TripStart = &trip.StartDate
+	m.TripEnd = &trip.EndDate
+}
+
+// maskName оставляет от каждого слова первую букву: «Иванов Иван» → «И*** И***»
+func maskName(name string) string {
+	words := strings.Fields(name)
+	for i, w := range words {
+		r := []rune(w)
+		words[i] = string(r[0]) + "***"
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,156 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/repository"
+	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/Ramcache/travel-backend/internal/testutil"
+)
+
+func newMyOrderService(db *testutil.MockDB) *services.MyOrderService {
+	orders := repository.NewOrderRepo(db)
+	schedules := services.NewPaymentScheduleService(repository.NewPaymentScheduleRepo(db), orders, nil, nil, 0, zap.NewNop().Sugar())
+	return services.NewMyOrderService(orders, nil, repository.NewPaymentRepo(db), schedules,
+		newDocumentService(db), zap.NewNop().Sugar())
+}
+
+// ownedOrderRow — orderRow с владельцем (user_id — предпоследняя колонка orderFields)
+func ownedOrderRow(id, userID int, status string, total float64) []any {
+	row := orderRow(id, status, total)
+	row[len(row)-2] = &userID
+	return row
+}
+
+func expectOrder(db *testutil.MockDB, row []any) {
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow(row), nil
+	})
+	db.ExpectQuery(func(context.Context, string, []any) (pgx.Rows, error) {
+		return testutil.NewMockRows(nil), nil
+	})
+}
+
+func TestMyOrderService_ForeignOrderIsNotFound(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newMyOrderService(db)
+
+	expectOrder(db, ownedOrderRow(7, 2, models.OrderStatusPaid, 1000))
+	_, err := svc.Get(context.Background(), 1, 7)
+	assert.ErrorIs(t, err, services.ErrOrderNotFound)
+
+	// анонимный заказ тоже не виден
+	expectOrder(db, orderRow(8, models.OrderStatusPaid, 1000))
+	_, _, err = svc.Document(context.Background(), 1, 8, "voucher")
+	assert.ErrorIs(t, err, services.ErrOrderNotFound)
+	db.Verify(t)
+}
+
+func TestMyOrderService_Get(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newMyOrderService(db)
+
+	expectOrder(db, ownedOrderRow(7, 1, models.OrderStatusConfirmed, 1000))
+	now := time.Now()
+	comment := "клиент просил перезвонить"
+	db.ExpectQuery(func(context.Context, string, []any) (pgx.Rows, error) {
+		return testutil.NewMockRows([][]any{
+			{1, 7, models.OrderEventStatus, nil, models.OrderStatusNew, nil, nil, nil, now, nil, nil, nil, nil},
+			{2, 7, models.OrderEventAssignment, nil, models.OrderStatusNew, nil, nil, nil, now, nil, nil, nil, nil},
+			{3, 7, models.OrderEventStatus, nil, models.OrderStatusConfirmed, nil, nil, &comment, now, nil, nil, nil, nil},
+		}), nil
+	})
+	// платежи
+	db.ExpectQuery(func(context.Context, string, []any) (pgx.Rows, error) {
+		return testutil.NewMockRows(nil), nil
+	})
+	// график: заказ и взносы
+	expectOrder(db, ownedOrderRow(7, 1, models.OrderStatusConfirmed, 1000))
+	db.ExpectQuery(func(context.Context, string, []any) (pgx.Rows, error) {
+		return testutil.NewMockRows(nil), nil
+	})
+
+	d, err := svc.Get(context.Background(), 1, 7)
+	require.NoError(t, err)
+	assert.Equal(t, []models.OrderTimelineItem{
+		{Status: models.OrderStatusNew, At: now},
+		{Status: models.OrderStatusConfirmed, At: now},
+	}, d.Timeline)
+	assert.Empty(t, d.Payments)
+	assert.Equal(t, 1000.0, d.Schedule.Outstanding)
+	// шрифты не настроены — документы не предлагаем
+	assert.Empty(t, d.Documents)
+	db.Verify(t)
+}
+
+func TestMyOrderService_DocumentNotReady(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newMyOrderService(db)
+
+	expectOrder(db, ownedOrderRow(7, 1, models.OrderStatusNew, 1000))
+	_, _, err := svc.Document(context.Background(), 1, 7, "voucher")
+	assert.ErrorIs(t, err, services.ErrDocumentNotReady)
+	db.Verify(t)
+}
+
+func TestMyOrderService_TrackMasksPersonalData(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newMyOrderService(db)
+
+	row := orderRow(7, models.OrderStatusConfirmed, 1000)
+	birth := time.Date(1990, 5, 21, 0, 0, 0, 0, time.UTC)
+	gender := models.GenderMale
+	db.ExpectQueryRow(func(_ context.Context, query string, args []any) (pgx.Row, error) {
+		assert.Contains(t, query, "tracking_token=$1")
+		assert.Equal(t, []any{"trk"}, args)
+		return testutil.NewSliceRow(row), nil
+	})
+	db.ExpectQuery(func(context.Context, string, []any) (pgx.Rows, error) {
+		return testutil.NewMockRows([][]any{
+			{1, 7, nil, "Иванов Иван", &birth, &gender, models.TravellerAdult, nil, nil, time.Now()},
+		}), nil
+	})
+	db.ExpectQuery(func(context.Context, string, []any) (pgx.Rows, error) { // история
+		return testutil.NewMockRows(nil), nil
+	})
+	db.ExpectQuery(func(context.Context, string, []any) (pgx.Rows, error) { // платежи
+		return testutil.NewMockRows(nil), nil
+	})
+	expectOrder(db, row) // график
+	db.ExpectQuery(func(context.Context, string, []any) (pgx.Rows, error) {
+		return testutil.NewMockRows(nil), nil
+	})
+
+	d, err := svc.Track(context.Background(), "trk")
+	require.NoError(t, err)
+	assert.Equal(t, "И***", d.UserName)
+	assert.Equal(t, "+7 (***) ***-**-00", d.UserPhone)
+	require.Len(t, d.Travellers, 1)
+	assert.Equal(t, "И*** И***", d.Travellers[0].FullName)
+	assert.Nil(t, d.Travellers[0].BirthDate)
+	assert.Nil(t, d.Travellers[0].Gender)
+	db.Verify(t)
+}
+
+func TestMyOrderService_TrackUnknownToken(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newMyOrderService(db)
+
+	_, err := svc.Track(context.Background(), "")
+	assert.ErrorIs(t, err, services.ErrOrderNotFound)
+
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return nil, pgx.ErrNoRows
+	})
+	_, err = svc.Track(context.Background(), "nope")
+	assert.ErrorIs(t, err, services.ErrOrderNotFound)
+	db.Verify(t)
+}
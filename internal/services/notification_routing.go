@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/notifications"
+	"github.com/Ramcache/travel-backend/internal/repository"
+)
+
+var ErrDestinationNotFound = errors.New("notification destination not found")
+
+const defaultQuietTimezone = "Europe/Moscow"
+
+// NotificationRoutingService — чаты менеджеров и правила, по которым уведомления расходятся по ним.
+// Реализует notifications.RuleStore: изменения из админки действуют на следующее событие.
+type NotificationRoutingService struct {
+	repo *repository.NotificationRoutingRepo
+	log  *zap.SugaredLogger
+}
+
+func NewNotificationRoutingService(repo *repository.NotificationRoutingRepo, log *zap.SugaredLogger) *NotificationRoutingService {
+	return &NotificationRoutingService{repo: repo, log: log}
+}
+
+// Rules — правила включённых чатов
+func (s *NotificationRoutingService) Rules(ctx context.Context) ([]notifications.Rule, error) {
+	list, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []notifications.Rule
+	for _, d := range list {
+		if !d.IsActive {
+			continue
+		}
+		quiet, err := notifications.ParseQuietHours(helpers.IfEmpty(d.QuietFrom, ""), helpers.IfEmpty(d.QuietTo, ""), d.Timezone)
+		if err != nil {
+			// сохранённое прошло проверку; если таймзону убрали из tzdata — шлём без тихих часов
+			s.log.Warnw("Некорректные тихие часы чата уведомлений", "destination_id", d.ID, "err", err)
+			quiet = notifications.QuietHours{}
+		}
+		dest := notifications.Destination{ID: d.ID, Name: d.Name, ChatID: d.ChatID, Quiet: quiet}
+		for _, r := range d.Rules {
+			rules = append(rules, notifications.Rule{
+				EventType:     notifications.EventType(helpers.IfEmpty(r.EventType, "")),
+				TripType:      helpers.IfEmpty(r.TripType, ""),
+				DepartureCity: helpers.IfEmpty(r.DepartureCity, ""),
+				MinAmount:     r.MinAmount,
+				MaxAmount:     r.MaxAmount,
+				Destination:   dest,
+			})
+		}
+	}
+	return rules, nil
+}
+
+func (s *NotificationRoutingService) List(ctx context.Context) ([]models.NotificationDestination, error) {
+	list, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		list = []models.NotificationDestination{}
+	}
+	return list, nil
+}
+
+func (s *NotificationRoutingService) Get(ctx context.Context, id int) (*models.NotificationDestination, error) {
+	d, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrDestinationNotFound
+	}
+	return d, err
+}
+
+func (s *NotificationRoutingService) Create(ctx context.Context, req models.NotificationDestinationRequest) (*models.NotificationDestination, error) {
+	d, err := buildDestination(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, d); err != nil {
+		return nil, err
+	}
+	s.log.Infow("notification_destination_created", "id", d.ID, "chat_id", d.ChatID, "rules", len(d.Rules))
+	return d, nil
+}
+
+func (s *NotificationRoutingService) Update(ctx context.Context, id int, req models.NotificationDestinationRequest) (*models.NotificationDestination, error) {
+	d, err := buildDestination(req)
+	if err != nil {
+		return nil, err
+	}
+	d.ID = id
+	if err := s.repo.Update(ctx, d); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrDestinationNotFound
+		}
+		return nil, err
+	}
+	s.log.Infow("notification_destination_updated", "id", id, "rules", len(d.Rules))
+	return d, nil
+}
+
+func (s *NotificationRoutingService) Delete(ctx context.Context, id int) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrDestinationNotFound
+		}
+		return err
+	}
+	s.log.Infow("notification_destination_deleted", "id", id)
+	return nil
+}
+
+func buildDestination(req models.NotificationDestinationRequest) (*models.NotificationDestination, error) {
+	d := &models.NotificationDestination{
+		Name:      strings.TrimSpace(req.Name),
+		ChatID:    strings.TrimSpace(req.ChatID),
+		QuietFrom: trimmedOrNil(req.QuietFrom),
+		QuietTo:   trimmedOrNil(req.QuietTo),
+		Timezone:  helpers.IfEmpty(&req.Timezone, defaultQuietTimezone),
+		IsActive:  req.IsActive == nil || *req.IsActive,
+	}
+	if (d.QuietFrom == nil) != (d.QuietTo == nil) {
+		return nil, helpers.ErrInvalidInput("quiet_from and quiet_to must be set together")
+	}
+	if _, err := notifications.ParseQuietHours(helpers.IfEmpty(d.QuietFrom, ""), helpers.IfEmpty(d.QuietTo, ""), d.Timezone); err != nil {
+		return nil, helpers.ErrInvalidInput(err.Error())
+	}
+
+	for i, r := range req.Rules {
+		rule := models.NotificationRule{
+			EventType:     trimmedOrNil(r.EventType),
+			TripType:      trimmedOrNil(r.TripType),
+			DepartureCity: trimmedOrNil(r.DepartureCity),
+			MinAmount:     r.MinAmount,
+			MaxAmount:     r.MaxAmount,
+		}
+		if rule.EventType != nil && !notifications.IsValidEventType(notifications.EventType(*rule.EventType)) {
+			return nil, helpers.ErrInvalidInput(fmt.Sprintf("rule %d: unknown event %q", i+1, *rule.EventType))
+		}
+		if rule.MinAmount != nil && rule.MaxAmount != nil && *rule.MinAmount > *rule.MaxAmount {
+			return nil, helpers.ErrInvalidInput(fmt.Sprintf("rule %d: min_amount is greater than max_amount", i+1))
+		}
+		d.Rules = append(d.Rules, rule)
+	}
+	return d, nil
+}
@@ -0,0 +1,117 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/notifications"
+	"github.com/Ramcache/travel-backend/internal/repository"
+	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/Ramcache/travel-backend/internal/testutil"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func newRoutingService(t *testing.T, db *testutil.MockDB) *services.NotificationRoutingService {
+	return services.NewNotificationRoutingService(repository.NewNotificationRoutingRepo(db), zaptest.NewLogger(t).Sugar())
+}
+
+func TestNotificationRoutingService_Create(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newRoutingService(t, db)
+
+	db.ExpectQueryRow(func(_ context.Context, sql string, args []any) (pgx.Row, error) {
+		assert.Contains(t, sql, "INSERT INTO notification_destinations")
+		// часовой пояс и активность по умолчанию
+		assert.Equal(t, []any{"VIP", "-100", ptr("22:00"), ptr("08:00"), "Europe/Moscow", true}, args)
+		return testutil.NewSliceRow([]any{4, time.Now(), time.Now()}), nil
+	})
+	db.ExpectQueryRow(func(_ context.Context, sql string, args []any) (pgx.Row, error) {
+		assert.Contains(t, sql, "INSERT INTO notification_rules")
+		assert.Equal(t, 4, args[0])
+		// пустые условия сохраняются как NULL
+		assert.Nil(t, args[2])
+		return testutil.NewSliceRow([]any{9}), nil
+	})
+
+	min := 250000.0
+	d, err := svc.Create(context.Background(), models.NotificationDestinationRequest{
+		Name: " VIP ", ChatID: "-100", QuietFrom: ptr("22:00"), QuietTo: ptr("08:00"),
+		Rules: []models.NotificationRuleRequest{{EventType: ptr("order.created"), TripType: ptr(" "), MinAmount: &min}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 4, d.ID)
+	assert.Equal(t, 9, d.Rules[0].ID)
+	db.Verify(t)
+}
+
+func TestNotificationRoutingService_Create_Invalid(t *testing.T) {
+	min, max := 200.0, 100.0
+	rule := []models.NotificationRuleRequest{{}}
+	cases := map[string]models.NotificationDestinationRequest{
+		"только начало тихих часов": {QuietFrom: ptr("22:00"), Rules: rule},
+		"неверное время":            {QuietFrom: ptr("22:00"), QuietTo: ptr("8 утра"), Rules: rule},
+		"неизвестный часовой пояс":  {Timezone: "Mars/Olympus", Rules: rule},
+		"неизвестное событие":       {Rules: []models.NotificationRuleRequest{{EventType: ptr("order.deleted")}}},
+		"min больше max":            {Rules: []models.NotificationRuleRequest{{MinAmount: &min, MaxAmount: &max}}},
+	}
+	for name, req := range cases {
+		t.Run(name, func(t *testing.T) {
+			db := testutil.NewMockDB(t)
+			req.Name, req.ChatID = "Чат", "-100"
+			_, err := newRoutingService(t, db).Create(context.Background(), req)
+			assert.True(t, helpers.IsInvalidInput(err), "%v", err)
+			db.Verify(t)
+		})
+	}
+}
+
+func TestNotificationRoutingService_Update_NotFound(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newRoutingService(t, db)
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return nil, pgx.ErrNoRows
+	})
+
+	_, err := svc.Update(context.Background(), 404, models.NotificationDestinationRequest{
+		Name: "Чат", ChatID: "-100", Rules: []models.NotificationRuleRequest{{}},
+	})
+	assert.ErrorIs(t, err, services.ErrDestinationNotFound)
+	db.Verify(t)
+}
+
+func TestNotificationRoutingService_Rules(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newRoutingService(t, db)
+
+	now := time.Now()
+	db.ExpectQuery(func(context.Context, string, []any) (pgx.Rows, error) {
+		return testutil.NewMockRows([][]any{
+			{1, "Умра", "-100", ptr("22:00"), ptr("08:00"), "Asia/Dubai", true, now, now},
+			{2, "Архив", "-200", nil, nil, "Europe/Moscow", false, now, now},
+		}), nil
+	})
+	db.ExpectQuery(func(_ context.Context, _ string, args []any) (pgx.Rows, error) {
+		assert.Equal(t, []any{[]int{1, 2}}, args)
+		return testutil.NewMockRows([][]any{
+			{10, 1, ptr("order.created"), ptr("umrah"), nil, nil, nil},
+			{11, 2, nil, nil, nil, nil, nil},
+		}), nil
+	})
+
+	rules, err := svc.Rules(context.Background())
+	require.NoError(t, err)
+	// выключенный чат не участвует
+	require.Len(t, rules, 1)
+	assert.Equal(t, notifications.EventOrderCreated, rules[0].EventType)
+	assert.Equal(t, "umrah", rules[0].TripType)
+	assert.Equal(t, "-100", rules[0].Destination.ChatID)
+	assert.Equal(t, 22*time.Hour, rules[0].Destination.Quiet.From)
+	assert.Equal(t, "Asia/Dubai", rules[0].Destination.Quiet.Location.String())
+	db.Verify(t)
+}
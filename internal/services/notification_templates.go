@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/notifications"
+	"github.com/Ramcache/travel-backend/internal/repository"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrTemplateNotFound = errors.New("notification template not found")
+	ErrTemplateExists   = errors.New("notification template already exists")
+)
+
+// NotificationTemplateService — шаблоны уведомлений из админки.
+// Реализует notifications.TemplateStore.
+type NotificationTemplateService struct {
+	repo   *repository.NotificationTemplateRepo
+	links  notifications.Links
+	locale string
+	log    *zap.SugaredLogger
+}
+
+func NewNotificationTemplateService(repo *repository.NotificationTemplateRepo, links notifications.Links, locale string, log *zap.SugaredLogger) *NotificationTemplateService {
+	if locale == "" {
+		locale = notifications.DefaultLocale
+	}
+	return &NotificationTemplateService{repo: repo, links: links, locale: locale, log: log}
+}
+
+// FindTemplate — активный шаблон или nil, если его нет
+func (s *NotificationTemplateService) FindTemplate(ctx context.Context, event notifications.EventType, audience, channel, locale string) (*notifications.Template, error) {
+	t, err := s.repo.GetByKey(ctx, string(event), audience, channel, locale)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !t.IsActive {
+		return nil, nil
+	}
+	return toTemplate(t), nil
+}
+
+func (s *NotificationTemplateService) List(ctx context.Context, f models.NotificationTemplateFilter) ([]models.NotificationTemplate, error) {
+	list, err := s.repo.List(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		list = []models.NotificationTemplate{}
+	}
+	return list, nil
+}
+
+func (s *NotificationTemplateService) Get(ctx context.Context, id int) (*models.NotificationTemplate, error) {
+	t, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrTemplateNotFound
+	}
+	return t, err
+}
+
+func (s *NotificationTemplateService) Create(ctx context.Context, req models.NotificationTemplateRequest, actorID *int) (*models.NotificationTemplate, error) {
+	t, err := s.build(req, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureUnique(ctx, t); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, t); err != nil {
+		return nil, err
+	}
+	s.log.Infow("notification_template_created", "id", t.ID, "event", t.EventType, "audience", t.Audience, "channel", t.Channel, "locale", t.Locale)
+	return t, nil
+}
+
+func (s *NotificationTemplateService) Update(ctx context.Context, id int, req models.NotificationTemplateRequest, actorID *int) (*models.NotificationTemplate, error) {
+	t, err := s.build(req, actorID)
+	if err != nil {
+		return nil, err
+	}
+	t.ID = id
+	if err := s.ensureUnique(ctx, t); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, t); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, err
+	}
+	s.log.Infow("notification_template_updated", "id", id)
+	return t, nil
+}
+
+func (s *NotificationTemplateService) Delete(ctx context.Context, id int) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrTemplateNotFound
+		}
+		return err
+	}
+	s.log.Infow("notification_template_deleted", "id", id)
+	return nil
+}
+
+// Preview рендерит шаблон (сохранять не нужно) на примере заказа
+func (s *NotificationTemplateService) Preview(req models.NotificationTemplateRequest) (*models.NotificationPreview, error) {
+	t, err := s.build(req, nil)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := s.render(t)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &models.NotificationPreview{Subject: msg.Subject, Body: msg.Text}
+	if msg.Link != nil {
+		p.LinkText, p.LinkURL = &msg.Link.Text, &msg.Link.URL
+	}
+	return p, nil
+}
+
+// build проверяет запрос и что шаблон рендерится на примере события
+func (s *NotificationTemplateService) build(req models.NotificationTemplateRequest, actorID *int) (*models.NotificationTemplate, error) {
+	if !notifications.IsValidEventType(notifications.EventType(req.EventType)) {
+		return nil, helpers.ErrInvalidInput(fmt.Sprintf("unknown event %q", req.EventType))
+	}
+	switch req.Channel {
+	case notifications.ChannelTelegram, notifications.ChannelEmail, notifications.ChannelSMS:
+	default:
+		return nil, helpers.ErrInvalidInput(fmt.Sprintf("unknown channel %q", req.Channel))
+	}
+
+	t := &models.NotificationTemplate{
+		EventType: req.EventType,
+		Audience:  helpers.IfEmpty(&req.Audience, notifications.AudienceAdmin),
+		Channel:   req.Channel,
+		Locale:    helpers.IfEmpty(&req.Locale, s.locale),
+		Subject:   req.Subject,
+		Body:      req.Body,
+		LinkText:  trimmedOrNil(req.LinkText),
+		LinkURL:   trimmedOrNil(req.LinkURL),
+		IsActive:  req.IsActive == nil || *req.IsActive,
+		UpdatedBy: actorID,
+	}
+	// ссылка на сброс пароля и коды подтверждения не должны попасть в чат менеджеров
+	if notifications.IsCustomerOnly(notifications.EventType(t.EventType)) && t.Audience != notifications.AudienceCustomer {
+		return nil, helpers.ErrInvalidInput(fmt.Sprintf("%s templates are for the customer audience only", t.EventType))
+	}
+	if _, err := s.render(t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (s *NotificationTemplateService) render(t *models.NotificationTemplate) (notifications.Message, error) {
+	ev := notifications.SampleEvent(notifications.EventType(t.EventType))
+	msg, err := toTemplate(t).Render(t.Channel, notifications.NewTemplateData(ev, s.links))
+	if err != nil {
+		return notifications.Message{}, helpers.ErrInvalidInput("template: " + err.Error())
+	}
+	return msg, nil
+}
+
+func (s *NotificationTemplateService) ensureUnique(ctx context.Context, t *models.NotificationTemplate) error {
+	existing, err := s.repo.GetByKey(ctx, t.EventType, t.Audience, t.Channel, t.Locale)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID != t.ID {
+		return ErrTemplateExists
+	}
+	return nil
+}
+
+func toTemplate(t *models.NotificationTemplate) *notifications.Template {
+	out := &notifications.Template{Subject: t.Subject, Body: t.Body}
+	if t.LinkText != nil {
+		out.LinkText = *t.LinkText
+	}
+	if t.LinkURL != nil {
+		out.LinkURL = *t.LinkURL
+	}
+	return out
+}
+
+func trimmedOrNil(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	v := strings.TrimSpace(*s)
+	if v == "" {
+		return nil
+	}
+	return &v
+}
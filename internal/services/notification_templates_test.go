@@ -0,0 +1,112 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/notifications"
+	"github.com/Ramcache/travel-backend/internal/repository"
+	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/Ramcache/travel-backend/internal/testutil"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func newTemplateService(t *testing.T, db *testutil.MockDB) *services.NotificationTemplateService {
+	return services.NewNotificationTemplateService(repository.NewNotificationTemplateRepo(db),
+		notifications.Links{TrackingURL: "https://t.example/track"}, "", zaptest.NewLogger(t).Sugar())
+}
+
+func templateRow(id int, active bool) []any {
+	now := time.Now()
+	return []any{id, "order.created", "admin", "telegram", "ru", "", "Заказ {{.Order.ID}}", nil, nil, active, nil, now, now}
+}
+
+func TestNotificationTemplateService_Create(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newTemplateService(t, db)
+
+	db.ExpectQueryRow(func(_ context.Context, _ string, args []any) (pgx.Row, error) {
+		// аудитория и язык по умолчанию
+		assert.Equal(t, []any{"order.created", "admin", "telegram", "ru"}, args)
+		return nil, pgx.ErrNoRows
+	})
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{5, time.Now(), time.Now()}), nil
+	})
+
+	actor := 1
+	tpl, err := svc.Create(context.Background(), models.NotificationTemplateRequest{
+		EventType: "order.created", Channel: "telegram", Body: "Заказ {{.Order.ID}}",
+	}, &actor)
+	require.NoError(t, err)
+	assert.Equal(t, 5, tpl.ID)
+	assert.True(t, tpl.IsActive)
+	db.Verify(t)
+}
+
+func TestNotificationTemplateService_Create_Exists(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newTemplateService(t, db)
+
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow(templateRow(3, true)), nil
+	})
+
+	_, err := svc.Create(context.Background(), models.NotificationTemplateRequest{
+		EventType: "order.created", Channel: "telegram", Body: "Заказ {{.Order.ID}}",
+	}, nil)
+	assert.ErrorIs(t, err, services.ErrTemplateExists)
+	db.Verify(t)
+}
+
+func TestNotificationTemplateService_Create_InvalidTemplate(t *testing.T) {
+	svc := newTemplateService(t, testutil.NewMockDB(t))
+
+	for _, req := range []models.NotificationTemplateRequest{
+		{EventType: "order.deleted", Channel: "telegram", Body: "x"},
+		{EventType: "order.created", Channel: "fax", Body: "x"},
+		{EventType: "order.created", Channel: "telegram", Body: "{{.Order.ID"},
+		// у заявки на консультацию нет заказа — проверяется на примере события
+		{EventType: "feedback.received", Channel: "telegram", Body: "{{.Order.ID}}"},
+		// ссылка на сброс пароля — только самому пользователю
+		{EventType: "auth.password_reset", Channel: "telegram", Body: "{{.ResetURL}}"},
+	} {
+		_, err := svc.Create(context.Background(), req, nil)
+		assert.True(t, helpers.IsInvalidInput(err), "%+v: %v", req, err)
+	}
+}
+
+func TestNotificationTemplateService_Preview(t *testing.T) {
+	svc := newTemplateService(t, testutil.NewMockDB(t))
+
+	link := "{{.TrackingURL}}"
+	p, err := svc.Preview(models.NotificationTemplateRequest{
+		EventType: "order.status_changed", Audience: "customer", Channel: "email",
+		Subject: "Заявка №{{.Order.ID}}", Body: "Статус: {{.ToTitle}}", LinkURL: &link,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Заявка №1024", p.Subject)
+	assert.Equal(t, "Статус: подтверждена", p.Body)
+	require.NotNil(t, p.LinkURL)
+	assert.Equal(t, "https://t.example/track/sample-tracking-token", *p.LinkURL)
+}
+
+func TestNotificationTemplateService_FindTemplate_SkipsInactive(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newTemplateService(t, db)
+
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow(templateRow(3, false)), nil
+	})
+
+	tpl, err := svc.FindTemplate(context.Background(), notifications.EventOrderCreated, "admin", "telegram", "ru")
+	require.NoError(t, err)
+	assert.Nil(t, tpl)
+	db.Verify(t)
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/notifications"
+	"github.com/Ramcache/travel-backend/internal/repository"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+	// ErrNotificationSecret — в уведомлении одноразовая ссылка или код, повторно не отправляется
+	ErrNotificationSecret = errors.New("notification contains a one-time secret")
+)
+
+const (
+	// сколько уведомлений диспетчер забирает за раз
+	notificationBatch = 50
+	// через сколько уведомление, взятое упавшим диспетчером, снова станет доступно
+	notificationLease = 5 * time.Minute
+	// потолок паузы между попытками
+	notificationMaxBackoff = 6 * time.Hour
+	// чем заменяется текст с одноразовым секретом в админке и после доставки
+	notificationRedacted = "Текст скрыт: в нём одноразовая ссылка или код для пользователя"
+)
+
+// OutboxConfig — повторы отправки уведомлений
+type OutboxConfig struct {
+	// после стольких неудачных попыток уведомление уходит в dead letter
+	MaxAttempts int
+	// пауза перед второй попыткой, дальше удваивается
+	RetryBase time.Duration
+}
+
+// NotificationService кладёт уведомления в outbox и доставляет их в фоне.
+// Уведомление пишется в той же транзакции, что и заказ: если уведомить не удалось,
+// заказ всё равно создан, а если заказ не создан — уведомления нет.
+type NotificationService struct {
+	repo   *repository.NotificationRepo
+	router *notifications.Router
+	cfg    OutboxConfig
+	log    *zap.SugaredLogger
+}
+
+func NewNotificationService(repo *repository.NotificationRepo, router *notifications.Router, cfg OutboxConfig, log *zap.SugaredLogger) *NotificationService {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 8
+	}
+	if cfg.RetryBase <= 0 {
+		cfg.RetryBase = 30 * time.Second
+	}
+	return &NotificationService{repo: repo, router: router, cfg: cfg, log: log}
+}
+
+// Enqueue рендерит событие по маршрутам и кладёт сообщения в outbox через tx.
+// У nil-сервиса ничего не делает — уведомления выключены.
+func (s *NotificationService) Enqueue(ctx context.Context, tx repository.DB, ev notifications.Event) error {
+	if s == nil {
+		return nil
+	}
+
+	repo := s.repo.WithTx(tx)
+	for _, d := range s.router.Deliveries(ctx, ev) {
+		n := &models.OutboxNotification{
+			EventType:  string(d.Event),
+			Channel:    d.Target.Channel,
+			Audience:   d.Target.Audience,
+			Recipients: d.Message.To,
+			Subject:    d.Message.Subject,
+			Body:       d.Message.Text,
+			// тихие часы чата: уведомление ждёт в очереди до утра
+			NextAttemptAt: d.NotBefore,
+		}
+		if d.Message.Link != nil {
+			n.LinkText = &d.Message.Link.Text
+			n.LinkURL = &d.Message.Link.URL
+		}
+		for _, a := range d.Message.Actions {
+			n.Actions = append(n.Actions, models.NotificationAction{Text: a.Text, Data: a.Data})
+		}
+		if err := repo.Create(ctx, n); err != nil {
+			return fmt.Errorf("enqueue notification: %w", err)
+		}
+	}
+	return nil
+}
+
+// Dispatch отправляет всё, чему пришло время; возвращает число доставленных
+func (s *NotificationService) Dispatch(ctx context.Context) (int, error) {
+	sent := 0
+	for {
+		batch, err := s.repo.Claim(ctx, notificationBatch, notificationLease)
+		if err != nil {
+			return sent, err
+		}
+		for _, n := range batch {
+			if s.deliver(ctx, n) {
+				sent++
+			}
+		}
+		if len(batch) < notificationBatch {
+			return sent, nil
+		}
+	}
+}
+
+func (s *NotificationService) deliver(ctx context.Context, n models.OutboxNotification) bool {
+	msg := notifications.Message{To: n.Recipients, Subject: n.Subject, Text: n.Body}
+	if n.LinkURL != nil {
+		msg.Link = &notifications.Link{URL: *n.LinkURL}
+		if n.LinkText != nil {
+			msg.Link.Text = *n.LinkText
+		}
+	}
+	for _, a := range n.Actions {
+		msg.Actions = append(msg.Actions, notifications.Action{Text: a.Text, Data: a.Data})
+	}
+
+	err := s.router.Deliver(ctx, notifications.Delivery{
+		Event:   notifications.EventType(n.EventType),
+		Target:  notifications.Target{Channel: n.Channel, Audience: n.Audience},
+		Message: msg,
+	})
+	if err == nil {
+		if err := s.repo.MarkSent(ctx, n.ID); err != nil {
+			// повторная отправка лучше потерянной: строка уйдёт ещё раз после lease
+			s.log.Errorw("notification_mark_sent_failed", "id", n.ID, "err", err)
+			return true
+		}
+		s.wipeSecret(ctx, n)
+		return true
+	}
+
+	var next *time.Time
+	if n.Attempts < s.cfg.MaxAttempts {
+		at := time.Now().Add(s.backoff(n.Attempts))
+		next = &at
+		s.log.Warnw("notification_retry", "id", n.ID, "channel", n.Channel, "attempt", n.Attempts, "next_attempt_at", at, "err", err)
+	} else {
+		s.log.Errorw("Уведомление не доставлено, попытки исчерпаны", "id", n.ID, "event", n.EventType, "channel", n.Channel, "attempts", n.Attempts, "err", err)
+	}
+	if err := s.repo.MarkFailed(ctx, n.ID, err.Error(), next); err != nil {
+		s.log.Errorw("notification_mark_failed_failed", "id", n.ID, "err", err)
+		return false
+	}
+	if next == nil {
+		s.wipeSecret(ctx, n)
+	}
+	return false
+}
+
+// wipeSecret стирает из базы одноразовый секрет, когда он больше не будет отправляться
+func (s *NotificationService) wipeSecret(ctx context.Context, n models.OutboxNotification) {
+	if !notifications.HasSecret(notifications.EventType(n.EventType)) {
+		return
+	}
+	if err := s.repo.Redact(ctx, n.ID, notificationRedacted); err != nil {
+		s.log.Errorw("notification_redact_failed", "id", n.ID, "err", err)
+	}
+}
+
+// redact скрывает одноразовый секрет от админки: ещё не доставленное уведомление хранит его в базе
+func redact(n *models.OutboxNotification) {
+	if !notifications.HasSecret(notifications.EventType(n.EventType)) {
+		return
+	}
+	n.Subject = ""
+	n.Body = notificationRedacted
+	n.LinkURL = nil
+}
+
+// backoff — пауза после attempt-й неудачной попытки: base, 2·base, 4·base… не больше потолка
+func (s *NotificationService) backoff(attempt int) time.Duration {
+	d := s.cfg.RetryBase
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= notificationMaxBackoff {
+			return notificationMaxBackoff
+		}
+	}
+	return d
+}
+
+// RunDispatcher раз в interval доставляет накопившиеся уведомления
+func (s *NotificationService) RunDispatcher(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			n, err := s.Dispatch(ctx)
+			if err != nil {
+				s.log.Errorw("notification_dispatch_failed", "err", err)
+				continue
+			}
+			if n > 0 {
+				s.log.Debugw("notifications_dispatched", "count", n)
+			}
+		}
+	}
+}
+
+type NotificationsWithTotal struct {
+	Total         int                         `json:"total"`
+	Notifications []models.OutboxNotification `json:"notifications"`
+}
+
+func (s *NotificationService) List(ctx context.Context, f models.NotificationFilter, limit, offset int) (*NotificationsWithTotal, error) {
+	if f.Status != "" && !models.IsValidNotificationStatus(f.Status) {
+		return nil, helpers.ErrInvalidInput(fmt.Sprintf("invalid status %q", f.Status))
+	}
+	if f.EventType != "" && !notifications.IsValidEventType(notifications.EventType(f.EventType)) {
+		return nil, helpers.ErrInvalidInput(fmt.Sprintf("invalid event %q", f.EventType))
+	}
+
+	total, err := s.repo.Count(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.repo.List(ctx, f, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		list = []models.OutboxNotification{}
+	}
+	for i := range list {
+		redact(&list[i])
+	}
+	return &NotificationsWithTotal{Total: total, Notifications: list}, nil
+}
+
+func (s *NotificationService) Get(ctx context.Context, id int) (*models.OutboxNotification, error) {
+	n, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrNotificationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	redact(n)
+	return n, nil
+}
+
+// Resend возвращает уведомление в очередь с новым счётчиком попыток —
+// для dead letter после починки канала или чтобы повторить уже доставленное.
+// Ссылку или код повторно не отправляем: пользователь запросит новые сам
+func (s *NotificationService) Resend(ctx context.Context, id int) (*models.OutboxNotification, error) {
+	n, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrNotificationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if notifications.HasSecret(notifications.EventType(n.EventType)) {
+		return nil, ErrNotificationSecret
+	}
+
+	n, err = s.repo.Resend(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrNotificationNotFound
+	}
+	return n, err
+}
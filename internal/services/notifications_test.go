@@ -0,0 +1,237 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/notifications"
+	"github.com/Ramcache/travel-backend/internal/repository"
+	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/Ramcache/travel-backend/internal/testutil"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func newNotificationService(t *testing.T, db repository.DB, routes string, notifiers ...notifications.Notifier) *services.NotificationService {
+	t.Helper()
+	parsed, err := notifications.ParseRoutes(routes)
+	require.NoError(t, err)
+	log := zaptest.NewLogger(t).Sugar()
+	router := notifications.NewRouter(parsed, notifications.NewDefaultRenderer(notifications.Links{TrackingURL: "https://t.example/"}), log, notifiers...)
+	return services.NewNotificationService(repository.NewNotificationRepo(db), router,
+		services.OutboxConfig{MaxAttempts: 3, RetryBase: time.Minute}, log)
+}
+
+func outboxRow(id, attempts int) []any {
+	now := time.Now()
+	link := "https://example.com"
+	return []any{id, "order.created", notifications.ChannelTelegram, notifications.AudienceAdmin, []string{}, "Новый заказ", "text",
+		nil, &link, nil, models.NotificationPending, attempts, nil, now, nil, now, now}
+}
+
+func expectClaim(db *testutil.MockDB, rows ...[]any) {
+	db.ExpectQuery(func(_ context.Context, _ string, args []any) (pgx.Rows, error) {
+		return testutil.NewMockRows(rows), nil
+	})
+}
+
+func TestNotificationService_Dispatch_Sent(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	tg := notifications.NewRecorder(notifications.ChannelTelegram)
+	svc := newNotificationService(t, db, notifications.DefaultRoutes, tg)
+
+	expectClaim(db, outboxRow(1, 1))
+	db.ExpectExec(func(_ context.Context, sql string, args []any) (pgconn.CommandTag, error) {
+		assert.Contains(t, sql, "status = 'sent'")
+		assert.Equal(t, 1, args[0])
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+
+	n, err := svc.Dispatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	db.Verify(t)
+
+	require.Len(t, tg.Sent(), 1)
+	assert.Equal(t, "text", tg.Sent()[0].Text)
+	require.NotNil(t, tg.Sent()[0].Link)
+	assert.Equal(t, "https://example.com", tg.Sent()[0].Link.URL)
+}
+
+func TestNotificationService_Dispatch_RetriesWithBackoff(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	tg := notifications.NewRecorder(notifications.ChannelTelegram)
+	tg.Err = errors.New("telegram is down")
+	svc := newNotificationService(t, db, notifications.DefaultRoutes, tg)
+
+	expectClaim(db, outboxRow(1, 2))
+	db.ExpectExec(func(_ context.Context, _ string, args []any) (pgconn.CommandTag, error) {
+		require.Len(t, args, 3)
+		assert.Equal(t, "telegram is down", args[1])
+		// вторая неудача — пауза удваивается: 2 минуты
+		next := args[2].(time.Time)
+		assert.Equal(t, time.UTC, next.Location())
+		assert.WithinDuration(t, time.Now().Add(2*time.Minute), next, 5*time.Second)
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+
+	n, err := svc.Dispatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	db.Verify(t)
+}
+
+// chatRules — правила маршрутизации, заданные в тесте
+type chatRules []notifications.Rule
+
+func (r chatRules) Rules(context.Context) ([]notifications.Rule, error) { return r, nil }
+
+func TestNotificationService_Enqueue_QuietHoursStoredInUTC(t *testing.T) {
+	// чат во Владивостоке (UTC+10): тихие часы идут сейчас и кончаются через час по его часам
+	loc, err := time.LoadLocation("Asia/Vladivostok")
+	require.NoError(t, err)
+	local := time.Now().In(loc)
+	quiet, err := notifications.ParseQuietHours(local.Add(-time.Hour).Format("15:04"), local.Add(time.Hour).Format("15:04"), loc.String())
+	require.NoError(t, err)
+	want := quiet.Until(time.Now())
+	require.True(t, want.After(time.Now()))
+
+	routes, err := notifications.ParseRoutes("order.created=telegram")
+	require.NoError(t, err)
+	log := zaptest.NewLogger(t).Sugar()
+	router := notifications.NewRouter(routes, notifications.NewDefaultRenderer(notifications.Links{}), log,
+		notifications.NewRecorder(notifications.ChannelTelegram)).
+		UseRules(chatRules{{Destination: notifications.Destination{ID: 1, ChatID: "-100", Quiet: quiet}}})
+	db := testutil.NewMockDB(t)
+	svc := services.NewNotificationService(repository.NewNotificationRepo(db), router,
+		services.OutboxConfig{MaxAttempts: 3, RetryBase: time.Minute}, log)
+
+	db.ExpectQueryRow(func(_ context.Context, _ string, args []any) (pgx.Row, error) {
+		next := args[9].(*time.Time)
+		require.NotNil(t, next)
+		// момент тот же, но без пояса чата: колонка сравнивается с now() сервера БД
+		assert.Equal(t, time.UTC, next.Location())
+		assert.True(t, want.Equal(*next), "got %s, want %s", next, want)
+		now := time.Now()
+		return testutil.NewSliceRow([]any{1, models.NotificationPending, *next, now, now}), nil
+	})
+
+	ev := notifications.OrderCreated{Order: &models.Order{ID: 7, Status: models.OrderStatusNew, UserName: "Иван"}, At: time.Now()}
+	require.NoError(t, svc.Enqueue(context.Background(), db, ev))
+	db.Verify(t)
+}
+
+func TestNotificationService_Dispatch_DeadLetter(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	// канал выключен в конфиге — доставить нельзя
+	svc := newNotificationService(t, db, notifications.DefaultRoutes)
+
+	expectClaim(db, outboxRow(1, 3))
+	db.ExpectExec(func(_ context.Context, sql string, args []any) (pgconn.CommandTag, error) {
+		assert.Contains(t, sql, "status = 'dead'")
+		require.Len(t, args, 2)
+		assert.Contains(t, args[1], "not configured")
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+
+	_, err := svc.Dispatch(context.Background())
+	require.NoError(t, err)
+	db.Verify(t)
+}
+
+func TestNotificationService_Resend_NotFound(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newNotificationService(t, db, notifications.DefaultRoutes)
+
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return nil, pgx.ErrNoRows
+	})
+
+	_, err := svc.Resend(context.Background(), 5)
+	assert.ErrorIs(t, err, services.ErrNotificationNotFound)
+	db.Verify(t)
+}
+
+func TestNotificationService_List_InvalidStatus(t *testing.T) {
+	svc := newNotificationService(t, testutil.NewMockDB(t), notifications.DefaultRoutes)
+	_, err := svc.List(context.Background(), models.NotificationFilter{Status: "lost"}, 20, 0)
+	assert.True(t, helpers.IsInvalidInput(err))
+}
+
+// secretRow — уведомление со ссылкой на сброс пароля
+func secretRow(id int, status string) []any {
+	now := time.Now()
+	link, text := "https://example.com/reset?token=s3cret", "Сбросить пароль"
+	return []any{id, "auth.password_reset", notifications.ChannelEmail, notifications.AudienceCustomer, []string{"ivan@example.com"},
+		"Сброс пароля", "https://example.com/reset?token=s3cret", &text, &link, nil, status, 1, nil, now, nil, now, now}
+}
+
+func TestNotificationService_HidesSecrets(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newNotificationService(t, db, notifications.DefaultRoutes)
+
+	expectCount(db, 1)
+	db.ExpectQuery(func(context.Context, string, []any) (pgx.Rows, error) {
+		return testutil.NewMockRows([][]any{secretRow(1, models.NotificationPending)}), nil
+	})
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow(secretRow(1, models.NotificationPending)), nil
+	})
+
+	list, err := svc.List(context.Background(), models.NotificationFilter{}, 20, 0)
+	require.NoError(t, err)
+	n, err := svc.Get(context.Background(), 1)
+	require.NoError(t, err)
+	for _, got := range []models.OutboxNotification{list.Notifications[0], *n} {
+		assert.NotContains(t, got.Body, "s3cret")
+		assert.Empty(t, got.Subject)
+		assert.Nil(t, got.LinkURL)
+	}
+	db.Verify(t)
+}
+
+func TestNotificationService_Resend_Secret(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newNotificationService(t, db, notifications.DefaultRoutes)
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow(secretRow(1, models.NotificationSent)), nil
+	})
+
+	// в очередь не возвращается
+	_, err := svc.Resend(context.Background(), 1)
+	assert.ErrorIs(t, err, services.ErrNotificationSecret)
+	db.Verify(t)
+}
+
+func TestNotificationService_Dispatch_WipesSecret(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	email := notifications.NewRecorder(notifications.ChannelEmail)
+	svc := newNotificationService(t, db, notifications.DefaultRoutes, email)
+
+	expectClaim(db, secretRow(1, models.NotificationPending))
+	db.ExpectExec(func(_ context.Context, sql string, _ []any) (pgconn.CommandTag, error) {
+		assert.Contains(t, sql, "status = 'sent'")
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+	db.ExpectExec(func(_ context.Context, sql string, args []any) (pgconn.CommandTag, error) {
+		assert.Contains(t, sql, "link_url = NULL")
+		assert.Equal(t, 1, args[0])
+		assert.NotContains(t, args[1], "s3cret")
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+
+	n, err := svc.Dispatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	db.Verify(t)
+	// пользователь ссылку получил
+	require.Len(t, email.Sent(), 1)
+	assert.Equal(t, "https://example.com/reset?token=s3cret", email.Sent()[0].Link.URL)
+}
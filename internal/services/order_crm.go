@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/notifications"
+	"github.com/Ramcache/travel-backend/internal/repository"
+)
+
+var (
+	ErrNoteNotFound      = errors.New("order note not found")
+	ErrAssignmentChanged = errors.New("order assignee changed concurrently")
+)
+
+const (
+	maxOrderTags   = 20
+	maxOrderTagLen = 32
+	maxNoteLen     = 4000
+)
+
+// OrderCRMService — работа менеджеров с заказом: ответственный, заметки,
+// следующий звонок и метки
+type OrderCRMService struct {
+	repo   *repository.OrderRepo
+	notify *NotificationService
+	log    *zap.SugaredLogger
+}
+
+func NewOrderCRMService(repo *repository.OrderRepo, notify *NotificationService, log *zap.SugaredLogger) *OrderCRMService {
+	return &OrderCRMService{repo: repo, notify: notify, log: log}
+}
+
+// Assign назначает ответственного менеджера (nil — снять назначение)
+// и пишет переназначение в историю заказа; назначение и запись в истории — одна транзакция
+func (s *OrderCRMService) Assign(ctx context.Context, orderID int, assignee *int, actor models.Actor, comment string) error {
+	return s.repo.Tx(ctx, func(tx repository.DB) error {
+		repo := s.repo.WithTx(tx)
+		status, current, err := repo.GetAssignment(ctx, orderID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrOrderNotFound
+			}
+			return err
+		}
+
+		if assignee != nil {
+			if _, err := repo.StaffName(ctx, *assignee); err != nil {
+				if errors.Is(err, repository.ErrNotFound) {
+					return helpers.ErrInvalidInput("user is not a manager")
+				}
+				return err
+			}
+		}
+
+		if sameAssignee(current, assignee) {
+			return nil
+		}
+
+		if err := repo.SetAssignee(ctx, orderID, current, assignee); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrAssignmentChanged
+			}
+			return err
+		}
+
+		h := &models.OrderStatusChange{
+			OrderID:      orderID,
+			Event:        models.OrderEventAssignment,
+			FromStatus:   &status,
+			ToStatus:     status,
+			ActorID:      actor.ID,
+			FromAssignee: current,
+			ToAssignee:   assignee,
+		}
+		if actor.Name != "" {
+			h.ActorName = &actor.Name
+		}
+		if c := strings.TrimSpace(comment); c != "" {
+			h.Comment = &c
+		}
+		return repo.AddStatusHistory(ctx, h)
+	})
+}
+
+func (s *OrderCRMService) Notes(ctx context.Context, orderID int) ([]models.OrderNote, error) {
+	if err := s.ensureOrder(ctx, orderID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListNotes(ctx, orderID)
+}
+
+func (s *OrderCRMService) AddNote(ctx context.Context, orderID, authorID int, body string) (*models.OrderNote, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, helpers.ErrInvalidInput("note body is required")
+	}
+	if utf8.RuneCountInString(body) > maxNoteLen {
+		return nil, helpers.ErrInvalidInput(fmt.Sprintf("note is longer than %d characters", maxNoteLen))
+	}
+	if err := s.ensureOrder(ctx, orderID); err != nil {
+		return nil, err
+	}
+
+	n := &models.OrderNote{OrderID: orderID, AuthorID: &authorID, Body: body}
+	if err := s.repo.AddNote(ctx, n); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// DeleteNote — удалить можно только свою заметку
+func (s *OrderCRMService) DeleteNote(ctx context.Context, orderID, noteID, authorID int) error {
+	if err := s.repo.DeleteNote(ctx, orderID, noteID, authorID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNoteNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// SetFollowUp ставит дату следующего звонка; nil снимает напоминание
+func (s *OrderCRMService) SetFollowUp(ctx context.Context, orderID int, at *time.Time) error {
+	if err := s.repo.SetNextCall(ctx, orderID, at); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrOrderNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// SetTags заменяет метки заказа; метки приводятся к нижнему регистру без дублей
+func (s *OrderCRMService) SetTags(ctx context.Context, orderID int, tags []string) ([]string, error) {
+	normalized, err := NormalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetTags(ctx, orderID, normalized); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// NormalizeTags чистит метки: trim, нижний регистр, без пустых и повторов
+func NormalizeTags(tags []string) ([]string, error) {
+	out := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			continue
+		}
+		if utf8.RuneCountInString(t) > maxOrderTagLen {
+			return nil, helpers.ErrInvalidInput(fmt.Sprintf("tag %q is longer than %d characters", t, maxOrderTagLen))
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	if len(out) > maxOrderTags {
+		return nil, helpers.ErrInvalidInput(fmt.Sprintf("too many tags, max %d", maxOrderTags))
+	}
+	return out, nil
+}
+
+// SendFollowUpReminders ставит в очередь уведомлений список заказов, по которым пора звонить
+func (s *OrderCRMService) SendFollowUpReminders(ctx context.Context, now time.Time) (int, error) {
+	if s.notify == nil {
+		return 0, nil
+	}
+
+	list, err := s.repo.ListFollowUpsDue(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	if len(list) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]int, 0, len(list))
+	for _, f := range list {
+		ids = append(ids, f.OrderID)
+	}
+	err = s.repo.Tx(ctx, func(tx repository.DB) error {
+		if err := s.repo.WithTx(tx).MarkFollowUpReminded(ctx, ids, now); err != nil {
+			return err
+		}
+		return s.notify.Enqueue(ctx, tx, notifications.FollowUpsDue{Reminders: list, At: now})
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(list), nil
+}
+
+// RunFollowUpReminders — планировщик напоминаний о звонках, работает до отмены ctx
+func (s *OrderCRMService) RunFollowUpReminders(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			n, err := s.SendFollowUpReminders(ctx, time.Now())
+			if err != nil {
+				s.log.Errorw("follow_up_reminders_failed", "err", err)
+				continue
+			}
+			if n > 0 {
+				s.log.Infow("follow_up_reminders_sent", "count", n)
+			}
+		}
+	}
+}
+
+func (s *OrderCRMService) ensureOrder(ctx context.Context, orderID int) error {
+	if _, err := s.repo.GetStatus(ctx, orderID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrOrderNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func sameAssignee(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
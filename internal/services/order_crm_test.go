@@ -0,0 +1,139 @@
+package services_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/notifications"
+	"github.com/Ramcache/travel-backend/internal/repository"
+	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/Ramcache/travel-backend/internal/testutil"
+)
+
+func newOrderCRMService(db *testutil.MockDB) *services.OrderCRMService {
+	return services.NewOrderCRMService(repository.NewOrderRepo(db), nil, zap.NewNop().Sugar())
+}
+
+func TestOrderCRMService_AssignWritesHistory(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newOrderCRMService(db)
+
+	prev := 2
+	db.ExpectQueryRow(func(_ context.Context, _ string, args []any) (pgx.Row, error) {
+		assert.Equal(t, 7, args[0])
+		return testutil.NewSliceRow([]any{models.OrderStatusConfirmed, &prev}), nil
+	})
+	db.ExpectQueryRow(func(_ context.Context, _ string, args []any) (pgx.Row, error) {
+		assert.Equal(t, 5, args[0])
+		return testutil.NewSliceRow([]any{"Марьям"}), nil
+	})
+	db.ExpectExec(func(_ context.Context, _ string, args []any) (pgconn.CommandTag, error) {
+		assert.Equal(t, 5, *args[0].(*int))
+		assert.Equal(t, 7, args[1])
+		assert.Equal(t, 2, *args[2].(*int))
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+	db.ExpectQueryRow(func(_ context.Context, _ string, args []any) (pgx.Row, error) {
+		assert.Equal(t, models.OrderStatusConfirmed, args[2])
+		assert.Equal(t, models.OrderEventAssignment, args[6])
+		assert.Equal(t, 2, *args[7].(*int))
+		assert.Equal(t, 5, *args[8].(*int))
+		return testutil.NewSliceRow([]any{1, time.Now()}), nil
+	})
+
+	actor, assignee := 3, 5
+	err := svc.Assign(context.Background(), 7, &assignee, models.Actor{ID: &actor}, "")
+	require.NoError(t, err)
+	db.Verify(t)
+}
+
+func TestOrderCRMService_AssignSameManagerIsNoop(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newOrderCRMService(db)
+
+	prev := 5
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{models.OrderStatusNew, &prev}), nil
+	})
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{"Марьям"}), nil
+	})
+
+	assignee := 5
+	require.NoError(t, svc.Assign(context.Background(), 7, &assignee, models.Actor{}, ""))
+	db.Verify(t)
+}
+
+func TestOrderCRMService_AssignRejectsNonStaff(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newOrderCRMService(db)
+
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{models.OrderStatusNew, nil}), nil
+	})
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return nil, pgx.ErrNoRows
+	})
+
+	customer := 10
+	err := svc.Assign(context.Background(), 7, &customer, models.Actor{}, "")
+	assert.True(t, helpers.IsInvalidInput(err))
+	db.Verify(t)
+}
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := services.NormalizeTags([]string{" VIP ", "vip", "", "Повторный"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"vip", "повторный"}, tags)
+
+	_, err = services.NormalizeTags([]string{strings.Repeat("я", 33)})
+	assert.True(t, helpers.IsInvalidInput(err))
+}
+
+func TestOrderCRMService_AddNoteValidation(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	_, err := newOrderCRMService(db).AddNote(context.Background(), 7, 3, "   ")
+	assert.True(t, helpers.IsInvalidInput(err))
+	db.Verify(t)
+}
+
+func TestOrderCRMService_FollowUpRemindersGoToOutbox(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	notify := newNotificationService(t, db, "order.follow_up=telegram", notifications.NewRecorder(notifications.ChannelTelegram))
+	svc := services.NewOrderCRMService(repository.NewOrderRepo(db), notify, zaptest.NewLogger(t).Sugar())
+	now := time.Date(2025, 10, 8, 10, 0, 0, 0, time.UTC)
+
+	db.ExpectQuery(func(context.Context, string, []any) (pgx.Rows, error) {
+		return testutil.NewMockRows([][]any{
+			{7, "Иван <Петров>", "+79991234567", models.OrderStatusNew, now.Add(-time.Hour), nil, nil},
+		}), nil
+	})
+	db.ExpectExec(func(_ context.Context, sql string, args []any) (pgconn.CommandTag, error) {
+		assert.Contains(t, sql, "next_call_reminded_at")
+		assert.Equal(t, []any{now, []int{7}}, args)
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+	db.ExpectQueryRow(func(_ context.Context, sql string, args []any) (pgx.Row, error) {
+		assert.Contains(t, sql, "INSERT INTO notification_outbox")
+		assert.Equal(t, "order.follow_up", args[0])
+		assert.Contains(t, args[5], "Заказ №7")
+		assert.Contains(t, args[5], "Иван &lt;Петров&gt;")
+		return testutil.NewSliceRow([]any{1, models.NotificationPending, now, now, now}), nil
+	})
+
+	n, err := svc.SendFollowUpReminders(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	db.Verify(t)
+}
@@ -3,12 +3,28 @@ package services
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Ramcache/travel-backend/internal/export"
+	"github.com/Ramcache/travel-backend/internal/helpers"
 	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/notifications"
+	"github.com/Ramcache/travel-backend/internal/phone"
 	"github.com/Ramcache/travel-backend/internal/repository"
 )
 
+var (
+	ErrOrderNotFound     = errors.New("order not found")
+	ErrInvalidStatus     = errors.New("invalid order status")
+	ErrInvalidTransition = errors.New("invalid order status transition")
+)
+
 type OrderService struct {
-	repo *repository.OrderRepo
+	repo   *repository.OrderRepo
+	notify *NotificationService
 }
 
 type OrdersWithTotal struct {
@@ -16,11 +32,17 @@ type OrdersWithTotal struct {
 	Orders []models.Order `json:"orders"`
 }
 
-func NewOrderService(repo *repository.OrderRepo) *OrderService {
-	return &OrderService{repo: repo}
+// NewOrderService — notify может быть nil, тогда о смене статуса не уведомляем
+func NewOrderService(repo *repository.OrderRepo, notify *NotificationService) *OrderService {
+	return &OrderService{repo: repo, notify: notify}
 }
 
 func (s *OrderService) Create(ctx context.Context, tripID int, userName, userPhone string) (*models.Order, error) {
+	num, err := parsePhone(userPhone)
+	if err != nil {
+		return nil, err
+	}
+
 	order := &models.Order{
 		TripID: models.NullInt32{
 			NullInt32: sql.NullInt32{
@@ -28,9 +50,10 @@ func (s *OrderService) Create(ctx context.Context, tripID int, userName, userPho
 				Valid: tripID > 0, // если 0 → будет NULL
 			},
 		},
-		UserName:  userName,
-		UserPhone: userPhone,
-		Status:    "new",
+		UserName:         userName,
+		UserPhone:        num.E164,
+		UserPhoneDisplay: num.Display,
+		Status:           models.OrderStatusNew,
 	}
 
 	if err := s.repo.Create(ctx, order); err != nil {
@@ -40,13 +63,26 @@ func (s *OrderService) Create(ctx context.Context, tripID int, userName, userPho
 	return order, nil
 }
 
-func (s *OrderService) List(ctx context.Context, limit, offset int, status, phone string, isRead *bool) (*OrdersWithTotal, error) {
-	total, err := s.repo.Count(ctx, status, phone, isRead)
+// parsePhone разбирает телефон клиента с учётом страны по умолчанию
+func parsePhone(raw string) (phone.Number, error) {
+	num, err := phone.Parse(raw, phone.DefaultCountry())
+	if err != nil {
+		return phone.Number{}, helpers.ErrInvalidInput("invalid phone number")
+	}
+	return num, nil
+}
+
+func (s *OrderService) List(ctx context.Context, limit, offset int, f models.OrderFilter) (*OrdersWithTotal, error) {
+	if f.Status != "" && !models.IsValidOrderStatus(f.Status) {
+		return nil, ErrInvalidStatus
+	}
+
+	total, err := s.repo.Count(ctx, f)
 	if err != nil {
 		return nil, err
 	}
 
-	orders, err := s.repo.List(ctx, limit, offset, status, phone, isRead)
+	orders, err := s.repo.List(ctx, limit, offset, f)
 	if err != nil {
 		return nil, err
 	}
@@ -57,8 +93,147 @@ func (s *OrderService) List(ctx context.Context, limit, offset int, status, phon
 	}, nil
 }
 
-func (s *OrderService) UpdateStatus(ctx context.Context, id int, status string) error {
-	return s.repo.UpdateStatus(ctx, id, status)
+var orderExportColumns = []string{
+	"ID", "Создан", "Статус", "Имя", "Телефон", "ID тура", "Тур", "Цена тура",
+	"Название (из заявки)", "Дата (из заявки)", "Цена (из заявки)", "Сумма заказа", "Прочитан",
+	"Менеджер", "Следующий звонок", "Метки",
+}
+
+// Export пишет заказы по фильтру в w построчно
+func (s *OrderService) Export(ctx context.Context, f models.OrderFilter, w export.Writer) error {
+	if f.Status != "" && !models.IsValidOrderStatus(f.Status) {
+		return ErrInvalidStatus
+	}
+	if err := w.WriteHeader(orderExportColumns); err != nil {
+		return err
+	}
+	return s.repo.Export(ctx, f, func(row models.OrderExportRow) error {
+		var tripID any
+		if row.TripID.Valid {
+			tripID = int(row.TripID.Int32)
+		}
+		var nextCall any
+		if row.NextCallAt != nil {
+			nextCall = *row.NextCallAt
+		}
+		return w.WriteRow([]any{
+			row.ID, row.CreatedAt, row.Status, row.UserName, row.UserPhone, tripID, row.TripTitle, row.TripPrice,
+			row.Name, row.Date, row.Price, row.TotalPrice, row.IsRead,
+			row.AssigneeName, nextCall, strings.Join(row.Tags, ", "),
+		})
+	})
+}
+
+func (s *OrderService) GetByID(ctx context.Context, id int) (*models.Order, error) {
+	o, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	return o, nil
+}
+
+// ChangeStatus переводит заказ в новый статус, проверяя допустимость перехода,
+// и пишет запись в историю статусов
+func (s *OrderService) ChangeStatus(ctx context.Context, id int, to string, actor models.Actor, comment string) error {
+	return s.changeStatus(ctx, id, to, actor, comment, models.CanTransitionOrder)
+}
+
+// ChangeStatusTx — ChangeStatus в транзакции вызывающего, вместе с тем, что вызвало смену статуса
+func (s *OrderService) ChangeStatusTx(ctx context.Context, tx repository.DB, id int, to string, actor models.Actor, comment string) error {
+	return s.changeStatusTx(ctx, tx, id, to, actor, comment, models.CanTransitionOrder)
+}
+
+// SetStatus — ручная смена статуса из админки или кнопкой бота.
+// Заявки на отмену подаются и рассматриваются только через CancellationService
+func (s *OrderService) SetStatus(ctx context.Context, id int, to string, actor models.Actor, comment string) error {
+	return s.changeStatus(ctx, id, to, actor, comment, models.CanSetOrderStatus)
+}
+
+// RestoreStatus возвращает заказ из cancellation_requested в статус до заявки на отмену
+func (s *OrderService) RestoreStatus(ctx context.Context, id int, to string, actor models.Actor, comment string) error {
+	return s.changeStatus(ctx, id, to, actor, comment, models.CanRestoreOrder)
+}
+
+func (s *OrderService) changeStatus(ctx context.Context, id int, to string, actor models.Actor, comment string, allowed func(from, to string) bool) error {
+	// статус, история и уведомление — одной транзакцией
+	return s.repo.Tx(ctx, func(tx repository.DB) error {
+		return s.changeStatusTx(ctx, tx, id, to, actor, comment, allowed)
+	})
+}
+
+func (s *OrderService) changeStatusTx(ctx context.Context, tx repository.DB, id int, to string, actor models.Actor, comment string, allowed func(from, to string) bool) error {
+	if !models.IsValidOrderStatus(to) {
+		return ErrInvalidStatus
+	}
+
+	repo := s.repo.WithTx(tx)
+	from, err := repo.GetStatus(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrOrderNotFound
+		}
+		return err
+	}
+
+	if !allowed(from, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, from, to)
+	}
+
+	h := &models.OrderStatusChange{
+		OrderID:    id,
+		FromStatus: &from,
+		ToStatus:   to,
+		ActorID:    actor.ID,
+	}
+	if actor.Name != "" {
+		h.ActorName = &actor.Name
+	}
+	if c := strings.TrimSpace(comment); c != "" {
+		h.Comment = &c
+	}
+
+	if err := repo.UpdateStatus(ctx, id, from, to); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			// статус успели поменять параллельно
+			return fmt.Errorf("%w: status changed concurrently", ErrInvalidTransition)
+		}
+		return err
+	}
+	if err := repo.AddStatusHistory(ctx, h); err != nil {
+		return err
+	}
+	return s.enqueueStatusChanged(ctx, tx, h)
+}
+
+func (s *OrderService) enqueueStatusChanged(ctx context.Context, tx repository.DB, h *models.OrderStatusChange) error {
+	if s.notify == nil {
+		return nil
+	}
+	order, err := s.repo.WithTx(tx).GetByID(ctx, h.OrderID)
+	if err != nil {
+		return err
+	}
+	ev := notifications.OrderStatusChanged{Order: order, From: *h.FromStatus, To: h.ToStatus, At: time.Now()}
+	if h.ActorName != nil {
+		ev.Actor = *h.ActorName
+	}
+	if h.Comment != nil {
+		ev.Comment = *h.Comment
+	}
+	return s.notify.Enqueue(ctx, tx, ev)
+}
+
+func (s *OrderService) History(ctx context.Context, id int) ([]models.OrderStatusChange, error) {
+	if _, err := s.repo.GetStatus(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	return s.repo.ListStatusHistory(ctx, id)
 }
 
 func (s *OrderService) MarkAsRead(ctx context.Context, id int) error {
@@ -68,3 +243,71 @@ func (s *OrderService) MarkAsRead(ctx context.Context, id int) error {
 func (s *OrderService) Delete(ctx context.Context, id int) error {
 	return s.repo.Delete(ctx, id)
 }
+
+// BuildTravellers проверяет туристов из заявки и проставляет цену места по типу.
+// trip может быть nil (заявка без тура) — тогда цена не рассчитывается.
+func BuildTravellers(reqs []models.TravellerRequest, trip *models.Trip) ([]models.OrderTraveller, error) {
+	out := make([]models.OrderTraveller, 0, len(reqs))
+	for i, req := range reqs {
+		name := strings.TrimSpace(req.FullName)
+		if name == "" {
+			return nil, helpers.ErrInvalidInput(fmt.Sprintf("traveller %d: full_name is required", i+1))
+		}
+
+		t := models.OrderTraveller{
+			ProfileID:     req.ProfileID,
+			FullName:      name,
+			TravellerType: req.TravellerType,
+		}
+		if t.TravellerType == "" {
+			t.TravellerType = models.TravellerAdult
+		}
+		switch t.TravellerType {
+		case models.TravellerAdult, models.TravellerChild, models.TravellerInfant:
+		default:
+			return nil, helpers.ErrInvalidInput(fmt.Sprintf("traveller %d: invalid traveller_type %q", i+1, req.TravellerType))
+		}
+
+		if req.BirthDate != "" {
+			d, err := helpers.ParseDateAny(req.BirthDate)
+			if err != nil || d.After(time.Now()) {
+				return nil, helpers.ErrInvalidInput(fmt.Sprintf("traveller %d: invalid birth_date", i+1))
+			}
+			t.BirthDate = &d
+		}
+
+		if req.Gender != "" {
+			if req.Gender != models.GenderMale && req.Gender != models.GenderFemale {
+				return nil, helpers.ErrInvalidInput(fmt.Sprintf("traveller %d: invalid gender %q", i+1, req.Gender))
+			}
+			g := req.Gender
+			t.Gender = &g
+		}
+
+		if room := strings.TrimSpace(req.Room); room != "" {
+			t.Room = &room
+		}
+
+		if trip != nil {
+			price := trip.PriceFor(t.TravellerType)
+			t.Price = &price
+		}
+
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// CalcOrderTotal — итоговая сумма заказа по типам туристов.
+// Заявка без списка туристов считается как одно взрослое место.
+func CalcOrderTotal(trip *models.Trip, travellers []models.OrderTraveller) float64 {
+	if len(travellers) == 0 {
+		return trip.PriceFor(models.TravellerAdult)
+	}
+
+	var total float64
+	for _, t := range travellers {
+		total += trip.PriceFor(t.TravellerType)
+	}
+	return total
+}
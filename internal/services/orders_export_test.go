@@ -0,0 +1,74 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/repository"
+	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/Ramcache/travel-backend/internal/testutil"
+)
+
+type recordingExport struct {
+	header []string
+	rows   [][]any
+}
+
+func (r *recordingExport) WriteHeader(columns []string) error { r.header = columns; return nil }
+func (r *recordingExport) WriteRow(values []any) error        { r.rows = append(r.rows, values); return nil }
+func (r *recordingExport) Close() error                       { return nil }
+
+func TestOrderService_Export(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := services.NewOrderService(repository.NewOrderRepo(db), nil)
+
+	from := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	isRead := false
+
+	title := "Умра"
+	price := 120000.0
+	total := 240000.0
+	tripID := models.NullInt32{NullInt32: sql.NullInt32{Int32: 5, Valid: true}}
+
+	db.ExpectQuery(func(_ context.Context, query string, args []any) (pgx.Rows, error) {
+		assert.Contains(t, query, "LEFT JOIN (SELECT id AS trip_ref, title AS trip_title, price AS trip_price FROM trips)")
+		assert.Contains(t, query, "status = $1 AND is_read = $2 AND created_at >= $3 AND created_at < $4")
+		assert.Equal(t, []any{models.OrderStatusPaid, false, from, to}, args)
+		return testutil.NewMockRows([][]any{
+			{1, tripID, nil, nil, nil, "Иван", "+79990000000", &total, nil, models.OrderStatusPaid, false, from, nil, nil, []string{"vip"}, nil, "", nil, "trk1", &title, &price, nil},
+			{2, nil, nil, nil, nil, "Анна", "+79990000001", nil, nil, models.OrderStatusPaid, false, from, nil, nil, []string{}, nil, "", nil, "trk2", nil, nil, nil},
+		}), nil
+	})
+
+	out := &recordingExport{}
+	err := svc.Export(context.Background(), models.OrderFilter{
+		Status: models.OrderStatusPaid, IsRead: &isRead, From: &from, To: &to,
+	}, out)
+	require.NoError(t, err)
+	db.Verify(t)
+
+	require.Len(t, out.rows, 2)
+	assert.Len(t, out.rows[0], len(out.header))
+	assert.Equal(t, 5, out.rows[0][5])
+	assert.Equal(t, &title, out.rows[0][6])
+	assert.Equal(t, &price, out.rows[0][7])
+	assert.Nil(t, out.rows[1][5])
+	assert.Nil(t, out.rows[1][6])
+	assert.Equal(t, "vip", out.rows[0][15])
+}
+
+func TestOrderService_ExportInvalidStatus(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := services.NewOrderService(repository.NewOrderRepo(db), nil)
+
+	err := svc.Export(context.Background(), models.OrderFilter{Status: "lost"}, &recordingExport{})
+	assert.ErrorIs(t, err, services.ErrInvalidStatus)
+}
@@ -0,0 +1,134 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/notifications"
+	"github.com/Ramcache/travel-backend/internal/repository"
+	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/Ramcache/travel-backend/internal/testutil"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderService_ChangeStatus_Success(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := services.NewOrderService(repository.NewOrderRepo(db), nil)
+
+	db.ExpectQueryRow(func(_ context.Context, _ string, args []any) (pgx.Row, error) {
+		assert.Equal(t, 7, args[0])
+		return testutil.NewSliceRow([]any{models.OrderStatusNew}), nil
+	})
+	db.ExpectExec(func(_ context.Context, _ string, args []any) (pgconn.CommandTag, error) {
+		assert.Equal(t, []any{models.OrderStatusConfirmed, 7, models.OrderStatusNew}, args)
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+	db.ExpectQueryRow(func(_ context.Context, _ string, args []any) (pgx.Row, error) {
+		from := args[1].(*string)
+		assert.Equal(t, models.OrderStatusNew, *from)
+		assert.Equal(t, models.OrderStatusConfirmed, args[2])
+		assert.Equal(t, 3, *args[3].(*int))
+		assert.Equal(t, "позвонили клиенту", *args[5].(*string))
+		return testutil.NewSliceRow([]any{1, time.Now()}), nil
+	})
+
+	adminID := 3
+	err := svc.ChangeStatus(context.Background(), 7, models.OrderStatusConfirmed,
+		models.Actor{ID: &adminID}, " позвонили клиенту ")
+	require.NoError(t, err)
+	db.Verify(t)
+}
+
+func TestOrderService_ChangeStatus_EnqueuesNotification(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	notify := newNotificationService(t, db, "order.status_changed=sms:customer", notifications.NewRecorder(notifications.ChannelSMS))
+	svc := services.NewOrderService(repository.NewOrderRepo(db), notify)
+
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{models.OrderStatusNew}), nil
+	})
+	db.ExpectExec(func(context.Context, string, []any) (pgconn.CommandTag, error) {
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{1, time.Now()}), nil
+	})
+	expectOrder(db, orderRow(7, models.OrderStatusConfirmed, 1000))
+	// сообщение клиенту ложится в outbox, а не уходит сразу
+	db.ExpectQueryRow(func(_ context.Context, sql string, args []any) (pgx.Row, error) {
+		assert.Contains(t, sql, "INSERT INTO notification_outbox")
+		assert.Equal(t, "order.status_changed", args[0])
+		assert.Equal(t, notifications.ChannelSMS, args[1])
+		assert.Equal(t, notifications.AudienceCustomer, args[2])
+		assert.Equal(t, []string{"+79990000000"}, args[3])
+		assert.Contains(t, args[5], "https://t.example/trk")
+		return testutil.NewSliceRow([]any{1, models.NotificationPending, time.Now(), time.Now(), time.Now()}), nil
+	})
+
+	err := svc.ChangeStatus(context.Background(), 7, models.OrderStatusConfirmed, models.Actor{Name: "admin"}, "")
+	require.NoError(t, err)
+	db.Verify(t)
+}
+
+func TestOrderService_ChangeStatus_InvalidTransition(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := services.NewOrderService(repository.NewOrderRepo(db), nil)
+
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{models.OrderStatusNew}), nil
+	})
+
+	err := svc.ChangeStatus(context.Background(), 7, models.OrderStatusCompleted, models.Actor{Name: "telegram"}, "")
+	assert.ErrorIs(t, err, services.ErrInvalidTransition)
+	db.Verify(t)
+}
+
+func TestOrderService_ChangeStatus_InvalidStatus(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := services.NewOrderService(repository.NewOrderRepo(db), nil)
+
+	err := svc.ChangeStatus(context.Background(), 7, "pending", models.Actor{}, "")
+	assert.ErrorIs(t, err, services.ErrInvalidStatus)
+	db.Verify(t)
+}
+
+func TestOrderService_ChangeStatus_NotFound(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := services.NewOrderService(repository.NewOrderRepo(db), nil)
+
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return nil, pgx.ErrNoRows
+	})
+
+	err := svc.ChangeStatus(context.Background(), 7, models.OrderStatusConfirmed, models.Actor{}, "")
+	assert.True(t, errors.Is(err, services.ErrOrderNotFound))
+	db.Verify(t)
+}
+
+func TestOrderService_SetStatus_NoCancellationShortcut(t *testing.T) {
+	tests := []struct{ from, to string }{
+		// без заявки Approve и Reject её не найдут
+		{models.OrderStatusPaid, models.OrderStatusCancellationRequested},
+		// заявка осталась бы нерассмотренной
+		{models.OrderStatusCancellationRequested, models.OrderStatusCancelled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from+"->"+tt.to, func(t *testing.T) {
+			db := testutil.NewMockDB(t)
+			svc := services.NewOrderService(repository.NewOrderRepo(db), nil)
+			db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+				return testutil.NewSliceRow([]any{tt.from}), nil
+			})
+
+			err := svc.SetStatus(context.Background(), 7, tt.to, models.Actor{}, "")
+			assert.ErrorIs(t, err, services.ErrInvalidTransition)
+			db.Verify(t)
+		})
+	}
+}
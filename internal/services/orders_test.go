@@ -0,0 +1,60 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func floatPtr(f float64) *float64 { return &f }
+
+func TestBuildTravellers_Validation(t *testing.T) {
+	_, err := services.BuildTravellers([]models.TravellerRequest{{FullName: " "}}, nil)
+	assert.True(t, helpers.IsInvalidInput(err))
+
+	_, err = services.BuildTravellers([]models.TravellerRequest{{FullName: "Иван", TravellerType: "pet"}}, nil)
+	assert.True(t, helpers.IsInvalidInput(err))
+
+	_, err = services.BuildTravellers([]models.TravellerRequest{{FullName: "Иван", Gender: "x"}}, nil)
+	assert.True(t, helpers.IsInvalidInput(err))
+
+	_, err = services.BuildTravellers([]models.TravellerRequest{{FullName: "Иван", BirthDate: "21.05.1990"}}, nil)
+	assert.True(t, helpers.IsInvalidInput(err))
+}
+
+func TestBuildTravellers_DefaultsAndPrices(t *testing.T) {
+	trip := &models.Trip{Price: 100000, ChildPrice: floatPtr(80000)}
+
+	list, err := services.BuildTravellers([]models.TravellerRequest{
+		{FullName: "Иван Иванов", Gender: models.GenderMale, BirthDate: "1985-01-02", Room: "1"},
+		{FullName: "Мария Иванова", TravellerType: models.TravellerChild, Room: "1"},
+	}, trip)
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+	assert.Equal(t, models.TravellerAdult, list[0].TravellerType)
+	assert.Equal(t, 100000.0, *list[0].Price)
+	assert.Equal(t, 80000.0, *list[1].Price)
+	assert.Equal(t, "1", *list[1].Room)
+}
+
+func TestCalcOrderTotal(t *testing.T) {
+	trip := &models.Trip{Price: 100000, DiscountPercent: 10, ChildPrice: floatPtr(50000)}
+
+	// без туристов — одно взрослое место
+	assert.Equal(t, 90000.0, services.CalcOrderTotal(trip, nil))
+
+	travellers := []models.OrderTraveller{
+		{TravellerType: models.TravellerAdult},
+		{TravellerType: models.TravellerAdult},
+		{TravellerType: models.TravellerChild},
+		{TravellerType: models.TravellerInfant},
+	}
+	// 2 × 90 000 + 45 000 + 0 (цена младенца не задана)
+	assert.Equal(t, 225000.0, services.CalcOrderTotal(trip, travellers))
+
+	trip.InfantPrice = floatPtr(10000)
+	assert.Equal(t, 234000.0, services.CalcOrderTotal(trip, travellers))
+}
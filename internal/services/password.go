@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/notifications"
+	"github.com/Ramcache/travel-backend/internal/repository"
+)
+
+var (
+	// ErrInvalidResetToken — токена сброса нет, он истёк или уже использован
+	ErrInvalidResetToken = errors.New("invalid password reset token")
+	ErrWrongPassword     = errors.New("current password is wrong")
+)
+
+// не чаще одной ссылки на сброс в минуту на пользователя — защита от засыпания письмами
+const passwordResetCooldown = time.Minute
+
+// PasswordResetConfig — ссылка на сброс пароля
+type PasswordResetConfig struct {
+	// URL — начало ссылки, к нему дописывается токен
+	URL string
+	// TTL — сколько действует ссылка
+	TTL time.Duration
+}
+
+// PasswordService — смена пароля и восстановление по ссылке.
+// После смены пароля прочие сессии закрываются
+type PasswordService struct {
+	users         repository.UserRepoI
+	resets        *repository.PasswordResetRepo
+	sessions      *repository.SessionRepo
+	notifications *NotificationService
+	cfg           PasswordResetConfig
+	log           *zap.SugaredLogger
+}
+
+func NewPasswordService(
+	users repository.UserRepoI,
+	resets *repository.PasswordResetRepo,
+	sessions *repository.SessionRepo,
+	notifications *NotificationService,
+	cfg PasswordResetConfig,
+	log *zap.SugaredLogger,
+) *PasswordService {
+	if cfg.TTL <= 0 {
+		cfg.TTL = time.Hour
+	}
+	return &PasswordService{users: users, resets: resets, sessions: sessions, notifications: notifications, cfg: cfg, log: log}
+}
+
+// Forgot отправляет ссылку на сброс пароля (событие auth.password_reset).
+// Неизвестный email — не ошибка: по ответу нельзя узнать, зарегистрирован ли адрес
+func (s *PasswordService) Forgot(ctx context.Context, email string) error {
+	user, err := s.users.GetByEmail(ctx, email)
+	if errors.Is(err, repository.ErrNotFound) {
+		s.log.Infow("password_reset_unknown_email", "email", email)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	token, err := helpers.RandomToken(32)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	expiresAt := now.Add(s.cfg.TTL)
+
+	issued := false
+	err = s.resets.Tx(ctx, func(tx repository.DB) error {
+		ok, err := s.resets.WithTx(tx).Create(ctx, user.ID, hashToken(token), expiresAt, passwordResetCooldown)
+		if err != nil || !ok {
+			return err
+		}
+		issued = true
+		return s.notifications.Enqueue(ctx, tx, notifications.PasswordResetRequested{
+			User:      user,
+			ResetURL:  s.cfg.URL + url.QueryEscape(token),
+			ExpiresAt: expiresAt,
+			At:        now,
+		})
+	})
+	if err != nil {
+		s.log.Errorw("password_reset_issue_failed", "user_id", user.ID, "err", err)
+		return err
+	}
+	if !issued {
+		s.log.Warnw("Повторный запрос сброса пароля слишком рано", "user_id", user.ID)
+		return nil
+	}
+	s.log.Infow("password_reset_requested", "user_id", user.ID)
+	return nil
+}
+
+// Reset задаёт новый пароль по токену из ссылки и закрывает все сессии пользователя
+func (s *PasswordService) Reset(ctx context.Context, token, password string) error {
+	if token == "" {
+		return ErrInvalidResetToken
+	}
+	userID, err := s.resets.Consume(ctx, hashToken(token))
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrInvalidResetToken
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.setPassword(ctx, userID, password); err != nil {
+		return err
+	}
+	if _, err := s.sessions.RevokeAll(ctx, userID); err != nil {
+		s.log.Errorw("sessions_revoke_failed", "user_id", userID, "err", err)
+		return err
+	}
+	s.log.Infow("password_reset", "user_id", userID)
+	return nil
+}
+
+// Change меняет пароль по текущему; остаётся открытой только сессия sessionID
+func (s *PasswordService) Change(ctx context.Context, userID int, sessionID int64, req models.ChangePasswordRequest) error {
+	user, err := s.users.GetByID(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if !helpers.CheckPassword(user.Password, req.CurrentPassword) {
+		s.log.Warnw("password_change_wrong_current", "user_id", userID)
+		return ErrWrongPassword
+	}
+
+	if err := s.setPassword(ctx, userID, req.NewPassword); err != nil {
+		return err
+	}
+	n, err := s.sessions.RevokeOthers(ctx, userID, sessionID)
+	if err != nil {
+		s.log.Errorw("sessions_revoke_failed", "user_id", userID, "err", err)
+		return err
+	}
+	s.log.Infow("password_changed", "user_id", userID, "sessions_revoked", n)
+	return nil
+}
+
+func (s *PasswordService) setPassword(ctx context.Context, userID int, password string) error {
+	hash, err := helpers.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	err = s.users.UpdatePassword(ctx, userID, hash)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
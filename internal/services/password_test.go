@@ -0,0 +1,161 @@
+package services_test
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/notifications"
+	"github.com/Ramcache/travel-backend/internal/repository"
+	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/Ramcache/travel-backend/internal/testutil"
+)
+
+const resetURL = "https://site.example/reset-password?token="
+
+func newPasswordService(t *testing.T, db *testutil.MockDB, users *MockUserRepo) *services.PasswordService {
+	t.Helper()
+	email := notifications.NewRecorder(notifications.ChannelEmail)
+	tg := notifications.NewRecorder(notifications.ChannelTelegram)
+	// сброс пароля в Telegram менеджерам не уходит, даже если такой маршрут настроен
+	notify := newNotificationService(t, db, "auth.password_reset=email:customer,telegram", email, tg)
+	return services.NewPasswordService(users, repository.NewPasswordResetRepo(db), repository.NewSessionRepo(db), notify,
+		services.PasswordResetConfig{URL: resetURL, TTL: time.Hour}, zaptest.NewLogger(t).Sugar())
+}
+
+func TestPasswordService_Forgot_SendsLink(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	users := new(MockUserRepo)
+	svc := newPasswordService(t, db, users)
+
+	users.On("GetByEmail", mock.Anything, "ivan@example.com").
+		Return(&models.User{ID: 7, Email: "ivan@example.com", FullName: "Иван"}, nil)
+
+	db.ExpectQueryRow(func(_ context.Context, sql string, args []any) (pgx.Row, error) {
+		assert.Contains(t, sql, "created_at > now()")
+		assert.Equal(t, []any{7, 60.0}, args)
+		return testutil.NewSliceRow([]any{false}), nil
+	})
+	db.ExpectExec(func(_ context.Context, sql string, args []any) (pgconn.CommandTag, error) {
+		assert.Contains(t, sql, "used_at IS NULL")
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+	var storedHash string
+	db.ExpectExec(func(_ context.Context, sql string, args []any) (pgconn.CommandTag, error) {
+		assert.Contains(t, sql, "INSERT INTO password_reset_tokens")
+		storedHash = args[1].(string)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), args[2].(time.Time), time.Minute)
+		return pgconn.NewCommandTag("INSERT 0 1"), nil
+	})
+	var body string
+	db.ExpectQueryRow(func(_ context.Context, _ string, args []any) (pgx.Row, error) {
+		assert.Equal(t, notifications.ChannelEmail, args[1])
+		assert.Equal(t, notifications.AudienceCustomer, args[2])
+		assert.Equal(t, []string{"ivan@example.com"}, args[3])
+		body = args[5].(string)
+		return testutil.NewSliceRow([]any{1, models.NotificationPending, time.Now(), time.Now(), time.Now()}), nil
+	})
+
+	require.NoError(t, svc.Forgot(context.Background(), "ivan@example.com"))
+	db.Verify(t)
+
+	// в письме — токен, в базе — только его хэш
+	start := strings.Index(body, resetURL)
+	require.NotEqual(t, -1, start)
+	raw := body[start+len(resetURL):]
+	raw = raw[:strings.IndexAny(raw, `"<`)]
+	token, err := url.QueryUnescape(raw)
+	require.NoError(t, err)
+	assert.Equal(t, sha256Hex(token), storedHash)
+}
+
+func TestPasswordService_Forgot_QuietOnUnknownEmailAndCooldown(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	users := new(MockUserRepo)
+	svc := newPasswordService(t, db, users)
+
+	users.On("GetByEmail", mock.Anything, "nobody@example.com").Return(nil, repository.ErrNotFound)
+	users.On("GetByEmail", mock.Anything, "ivan@example.com").Return(&models.User{ID: 7, Email: "ivan@example.com"}, nil)
+	// предыдущая ссылка выдана меньше минуты назад: новой нет, письма нет
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{true}), nil
+	})
+
+	require.NoError(t, svc.Forgot(context.Background(), "nobody@example.com"))
+	require.NoError(t, svc.Forgot(context.Background(), "ivan@example.com"))
+	db.Verify(t)
+}
+
+func TestPasswordService_Reset(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	users := new(MockUserRepo)
+	svc := newPasswordService(t, db, users)
+
+	db.ExpectQueryRow(func(_ context.Context, sql string, args []any) (pgx.Row, error) {
+		assert.Contains(t, sql, "used_at IS NULL AND expires_at > now()")
+		assert.Equal(t, []any{sha256Hex("reset-token")}, args)
+		return testutil.NewSliceRow([]any{7}), nil
+	})
+	users.On("UpdatePassword", mock.Anything, 7, mock.MatchedBy(func(hash string) bool {
+		return helpers.CheckPassword(hash, "new-secret")
+	})).Return(nil)
+	db.ExpectExec(func(_ context.Context, sql string, args []any) (pgconn.CommandTag, error) {
+		assert.Contains(t, sql, "WHERE user_id = $1 AND revoked_at IS NULL")
+		assert.Equal(t, []any{7}, args)
+		return pgconn.NewCommandTag("UPDATE 2"), nil
+	})
+
+	require.NoError(t, svc.Reset(context.Background(), "reset-token", "new-secret"))
+	db.Verify(t)
+	users.AssertExpectations(t)
+}
+
+func TestPasswordService_Reset_UsedToken(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	users := new(MockUserRepo)
+	svc := newPasswordService(t, db, users)
+
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return nil, pgx.ErrNoRows
+	})
+
+	err := svc.Reset(context.Background(), "reset-token", "new-secret")
+	assert.ErrorIs(t, err, services.ErrInvalidResetToken)
+	users.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+	db.Verify(t)
+}
+
+func TestPasswordService_Change(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	users := new(MockUserRepo)
+	svc := newPasswordService(t, db, users)
+
+	hash, _ := helpers.HashPassword("old-secret")
+	users.On("GetByID", mock.Anything, 7).Return(&models.User{ID: 7, Password: hash}, nil)
+	users.On("UpdatePassword", mock.Anything, 7, mock.AnythingOfType("string")).Return(nil).Once()
+	// текущая сессия 5 остаётся
+	db.ExpectExec(func(_ context.Context, sql string, args []any) (pgconn.CommandTag, error) {
+		assert.Contains(t, sql, "id <> $2")
+		assert.Equal(t, []any{7, int64(5)}, args)
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+
+	err := svc.Change(context.Background(), 7, 5, models.ChangePasswordRequest{CurrentPassword: "wrong", NewPassword: "new-secret"})
+	assert.ErrorIs(t, err, services.ErrWrongPassword)
+
+	err = svc.Change(context.Background(), 7, 5, models.ChangePasswordRequest{CurrentPassword: "old-secret", NewPassword: "new-secret"})
+	require.NoError(t, err)
+	db.Verify(t)
+	users.AssertExpectations(t)
+}
@@ -0,0 +1,407 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/notifications"
+	"github.com/Ramcache/travel-backend/internal/repository"
+)
+
+var ErrScheduleHasPayments = errors.New("schedule already has payments")
+
+type PaymentScheduleService struct {
+	repo         *repository.PaymentScheduleRepo
+	orders       *repository.OrderRepo
+	trips        repository.TripRepositoryI
+	notify       *NotificationService
+	reminderLead time.Duration
+	log          *zap.SugaredLogger
+}
+
+// NewPaymentScheduleService — reminderLead: за сколько до срока напоминать о платеже
+func NewPaymentScheduleService(repo *repository.PaymentScheduleRepo, orders *repository.OrderRepo, trips repository.TripRepositoryI, notify *NotificationService, reminderLead time.Duration, log *zap.SugaredLogger) *PaymentScheduleService {
+	return &PaymentScheduleService{
+		repo:         repo,
+		orders:       orders,
+		trips:        trips,
+		notify:       notify,
+		reminderLead: reminderLead,
+		log:          log,
+	}
+}
+
+// ---- правила тура ----
+
+func (s *PaymentScheduleService) Rules(ctx context.Context, tripID int) ([]models.TripPaymentRule, error) {
+	if _, err := s.getTrip(ctx, tripID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListRules(ctx, tripID)
+}
+
+func (s *PaymentScheduleService) SetRules(ctx context.Context, tripID int, reqs []models.TripPaymentRuleRequest) ([]models.TripPaymentRule, error) {
+	if _, err := s.getTrip(ctx, tripID); err != nil {
+		return nil, err
+	}
+	rules, err := BuildPaymentRules(reqs)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceRules(ctx, tripID, rules); err != nil {
+		return nil, err
+	}
+	s.log.Infow("trip_payment_rules_updated", "trip_id", tripID, "count", len(rules))
+	return rules, nil
+}
+
+// BuildPaymentRules проверяет правила графика. Пустой список — оплата одним платежом.
+func BuildPaymentRules(reqs []models.TripPaymentRuleRequest) ([]models.TripPaymentRule, error) {
+	rules := make([]models.TripPaymentRule, 0, len(reqs))
+	var percentSum float64
+	remainder := false
+
+	for i, req := range reqs {
+		n := i + 1
+		rule := models.TripPaymentRule{
+			Seq:           n,
+			Title:         strings.TrimSpace(req.Title),
+			Percent:       req.Percent,
+			Amount:        req.Amount,
+			DueAnchor:     req.DueAnchor,
+			DueOffsetDays: req.DueOffsetDays,
+		}
+		if rule.Title == "" {
+			rule.Title = fmt.Sprintf("Платёж %d", n)
+		}
+
+		switch rule.DueAnchor {
+		case models.DueAnchorBooking, models.DueAnchorStartDate, models.DueAnchorBookingDeadline:
+		default:
+			return nil, helpers.ErrInvalidInput(fmt.Sprintf("rule %d: invalid due_anchor %q", n, req.DueAnchor))
+		}
+
+		switch {
+		case rule.Percent != nil && rule.Amount != nil:
+			return nil, helpers.ErrInvalidInput(fmt.Sprintf("rule %d: percent and amount are mutually exclusive", n))
+		case rule.Percent != nil:
+			if *rule.Percent <= 0 || *rule.Percent > 100 {
+				return nil, helpers.ErrInvalidInput(fmt.Sprintf("rule %d: percent must be in (0, 100]", n))
+			}
+			percentSum += *rule.Percent
+		case rule.Amount != nil:
+			if *rule.Amount <= 0 {
+				return nil, helpers.ErrInvalidInput(fmt.Sprintf("rule %d: amount must be positive", n))
+			}
+		default:
+			if remainder {
+				return nil, helpers.ErrInvalidInput("only one remainder rule is allowed")
+			}
+			remainder = true
+		}
+
+		rules = append(rules, rule)
+	}
+
+	if percentSum > 100 {
+		return nil, helpers.ErrInvalidInput("total percent exceeds 100")
+	}
+	return rules, nil
+}
+
+// BuildSchedule раскладывает сумму заказа по правилам тура.
+// Всё, что не покрыто правилами, добавляется к платежу «на остаток» (или к последнему платежу).
+// Срок не может быть раньше даты бронирования.
+func BuildSchedule(rules []models.TripPaymentRule, trip *models.Trip, total float64, bookedAt time.Time) []models.OrderInstallment {
+	if len(rules) == 0 || total <= 0 {
+		return nil
+	}
+
+	booked := truncateDay(bookedAt)
+	list := make([]models.OrderInstallment, 0, len(rules))
+	remainderIdx := -1
+	left := roundMoney(total)
+
+	for _, rule := range rules {
+		var amount float64
+		switch {
+		case rule.Percent != nil:
+			amount = roundMoney(total * *rule.Percent / 100)
+		case rule.Amount != nil:
+			amount = *rule.Amount
+		}
+		if amount > left {
+			amount = left
+		}
+		left = roundMoney(left - amount)
+
+		due := booked
+		switch rule.DueAnchor {
+		case models.DueAnchorStartDate:
+			due = truncateDay(trip.StartDate)
+		case models.DueAnchorBookingDeadline:
+			due = truncateDay(trip.StartDate)
+			if trip.BookingDeadline != nil {
+				due = truncateDay(*trip.BookingDeadline)
+			}
+		}
+		due = due.AddDate(0, 0, rule.DueOffsetDays)
+		if due.Before(booked) {
+			due = booked
+		}
+
+		if rule.Percent == nil && rule.Amount == nil {
+			remainderIdx = len(list)
+		}
+		list = append(list, models.OrderInstallment{Title: rule.Title, Amount: amount, DueDate: due})
+	}
+
+	if left > 0 {
+		if remainderIdx < 0 {
+			remainderIdx = len(list) - 1
+		}
+		list[remainderIdx].Amount = roundMoney(list[remainderIdx].Amount + left)
+	}
+
+	// нулевые платежи (например, фиксированный задаток больше суммы заказа) не нужны
+	out := list[:0]
+	for _, in := range list {
+		if in.Amount > 0 {
+			in.Seq = len(out) + 1
+			out = append(out, in)
+		}
+	}
+	return out
+}
+
+// ---- график заказа ----
+
+// GenerateForOrder создаёт график для нового заказа, если у тура заданы правила
+func (s *PaymentScheduleService) GenerateForOrder(ctx context.Context, order *models.Order, trip *models.Trip) error {
+	if trip == nil || order.TotalPrice == nil {
+		return nil
+	}
+	rules, err := s.repo.ListRules(ctx, trip.ID)
+	if err != nil {
+		return err
+	}
+	list := BuildSchedule(rules, trip, *order.TotalPrice, order.CreatedAt)
+	if len(list) == 0 {
+		return nil
+	}
+	return s.repo.ReplaceInstallments(ctx, order.ID, list)
+}
+
+// Regenerate пересчитывает график по текущим правилам тура (пока по нему ничего не оплачено)
+func (s *PaymentScheduleService) Regenerate(ctx context.Context, orderID int) (*models.OrderSchedule, error) {
+	order, err := s.getOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !order.TripID.Valid || order.TotalPrice == nil {
+		return nil, helpers.ErrInvalidInput("order has no trip or total price")
+	}
+
+	current, err := s.repo.ListInstallments(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	for _, in := range current {
+		if in.PaidAmount > 0 {
+			return nil, ErrScheduleHasPayments
+		}
+	}
+
+	trip, err := s.getTrip(ctx, int(order.TripID.Int32))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.GenerateForOrder(ctx, order, trip); err != nil {
+		return nil, err
+	}
+	return s.Schedule(ctx, orderID)
+}
+
+func (s *PaymentScheduleService) Schedule(ctx context.Context, orderID int) (*models.OrderSchedule, error) {
+	order, err := s.getOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.repo.ListInstallments(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	sch := &models.OrderSchedule{OrderID: orderID, Installments: list}
+	if sch.Installments == nil {
+		sch.Installments = []models.OrderInstallment{}
+	}
+	for _, in := range list {
+		sch.Total += in.Amount
+		sch.Paid += in.PaidAmount
+	}
+	if len(list) == 0 && order.TotalPrice != nil {
+		sch.Total = *order.TotalPrice
+	}
+	sch.Total = roundMoney(sch.Total)
+	sch.Paid = roundMoney(sch.Paid)
+	sch.Outstanding = roundMoney(sch.Total - sch.Paid)
+	return sch, nil
+}
+
+// NextDue — неоплаченная часть ближайшего платежа; nil, если графика нет или всё оплачено
+func (s *PaymentScheduleService) NextDue(ctx context.Context, orderID int) (*float64, error) {
+	list, err := s.repo.ListInstallments(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	for _, in := range list {
+		if rest := roundMoney(in.Outstanding()); rest > 0 {
+			return &rest, nil
+		}
+	}
+	return nil, nil
+}
+
+// ApplyPaymentTx распределяет оплату по платежам графика по порядку сроков.
+// Отрицательная сумма (возврат) снимается с последних оплаченных платежей.
+// Вызывается в транзакции платежа, чтобы график не разошёлся с оплатой.
+func (s *PaymentScheduleService) ApplyPaymentTx(ctx context.Context, tx repository.DB, orderID int, amount float64) error {
+	repo := s.repo.WithTx(tx)
+	list, err := repo.ListInstallments(ctx, orderID)
+	if err != nil || len(list) == 0 {
+		return err
+	}
+
+	now := time.Now()
+	left := roundMoney(amount)
+	if left > 0 {
+		for _, in := range list {
+			if left <= 0 {
+				break
+			}
+			rest := roundMoney(in.Outstanding())
+			if rest <= 0 {
+				continue
+			}
+			add := min(rest, left)
+			left = roundMoney(left - add)
+
+			paid := roundMoney(in.PaidAmount + add)
+			var paidAt *time.Time
+			if paid >= in.Amount {
+				paidAt = &now
+			}
+			if err := repo.SetPaid(ctx, in.ID, paid, paidAt); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for i := len(list) - 1; i >= 0 && left < 0; i-- {
+		in := list[i]
+		if in.PaidAmount <= 0 {
+			continue
+		}
+		sub := min(in.PaidAmount, -left)
+		left = roundMoney(left + sub)
+		if err := repo.SetPaid(ctx, in.ID, roundMoney(in.PaidAmount-sub), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *PaymentScheduleService) Overdue(ctx context.Context, limit, offset int) ([]models.OverdueInstallment, error) {
+	return s.repo.ListOverdue(ctx, time.Now(), limit, offset)
+}
+
+// ---- напоминания ----
+
+// SendReminders ставит в очередь уведомлений сводку платежей, срок которых наступает
+// в ближайшие reminderLead или уже прошёл. Возвращает число платежей в сводке.
+func (s *PaymentScheduleService) SendReminders(ctx context.Context, now time.Time) (int, error) {
+	if s.notify == nil {
+		return 0, nil
+	}
+
+	list, err := s.repo.ListForReminder(ctx, now, now.Add(s.reminderLead))
+	if err != nil {
+		return 0, err
+	}
+	if len(list) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]int, 0, len(list))
+	for _, in := range list {
+		ids = append(ids, in.ID)
+	}
+	// отметка и сводка одной транзакцией: повторной сводки не будет, а отмеченные не потеряются
+	err = s.repo.Tx(ctx, func(tx repository.DB) error {
+		if err := s.repo.WithTx(tx).MarkReminded(ctx, ids, now); err != nil {
+			return err
+		}
+		return s.notify.Enqueue(ctx, tx, notifications.PaymentsDue{Installments: list, At: now})
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(list), nil
+}
+
+// RunReminders — планировщик напоминаний, работает до отмены ctx
+func (s *PaymentScheduleService) RunReminders(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			n, err := s.SendReminders(ctx, time.Now())
+			if err != nil {
+				s.log.Errorw("payment_reminders_failed", "err", err)
+				continue
+			}
+			if n > 0 {
+				s.log.Infow("payment_reminders_sent", "count", n)
+			}
+		}
+	}
+}
+
+func (s *PaymentScheduleService) getTrip(ctx context.Context, id int) (*models.Trip, error) {
+	trip, err := s.trips.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrTripNotFound
+		}
+		return nil, err
+	}
+	return trip, nil
+}
+
+func (s *PaymentScheduleService) getOrder(ctx context.Context, id int) (*models.Order, error) {
+	order, err := s.orders.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	return order, nil
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,80 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildPaymentRules_Validation(t *testing.T) {
+	cases := [][]models.TripPaymentRuleRequest{
+		{{DueAnchor: "tomorrow"}},
+		{{Percent: floatPtr(30), Amount: floatPtr(1000), DueAnchor: models.DueAnchorBooking}},
+		{{Percent: floatPtr(120), DueAnchor: models.DueAnchorBooking}},
+		{{Amount: floatPtr(-5), DueAnchor: models.DueAnchorBooking}},
+		{{DueAnchor: models.DueAnchorBooking}, {DueAnchor: models.DueAnchorStartDate}},
+		{{Percent: floatPtr(60), DueAnchor: models.DueAnchorBooking}, {Percent: floatPtr(50), DueAnchor: models.DueAnchorStartDate}},
+	}
+	for i, reqs := range cases {
+		_, err := services.BuildPaymentRules(reqs)
+		assert.True(t, helpers.IsInvalidInput(err), "case %d", i)
+	}
+
+	rules, err := services.BuildPaymentRules([]models.TripPaymentRuleRequest{
+		{Percent: floatPtr(30), DueAnchor: models.DueAnchorBooking, DueOffsetDays: 3},
+		{Title: "Остаток", DueAnchor: models.DueAnchorStartDate, DueOffsetDays: -30},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Платёж 1", rules[0].Title)
+	assert.Equal(t, 2, rules[1].Seq)
+}
+
+func TestBuildSchedule_DepositAndBalance(t *testing.T) {
+	start := time.Date(2026, 5, 20, 0, 0, 0, 0, time.UTC)
+	deadline := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	trip := &models.Trip{StartDate: start, BookingDeadline: &deadline}
+	booked := time.Date(2026, 1, 10, 15, 30, 0, 0, time.UTC)
+
+	rules := []models.TripPaymentRule{
+		{Title: "Задаток", Percent: floatPtr(30), DueAnchor: models.DueAnchorBooking, DueOffsetDays: 3},
+		{Title: "Остаток", DueAnchor: models.DueAnchorBookingDeadline},
+	}
+
+	list := services.BuildSchedule(rules, trip, 100001, booked)
+	require.Len(t, list, 2)
+	assert.Equal(t, 30000.3, list[0].Amount)
+	assert.Equal(t, time.Date(2026, 1, 13, 0, 0, 0, 0, time.UTC), list[0].DueDate)
+	assert.Equal(t, 70000.7, list[1].Amount)
+	assert.Equal(t, deadline, list[1].DueDate)
+}
+
+func TestBuildSchedule_LateBookingAndFixedDeposit(t *testing.T) {
+	start := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	trip := &models.Trip{StartDate: start}
+	booked := time.Date(2026, 1, 25, 0, 0, 0, 0, time.UTC)
+
+	rules := []models.TripPaymentRule{
+		{Title: "Задаток", Amount: floatPtr(50000), DueAnchor: models.DueAnchorBooking},
+		{Title: "Доплата", Percent: floatPtr(20), DueAnchor: models.DueAnchorStartDate, DueOffsetDays: -30},
+	}
+
+	list := services.BuildSchedule(rules, trip, 200000, booked)
+	require.Len(t, list, 2)
+	assert.Equal(t, 50000.0, list[0].Amount)
+	// срок «за 30 дней до старта» уже прошёл — платёж сдвигается на дату бронирования,
+	// непокрытый правилами остаток добавляется к последнему платежу
+	assert.Equal(t, booked, list[1].DueDate)
+	assert.Equal(t, 150000.0, list[1].Amount)
+
+	// фиксированный задаток больше суммы заказа — лишние платежи не создаются
+	list = services.BuildSchedule(rules, trip, 30000, booked)
+	require.Len(t, list, 1)
+	assert.Equal(t, 30000.0, list[0].Amount)
+
+	assert.Empty(t, services.BuildSchedule(nil, trip, 30000, booked))
+}
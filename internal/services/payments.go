@@ -0,0 +1,386 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/payments"
+	"github.com/Ramcache/travel-backend/internal/repository"
+)
+
+var (
+	ErrPaymentNotFound      = errors.New("payment not found")
+	ErrOrderNotPayable      = errors.New("order can not be paid in its current status")
+	ErrPaymentNotRefundable = errors.New("payment can not be refunded")
+	// ErrPaymentAmountMismatch — сумма в уведомлении провайдера не совпадает с суммой платежа
+	ErrPaymentAmountMismatch = errors.New("webhook amount does not match payment amount")
+)
+
+// допуск при сравнении денежных сумм
+const moneyEpsilon = 0.005
+
+type PaymentService struct {
+	repo      *repository.PaymentRepo
+	orders    *OrderService
+	schedules *PaymentScheduleService
+	provider  payments.Provider
+	returnURL string
+	log       *zap.SugaredLogger
+}
+
+// NewPaymentService — schedules может быть nil, тогда оплата не распределяется по графику
+func NewPaymentService(repo *repository.PaymentRepo, orders *OrderService, schedules *PaymentScheduleService, provider payments.Provider, returnURL string, log *zap.SugaredLogger) *PaymentService {
+	return &PaymentService{repo: repo, orders: orders, schedules: schedules, provider: provider, returnURL: returnURL, log: log}
+}
+
+// CreateForOrder регистрирует платёж у провайдера и возвращает ссылку на оплату.
+// Оплатить можно только подтверждённый менеджером заказ.
+func (s *PaymentService) CreateForOrder(ctx context.Context, orderID int, req models.CreatePaymentRequest) (*models.Payment, error) {
+	order, err := s.orders.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != models.OrderStatusConfirmed {
+		return nil, ErrOrderNotPayable
+	}
+
+	amount, err := s.resolveAmount(ctx, order, req.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &models.Payment{
+		OrderID:  orderID,
+		Provider: s.provider.Name(),
+		Amount:   amount,
+		Currency: "RUB",
+		Status:   payments.StatusPending,
+	}
+	if err := s.repo.Create(ctx, p); err != nil {
+		return nil, err
+	}
+
+	returnURL := req.ReturnURL
+	if returnURL == "" {
+		returnURL = s.returnURL
+	}
+
+	res, err := s.provider.Create(ctx, payments.CreateRequest{
+		OrderID:        orderID,
+		Amount:         amount,
+		Currency:       p.Currency,
+		Description:    fmt.Sprintf("Оплата заказа №%d", orderID),
+		ReturnURL:      returnURL,
+		IdempotencyKey: fmt.Sprintf("order-%d-payment-%d", orderID, p.ID),
+	})
+	if err != nil {
+		if uerr := s.repo.UpdateStatus(ctx, p.ID, payments.StatusCanceled); uerr != nil {
+			s.log.Errorw("payment_cancel_failed", "payment_id", p.ID, "err", uerr)
+		}
+		return nil, fmt.Errorf("provider create payment: %w", err)
+	}
+
+	if err := s.repo.SetExternal(ctx, p.ID, res.ExternalID, res.ConfirmationURL, res.Status); err != nil {
+		return nil, err
+	}
+	p.ExternalID = &res.ExternalID
+	p.Status = res.Status
+	if res.ConfirmationURL != "" {
+		p.ConfirmationURL = &res.ConfirmationURL
+	}
+
+	s.log.Infow("payment_created", "order_id", orderID, "payment_id", p.ID, "provider", p.Provider, "amount", amount)
+	return p, nil
+}
+
+func (s *PaymentService) ListByOrder(ctx context.Context, orderID int) ([]models.Payment, error) {
+	if _, err := s.orders.GetByID(ctx, orderID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByOrder(ctx, orderID)
+}
+
+// HandleWebhook обрабатывает уведомление провайдера. Отметка о событии, статус платежа,
+// график и статус заказа меняются в одной транзакции: повторная доставка того же события
+// ничего не меняет, а после ошибки провайдер повторит доставку и событие обработается заново.
+func (s *PaymentService) HandleWebhook(ctx context.Context, header http.Header, body []byte) error {
+	ev, err := s.provider.ParseWebhook(header, body)
+	if err != nil {
+		return err
+	}
+
+	found, err := s.repo.GetByExternalID(ctx, s.provider.Name(), ev.ExternalID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrPaymentNotFound
+		}
+		return err
+	}
+
+	return s.repo.Tx(ctx, func(tx repository.DB) error {
+		repo := s.repo.WithTx(tx)
+		fresh, err := repo.SaveEvent(ctx, s.provider.Name(), ev.ID, found.ID, ev.Status, body)
+		if err != nil {
+			return err
+		}
+		if !fresh {
+			s.log.Infow("payment_webhook_duplicate", "event_id", ev.ID, "payment_id", found.ID)
+			return nil
+		}
+
+		p, err := repo.GetForUpdate(ctx, found.ID)
+		if err != nil {
+			return err
+		}
+		return s.applyEvent(ctx, tx, p, ev)
+	})
+}
+
+func (s *PaymentService) applyEvent(ctx context.Context, tx repository.DB, p *models.Payment, ev *payments.Event) error {
+	repo := s.repo.WithTx(tx)
+	switch ev.Status {
+	case payments.StatusSucceeded, payments.StatusCanceled:
+		if math.Abs(ev.Amount-p.Amount) > moneyEpsilon {
+			s.log.Errorw("payment_webhook_amount_mismatch", "payment_id", p.ID, "event_id", ev.ID,
+				"amount", p.Amount, "event_amount", ev.Amount)
+			return ErrPaymentAmountMismatch
+		}
+	}
+
+	switch ev.Status {
+	case payments.StatusSucceeded:
+		if p.Status == payments.StatusPending {
+			if err := repo.UpdateStatus(ctx, p.ID, payments.StatusSucceeded); err != nil {
+				return err
+			}
+			if s.schedules != nil {
+				if err := s.schedules.ApplyPaymentTx(ctx, tx, p.OrderID, p.Amount); err != nil {
+					return err
+				}
+			}
+		}
+		return s.markOrderPaid(ctx, tx, p)
+	case payments.StatusCanceled:
+		if p.Status != payments.StatusPending {
+			return nil
+		}
+		return repo.UpdateStatus(ctx, p.ID, payments.StatusCanceled)
+	default:
+		// возвраты инициируем сами через Refund — уведомление только подтверждает их
+		s.log.Infow("payment_webhook_ignored", "payment_id", p.ID, "status", ev.Status)
+		return nil
+	}
+}
+
+// markOrderPaid переводит заказ в paid, когда оплачена вся сумма заказа
+func (s *PaymentService) markOrderPaid(ctx context.Context, tx repository.DB, p *models.Payment) error {
+	order, err := s.orders.GetByID(ctx, p.OrderID)
+	if err != nil {
+		return err
+	}
+	if order.Status != models.OrderStatusConfirmed {
+		return nil
+	}
+
+	if order.TotalPrice != nil {
+		paid, err := s.repo.WithTx(tx).PaidAmount(ctx, p.OrderID)
+		if err != nil {
+			return err
+		}
+		if paid+moneyEpsilon < *order.TotalPrice {
+			s.log.Infow("order_partially_paid", "order_id", p.OrderID, "paid", paid, "total", *order.TotalPrice)
+			return nil
+		}
+	}
+
+	actor := models.Actor{Name: "payments:" + p.Provider}
+	err = s.orders.ChangeStatusTx(ctx, tx, p.OrderID, models.OrderStatusPaid, actor, fmt.Sprintf("Платёж #%d", p.ID))
+	if errors.Is(err, ErrInvalidTransition) {
+		// заказ успели перевести параллельно — оплату всё равно фиксируем
+		s.log.Warnw("order_paid_transition_skipped", "order_id", p.OrderID, "err", err)
+		return nil
+	}
+	return err
+}
+
+// Refund возвращает деньги через провайдера. amount == nil — вернуть весь остаток платежа.
+// Возврат сначала записывается в pending, запрос к провайдеру идёт вне транзакции с ключом
+// из id возврата, а результат проводится второй транзакцией. Если провайдер не ответил или
+// проводка не удалась, возврат остаётся pending и повтор Refund отправит тот же ключ.
+// Когда по заказу не остаётся оплаченных средств, оплаченный заказ переводится в refunded.
+func (s *PaymentService) Refund(ctx context.Context, paymentID int, amount *float64, actor models.Actor, comment string) (*models.Payment, error) {
+	p, rf, err := s.startRefund(ctx, paymentID, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.provider.Refund(ctx, payments.RefundRequest{
+		ExternalID:     *p.ExternalID,
+		Amount:         rf.Amount,
+		Currency:       p.Currency,
+		IdempotencyKey: fmt.Sprintf("payment-%d-refund-%d", p.ID, rf.ID),
+	})
+	if err != nil {
+		if errors.Is(err, payments.ErrRefundRejected) {
+			if _, ferr := s.repo.FinishRefund(ctx, rf.ID, models.RefundFailed, nil); ferr != nil {
+				s.log.Errorw("payment_refund_fail_not_saved", "payment_id", p.ID, "refund_id", rf.ID, "err", ferr)
+			}
+		} else {
+			// исход неизвестен: возврат остаётся pending, повтор пойдёт с тем же ключом
+			s.log.Warnw("payment_refund_pending", "payment_id", p.ID, "refund_id", rf.ID, "err", err)
+		}
+		return nil, fmt.Errorf("provider refund: %w", err)
+	}
+
+	updated, err := s.completeRefund(ctx, p, rf, res.ExternalID, actor, comment)
+	if err != nil {
+		s.log.Errorw("payment_refund_not_saved", "payment_id", p.ID, "refund_id", rf.ID, "amount", rf.Amount, "err", err)
+		return nil, err
+	}
+	return updated, nil
+}
+
+// startRefund проверяет платёж под блокировкой и создаёт возврат в pending.
+// Незавершённый возврат по платежу не создаётся заново, а повторяется.
+func (s *PaymentService) startRefund(ctx context.Context, paymentID int, amount *float64) (*models.Payment, *models.PaymentRefund, error) {
+	var (
+		p  *models.Payment
+		rf *models.PaymentRefund
+	)
+	err := s.repo.Tx(ctx, func(tx repository.DB) error {
+		repo := s.repo.WithTx(tx)
+		var err error
+		p, err = repo.GetForUpdate(ctx, paymentID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrPaymentNotFound
+			}
+			return err
+		}
+		if p.Status != payments.StatusSucceeded || p.ExternalID == nil {
+			return ErrPaymentNotRefundable
+		}
+
+		rf, err = repo.PendingRefund(ctx, p.ID)
+		switch {
+		case err == nil:
+			if amount != nil && math.Abs(roundMoney(*amount)-rf.Amount) > moneyEpsilon {
+				return helpers.ErrInvalidInput(fmt.Sprintf("refund of %.2f is still pending, repeat it first", rf.Amount))
+			}
+			s.log.Infow("payment_refund_retry", "payment_id", p.ID, "refund_id", rf.ID, "amount", rf.Amount)
+			return nil
+		case !errors.Is(err, repository.ErrNotFound):
+			return err
+		}
+
+		remaining := roundMoney(p.Amount - p.RefundedAmount)
+		value := remaining
+		if amount != nil {
+			value = roundMoney(*amount)
+		}
+		if value <= 0 || value > remaining+moneyEpsilon {
+			return helpers.ErrInvalidInput(fmt.Sprintf("refund amount must be between 0 and %.2f", remaining))
+		}
+
+		rf = &models.PaymentRefund{PaymentID: p.ID, Amount: value}
+		return repo.CreateRefund(ctx, rf)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return p, rf, nil
+}
+
+// completeRefund проводит подтверждённый провайдером возврат: сумма платежа, график и статус заказа.
+// Возврат, уже проведённый параллельным повтором, второй раз не учитывается.
+func (s *PaymentService) completeRefund(ctx context.Context, p *models.Payment, rf *models.PaymentRefund, externalID string, actor models.Actor, comment string) (*models.Payment, error) {
+	var updated *models.Payment
+	err := s.repo.Tx(ctx, func(tx repository.DB) error {
+		repo := s.repo.WithTx(tx)
+		done, err := repo.FinishRefund(ctx, rf.ID, models.RefundSucceeded, &externalID)
+		if err != nil {
+			return err
+		}
+		if !done {
+			updated, err = repo.GetByID(ctx, p.ID)
+			return err
+		}
+
+		if updated, err = repo.AddRefund(ctx, p.ID, rf.Amount); err != nil {
+			return err
+		}
+		s.log.Infow("payment_refunded", "payment_id", p.ID, "order_id", p.OrderID, "refund_id", rf.ID, "amount", rf.Amount)
+
+		if s.schedules != nil {
+			if err := s.schedules.ApplyPaymentTx(ctx, tx, p.OrderID, -rf.Amount); err != nil {
+				return err
+			}
+		}
+
+		paid, err := repo.PaidAmount(ctx, p.OrderID)
+		if err != nil {
+			return err
+		}
+		if paid > moneyEpsilon {
+			return nil
+		}
+
+		if strings.TrimSpace(comment) == "" {
+			comment = fmt.Sprintf("Возврат по платежу #%d", p.ID)
+		}
+		err = s.orders.ChangeStatusTx(ctx, tx, p.OrderID, models.OrderStatusRefunded, actor, comment)
+		if err != nil && !errors.Is(err, ErrInvalidTransition) {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// resolveAmount — сумма платежа: явно заданная, ближайший платёж по графику или неоплаченный остаток заказа
+func (s *PaymentService) resolveAmount(ctx context.Context, order *models.Order, requested *float64) (float64, error) {
+	if requested != nil {
+		if *requested <= 0 {
+			return 0, helpers.ErrInvalidInput("amount must be positive")
+		}
+		return roundMoney(*requested), nil
+	}
+
+	if s.schedules != nil {
+		next, err := s.schedules.NextDue(ctx, order.ID)
+		if err != nil {
+			return 0, err
+		}
+		if next != nil {
+			return *next, nil
+		}
+	}
+
+	if order.TotalPrice == nil {
+		return 0, helpers.ErrInvalidInput("order has no total price, amount is required")
+	}
+	paid, err := s.repo.PaidAmount(ctx, order.ID)
+	if err != nil {
+		return 0, err
+	}
+	rest := roundMoney(*order.TotalPrice - paid)
+	if rest <= 0 {
+		return 0, helpers.ErrInvalidInput("order is already paid")
+	}
+	return rest, nil
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
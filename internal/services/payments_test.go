@@ -0,0 +1,284 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/payments"
+	"github.com/Ramcache/travel-backend/internal/repository"
+	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/Ramcache/travel-backend/internal/testutil"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newPaymentService(db *testutil.MockDB, provider payments.Provider) *services.PaymentService {
+	log := zap.NewNop().Sugar()
+	orderRepo := repository.NewOrderRepo(db)
+	schedules := services.NewPaymentScheduleService(repository.NewPaymentScheduleRepo(db), orderRepo, nil, nil, 0, log)
+	return services.NewPaymentService(repository.NewPaymentRepo(db), services.NewOrderService(orderRepo, nil), schedules, provider, "", log)
+}
+
+func paymentRow(id, orderID int, externalID, status string, amount float64) []any {
+	now := time.Now()
+	return []any{id, orderID, "fake", &externalID, amount, 0.0, "RUB", status, nil, now, now}
+}
+
+func orderRow(id int, status string, total float64) []any {
+	return []any{id, nil, nil, nil, nil, "Иван", "+79990000000", &total, nil, status, false, time.Now(), nil, nil, []string{}, nil, "", nil, "trk"}
+}
+
+func TestPaymentService_WebhookMarksOrderPaid(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	provider := payments.NewFakeProvider("secret")
+	svc := newPaymentService(db, provider)
+
+	db.ExpectQueryRow(func(_ context.Context, _ string, args []any) (pgx.Row, error) {
+		assert.Equal(t, []any{"fake", "fake_1"}, args)
+		return testutil.NewSliceRow(paymentRow(3, 7, "fake_1", payments.StatusPending, 700)), nil
+	})
+	db.ExpectExec(func(_ context.Context, _ string, args []any) (pgconn.CommandTag, error) {
+		assert.Equal(t, "ev-1", args[1])
+		return pgconn.NewCommandTag("INSERT 0 1"), nil
+	})
+	// в транзакции платёж перечитывается с блокировкой
+	db.ExpectQueryRow(func(_ context.Context, sql string, args []any) (pgx.Row, error) {
+		assert.Contains(t, sql, "FOR UPDATE")
+		assert.Equal(t, []any{3}, args)
+		return testutil.NewSliceRow(paymentRow(3, 7, "fake_1", payments.StatusPending, 700)), nil
+	})
+	db.ExpectExec(func(_ context.Context, _ string, args []any) (pgconn.CommandTag, error) {
+		assert.Equal(t, []any{payments.StatusSucceeded, 3}, args)
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+	// график платежей: задаток 300 уже оплачен, остаток 700 закрывается этим платежом
+	db.ExpectQuery(func(_ context.Context, _ string, args []any) (pgx.Rows, error) {
+		now := time.Now()
+		return testutil.NewMockRows([][]any{
+			{10, 7, 1, "Задаток", 300.0, 300.0, now, &now, nil, now},
+			{11, 7, 2, "Остаток", 700.0, 0.0, now, nil, nil, now},
+		}), nil
+	})
+	db.ExpectExec(func(_ context.Context, _ string, args []any) (pgconn.CommandTag, error) {
+		assert.Equal(t, 700.0, args[0])
+		assert.NotNil(t, args[1])
+		assert.Equal(t, 11, args[2])
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+	// заказ и его туристы
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow(orderRow(7, models.OrderStatusConfirmed, 1000)), nil
+	})
+	db.ExpectQuery(func(context.Context, string, []any) (pgx.Rows, error) {
+		return testutil.NewMockRows(nil), nil
+	})
+	// оплачено полностью
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{1000.0}), nil
+	})
+	// смена статуса заказа confirmed → paid
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{models.OrderStatusConfirmed}), nil
+	})
+	db.ExpectExec(func(_ context.Context, _ string, args []any) (pgconn.CommandTag, error) {
+		assert.Equal(t, []any{models.OrderStatusPaid, 7, models.OrderStatusConfirmed}, args)
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+	db.ExpectQueryRow(func(_ context.Context, _ string, args []any) (pgx.Row, error) {
+		assert.Equal(t, "payments:fake", *args[4].(*string))
+		return testutil.NewSliceRow([]any{1, time.Now()}), nil
+	})
+
+	header, body := provider.Webhook("ev-1", "fake_1", payments.StatusSucceeded, 700)
+	require.NoError(t, svc.HandleWebhook(context.Background(), header, body))
+	db.Verify(t)
+}
+
+func TestPaymentService_WebhookDuplicateIgnored(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	provider := payments.NewFakeProvider("secret")
+	svc := newPaymentService(db, provider)
+
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow(paymentRow(3, 7, "fake_1", payments.StatusSucceeded, 1000)), nil
+	})
+	db.ExpectExec(func(context.Context, string, []any) (pgconn.CommandTag, error) {
+		return pgconn.NewCommandTag("INSERT 0 0"), nil
+	})
+
+	header, body := provider.Webhook("ev-1", "fake_1", payments.StatusSucceeded, 1000)
+	require.NoError(t, svc.HandleWebhook(context.Background(), header, body))
+	db.Verify(t)
+}
+
+func TestPaymentService_WebhookAmountMismatch(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	provider := payments.NewFakeProvider("secret")
+	svc := newPaymentService(db, provider)
+
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow(paymentRow(3, 7, "fake_1", payments.StatusPending, 700)), nil
+	})
+	db.ExpectExec(func(context.Context, string, []any) (pgconn.CommandTag, error) {
+		return pgconn.NewCommandTag("INSERT 0 1"), nil
+	})
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow(paymentRow(3, 7, "fake_1", payments.StatusPending, 700)), nil
+	})
+
+	// платёж не отмечается оплаченным, отметка о событии откатывается вместе с транзакцией
+	header, body := provider.Webhook("ev-1", "fake_1", payments.StatusSucceeded, 1)
+	err := svc.HandleWebhook(context.Background(), header, body)
+	assert.ErrorIs(t, err, services.ErrPaymentAmountMismatch)
+	db.Verify(t)
+}
+
+func TestPaymentService_WebhookBadSignature(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	provider := payments.NewFakeProvider("secret")
+	svc := newPaymentService(db, provider)
+
+	header, body := payments.NewFakeProvider("other").Webhook("ev-1", "fake_1", payments.StatusSucceeded, 1000)
+	err := svc.HandleWebhook(context.Background(), header, body)
+	assert.ErrorIs(t, err, payments.ErrInvalidSignature)
+	db.Verify(t)
+}
+
+func TestPaymentService_CreateRequiresConfirmedOrder(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newPaymentService(db, payments.NewFakeProvider("secret"))
+
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow(orderRow(7, models.OrderStatusNew, 1000)), nil
+	})
+	db.ExpectQuery(func(context.Context, string, []any) (pgx.Rows, error) {
+		return testutil.NewMockRows(nil), nil
+	})
+
+	_, err := svc.CreateForOrder(context.Background(), 7, models.CreatePaymentRequest{})
+	assert.ErrorIs(t, err, services.ErrOrderNotPayable)
+	db.Verify(t)
+}
+
+// refundKeys запоминает ключи идемпотентности возвратов; первые fail вызовов обрываются,
+// как при таймауте — провайдер мог и провести возврат
+type refundKeys struct {
+	payments.Provider
+	keys []string
+	fail int
+}
+
+func (p *refundKeys) Refund(ctx context.Context, req payments.RefundRequest) (*payments.Refund, error) {
+	p.keys = append(p.keys, req.IdempotencyKey)
+	res, err := p.Provider.Refund(ctx, req)
+	if p.fail > 0 {
+		p.fail--
+		return nil, context.DeadlineExceeded
+	}
+	return res, err
+}
+
+func refundRow(id, paymentID int, amount float64) []any {
+	now := time.Now()
+	return []any{id, paymentID, amount, models.RefundPending, nil, now, now}
+}
+
+func TestPaymentService_RefundRetryReusesPendingKey(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	fake := payments.NewFakeProvider("secret")
+	provider := &refundKeys{Provider: fake, fail: 1}
+	svc := newPaymentService(db, provider)
+
+	created, err := fake.Create(context.Background(), payments.CreateRequest{OrderID: 7, Amount: 1000})
+	require.NoError(t, err)
+	_, err = fake.ParseWebhook(fake.Webhook("ev-1", created.ExternalID, payments.StatusSucceeded, 1000))
+	require.NoError(t, err)
+
+	lock := func() {
+		db.ExpectQueryRow(func(_ context.Context, sql string, args []any) (pgx.Row, error) {
+			assert.Contains(t, sql, "FOR UPDATE")
+			return testutil.NewSliceRow(paymentRow(3, 7, created.ExternalID, payments.StatusSucceeded, 1000)), nil
+		})
+	}
+
+	// первая попытка: возврат записан в pending, провайдер не ответил
+	lock()
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return nil, pgx.ErrNoRows
+	})
+	db.ExpectQueryRow(func(_ context.Context, _ string, args []any) (pgx.Row, error) {
+		assert.Equal(t, []any{3, 100.0}, args)
+		now := time.Now()
+		return testutil.NewSliceRow([]any{11, models.RefundPending, now, now}), nil
+	})
+
+	amount := 100.0
+	_, err = svc.Refund(context.Background(), 3, &amount, models.Actor{}, "")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// повтор берёт тот же незавершённый возврат и проводит его один раз
+	lock()
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow(refundRow(11, 3, 100)), nil
+	})
+	db.ExpectExec(func(_ context.Context, sql string, args []any) (pgconn.CommandTag, error) {
+		assert.Contains(t, sql, "status='pending'")
+		assert.Equal(t, []any{11, models.RefundSucceeded}, args[:2])
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+	db.ExpectQueryRow(func(_ context.Context, _ string, args []any) (pgx.Row, error) {
+		assert.Equal(t, []any{100.0, 3}, args)
+		row := paymentRow(3, 7, created.ExternalID, payments.StatusSucceeded, 1000)
+		row[5] = 100.0
+		return testutil.NewSliceRow(row), nil
+	})
+	db.ExpectQuery(func(context.Context, string, []any) (pgx.Rows, error) { // график
+		return testutil.NewMockRows(nil), nil
+	})
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{900.0}), nil
+	})
+
+	p, err := svc.Refund(context.Background(), 3, nil, models.Actor{}, "")
+	require.NoError(t, err)
+	assert.Equal(t, 100.0, p.RefundedAmount)
+	assert.Equal(t, []string{"payment-3-refund-11", "payment-3-refund-11"}, provider.keys)
+	db.Verify(t)
+}
+
+func TestPaymentService_RefundRejectedMarksFailed(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	// у фейкового провайдера нет такого платежа — возврат отклонён окончательно
+	svc := newPaymentService(db, &refundKeys{Provider: rejectRefunds{payments.NewFakeProvider("secret")}})
+
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow(paymentRow(3, 7, "fake_1", payments.StatusSucceeded, 1000)), nil
+	})
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return nil, pgx.ErrNoRows
+	})
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		now := time.Now()
+		return testutil.NewSliceRow([]any{11, models.RefundPending, now, now}), nil
+	})
+	db.ExpectExec(func(_ context.Context, _ string, args []any) (pgconn.CommandTag, error) {
+		assert.Equal(t, []any{11, models.RefundFailed}, args[:2])
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+
+	_, err := svc.Refund(context.Background(), 3, nil, models.Actor{}, "")
+	assert.ErrorIs(t, err, payments.ErrRefundRejected)
+	db.Verify(t)
+}
+
+// rejectRefunds — провайдер, который отказывает в любом возврате
+type rejectRefunds struct{ payments.Provider }
+
+func (rejectRefunds) Refund(context.Context, payments.RefundRequest) (*payments.Refund, error) {
+	return nil, payments.ErrRefundRejected
+}
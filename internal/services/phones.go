@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/phone"
+	"github.com/Ramcache/travel-backend/internal/repository"
+)
+
+const phoneBatchSize = 500
+
+// PhoneService приводит сохранённые номера к E.164
+type PhoneService struct {
+	repo      *repository.PhoneRepo
+	customers *repository.CustomerRepo
+	log       *zap.SugaredLogger
+}
+
+func NewPhoneService(repo *repository.PhoneRepo, customers *repository.CustomerRepo, log *zap.SugaredLogger) *PhoneService {
+	return &PhoneService{repo: repo, customers: customers, log: log}
+}
+
+// Normalize проходит заказы, заявки и клиентов пачками. Неразборчивые номера
+// не трогает, только считает. Клиенты, чей номер после приведения совпал с
+// другим клиентом, сливаются в него. dryRun — только посчитать
+func (s *PhoneService) Normalize(ctx context.Context, dryRun bool) (*models.PhoneNormalizeResult, error) {
+	res := &models.PhoneNormalizeResult{DryRun: dryRun}
+
+	var err error
+	if res.Orders, err = s.normalizeContacts(ctx, "orders", dryRun); err != nil {
+		return nil, err
+	}
+	if res.Feedbacks, err = s.normalizeContacts(ctx, "feedbacks", dryRun); err != nil {
+		return nil, err
+	}
+	if res.Customers, err = s.normalizeCustomers(ctx, dryRun); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *PhoneService) normalizeContacts(ctx context.Context, table string, dryRun bool) (models.PhoneNormalizeStats, error) {
+	var stats models.PhoneNormalizeStats
+	afterID := 0
+	for {
+		batch, err := s.repo.ListContactPhones(ctx, table, afterID, phoneBatchSize)
+		if err != nil {
+			return stats, err
+		}
+		for _, rec := range batch {
+			afterID = rec.ID
+			stats.Checked++
+
+			num, err := phone.Parse(rec.Phone, phone.DefaultCountry())
+			if err != nil {
+				stats.Invalid++
+				s.log.Warnw("Номер не удалось разобрать", "table", table, "id", rec.ID, "phone", rec.Phone)
+				continue
+			}
+			if num.E164 == rec.Phone && num.Display == rec.Display {
+				continue
+			}
+			stats.Updated++
+			if dryRun {
+				continue
+			}
+			if err := s.repo.UpdateContactPhone(ctx, table, rec.ID, num.E164, num.Display); err != nil {
+				return stats, err
+			}
+		}
+		if len(batch) < phoneBatchSize {
+			return stats, nil
+		}
+	}
+}
+
+func (s *PhoneService) normalizeCustomers(ctx context.Context, dryRun bool) (models.PhoneNormalizeStats, error) {
+	var stats models.PhoneNormalizeStats
+	afterID := 0
+	for {
+		batch, err := s.repo.ListCustomerPhones(ctx, afterID, phoneBatchSize)
+		if err != nil {
+			return stats, err
+		}
+		for _, rec := range batch {
+			afterID = rec.ID
+			stats.Checked++
+
+			num, err := parseCustomerPhone(rec.Phone)
+			if err != nil {
+				stats.Invalid++
+				s.log.Warnw("Номер клиента не удалось разобрать", "customer_id", rec.ID, "phone", rec.Phone)
+				continue
+			}
+			if num.E164 == rec.Phone {
+				continue
+			}
+
+			targetID, err := s.repo.CustomerByPhone(ctx, num.E164, rec.ID)
+			switch {
+			case errors.Is(err, repository.ErrNotFound):
+				stats.Updated++
+				if !dryRun {
+					if err := s.repo.UpdateCustomerPhone(ctx, rec.ID, num.E164); err != nil {
+						return stats, err
+					}
+				}
+			case err != nil:
+				return stats, err
+			case targetID == rec.ID:
+				// номер занят записью, уже слитой в эту — поменять его нельзя
+				stats.Invalid++
+				s.log.Warnw("Номер клиента занят слитой записью", "customer_id", rec.ID, "phone", num.E164)
+			default:
+				stats.Merged++
+				if !dryRun {
+					if err := s.customers.Merge(ctx, targetID, rec.ID); err != nil {
+						return stats, err
+					}
+					s.log.Infow("Клиент слит по номеру", "customer_id", rec.ID, "into", targetID, "phone", num.E164)
+				}
+			}
+		}
+		if len(batch) < phoneBatchSize {
+			return stats, nil
+		}
+	}
+}
+
+// parseCustomerPhone: миграция сохранила номера клиентов как «+» и все цифры,
+// поэтому национальный номер (например, саудовский 05…) стал «+05…» —
+// такой номер разбирается повторно без «+»
+func parseCustomerPhone(raw string) (phone.Number, error) {
+	num, err := phone.Parse(raw, phone.DefaultCountry())
+	if err != nil && strings.HasPrefix(raw, "+") {
+		return phone.Parse(strings.TrimPrefix(raw, "+"), phone.DefaultCountry())
+	}
+	return num, err
+}
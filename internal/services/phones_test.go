@@ -0,0 +1,129 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/phone"
+	"github.com/Ramcache/travel-backend/internal/repository"
+	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/Ramcache/travel-backend/internal/testutil"
+)
+
+func newPhoneService(db *testutil.MockDB) *services.PhoneService {
+	return services.NewPhoneService(repository.NewPhoneRepo(db), repository.NewCustomerRepo(db), zap.NewNop().Sugar())
+}
+
+func TestPhoneService_Normalize(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newPhoneService(db)
+
+	// orders: один в старом формате, один уже приведён, один мусор
+	db.ExpectQuery(func(_ context.Context, query string, args []any) (pgx.Rows, error) {
+		assert.Contains(t, query, "FROM orders WHERE id > $1")
+		assert.Equal(t, []any{0, 500}, args)
+		return testutil.NewMockRows([][]any{
+			{1, "8 (928) 123-45-67", ""},
+			{2, "+79281234567", "+7 (928) 123-45-67"},
+			{3, "звоните вечером", ""},
+		}), nil
+	})
+	db.ExpectExec(func(_ context.Context, query string, args []any) (pgconn.CommandTag, error) {
+		assert.Contains(t, query, "UPDATE orders")
+		assert.Equal(t, []any{1, "+79281234567", "+7 (928) 123-45-67"}, args)
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+	db.ExpectQuery(func(_ context.Context, query string, _ []any) (pgx.Rows, error) {
+		assert.Contains(t, query, "FROM feedbacks")
+		return testutil.NewMockRows(nil), nil
+	})
+
+	db.ExpectQuery(func(context.Context, string, []any) (pgx.Rows, error) {
+		return testutil.NewMockRows([][]any{{4, "+79281234567", ""}}), nil
+	})
+
+	res, err := svc.Normalize(context.Background(), false)
+	require.NoError(t, err)
+	assert.Equal(t, 3, res.Orders.Checked)
+	assert.Equal(t, 1, res.Orders.Updated)
+	assert.Equal(t, 1, res.Orders.Invalid)
+	assert.Equal(t, 0, res.Feedbacks.Checked)
+	assert.Equal(t, 1, res.Customers.Checked)
+	assert.Equal(t, 0, res.Customers.Updated)
+	db.Verify(t)
+}
+
+func TestPhoneService_NormalizeMergesCustomers(t *testing.T) {
+	require.NoError(t, phone.SetDefaultCountry(phone.CountrySA))
+	t.Cleanup(func() { _ = phone.SetDefaultCountry(phone.CountryRU) })
+
+	db := testutil.NewMockDB(t)
+	svc := newPhoneService(db)
+
+	db.ExpectQuery(func(context.Context, string, []any) (pgx.Rows, error) {
+		return testutil.NewMockRows(nil), nil
+	})
+	db.ExpectQuery(func(context.Context, string, []any) (pgx.Rows, error) {
+		return testutil.NewMockRows(nil), nil
+	})
+	// миграция записала национальные номера как «+0…»: 11 совпадает с 10 и сливается в него
+	db.ExpectQuery(func(context.Context, string, []any) (pgx.Rows, error) {
+		return testutil.NewMockRows([][]any{
+			{10, "+966501234567", ""},
+			{11, "+0501234567", ""},
+			{12, "+0551112233", ""},
+		}), nil
+	})
+	db.ExpectQueryRow(func(_ context.Context, _ string, args []any) (pgx.Row, error) {
+		assert.Equal(t, []any{"+966501234567", 11}, args)
+		return testutil.NewSliceRow([]any{10}), nil
+	})
+	for i := 0; i < 4; i++ {
+		db.ExpectExec(func(_ context.Context, _ string, args []any) (pgconn.CommandTag, error) {
+			assert.Equal(t, []any{10, 11}, args)
+			return pgconn.NewCommandTag("UPDATE 1"), nil
+		})
+	}
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return nil, pgx.ErrNoRows
+	})
+	db.ExpectExec(func(_ context.Context, query string, args []any) (pgconn.CommandTag, error) {
+		assert.Contains(t, query, "UPDATE customers SET phone")
+		assert.Equal(t, []any{12, "+966551112233"}, args)
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+
+	res, err := svc.Normalize(context.Background(), false)
+	require.NoError(t, err)
+	assert.Equal(t, 3, res.Customers.Checked)
+	assert.Equal(t, 1, res.Customers.Merged)
+	assert.Equal(t, 1, res.Customers.Updated)
+	db.Verify(t)
+}
+
+func TestPhoneService_NormalizeDryRun(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newPhoneService(db)
+
+	db.ExpectQuery(func(context.Context, string, []any) (pgx.Rows, error) {
+		return testutil.NewMockRows([][]any{{1, "89281234567", ""}}), nil
+	})
+	db.ExpectQuery(func(context.Context, string, []any) (pgx.Rows, error) {
+		return testutil.NewMockRows(nil), nil
+	})
+	db.ExpectQuery(func(context.Context, string, []any) (pgx.Rows, error) {
+		return testutil.NewMockRows(nil), nil
+	})
+
+	res, err := svc.Normalize(context.Background(), true)
+	require.NoError(t, err)
+	assert.True(t, res.DryRun)
+	assert.Equal(t, 1, res.Orders.Updated)
+	db.Verify(t)
+}
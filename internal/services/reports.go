@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/notifications"
+	"github.com/Ramcache/travel-backend/internal/repository"
+)
+
+const (
+	// сколько туров в топе просмотров
+	digestTopTrips = 10
+	// ближайшие вылеты — на две недели вперёд, не больше digestDepartures
+	digestDeparturesAhead = 14
+	digestDepartures      = 20
+)
+
+// DigestConfig — когда рассылать сводки
+type DigestConfig struct {
+	// daily и/или weekly; пусто — не рассылать
+	Periods []string
+	// At — время отправки, смещение от полуночи в Location
+	At       time.Duration
+	Location *time.Location
+}
+
+// ReportService собирает сводки за день и неделю и рассылает их по расписанию
+// через outbox (событие report.digest, каналы — по маршрутам уведомлений)
+type ReportService struct {
+	repo          *repository.ReportRepo
+	notifications *NotificationService
+	cfg           DigestConfig
+	log           *zap.SugaredLogger
+}
+
+func NewReportService(repo *repository.ReportRepo, notifications *NotificationService, cfg DigestConfig, log *zap.SugaredLogger) *ReportService {
+	if cfg.Location == nil {
+		cfg.Location = time.Local
+	}
+	return &ReportService{repo: repo, notifications: notifications, cfg: cfg, log: log}
+}
+
+func isValidDigestPeriod(period string) bool {
+	return period == models.DigestDaily || period == models.DigestWeekly
+}
+
+// DigestBounds — последний завершившийся период на момент now: вчера
+// или прошлая неделя с понедельника по воскресенье
+func (s *ReportService) DigestBounds(period string, now time.Time) (from, to time.Time) {
+	local := now.In(s.cfg.Location)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, s.cfg.Location)
+	if period == models.DigestWeekly {
+		monday := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+		return monday.AddDate(0, 0, -7), monday
+	}
+	return today.AddDate(0, 0, -1), today
+}
+
+// Digest — сводка за последний завершившийся период
+func (s *ReportService) Digest(ctx context.Context, period string, now time.Time) (*models.Digest, error) {
+	if !isValidDigestPeriod(period) {
+		return nil, helpers.ErrInvalidInput(fmt.Sprintf("invalid period %q, expected daily or weekly", period))
+	}
+	from, to := s.DigestBounds(period, now)
+	return s.build(ctx, s.repo, period, from, to)
+}
+
+func (s *ReportService) build(ctx context.Context, repo *repository.ReportRepo, period string, from, to time.Time) (*models.Digest, error) {
+	d := &models.Digest{Period: period, From: from, To: to}
+
+	var err error
+	if d.OrdersByStatus, err = repo.OrdersByStatus(ctx, from, to); err != nil {
+		return nil, err
+	}
+	for _, kv := range d.OrdersByStatus {
+		d.OrdersTotal += int(kv.Count)
+	}
+	if d.FeedbackCount, err = repo.FeedbackCount(ctx, from, to); err != nil {
+		return nil, err
+	}
+	if d.TopTrips, err = repo.TripActivity(ctx, from, to, digestTopTrips); err != nil {
+		return nil, err
+	}
+	for i := range d.TopTrips {
+		t := &d.TopTrips[i]
+		if t.Views > 0 {
+			c := math.Round(float64(t.Orders)/float64(t.Views)*10000) / 100
+			t.Conversion = &c
+		}
+	}
+	// вылеты — начиная с конца периода, то есть с сегодняшнего дня отправки
+	if d.Departures, err = repo.Departures(ctx, to, to.AddDate(0, 0, digestDeparturesAhead), digestDepartures); err != nil {
+		return nil, err
+	}
+
+	if d.OrdersByStatus == nil {
+		d.OrdersByStatus = []models.KV{}
+	}
+	if d.TopTrips == nil {
+		d.TopTrips = []models.TripActivity{}
+	}
+	if d.Departures == nil {
+		d.Departures = []models.TripDeparture{}
+	}
+	return d, nil
+}
+
+// SendDueDigests ставит в очередь сводки, время которых пришло и которые ещё не отправлены.
+// Отправка отмечается в той же транзакции, поэтому несколько экземпляров API
+// и перезапуски не шлют сводку дважды. Возвращает число поставленных сводок.
+func (s *ReportService) SendDueDigests(ctx context.Context, now time.Time) (int, error) {
+	sent := 0
+	for _, period := range s.cfg.Periods {
+		if !isValidDigestPeriod(period) {
+			continue
+		}
+		from, to := s.DigestBounds(period, now)
+		if now.Before(to.Add(s.cfg.At)) {
+			continue
+		}
+
+		claimed := false
+		err := s.repo.Tx(ctx, func(tx repository.DB) error {
+			repo := s.repo.WithTx(tx)
+			ok, err := repo.ClaimDigest(ctx, period, from)
+			if err != nil || !ok {
+				return err
+			}
+			d, err := s.build(ctx, repo, period, from, to)
+			if err != nil {
+				return err
+			}
+			claimed = true
+			return s.notifications.Enqueue(ctx, tx, notifications.DigestReady{Digest: d, At: now})
+		})
+		if err != nil {
+			return sent, fmt.Errorf("%s digest: %w", period, err)
+		}
+		if claimed {
+			s.log.Infow("digest_enqueued", "period", period, "from", from, "to", to)
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// RunDigests — планировщик сводок, работает до отмены ctx
+func (s *ReportService) RunDigests(ctx context.Context, interval time.Duration) {
+	if len(s.cfg.Periods) == 0 {
+		return
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if _, err := s.SendDueDigests(ctx, time.Now()); err != nil {
+				s.log.Errorw("Ошибка рассылки сводки", "err", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/notifications"
+	"github.com/Ramcache/travel-backend/internal/repository"
+	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/Ramcache/travel-backend/internal/testutil"
+)
+
+var msk = func() *time.Location {
+	loc, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}()
+
+func newReportService(t *testing.T, db *testutil.MockDB, periods ...string) *services.ReportService {
+	return services.NewReportService(repository.NewReportRepo(db),
+		newNotificationService(t, db, "report.digest=telegram", notifications.NewRecorder(notifications.ChannelTelegram)),
+		services.DigestConfig{Periods: periods, At: 9 * time.Hour, Location: msk},
+		zaptest.NewLogger(t).Sugar())
+}
+
+// expectDigest — выборки для сводки: статусы, заявки, активность туров, вылеты
+func expectDigest(t *testing.T, db *testutil.MockDB, from, to time.Time) {
+	db.ExpectQuery(func(_ context.Context, sql string, args []any) (pgx.Rows, error) {
+		assert.Contains(t, sql, "GROUP BY status")
+		assert.Equal(t, []any{from, to}, args)
+		return testutil.NewMockRows([][]any{{"new", int64(4)}, {"confirmed", int64(2)}}), nil
+	})
+	expectCount(db, 3)
+	db.ExpectQuery(func(_ context.Context, _ string, args []any) (pgx.Rows, error) {
+		// дни для просмотров — в часовом поясе сводки
+		assert.Equal(t, from.Format("2006-01-02"), args[2])
+		return testutil.NewMockRows([][]any{{12, "Умра", 300, 6}, {14, "Хадж", 0, 1}}), nil
+	})
+	db.ExpectQuery(func(_ context.Context, _ string, args []any) (pgx.Rows, error) {
+		// вылеты — две недели с конца периода
+		assert.Equal(t, to.Format("2006-01-02"), args[0])
+		assert.Equal(t, to.AddDate(0, 0, 14).Format("2006-01-02"), args[1])
+		return testutil.NewMockRows([][]any{{12, "Умра", to.AddDate(0, 0, 3), 9, 21}}), nil
+	})
+}
+
+func TestReportService_DigestBounds(t *testing.T) {
+	svc := newReportService(t, testutil.NewMockDB(t))
+	// среда, 01:30 по Москве — в UTC ещё вторник
+	now := time.Date(2025, 10, 7, 22, 30, 0, 0, time.UTC)
+
+	from, to := svc.DigestBounds(models.DigestDaily, now)
+	assert.True(t, from.Equal(time.Date(2025, 10, 7, 0, 0, 0, 0, msk)), from)
+	assert.True(t, to.Equal(time.Date(2025, 10, 8, 0, 0, 0, 0, msk)), to)
+
+	from, to = svc.DigestBounds(models.DigestWeekly, now)
+	assert.True(t, from.Equal(time.Date(2025, 9, 29, 0, 0, 0, 0, msk)), from)
+	assert.True(t, to.Equal(time.Date(2025, 10, 6, 0, 0, 0, 0, msk)), to)
+
+	// в воскресенье неделя ещё не кончилась — сводка за предыдущую
+	from, _ = svc.DigestBounds(models.DigestWeekly, time.Date(2025, 10, 12, 20, 0, 0, 0, msk))
+	assert.True(t, from.Equal(time.Date(2025, 9, 29, 0, 0, 0, 0, msk)), from)
+}
+
+func TestReportService_Digest(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newReportService(t, db)
+	now := time.Date(2025, 10, 8, 12, 0, 0, 0, msk)
+	expectDigest(t, db, time.Date(2025, 10, 7, 0, 0, 0, 0, msk), time.Date(2025, 10, 8, 0, 0, 0, 0, msk))
+
+	d, err := svc.Digest(context.Background(), models.DigestDaily, now)
+	require.NoError(t, err)
+	assert.Equal(t, 6, d.OrdersTotal)
+	assert.Equal(t, 3, d.FeedbackCount)
+	require.Len(t, d.TopTrips, 2)
+	assert.Equal(t, 2.0, *d.TopTrips[0].Conversion)
+	// без просмотров конверсии нет
+	assert.Nil(t, d.TopTrips[1].Conversion)
+	assert.Equal(t, 21, d.Departures[0].Travellers)
+	db.Verify(t)
+
+	_, err = svc.Digest(context.Background(), "monthly", now)
+	assert.True(t, helpers.IsInvalidInput(err))
+}
+
+func TestReportService_SendDueDigests(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newReportService(t, db, models.DigestDaily, models.DigestWeekly)
+	// среда: недельная уже ушла в понедельник, дневная ещё нет
+	now := time.Date(2025, 10, 8, 9, 5, 0, 0, msk)
+
+	db.ExpectExec(func(_ context.Context, sql string, args []any) (pgconn.CommandTag, error) {
+		assert.Contains(t, sql, "report_digest_runs")
+		assert.Equal(t, []any{models.DigestDaily, "2025-10-07"}, args)
+		return pgconn.NewCommandTag("INSERT 0 1"), nil
+	})
+	expectDigest(t, db, time.Date(2025, 10, 7, 0, 0, 0, 0, msk), time.Date(2025, 10, 8, 0, 0, 0, 0, msk))
+	db.ExpectQueryRow(func(_ context.Context, sql string, args []any) (pgx.Row, error) {
+		assert.Contains(t, sql, "INSERT INTO notification_outbox")
+		assert.Equal(t, "report.digest", args[0])
+		assert.Contains(t, args[5], "Сводка за 07.10.2025")
+		assert.Contains(t, args[5], "Умра — 300 → 6 (2.0%)")
+		return testutil.NewSliceRow([]any{1, models.NotificationPending, now, now, now}), nil
+	})
+	db.ExpectExec(func(_ context.Context, _ string, args []any) (pgconn.CommandTag, error) {
+		assert.Equal(t, []any{models.DigestWeekly, "2025-09-29"}, args)
+		return pgconn.NewCommandTag("INSERT 0 0"), nil
+	})
+
+	n, err := svc.SendDueDigests(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	db.Verify(t)
+}
+
+func TestReportService_SendDueDigests_NotYet(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newReportService(t, db, models.DigestDaily)
+
+	// до 09:00 по Москве ничего не отправляется и не отмечается
+	n, err := svc.SendDueDigests(context.Background(), time.Date(2025, 10, 8, 8, 59, 0, 0, msk))
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	db.Verify(t)
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/repository"
+)
+
+var (
+	ErrRoleNotFound  = errors.New("role not found")
+	ErrRoleProtected = errors.New("built-in role cannot be changed this way")
+	ErrRoleInUse     = errors.New("role is assigned to users")
+)
+
+// RoleService — роли и их права. Права проверяются на каждом запросе,
+// поэтому изменения действуют сразу, без повторного входа
+type RoleService struct {
+	repo *repository.RoleRepo
+	log  *zap.SugaredLogger
+}
+
+func NewRoleService(repo *repository.RoleRepo, log *zap.SugaredLogger) *RoleService {
+	return &RoleService{repo: repo, log: log}
+}
+
+func (s *RoleService) List(ctx context.Context) ([]models.Role, error) {
+	list, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		list = []models.Role{}
+	}
+	return list, nil
+}
+
+func (s *RoleService) Get(ctx context.Context, id int) (*models.Role, error) {
+	role, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrRoleNotFound
+	}
+	return role, err
+}
+
+func (s *RoleService) Permissions(ctx context.Context) ([]models.Permission, error) {
+	list, err := s.repo.Permissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		list = []models.Permission{}
+	}
+	return list, nil
+}
+
+func (s *RoleService) Create(ctx context.Context, req models.RoleRequest) (*models.Role, error) {
+	role, err := s.build(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, role); err != nil {
+		return nil, err
+	}
+	s.log.Infow("role_created", "id", role.ID, "name", role.Name, "permissions", role.Permissions)
+	return role, nil
+}
+
+// Update заменяет название, описание и права роли. Встроенную роль нельзя переименовать,
+// а права роли admin — изменить, чтобы не остаться без доступа к админке
+func (s *RoleService) Update(ctx context.Context, id int, req models.RoleRequest) (*models.Role, error) {
+	current, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	role, err := s.build(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if current.IsSystem && role.Name != current.Name {
+		return nil, ErrRoleProtected
+	}
+	if id == models.RoleAdmin && !slices.Equal(role.Permissions, current.Permissions) {
+		return nil, ErrRoleProtected
+	}
+
+	role.ID, role.IsSystem, role.UsersCount = id, current.IsSystem, current.UsersCount
+	if err := s.repo.Update(ctx, role); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+	s.log.Infow("role_updated", "id", id, "name", role.Name, "permissions", role.Permissions)
+	return role, nil
+}
+
+// Delete удаляет роль, если она не встроенная и не назначена ни одному пользователю
+func (s *RoleService) Delete(ctx context.Context, id int) error {
+	role, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if role.IsSystem {
+		return ErrRoleProtected
+	}
+	if role.UsersCount > 0 {
+		return ErrRoleInUse
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		status, _, _ := helpers.MapPgErr(err)
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return ErrRoleNotFound
+		case status == http.StatusBadRequest:
+			// роль назначили, пока шло удаление
+			return ErrRoleInUse
+		}
+		return err
+	}
+	s.log.Infow("role_deleted", "id", id, "name", role.Name)
+	return nil
+}
+
+// build проверяет запрос: права — из справочника, без повторов, по алфавиту
+func (s *RoleService) build(ctx context.Context, req models.RoleRequest) (*models.Role, error) {
+	role := &models.Role{
+		Name:        strings.TrimSpace(req.Name),
+		Description: strings.TrimSpace(req.Description),
+		Permissions: []string{},
+	}
+	if role.Name == "" {
+		return nil, helpers.ErrInvalidInput("name is required")
+	}
+
+	catalog, err := s.repo.Permissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(catalog))
+	for _, p := range catalog {
+		known[p.Code] = true
+	}
+	for _, p := range req.Permissions {
+		p = strings.TrimSpace(p)
+		if !known[p] {
+			return nil, helpers.ErrInvalidInput(fmt.Sprintf("unknown permission %q", p))
+		}
+		role.Permissions = append(role.Permissions, p)
+	}
+	slices.Sort(role.Permissions)
+	role.Permissions = slices.Compact(role.Permissions)
+	return role, nil
+}
@@ -0,0 +1,178 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/repository"
+	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/Ramcache/travel-backend/internal/testutil"
+)
+
+func newRoleService(t *testing.T, db *testutil.MockDB) *services.RoleService {
+	return services.NewRoleService(repository.NewRoleRepo(db), zaptest.NewLogger(t).Sugar())
+}
+
+func expectPermissionCatalog(db *testutil.MockDB) {
+	db.ExpectQuery(func(context.Context, string, []any) (pgx.Rows, error) {
+		return testutil.NewMockRows([][]any{
+			{"media.write", "Загрузка и удаление файлов"},
+			{"news.write", "Новости и их категории"},
+			{"orders.write", "Обработка заказов"},
+		}), nil
+	})
+}
+
+// expectRole — роль: ID, название, описание, встроенная, права, число пользователей
+func expectRole(db *testutil.MockDB, id int, name string, system bool, permissions []string, users int) {
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{id, name, "", system, permissions, users}), nil
+	})
+}
+
+func TestRoleService_Create(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newRoleService(t, db)
+
+	expectPermissionCatalog(db)
+	db.ExpectQueryRow(func(_ context.Context, sql string, args []any) (pgx.Row, error) {
+		assert.Contains(t, sql, "INSERT INTO roles")
+		assert.Equal(t, []any{"editor", "Новости"}, args)
+		return testutil.NewSliceRow([]any{4}), nil
+	})
+	db.ExpectExec(func(_ context.Context, sql string, args []any) (pgconn.CommandTag, error) {
+		assert.Contains(t, sql, "INSERT INTO role_permissions")
+		// без повторов и по алфавиту
+		assert.Equal(t, []any{4, []string{"media.write", "news.write"}}, args)
+		return pgconn.NewCommandTag("INSERT 0 2"), nil
+	})
+
+	role, err := svc.Create(context.Background(), models.RoleRequest{
+		Name:        " editor ",
+		Description: "Новости",
+		Permissions: []string{"news.write", "media.write", "news.write"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 4, role.ID)
+	db.Verify(t)
+}
+
+func TestRoleService_Create_UnknownPermission(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newRoleService(t, db)
+	expectPermissionCatalog(db)
+
+	_, err := svc.Create(context.Background(), models.RoleRequest{Name: "editor", Permissions: []string{"news.delete"}})
+	assert.True(t, helpers.IsInvalidInput(err))
+	assert.Contains(t, err.Error(), "news.delete")
+	db.Verify(t)
+}
+
+func TestRoleService_Update_Protected(t *testing.T) {
+	tests := []struct {
+		name string
+		id   int
+		role string
+		req  models.RoleRequest
+	}{
+		{"rename built-in", models.RoleManager, "manager", models.RoleRequest{Name: "sales", Permissions: []string{"orders.write"}}},
+		// иначе можно остаться без доступа к админке
+		{"admin permissions", models.RoleAdmin, "admin", models.RoleRequest{Name: "admin", Permissions: []string{"orders.write"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testutil.NewMockDB(t)
+			svc := newRoleService(t, db)
+			expectRole(db, tt.id, tt.role, true, []string{"media.write", "news.write", "orders.write"}, 1)
+			expectPermissionCatalog(db)
+
+			_, err := svc.Update(context.Background(), tt.id, tt.req)
+			assert.ErrorIs(t, err, services.ErrRoleProtected)
+			db.Verify(t)
+		})
+	}
+}
+
+func TestRoleService_Update_BuiltInPermissions(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newRoleService(t, db)
+
+	expectRole(db, models.RoleManager, "manager", true, []string{"orders.write"}, 3)
+	expectPermissionCatalog(db)
+	db.ExpectExec(func(_ context.Context, sql string, _ []any) (pgconn.CommandTag, error) {
+		assert.Contains(t, sql, "UPDATE roles")
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+	db.ExpectExec(func(_ context.Context, sql string, _ []any) (pgconn.CommandTag, error) {
+		assert.Contains(t, sql, "DELETE FROM role_permissions")
+		return pgconn.NewCommandTag("DELETE 1"), nil
+	})
+	db.ExpectExec(func(_ context.Context, _ string, args []any) (pgconn.CommandTag, error) {
+		assert.Equal(t, []any{models.RoleManager, []string{"media.write", "orders.write"}}, args)
+		return pgconn.NewCommandTag("INSERT 0 2"), nil
+	})
+
+	role, err := svc.Update(context.Background(), models.RoleManager, models.RoleRequest{
+		Name:        "manager",
+		Permissions: []string{"orders.write", "media.write"},
+	})
+	require.NoError(t, err)
+	assert.True(t, role.IsSystem)
+	assert.Equal(t, 3, role.UsersCount)
+	db.Verify(t)
+}
+
+func TestRoleService_Delete(t *testing.T) {
+	tests := []struct {
+		name   string
+		system bool
+		users  int
+		want   error
+	}{
+		{"built-in", true, 0, services.ErrRoleProtected},
+		{"assigned", false, 2, services.ErrRoleInUse},
+		{"unused", false, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testutil.NewMockDB(t)
+			svc := newRoleService(t, db)
+			expectRole(db, 4, "editor", tt.system, []string{"news.write"}, tt.users)
+			if tt.want == nil {
+				db.ExpectExec(func(_ context.Context, sql string, args []any) (pgconn.CommandTag, error) {
+					assert.Contains(t, sql, "DELETE FROM roles")
+					assert.Equal(t, []any{4}, args)
+					return pgconn.NewCommandTag("DELETE 1"), nil
+				})
+			}
+
+			err := svc.Delete(context.Background(), 4)
+			if tt.want == nil {
+				require.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.want)
+			}
+			db.Verify(t)
+		})
+	}
+}
+
+func TestRoleService_Get_NotFound(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newRoleService(t, db)
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return nil, pgx.ErrNoRows
+	})
+
+	_, err := svc.Get(context.Background(), 99)
+	assert.ErrorIs(t, err, services.ErrRoleNotFound)
+	db.Verify(t)
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/notifications"
+	"github.com/Ramcache/travel-backend/internal/repository"
+)
+
+// ErrTelegramNotLinked — Telegram-аккаунт не привязан к сотруднику
+var ErrTelegramNotLinked = errors.New("telegram account is not linked to staff")
+
+// TelegramBotService — кнопки бота под заказами в чате менеджеров и команды бота.
+// Нажать кнопку или выполнить команду может сотрудник, чей Telegram ID указан в профиле,
+// если у его роли есть нужное право — то же, что для этого действия в админке.
+// Действие выполняется от его имени и попадает в историю заказа.
+type TelegramBotService struct {
+	users    *repository.UserRepository
+	orders   *OrderService
+	crm      *OrderCRMService
+	repo     *repository.OrderRepo
+	trips    *repository.TripRepository
+	feedback *repository.FeedbackRepo
+	stats    *repository.StatsRepository
+	bot      *notifications.Telegram
+	renderer notifications.Renderer
+	log      *zap.SugaredLogger
+}
+
+func NewTelegramBotService(
+	users *repository.UserRepository,
+	orders *OrderService,
+	crm *OrderCRMService,
+	repo *repository.OrderRepo,
+	trips *repository.TripRepository,
+	feedback *repository.FeedbackRepo,
+	stats *repository.StatsRepository,
+	bot *notifications.Telegram,
+	renderer notifications.Renderer,
+	log *zap.SugaredLogger,
+) *TelegramBotService {
+	return &TelegramBotService{
+		users:    users,
+		orders:   orders,
+		crm:      crm,
+		repo:     repo,
+		trips:    trips,
+		feedback: feedback,
+		stats:    stats,
+		bot:      bot,
+		renderer: renderer,
+		log:      log,
+	}
+}
+
+// Staff — сотрудник, привязавший этот Telegram-аккаунт, с правами роли.
+// Пользователь без прав (клиент сайта) сотрудником не считается
+func (s *TelegramBotService) Staff(ctx context.Context, telegramID int64) (*models.User, error) {
+	u, err := s.users.GetByTelegramID(ctx, telegramID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrTelegramNotLinked
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(u.Permissions) == 0 {
+		return nil, ErrTelegramNotLinked
+	}
+	return u, nil
+}
+
+// denied — текст отказа, если у сотрудника нет права permission; пусто — можно
+func denied(u *models.User, permission string) string {
+	if slices.Contains(u.Permissions, permission) {
+		return ""
+	}
+	return "Недостаточно прав: нужно право " + permission
+}
+
+// HandleCallback выполняет нажатую кнопку, отвечает на нажатие
+// и обновляет сообщение: новый статус, ответственный и кто нажал
+func (s *TelegramBotService) HandleCallback(ctx context.Context, q *notifications.TelegramCallbackQuery) error {
+	action, orderID, err := notifications.ParseAction(q.Data)
+	if err != nil {
+		s.log.Warnw("Неизвестная кнопка Telegram", "data", q.Data, "telegram_id", q.From.ID)
+		return s.bot.AnswerCallback(ctx, q.ID, "Кнопка устарела", false)
+	}
+
+	user, err := s.Staff(ctx, q.From.ID)
+	if err != nil {
+		if errors.Is(err, ErrTelegramNotLinked) {
+			s.log.Warnw("telegram_callback_denied", "telegram_id", q.From.ID, "action", action, "order_id", orderID)
+			text := fmt.Sprintf("Нет доступа. Попросите администратора указать ваш Telegram ID %d в профиле", q.From.ID)
+			return s.bot.AnswerCallback(ctx, q.ID, text, true)
+		}
+		_ = s.bot.AnswerCallback(ctx, q.ID, "Не удалось выполнить действие", false)
+		return err
+	}
+
+	// все кнопки меняют заказ — как PATCH /admin/orders/{id}/status и assign
+	if msg := denied(user, models.PermOrdersWrite); msg != "" {
+		s.log.Warnw("telegram_callback_denied", "user_id", user.ID, "action", action, "order_id", orderID)
+		return s.bot.AnswerCallback(ctx, q.ID, msg, true)
+	}
+
+	actor := models.Actor{ID: &user.ID, Name: user.FullName}
+	switch action {
+	case notifications.ActionConfirm:
+		err = s.orders.SetStatus(ctx, orderID, models.OrderStatusConfirmed, actor, "")
+	case notifications.ActionReject:
+		err = s.orders.SetStatus(ctx, orderID, models.OrderStatusRejected, actor, "")
+	case notifications.ActionAssign:
+		err = s.crm.Assign(ctx, orderID, &user.ID, actor, "")
+	}
+
+	answer, known := callbackAnswer(action, err)
+	if err != nil && !known {
+		s.log.Errorw("Ошибка обработки кнопки Telegram", "action", action, "order_id", orderID, "user_id", user.ID, "err", err)
+	} else {
+		s.log.Infow("telegram_callback", "action", action, "order_id", orderID, "user_id", user.ID, "result", answer)
+	}
+	if err := s.bot.AnswerCallback(ctx, q.ID, answer, err != nil); err != nil {
+		s.log.Warnw("Ошибка ответа на нажатие кнопки", "err", err)
+	}
+
+	// и после успеха, и когда заказ уже изменили другие, сообщение показывает актуальное состояние
+	if q.Message != nil && (err == nil || known) {
+		var last *lastAction
+		if err == nil {
+			last = &lastAction{action: action, actor: user.FullName, at: time.Now()}
+		}
+		if err := s.refresh(ctx, q.Message, orderID, last); err != nil {
+			s.log.Warnw("Не удалось обновить сообщение о заказе", "order_id", orderID, "err", err)
+		}
+	}
+	return nil
+}
+
+// callbackAnswer — текст ответа на нажатие; known=false — неожиданная ошибка
+func callbackAnswer(action string, err error) (string, bool) {
+	switch {
+	case err == nil:
+		switch action {
+		case notifications.ActionConfirm:
+			return "Заказ подтверждён", true
+		case notifications.ActionReject:
+			return "Заказ отклонён", true
+		default:
+			return "Заказ назначен на вас", true
+		}
+	case errors.Is(err, ErrOrderNotFound):
+		return "Заказ не найден", true
+	case errors.Is(err, ErrInvalidTransition):
+		return "Статус заказа уже изменён", true
+	case errors.Is(err, ErrAssignmentChanged):
+		return "Заказ уже взял другой менеджер", true
+	}
+	return "Не удалось выполнить действие", false
+}
+
+type lastAction struct {
+	action string
+	actor  string
+	at     time.Time
+}
+
+var actionTitles = map[string]string{
+	notifications.ActionConfirm: "подтверждение",
+	notifications.ActionReject:  "отклонение",
+	notifications.ActionAssign:  "взят в работу",
+}
+
+// refresh перерисовывает сообщение о заказе с текущим статусом и кнопками
+func (s *TelegramBotService) refresh(ctx context.Context, m *notifications.TelegramMessage, orderID int, last *lastAction) error {
+	msg, err := s.OrderMessage(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if last != nil {
+		msg.Text += fmt.Sprintf("\n✍️ <b>%s:</b> %s, %s",
+			html.EscapeString(last.actor), actionTitles[last.action], last.at.Format("02.01.2006 15:04"))
+	}
+	return s.bot.EditMessage(ctx, m.Chat.ID, m.MessageID, msg)
+}
+
+// OrderMessage — карточка заказа для чата менеджеров: текст уведомления о заказе,
+// текущий статус, ответственный и кнопки, доступные в этом статусе
+func (s *TelegramBotService) OrderMessage(ctx context.Context, orderID int) (notifications.Message, error) {
+	order, err := s.orders.GetByID(ctx, orderID)
+	if err != nil {
+		return notifications.Message{}, err
+	}
+
+	var trip *models.Trip
+	if order.TripID.Valid {
+		trip, err = s.trips.GetByID(ctx, int(order.TripID.Int32))
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return notifications.Message{}, err
+		}
+	}
+
+	ev := notifications.OrderCreated{Order: order, Trip: trip, At: order.CreatedAt}
+	msg, err := s.renderer.Render(ctx, ev, notifications.ChannelTelegram, notifications.AudienceAdmin)
+	if err != nil {
+		return notifications.Message{}, err
+	}
+
+	var b strings.Builder
+	b.WriteString(msg.Text)
+	fmt.Fprintf(&b, "\n\n📌 <b>Заказ №%d, статус:</b> %s", order.ID, html.EscapeString(notifications.StatusTitle(order.Status)))
+	if order.AssignedTo != nil {
+		name, err := s.repo.StaffName(ctx, *order.AssignedTo)
+		switch {
+		case err == nil:
+			fmt.Fprintf(&b, "\n👤 <b>Ответственный:</b> %s", html.EscapeString(name))
+		case !errors.Is(err, repository.ErrNotFound):
+			return notifications.Message{}, err
+		}
+	}
+	msg.Text = b.String()
+	msg.Actions = notifications.OrderActions(order)
+	return msg, nil
+}
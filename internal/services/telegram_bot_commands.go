@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/notifications"
+	"github.com/Ramcache/travel-backend/internal/repository"
+)
+
+// сколько записей бот показывает в списках
+const botListLimit = 10
+
+const botHelp = "🤖 <b>Команды</b>\n\n" +
+	"/orders new — непрочитанные заказы\n" +
+	"/order &lt;id&gt; — заказ с кнопками\n" +
+	"/feedback — непрочитанные заявки на консультацию\n" +
+	"/stats today — статистика за сегодня\n" +
+	"/trip &lt;id&gt; on|off — включить или выключить тур"
+
+// HandleMessage выполняет команду из сообщения и отвечает в тот же чат.
+// Сообщения без команды игнорируются.
+func (s *TelegramBotService) HandleMessage(ctx context.Context, m *notifications.TelegramMessage) error {
+	cmd, args, ok := parseCommand(m.Text)
+	if !ok || m.From == nil {
+		return nil
+	}
+
+	user, err := s.Staff(ctx, m.From.ID)
+	if err != nil {
+		if errors.Is(err, ErrTelegramNotLinked) {
+			s.log.Warnw("telegram_command_denied", "telegram_id", m.From.ID, "command", cmd)
+			return s.reply(ctx, m.Chat.ID, notifications.Message{Text: fmt.Sprintf(
+				"⛔ Нет доступа. Попросите администратора указать ваш Telegram ID <code>%d</code> в профиле", m.From.ID)})
+		}
+		return err
+	}
+
+	msg, err := s.runCommand(ctx, user, cmd, args)
+	if err != nil {
+		s.log.Errorw("Ошибка выполнения команды бота", "command", cmd, "args", args, "user_id", user.ID, "err", err)
+		msg = notifications.Message{Text: "Не удалось выполнить команду, попробуйте позже"}
+	} else {
+		s.log.Infow("telegram_command", "command", cmd, "args", args, "user_id", user.ID)
+	}
+	return s.reply(ctx, m.Chat.ID, msg)
+}
+
+func (s *TelegramBotService) reply(ctx context.Context, chatID int64, msg notifications.Message) error {
+	msg.To = []string{strconv.FormatInt(chatID, 10)}
+	return s.bot.Send(ctx, msg)
+}
+
+// parseCommand разбирает "/orders@my_bot new" в ("orders", ["new"]).
+// "/order_7" — то же, что "/order 7": такую команду можно нажать в списке.
+func parseCommand(text string) (string, []string, bool) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return "", nil, false
+	}
+	cmd, _, _ := strings.Cut(strings.TrimPrefix(fields[0], "/"), "@")
+	args := fields[1:]
+	if name, arg, ok := strings.Cut(cmd, "_"); ok {
+		cmd, args = name, append([]string{arg}, args...)
+	}
+	return strings.ToLower(cmd), args, true
+}
+
+// commandPermissions — право, нужное для команды; те же, что у соответствующих ручек админки
+var commandPermissions = map[string]string{
+	"orders":   models.PermOrdersRead,
+	"order":    models.PermOrdersRead,
+	"feedback": models.PermFeedbackRead,
+	"stats":    models.PermStatsRead,
+	"trip":     models.PermTripsWrite,
+}
+
+// runCommand возвращает ответ на команду; ошибка — только непредвиденная,
+// неверные аргументы, нехватка прав и отсутствующие записи объясняются в ответе
+func (s *TelegramBotService) runCommand(ctx context.Context, user *models.User, cmd string, args []string) (notifications.Message, error) {
+	if p, ok := commandPermissions[cmd]; ok {
+		if msg := denied(user, p); msg != "" {
+			s.log.Warnw("telegram_command_denied", "user_id", user.ID, "command", cmd)
+			return notifications.Message{Text: "⛔ " + msg}, nil
+		}
+	}
+
+	switch {
+	case cmd == "orders" && len(args) == 1 && args[0] == "new":
+		return s.newOrders(ctx)
+	case cmd == "order" && len(args) == 1:
+		id, err := strconv.Atoi(args[0])
+		if err != nil || id <= 0 {
+			return notifications.Message{Text: "Использование: /order &lt;id&gt;"}, nil
+		}
+		msg, err := s.OrderMessage(ctx, id)
+		if errors.Is(err, ErrOrderNotFound) {
+			return notifications.Message{Text: fmt.Sprintf("Заказ №%d не найден", id)}, nil
+		}
+		return msg, err
+	case cmd == "feedback" && len(args) == 0:
+		return s.unreadFeedback(ctx)
+	case cmd == "stats" && len(args) == 1 && args[0] == "today":
+		return s.statsToday(ctx, time.Now())
+	case cmd == "trip" && len(args) == 2:
+		id, err := strconv.Atoi(args[0])
+		if err != nil || id <= 0 || (args[1] != "on" && args[1] != "off") {
+			return notifications.Message{Text: "Использование: /trip &lt;id&gt; on|off"}, nil
+		}
+		return s.setTripActive(ctx, id, args[1] == "on")
+	}
+	return notifications.Message{Text: botHelp}, nil
+}
+
+func (s *TelegramBotService) newOrders(ctx context.Context) (notifications.Message, error) {
+	unread := false
+	f := models.OrderFilter{IsRead: &unread}
+	total, err := s.repo.Count(ctx, f)
+	if err != nil {
+		return notifications.Message{}, err
+	}
+	if total == 0 {
+		return notifications.Message{Text: "Непрочитанных заказов нет 👌"}, nil
+	}
+	list, err := s.repo.List(ctx, botListLimit, 0, f)
+	if err != nil {
+		return notifications.Message{}, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "🛒 <b>Непрочитанные заказы: %d</b>\n", total)
+	for _, o := range list {
+		price := "—"
+		if o.TotalPrice != nil {
+			price = helpers.FormatPrice(*o.TotalPrice) + " руб."
+		}
+		fmt.Fprintf(&b, "\n/order_%d — %s, %s, %s · %s · %s",
+			o.ID,
+			html.EscapeString(o.UserName),
+			html.EscapeString(helpers.IfEmpty(&o.UserPhoneDisplay, o.UserPhone)),
+			price,
+			notifications.StatusTitle(o.Status),
+			o.CreatedAt.Format("02.01 15:04"),
+		)
+	}
+	if total > len(list) {
+		fmt.Fprintf(&b, "\n\n…и ещё %d", total-len(list))
+	}
+	return notifications.Message{Text: b.String()}, nil
+}
+
+func (s *TelegramBotService) unreadFeedback(ctx context.Context) (notifications.Message, error) {
+	unread := false
+	total, err := s.feedback.Count(ctx, "", &unread)
+	if err != nil {
+		return notifications.Message{}, err
+	}
+	if total == 0 {
+		return notifications.Message{Text: "Непрочитанных заявок на консультацию нет 👌"}, nil
+	}
+	list, err := s.feedback.List(ctx, botListLimit, 0, "", &unread)
+	if err != nil {
+		return notifications.Message{}, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "💬 <b>Непрочитанные заявки: %d</b>\n", total)
+	for _, f := range list {
+		fmt.Fprintf(&b, "\n%s — %s, %s",
+			f.CreatedAt.Format("02.01 15:04"),
+			html.EscapeString(f.UserName),
+			html.EscapeString(helpers.IfEmpty(&f.UserPhoneDisplay, f.UserPhone)),
+		)
+	}
+	if total > len(list) {
+		fmt.Fprintf(&b, "\n\n…и ещё %d", total-len(list))
+	}
+	return notifications.Message{Text: b.String()}, nil
+}
+
+func (s *TelegramBotService) statsToday(ctx context.Context, now time.Time) (notifications.Message, error) {
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	today, err := s.repo.Count(ctx, models.OrderFilter{From: &dayStart})
+	if err != nil {
+		return notifications.Message{}, err
+	}
+	todayNew, err := s.repo.Count(ctx, models.OrderFilter{From: &dayStart, Status: models.OrderStatusNew})
+	if err != nil {
+		return notifications.Message{}, err
+	}
+	unread := false
+	unreadOrders, err := s.repo.Count(ctx, models.OrderFilter{IsRead: &unread})
+	if err != nil {
+		return notifications.Message{}, err
+	}
+	st, err := s.stats.Get(ctx)
+	if err != nil {
+		return notifications.Message{}, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "📊 <b>Статистика на %s</b>\n\n", now.Format("02.01.2006"))
+	fmt.Fprintf(&b, "🛒 Заказов сегодня: <b>%d</b> (ещё не разобрано: %d)\n", today, todayNew)
+	fmt.Fprintf(&b, "📥 Непрочитанных заказов: %d\n\n", unreadOrders)
+	fmt.Fprintf(&b, "🌍 Туров: %d · 📰 Новостей: %d · 👤 Пользователей: %d", st.TotalTrips, st.TotalNews, st.TotalUsers)
+	if len(st.TripsByType) > 0 {
+		b.WriteString("\n\n<b>Туры по типам:</b>")
+		for _, kv := range st.TripsByType {
+			fmt.Fprintf(&b, "\n• %s: %d", html.EscapeString(kv.Key), kv.Count)
+		}
+	}
+	return notifications.Message{Text: b.String()}, nil
+}
+
+func (s *TelegramBotService) setTripActive(ctx context.Context, id int, active bool) (notifications.Message, error) {
+	title, err := s.trips.SetActive(ctx, id, active)
+	if errors.Is(err, repository.ErrNotFound) {
+		return notifications.Message{Text: fmt.Sprintf("Тур №%d не найден", id)}, nil
+	}
+	if err != nil {
+		return notifications.Message{}, err
+	}
+
+	state := "выключен 🔴"
+	if active {
+		state = "включён 🟢"
+	}
+	return notifications.Message{Text: fmt.Sprintf("Тур «%s» (№%d) %s", html.EscapeString(title), id, state)}, nil
+}
@@ -0,0 +1,222 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/notifications"
+	"github.com/Ramcache/travel-backend/internal/testutil"
+)
+
+func command(text string) *notifications.TelegramMessage {
+	return &notifications.TelegramMessage{
+		MessageID: 20,
+		From:      &notifications.TelegramUser{ID: 555},
+		Chat:      notifications.TelegramChat{ID: -100},
+		Text:      text,
+	}
+}
+
+func expectCount(db *testutil.MockDB, n int) {
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{n}), nil
+	})
+}
+
+// runCommand выполняет команду и возвращает единственный ответ бота
+func runCommand(t *testing.T, db *testutil.MockDB, api *testutil.FakeBotAPI, text string, run func(*notifications.TelegramMessage) error) testutil.BotCall {
+	t.Helper()
+	require.NoError(t, run(command(text)))
+	db.Verify(t)
+	calls := api.Calls("sendMessage")
+	require.Len(t, calls, 1)
+	assert.Equal(t, "-100", calls[0].Payload["chat_id"])
+	return calls[0]
+}
+
+func TestTelegramBot_OrdersNew(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc, api := newTelegramBot(t, db)
+
+	expectStaff(db, staffRow(3, models.RoleAdmin, 555))
+	db.ExpectQueryRow(func(_ context.Context, sql string, args []any) (pgx.Row, error) {
+		assert.Contains(t, sql, "is_read")
+		assert.Equal(t, []any{false}, args)
+		return testutil.NewSliceRow([]any{11}), nil
+	})
+	db.ExpectQuery(func(context.Context, string, []any) (pgx.Rows, error) {
+		return testutil.NewMockRows([][]any{orderRow(7, models.OrderStatusNew, 285000)}), nil
+	})
+	db.ExpectQuery(func(context.Context, string, []any) (pgx.Rows, error) {
+		return testutil.NewMockRows(nil), nil
+	})
+
+	reply := runCommand(t, db, api, "/orders@travel_bot new", func(m *notifications.TelegramMessage) error {
+		return svc.HandleMessage(context.Background(), m)
+	})
+	text := reply.Payload["text"].(string)
+	assert.Contains(t, text, "Непрочитанные заказы: 11")
+	assert.Contains(t, text, "/order_7 — Иван, +79990000000, 285 000 руб.")
+	assert.Contains(t, text, "…и ещё 10")
+}
+
+func TestTelegramBot_OrderCard(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc, api := newTelegramBot(t, db)
+
+	expectStaff(db, staffRow(3, models.RoleAdmin, 555))
+	expectOrder(db, orderRow(7, models.OrderStatusNew, 1000))
+
+	reply := runCommand(t, db, api, "/order_7", func(m *notifications.TelegramMessage) error {
+		return svc.HandleMessage(context.Background(), m)
+	})
+	assert.Contains(t, reply.Payload["text"], "№7")
+	assert.Equal(t, []string{"confirm_7", "reject_7", "assign_7"}, keyboardData(t, reply))
+}
+
+func TestTelegramBot_OrderNotFound(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc, api := newTelegramBot(t, db)
+
+	expectStaff(db, staffRow(3, models.RoleAdmin, 555))
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return nil, pgx.ErrNoRows
+	})
+
+	reply := runCommand(t, db, api, "/order 404", func(m *notifications.TelegramMessage) error {
+		return svc.HandleMessage(context.Background(), m)
+	})
+	assert.Equal(t, "Заказ №404 не найден", reply.Payload["text"])
+}
+
+func TestTelegramBot_Feedback(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc, api := newTelegramBot(t, db)
+
+	expectStaff(db, staffRow(3, models.RoleAdmin, 555))
+	expectCount(db, 1)
+	db.ExpectQuery(func(context.Context, string, []any) (pgx.Rows, error) {
+		at := time.Date(2025, 3, 14, 12, 30, 0, 0, time.Local)
+		return testutil.NewMockRows([][]any{{1, "Мария <b>", "+79991112233", false, at, nil, "+7 999 111-22-33"}}), nil
+	})
+
+	reply := runCommand(t, db, api, "/feedback", func(m *notifications.TelegramMessage) error {
+		return svc.HandleMessage(context.Background(), m)
+	})
+	assert.Contains(t, reply.Payload["text"], "14.03 12:30 — Мария &lt;b&gt;, +7 999 111-22-33")
+}
+
+func TestTelegramBot_StatsToday(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc, api := newTelegramBot(t, db)
+
+	expectStaff(db, staffRow(3, models.RoleAdmin, 555))
+	// сегодня, из них новых, непрочитанные
+	db.ExpectQueryRow(func(_ context.Context, _ string, args []any) (pgx.Row, error) {
+		from := args[0].(time.Time)
+		assert.Zero(t, from.Hour())
+		assert.Equal(t, time.Now().Day(), from.Day())
+		return testutil.NewSliceRow([]any{5}), nil
+	})
+	expectCount(db, 2)
+	expectCount(db, 9)
+	// StatsRepository: счётчики и разбивки
+	for _, n := range []int64{40, 12, 8} {
+		db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+			return testutil.NewSliceRow([]any{n}), nil
+		})
+	}
+	for i := 0; i < 5; i++ {
+		db.ExpectQuery(func(context.Context, string, []any) (pgx.Rows, error) {
+			if i == 3 {
+				return testutil.NewMockRows([][]any{{"umrah", int64(6)}}), nil
+			}
+			return testutil.NewMockRows(nil), nil
+		})
+	}
+
+	reply := runCommand(t, db, api, "/stats today", func(m *notifications.TelegramMessage) error {
+		return svc.HandleMessage(context.Background(), m)
+	})
+	text := reply.Payload["text"].(string)
+	assert.Contains(t, text, "Заказов сегодня: <b>5</b> (ещё не разобрано: 2)")
+	assert.Contains(t, text, "Непрочитанных заказов: 9")
+	assert.Contains(t, text, "Туров: 8 · 📰 Новостей: 12 · 👤 Пользователей: 40")
+	assert.Contains(t, text, "umrah: 6")
+}
+
+func TestTelegramBot_TripToggle(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc, api := newTelegramBot(t, db)
+
+	expectStaff(db, staffRow(3, models.RoleAdmin, 555))
+	db.ExpectQueryRow(func(_ context.Context, sql string, args []any) (pgx.Row, error) {
+		assert.Contains(t, sql, "UPDATE trips SET active")
+		assert.Equal(t, []any{false, 12}, args)
+		return testutil.NewSliceRow([]any{"Умра — 10 дней"}), nil
+	})
+
+	reply := runCommand(t, db, api, "/trip 12 off", func(m *notifications.TelegramMessage) error {
+		return svc.HandleMessage(context.Background(), m)
+	})
+	assert.Contains(t, reply.Payload["text"], "Тур «Умра — 10 дней» (№12) выключен")
+}
+
+func TestTelegramBot_CommandUsage(t *testing.T) {
+	for text, want := range map[string]string{
+		"/trip 12 maybe": "Использование: /trip",
+		"/order abc":     "Использование: /order",
+		"/start":         "/orders new",
+	} {
+		t.Run(text, func(t *testing.T) {
+			db := testutil.NewMockDB(t)
+			svc, api := newTelegramBot(t, db)
+			expectStaff(db, staffRow(3, models.RoleAdmin, 555))
+
+			reply := runCommand(t, db, api, text, func(m *notifications.TelegramMessage) error {
+				return svc.HandleMessage(context.Background(), m)
+			})
+			assert.Contains(t, reply.Payload["text"], want)
+		})
+	}
+}
+
+func TestTelegramBot_CommandsOnlyForLinkedAdmins(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc, api := newTelegramBot(t, db)
+	expectStaff(db, nil)
+
+	// заказ не запрашивается: ответ — только отказ
+	reply := runCommand(t, db, api, "/order 7", func(m *notifications.TelegramMessage) error {
+		return svc.HandleMessage(context.Background(), m)
+	})
+	assert.Contains(t, reply.Payload["text"], "Нет доступа")
+	assert.Contains(t, reply.Payload["text"], "555")
+}
+
+func TestTelegramBot_IgnoresPlainText(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc, api := newTelegramBot(t, db)
+
+	require.NoError(t, svc.HandleMessage(context.Background(), command("привет")))
+	db.Verify(t)
+	assert.Empty(t, api.Calls("sendMessage"))
+}
+
+func TestTelegramBot_CommandRequiresPermission(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc, api := newTelegramBot(t, db)
+	expectStaff(db, staffRow(4, models.RoleManager, 555, models.PermOrdersRead, models.PermOrdersWrite))
+
+	// тур не трогаем: у роли нет trips.write
+	reply := runCommand(t, db, api, "/trip 12 off", func(m *notifications.TelegramMessage) error {
+		return svc.HandleMessage(context.Background(), m)
+	})
+	assert.Contains(t, reply.Payload["text"], "Недостаточно прав: нужно право trips.write")
+}
@@ -0,0 +1,232 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/notifications"
+	"github.com/Ramcache/travel-backend/internal/repository"
+	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/Ramcache/travel-backend/internal/testutil"
+)
+
+func newTelegramBot(t *testing.T, db *testutil.MockDB) (*services.TelegramBotService, *testutil.FakeBotAPI) {
+	t.Helper()
+	api := testutil.NewFakeBotAPI(t)
+	log := zaptest.NewLogger(t).Sugar()
+	orderRepo := repository.NewOrderRepo(db)
+	bot := notifications.NewTelegram(notifications.TelegramConfig{Token: "T", BaseURL: api.URL})
+	svc := services.NewTelegramBotService(
+		repository.NewUserRepository(db),
+		services.NewOrderService(orderRepo, nil),
+		services.NewOrderCRMService(orderRepo, nil, log),
+		orderRepo,
+		repository.NewTripRepository(db),
+		repository.NewFeedbackRepo(db),
+		repository.NewStatsRepository(db),
+		bot,
+		notifications.NewDefaultRenderer(notifications.Links{}),
+		log,
+	)
+	return svc, api
+}
+
+// staffRow — сотрудник с правами роли; у admin по умолчанию все права, нужные боту
+func staffRow(id, role int, telegramID int64, permissions ...string) []any {
+	if role == models.RoleAdmin && permissions == nil {
+		permissions = []string{models.PermFeedbackRead, models.PermOrdersRead, models.PermOrdersWrite, models.PermStatsRead, models.PermTripsWrite}
+	}
+	now := time.Now()
+	return []any{id, "anna@example.com", "Анна Смирнова", nil, role, now, now, nil, nil, &telegramID, &now, permissions}
+}
+
+func expectStaff(db *testutil.MockDB, row []any) {
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		if row == nil {
+			return nil, pgx.ErrNoRows
+		}
+		return testutil.NewSliceRow(row), nil
+	})
+}
+
+func callback(data string) *notifications.TelegramCallbackQuery {
+	return &notifications.TelegramCallbackQuery{
+		ID:      "cb1",
+		From:    notifications.TelegramUser{ID: 555},
+		Message: &notifications.TelegramMessage{MessageID: 10, Chat: notifications.TelegramChat{ID: -100}},
+		Data:    data,
+	}
+}
+
+func keyboardData(t *testing.T, call testutil.BotCall) []string {
+	t.Helper()
+	var data []string
+	rows := call.Payload["reply_markup"].(map[string]any)["inline_keyboard"].([]any)
+	for _, row := range rows {
+		for _, b := range row.([]any) {
+			if d, ok := b.(map[string]any)["callback_data"]; ok {
+				data = append(data, d.(string))
+			}
+		}
+	}
+	return data
+}
+
+func TestTelegramBot_Confirm(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc, api := newTelegramBot(t, db)
+
+	expectStaff(db, staffRow(3, models.RoleAdmin, 555))
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{models.OrderStatusNew}), nil
+	})
+	db.ExpectExec(func(_ context.Context, _ string, args []any) (pgconn.CommandTag, error) {
+		assert.Equal(t, []any{models.OrderStatusConfirmed, 7, models.OrderStatusNew}, args)
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+	// в историю — от имени привязанного администратора
+	db.ExpectQueryRow(func(_ context.Context, _ string, args []any) (pgx.Row, error) {
+		assert.Equal(t, 3, *args[3].(*int))
+		assert.Equal(t, "Анна Смирнова", *args[4].(*string))
+		return testutil.NewSliceRow([]any{1, time.Now()}), nil
+	})
+	expectOrder(db, orderRow(7, models.OrderStatusConfirmed, 1000))
+
+	require.NoError(t, svc.HandleCallback(context.Background(), callback("confirm_7")))
+	db.Verify(t)
+
+	answer := api.Calls("answerCallbackQuery")
+	require.Len(t, answer, 1)
+	assert.Equal(t, "Заказ подтверждён", answer[0].Payload["text"])
+	assert.Equal(t, false, answer[0].Payload["show_alert"])
+
+	edit := api.Calls("editMessageText")
+	require.Len(t, edit, 1)
+	assert.EqualValues(t, -100, edit[0].Payload["chat_id"])
+	assert.EqualValues(t, 10, edit[0].Payload["message_id"])
+	text := edit[0].Payload["text"].(string)
+	assert.Contains(t, text, notifications.StatusTitle(models.OrderStatusConfirmed))
+	assert.Contains(t, text, "Анна Смирнова")
+	// подтверждать больше нечего, взять себе — можно
+	assert.Equal(t, []string{"assign_7"}, keyboardData(t, edit[0]))
+}
+
+func TestTelegramBot_Assign(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc, api := newTelegramBot(t, db)
+
+	expectStaff(db, staffRow(3, models.RoleAdmin, 555))
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{models.OrderStatusNew, nil}), nil
+	})
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{"Анна Смирнова"}), nil
+	})
+	db.ExpectExec(func(_ context.Context, _ string, args []any) (pgconn.CommandTag, error) {
+		assert.Equal(t, 3, *args[0].(*int))
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{1, time.Now()}), nil
+	})
+	assignee := 3
+	row := orderRow(7, models.OrderStatusNew, 1000)
+	row[12] = &assignee
+	expectOrder(db, row)
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{"Анна Смирнова"}), nil
+	})
+
+	require.NoError(t, svc.HandleCallback(context.Background(), callback("assign_7")))
+	db.Verify(t)
+
+	assert.Equal(t, "Заказ назначен на вас", api.Calls("answerCallbackQuery")[0].Payload["text"])
+	edit := api.Calls("editMessageText")
+	require.Len(t, edit, 1)
+	assert.Contains(t, edit[0].Payload["text"], "Ответственный:</b> Анна Смирнова")
+	assert.Equal(t, []string{"confirm_7", "reject_7"}, keyboardData(t, edit[0]))
+}
+
+func TestTelegramBot_StaleButtonRefreshesMessage(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc, api := newTelegramBot(t, db)
+
+	expectStaff(db, staffRow(3, models.RoleAdmin, 555))
+	// заказ уже отклонил другой менеджер
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{models.OrderStatusRejected}), nil
+	})
+	expectOrder(db, orderRow(7, models.OrderStatusRejected, 1000))
+
+	require.NoError(t, svc.HandleCallback(context.Background(), callback("confirm_7")))
+	db.Verify(t)
+
+	answer := api.Calls("answerCallbackQuery")
+	require.Len(t, answer, 1)
+	assert.Equal(t, "Статус заказа уже изменён", answer[0].Payload["text"])
+	edit := api.Calls("editMessageText")
+	require.Len(t, edit, 1)
+	assert.Empty(t, keyboardData(t, edit[0]))
+}
+
+func TestTelegramBot_OnlyLinkedStaff(t *testing.T) {
+	for name, row := range map[string][]any{
+		"not linked": nil,
+		// клиент сайта без прав
+		"customer": staffRow(4, models.RoleUser, 555),
+	} {
+		t.Run(name, func(t *testing.T) {
+			db := testutil.NewMockDB(t)
+			svc, api := newTelegramBot(t, db)
+			expectStaff(db, row)
+
+			require.NoError(t, svc.HandleCallback(context.Background(), callback("confirm_7")))
+			db.Verify(t)
+
+			answer := api.Calls("answerCallbackQuery")
+			require.Len(t, answer, 1)
+			assert.Equal(t, true, answer[0].Payload["show_alert"])
+			assert.Contains(t, answer[0].Payload["text"], "555")
+			assert.Empty(t, api.Calls("editMessageText"))
+		})
+	}
+}
+
+func TestTelegramBot_ButtonsRequireOrdersWrite(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc, api := newTelegramBot(t, db)
+	// редактор видит заказы, но менять их не может
+	expectStaff(db, staffRow(5, 4, 555, models.PermOrdersRead, models.PermTripsRead))
+
+	require.NoError(t, svc.HandleCallback(context.Background(), callback("confirm_7")))
+	db.Verify(t)
+
+	answer := api.Calls("answerCallbackQuery")
+	require.Len(t, answer, 1)
+	assert.Equal(t, "Недостаточно прав: нужно право orders.write", answer[0].Payload["text"])
+	assert.Empty(t, api.Calls("editMessageText"))
+}
+
+func TestTelegramBot_ManagerConfirms(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc, api := newTelegramBot(t, db)
+
+	expectStaff(db, staffRow(4, models.RoleManager, 555, models.PermOrdersRead, models.PermOrdersWrite))
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{models.OrderStatusRejected}), nil
+	})
+	expectOrder(db, orderRow(7, models.OrderStatusRejected, 1000))
+
+	require.NoError(t, svc.HandleCallback(context.Background(), callback("confirm_7")))
+	db.Verify(t)
+	// до смены статуса дошло: ответ про сам заказ, а не про доступ
+	assert.Equal(t, "Статус заказа уже изменён", api.Calls("answerCallbackQuery")[0].Payload["text"])
+}
@@ -0,0 +1,314 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/repository"
+)
+
+var (
+	ErrProfileNotFound       = errors.New("traveller profile not found")
+	ErrEncryptionUnavailable = errors.New("encryption key is not configured")
+)
+
+const auditEntityTravellerProfile = "traveller_profile"
+
+type TravellerProfileService struct {
+	repo   *repository.TravellerProfileRepo
+	audit  *repository.AuditRepo
+	cipher *helpers.Cipher
+	log    *zap.SugaredLogger
+}
+
+// NewTravellerProfileService — cipher может быть nil (ключ не задан), тогда работа с профилями недоступна
+func NewTravellerProfileService(repo *repository.TravellerProfileRepo, audit *repository.AuditRepo, cipher *helpers.Cipher, log *zap.SugaredLogger) *TravellerProfileService {
+	return &TravellerProfileService{repo: repo, audit: audit, cipher: cipher, log: log}
+}
+
+func (s *TravellerProfileService) List(ctx context.Context, userID int) ([]models.TravellerProfile, error) {
+	if s.cipher == nil {
+		return nil, ErrEncryptionUnavailable
+	}
+	records, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]models.TravellerProfile, 0, len(records))
+	for _, rec := range records {
+		p, err := s.decrypt(rec)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *p)
+	}
+	return out, nil
+}
+
+func (s *TravellerProfileService) Get(ctx context.Context, userID, id int) (*models.TravellerProfile, error) {
+	rec, err := s.getOwned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.decrypt(*rec)
+}
+
+func (s *TravellerProfileService) Create(ctx context.Context, userID int, req models.TravellerProfileRequest) (*models.TravellerProfile, error) {
+	if s.cipher == nil {
+		return nil, ErrEncryptionUnavailable
+	}
+	rec, err := s.encrypt(req)
+	if err != nil {
+		return nil, err
+	}
+	rec.UserID = userID
+
+	if err := s.repo.Create(ctx, rec); err != nil {
+		return nil, err
+	}
+
+	s.log.Infow("traveller_profile_created", "user_id", userID, "profile_id", rec.ID)
+	return s.decrypt(*rec)
+}
+
+func (s *TravellerProfileService) Update(ctx context.Context, userID, id int, req models.TravellerProfileRequest) (*models.TravellerProfile, error) {
+	if _, err := s.getOwned(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	rec, err := s.encrypt(req)
+	if err != nil {
+		return nil, err
+	}
+	rec.ID = id
+	rec.UserID = userID
+
+	if err := s.repo.Update(ctx, rec); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrProfileNotFound
+		}
+		return nil, err
+	}
+	return s.Get(ctx, userID, id)
+}
+
+func (s *TravellerProfileService) Delete(ctx context.Context, userID, id int) error {
+	if err := s.repo.Delete(ctx, id, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrProfileNotFound
+		}
+		return err
+	}
+	s.log.Infow("traveller_profile_deleted", "user_id", userID, "profile_id", id)
+	return nil
+}
+
+// AdminGet — расшифрованный профиль для администратора.
+// Доступ без записи в журнал аудита невозможен: если аудит не записался, данные не отдаём.
+func (s *TravellerProfileService) AdminGet(ctx context.Context, adminID, id int, ip, reason string) (*models.TravellerProfile, error) {
+	if s.cipher == nil {
+		return nil, ErrEncryptionUnavailable
+	}
+	rec, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrProfileNotFound
+		}
+		return nil, err
+	}
+
+	entry := &models.AuditEntry{
+		ActorID:  &adminID,
+		Action:   "passport_view",
+		Entity:   auditEntityTravellerProfile,
+		EntityID: &id,
+	}
+	if ip != "" {
+		entry.IP = &ip
+	}
+	if reason = strings.TrimSpace(reason); reason != "" {
+		entry.Details = &reason
+	}
+	if err := s.audit.Create(ctx, entry); err != nil {
+		s.log.Errorw("audit_write_failed", "admin_id", adminID, "profile_id", id, "err", err)
+		return nil, fmt.Errorf("audit: %w", err)
+	}
+
+	s.log.Infow("traveller_profile_admin_view", "admin_id", adminID, "profile_id", id)
+	return s.decrypt(*rec)
+}
+
+// AdminListByUser — профили пользователя без расшифровки (только последние цифры паспорта)
+func (s *TravellerProfileService) AdminListByUser(ctx context.Context, userID int) ([]models.TravellerProfileSummary, error) {
+	records, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]models.TravellerProfileSummary, 0, len(records))
+	for _, rec := range records {
+		out = append(out, models.TravellerProfileSummary{
+			ID:            rec.ID,
+			UserID:        rec.UserID,
+			FullName:      rec.FullName,
+			PassportLast4: rec.PassportLast4,
+		})
+	}
+	return out, nil
+}
+
+// ApplyToTravellers подставляет данные сохранённых профилей в туристов заказа.
+// Профиль можно использовать только если он принадлежит текущему пользователю.
+func (s *TravellerProfileService) ApplyToTravellers(ctx context.Context, userID int, reqs []models.TravellerRequest) ([]models.TravellerRequest, error) {
+	out := make([]models.TravellerRequest, len(reqs))
+	copy(out, reqs)
+
+	for i := range out {
+		if out[i].ProfileID == nil {
+			continue
+		}
+		if userID == 0 {
+			return nil, helpers.ErrInvalidInput(fmt.Sprintf("traveller %d: saved profiles require authorization", i+1))
+		}
+
+		p, err := s.Get(ctx, userID, *out[i].ProfileID)
+		if err != nil {
+			if errors.Is(err, ErrProfileNotFound) {
+				return nil, helpers.ErrInvalidInput(fmt.Sprintf("traveller %d: profile not found", i+1))
+			}
+			return nil, err
+		}
+
+		out[i].FullName = p.FullName
+		if p.BirthDate != nil {
+			out[i].BirthDate = p.BirthDate.Format("2006-01-02")
+		}
+		if p.Gender != nil {
+			out[i].Gender = *p.Gender
+		}
+	}
+	return out, nil
+}
+
+func (s *TravellerProfileService) getOwned(ctx context.Context, userID, id int) (*models.TravellerProfileRecord, error) {
+	if s.cipher == nil {
+		return nil, ErrEncryptionUnavailable
+	}
+	rec, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrProfileNotFound
+		}
+		return nil, err
+	}
+	// чужой профиль — как будто его нет
+	if rec.UserID != userID {
+		return nil, ErrProfileNotFound
+	}
+	return rec, nil
+}
+
+func (s *TravellerProfileService) encrypt(req models.TravellerProfileRequest) (*models.TravellerProfileRecord, error) {
+	name := strings.TrimSpace(req.FullName)
+	if name == "" {
+		return nil, helpers.ErrInvalidInput("full_name is required")
+	}
+	passport := strings.TrimSpace(req.PassportNumber)
+	if passport == "" {
+		return nil, helpers.ErrInvalidInput("passport_number is required")
+	}
+
+	rec := &models.TravellerProfileRecord{FullName: name}
+
+	if req.Gender != "" {
+		if req.Gender != models.GenderMale && req.Gender != models.GenderFemale {
+			return nil, helpers.ErrInvalidInput("invalid gender")
+		}
+		g := req.Gender
+		rec.Gender = &g
+	}
+	if req.BirthDate != "" {
+		d, err := helpers.ParseDateAny(req.BirthDate)
+		if err != nil || d.After(time.Now()) {
+			return nil, helpers.ErrInvalidInput("invalid birth_date")
+		}
+		req.BirthDate = d.Format("2006-01-02")
+	}
+	if req.PassportExpiry != "" {
+		d, err := helpers.ParseDateAny(req.PassportExpiry)
+		if err != nil {
+			return nil, helpers.ErrInvalidInput("invalid passport_expiry")
+		}
+		req.PassportExpiry = d.Format("2006-01-02")
+	}
+	nationality := strings.ToUpper(strings.TrimSpace(req.Nationality))
+
+	var err error
+	if rec.BirthDateEnc, err = s.cipher.Encrypt(req.BirthDate); err != nil {
+		return nil, err
+	}
+	if rec.PassportNumberEnc, err = s.cipher.Encrypt(passport); err != nil {
+		return nil, err
+	}
+	if rec.PassportExpiryEnc, err = s.cipher.Encrypt(req.PassportExpiry); err != nil {
+		return nil, err
+	}
+	if rec.NationalityEnc, err = s.cipher.Encrypt(nationality); err != nil {
+		return nil, err
+	}
+	rec.PassportLast4 = lastN(passport, 4)
+
+	return rec, nil
+}
+
+func (s *TravellerProfileService) decrypt(rec models.TravellerProfileRecord) (*models.TravellerProfile, error) {
+	p := &models.TravellerProfile{
+		ID:        rec.ID,
+		UserID:    rec.UserID,
+		FullName:  rec.FullName,
+		Gender:    rec.Gender,
+		CreatedAt: rec.CreatedAt,
+		UpdatedAt: rec.UpdatedAt,
+	}
+
+	var err error
+	if p.PassportNumber, err = s.cipher.Decrypt(rec.PassportNumberEnc); err != nil {
+		return nil, fmt.Errorf("decrypt passport_number: %w", err)
+	}
+	if p.Nationality, err = s.cipher.Decrypt(rec.NationalityEnc); err != nil {
+		return nil, fmt.Errorf("decrypt nationality: %w", err)
+	}
+	if p.BirthDate, err = s.decryptDate(rec.BirthDateEnc); err != nil {
+		return nil, fmt.Errorf("decrypt birth_date: %w", err)
+	}
+	if p.PassportExpiry, err = s.decryptDate(rec.PassportExpiryEnc); err != nil {
+		return nil, fmt.Errorf("decrypt passport_expiry: %w", err)
+	}
+	return p, nil
+}
+
+func (s *TravellerProfileService) decryptDate(enc string) (*time.Time, error) {
+	raw, err := s.cipher.Decrypt(enc)
+	if err != nil || raw == "" {
+		return nil, err
+	}
+	d, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func lastN(s string, n int) string {
+	r := []rune(strings.ReplaceAll(s, " ", ""))
+	if len(r) <= n {
+		return string(r)
+	}
+	return string(r[len(r)-n:])
+}
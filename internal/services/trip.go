@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"strconv"
 	"strings"
 	"time"
 
@@ -13,6 +12,7 @@ import (
 
 	"github.com/Ramcache/travel-backend/internal/helpers"
 	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/notifications"
 	"github.com/Ramcache/travel-backend/internal/repository"
 )
 
@@ -26,23 +26,49 @@ type TripService struct {
 	orderRepo     *repository.OrderRepo
 	tripHotelRepo repository.HotelRepositoryI
 	routeRepo     repository.TripRouteRepository
-	telegram      *helpers.TelegramClient
-	frontendURL   string
+	profiles      *TravellerProfileService
+	schedules     *PaymentScheduleService
+	notify        *NotificationService
+	trackingURL   string
+	viewsLoc      *time.Location
+	botToken      string
 	log           *zap.SugaredLogger
 }
 
-func NewTripService(repo repository.TripRepositoryI, orderRepo *repository.OrderRepo, tripHotelRepo repository.HotelRepositoryI, routeRepo repository.TripRouteRepository, telegram *helpers.TelegramClient, frontendURL string, log *zap.SugaredLogger) *TripService {
+// telegramInitDataTTL — сколько после открытия мини-приложения принимается его initData
+const telegramInitDataTTL = 24 * time.Hour
+
+func NewTripService(repo repository.TripRepositoryI, orderRepo *repository.OrderRepo, tripHotelRepo repository.HotelRepositoryI, routeRepo repository.TripRouteRepository, profiles *TravellerProfileService, schedules *PaymentScheduleService, notify *NotificationService, trackingURL string, log *zap.SugaredLogger) *TripService {
 	return &TripService{
 		repo:          repo,
 		orderRepo:     orderRepo,
 		tripHotelRepo: tripHotelRepo,
 		routeRepo:     routeRepo,
-		telegram:      telegram,
-		frontendURL:   frontendURL,
+		profiles:      profiles,
+		schedules:     schedules,
+		notify:        notify,
+		trackingURL:   trackingURL,
+		viewsLoc:      time.Local,
 		log:           log,
 	}
 }
 
+// UseTelegramWebApp включает приём chat_id клиента из мини-приложения: initData заявки
+// проверяется подписью по токену бота. Без токена chat_id не сохраняется
+func (s *TripService) UseTelegramWebApp(botToken string) *TripService {
+	s.botToken = botToken
+	return s
+}
+
+// UseViewsLocation задаёт часовой пояс, в котором просмотры делятся по дням;
+// он должен совпадать с часовым поясом сводок, иначе ночные просмотры попадут не в тот день
+func (s *TripService) UseViewsLocation(loc *time.Location) *TripService {
+	if loc != nil {
+		s.viewsLoc = loc
+	}
+	return s
+}
+
 type TripServiceI interface {
 	List(ctx context.Context, f models.TripFilter) ([]models.Trip, error)
 	Get(ctx context.Context, id int) (*models.Trip, error)
@@ -53,8 +79,8 @@ type TripServiceI interface {
 	Popular(ctx context.Context, limit int) ([]models.Trip, error)
 	IncrementViews(ctx context.Context, id int) error
 	IncrementBuys(ctx context.Context, id int) error
-	Buy(ctx context.Context, id int, req models.BuyRequest) error
-	BuyWithoutTrip(ctx context.Context, req models.BuyRequest) error
+	Buy(ctx context.Context, id int, req models.BuyRequest) (*models.BuyResponse, error)
+	BuyWithoutTrip(ctx context.Context, req models.BuyRequest) (*models.BuyResponse, error)
 	CreateHotel(ctx context.Context, hotel *models.Hotel) error
 	CreateRoute(ctx context.Context, tripID int, req models.TripRouteRequest) (*models.TripRoute, error)
 	GetFull(ctx context.Context, id int) (*models.TripFullResponse, error)
@@ -87,6 +113,8 @@ func (s *TripService) Create(ctx context.Context, req models.CreateTripRequest)
 		TripType:        req.TripType,
 		Season:          req.Season,
 		Price:           req.Price,
+		ChildPrice:      req.ChildPrice,
+		InfantPrice:     req.InfantPrice,
 		DiscountPercent: req.DiscountPercent,
 		Currency:        req.Currency,
 		Main:            req.Main,
@@ -182,6 +210,12 @@ func (s *TripService) Update(ctx context.Context, id int, req models.UpdateTripR
 	if req.Price != nil {
 		trip.Price = *req.Price
 	}
+	if req.ChildPrice != nil {
+		trip.ChildPrice = req.ChildPrice
+	}
+	if req.InfantPrice != nil {
+		trip.InfantPrice = req.InfantPrice
+	}
 	if req.DiscountPercent != nil {
 		trip.DiscountPercent = *req.DiscountPercent
 	}
@@ -260,20 +294,20 @@ func (s *TripService) Popular(ctx context.Context, limit int) ([]models.Trip, er
 }
 
 func (s *TripService) IncrementViews(ctx context.Context, id int) error {
-	return s.repo.IncrementViews(ctx, id)
+	return s.repo.IncrementViews(ctx, id, time.Now().In(s.viewsLoc))
 }
 
 func (s *TripService) IncrementBuys(ctx context.Context, id int) error {
 	return s.repo.IncrementBuys(ctx, id)
 }
 
-func (s *TripService) Buy(ctx context.Context, id int, req models.BuyRequest) error {
+func (s *TripService) Buy(ctx context.Context, id int, req models.BuyRequest) (*models.BuyResponse, error) {
 	trip, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
-			return ErrTripNotFound
+			return nil, ErrTripNotFound
 		}
-		return err
+		return nil, err
 	}
 
 	var tripID models.NullInt32
@@ -283,45 +317,39 @@ func (s *TripService) Buy(ctx context.Context, id int, req models.BuyRequest) er
 		tripID = models.NullInt32{NullInt32: sql.NullInt32{Valid: false}}
 	}
 
+	num, err := parsePhone(req.UserPhone)
+	if err != nil {
+		return nil, err
+	}
+
+	travellers, err := s.buildTravellers(ctx, req.Travellers, trip)
+	if err != nil {
+		return nil, err
+	}
+	total := CalcOrderTotal(trip, travellers)
+
 	order := models.Order{
-		TripID:    tripID,
-		UserName:  req.UserName,
-		UserPhone: req.UserPhone,
-		Status:    "pending",
+		TripID:     tripID,
+		UserName:   req.UserName,
+		UserPhone:  num.E164,
+		TotalPrice: &total,
+		Travellers: travellers,
+		Status:     models.OrderStatusNew,
+
+		UserPhoneDisplay: num.Display,
+
+		TelegramChatID: s.customerChat(req.TelegramInitData),
+		UserID:         currentUserID(ctx),
 	}
 
-	if err := s.orderRepo.Create(ctx, &order); err != nil {
-		return err
+	if err := s.createOrder(ctx, &order, trip); err != nil {
+		return nil, err
 	}
 
-	price := formatPrice(trip.FinalPrice)
-
-	msg := fmt.Sprintf(
-		"🛒 <b>Новый заказ!</b>\n\n"+
-			"📅 <b>Дата:</b> %s\n"+
-			"👤 <b>Имя:</b> %s\n"+
-			"📞 <b>Телефон:</b> <a href=\"tel:%s\">%s</a>\n\n"+
-			"🌍 <b>Тур:</b> %s\n"+
-			"💰 <b>Цена:</b> %s руб.",
-		time.Now().Format("02.01.2006 15:04"),
-		order.UserName,
-		order.UserPhone, order.UserPhone,
-		trip.Title,
-		price,
-	)
-
-	//if s.telegram != nil {
-	//	if err := s.telegram.SendMessage(msg); err != nil {
-	//		s.log.Errorw("Ошибка отправки заказа в Telegram", "order_id", order.ID, "err", err)
-	//		return err
-	//	}
-	//}
-	if s.telegram != nil {
-		link := fmt.Sprintf("%s/api/v1/trips/%d", strings.TrimRight(s.frontendURL, "/"), trip.ID)
-
-		if err := s.telegram.SendMessageWithButton(msg, "Открыть тур", link); err != nil {
-			s.log.Errorw("Ошибка отправки заказа в Telegram", "order_id", order.ID, "err", err)
-			return err
+	// график задатка/остатка — не критичен для приёма заявки, его можно пересоздать из админки
+	if s.schedules != nil {
+		if err := s.schedules.GenerateForOrder(ctx, &order, trip); err != nil {
+			s.log.Errorw("payment_schedule_generate_failed", "order_id", order.ID, "err", err)
 		}
 	}
 
@@ -331,81 +359,78 @@ func (s *TripService) Buy(ctx context.Context, id int, req models.BuyRequest) er
 		}
 	}()
 
-	return nil
+	return s.buyResponse(&order), nil
 }
 
 // BuyWithoutTrip — заявка без привязки к туру
-func (s *TripService) BuyWithoutTrip(ctx context.Context, req models.BuyRequest) error {
-	order := models.Order{
-		Name:      &req.Name,
-		Date:      &req.Date,
-		Price:     &req.Price,
-		UserName:  req.UserName,
-		UserPhone: req.UserPhone,
-		Status:    "pending",
+func (s *TripService) BuyWithoutTrip(ctx context.Context, req models.BuyRequest) (*models.BuyResponse, error) {
+	num, err := parsePhone(req.UserPhone)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := s.orderRepo.Create(ctx, &order); err != nil {
-		return err
+	travellers, err := s.buildTravellers(ctx, req.Travellers, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	msg := fmt.Sprintf(
-		"🛒 <b>Новый заказ!</b>\n\n"+
-			"🏖️ <b>Тур:</b> %s\n"+
-			"📅 <b>Дата поездки:</b> %s\n"+
-			"💰 <b>Цена:</b> %s\n\n"+
-			"👤 <b>Имя:</b> %s\n"+
-			"📞 <b>Телефон:</b> <a href=\"tel:%s\">%s</a>\n"+
-			"🕒 <b>Создан:</b> %s",
-		helpers.IfEmpty(order.Name, "—"),
-		helpers.IfEmpty(order.Date, "—"),
-		helpers.IfEmpty(order.Price, "—"),
-		order.UserName,
-		order.UserPhone, order.UserPhone,
-		time.Now().Format("02.01.2006 15:04"),
-	)
-
-	//if s.telegram != nil {
-	//	if err := s.telegram.SendMessage(msg); err != nil {
-	//		s.log.Errorw("Ошибка отправки заказа в Telegram", "order_id", order.ID, "err", err)
-	//		return err
-	//	}
-	//}
-
-	if s.telegram != nil {
-		link := fmt.Sprintf("https://web95.tech/admin.html") // не забудь
-		if err := s.telegram.SendMessageWithButton(msg, "Открыть заказы", link); err != nil {
-			s.log.Errorw("Ошибка отправки заказа в Telegram", "order_id", order.ID, "err", err)
-			return err
-		}
+	order := models.Order{
+		Name:       &req.Name,
+		Date:       &req.Date,
+		Price:      &req.Price,
+		UserName:   req.UserName,
+		UserPhone:  num.E164,
+		Travellers: travellers,
+		Status:     models.OrderStatusNew,
+
+		UserPhoneDisplay: num.Display,
+
+		TelegramChatID: s.customerChat(req.TelegramInitData),
+		UserID:         currentUserID(ctx),
 	}
 
-	return nil
+	if err := s.createOrder(ctx, &order, nil); err != nil {
+		return nil, err
+	}
+
+	return s.buyResponse(&order), nil
 }
 
-func formatPrice(price float64) string {
-	s := strconv.FormatInt(int64(price), 10)
-	n := len(s)
-	if n <= 3 {
-		return s
+// customerChat — chat_id клиента из подписанной initData мини-приложения. Непроверенный
+// chat_id не сохраняется: в этот чат бот отправляет документы с персональными данными
+func (s *TripService) customerChat(initData string) *int64 {
+	if initData == "" {
+		return nil
 	}
+	id, err := helpers.TelegramWebAppUserID(initData, s.botToken, telegramInitDataTTL, time.Now())
+	if err != nil {
+		s.log.Warnw("telegram_init_data_rejected", "err", err)
+		return nil
+	}
+	return &id
+}
 
-	var out strings.Builder
-	mod := n % 3
-	if mod > 0 {
-		out.WriteString(s[:mod])
-		if n > mod {
-			out.WriteString(" ")
+// createOrder сохраняет заказ и уведомления о нём одной транзакцией:
+// доставка идёт из outbox, и сбой канала не ломает приём заявки
+func (s *TripService) createOrder(ctx context.Context, order *models.Order, trip *models.Trip) error {
+	return s.orderRepo.Tx(ctx, func(tx repository.DB) error {
+		if err := s.orderRepo.WithTx(tx).Create(ctx, order); err != nil {
+			return err
 		}
-	}
+		return s.notify.Enqueue(ctx, tx, notifications.OrderCreated{Order: order, Trip: trip, At: time.Now()})
+	})
+}
 
-	for i := mod; i < n; i += 3 {
-		out.WriteString(s[i : i+3])
-		if i+3 < n {
-			out.WriteString(" ")
+// buildTravellers — подставляет сохранённые профили текущего пользователя и валидирует туристов
+func (s *TripService) buildTravellers(ctx context.Context, reqs []models.TravellerRequest, trip *models.Trip) ([]models.OrderTraveller, error) {
+	if s.profiles != nil {
+		applied, err := s.profiles.ApplyToTravellers(ctx, helpers.GetUserID(ctx), reqs)
+		if err != nil {
+			return nil, err
 		}
+		reqs = applied
 	}
-	return out.String()
+	return BuildTravellers(reqs, trip)
 }
 
 func (s *TripService) CreateHotel(ctx context.Context, hotel *models.Hotel) error {
@@ -456,3 +481,25 @@ func (s *TripService) GetFull(ctx context.Context, id int) (*models.TripFullResp
 func (s *TripService) ClearRoutesByTrip(ctx context.Context, tripID int) (int64, error) {
 	return s.routeRepo.ClearByTrip(ctx, tripID)
 }
+
+// currentUserID — авторизованный покупатель (OptionalJWTAuth) или nil для анонимной заявки
+func currentUserID(ctx context.Context) *int {
+	if id := helpers.GetUserID(ctx); id > 0 {
+		return &id
+	}
+	return nil
+}
+
+func (s *TripService) trackingLink(token string) string {
+	return strings.TrimRight(s.trackingURL, "/") + "/" + token
+}
+
+// buyResponse — номер заказа и ссылка отслеживания для клиента
+func (s *TripService) buyResponse(order *models.Order) *models.BuyResponse {
+	return &models.BuyResponse{
+		Status:        "success",
+		OrderID:       order.ID,
+		TrackingToken: order.TrackingToken,
+		TrackingURL:   s.trackingLink(order.TrackingToken),
+	}
+}
@@ -2,11 +2,21 @@ package services_test
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Ramcache/travel-backend/internal/models"
 	"github.com/Ramcache/travel-backend/internal/repository"
 	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/Ramcache/travel-backend/internal/testutil"
+	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.uber.org/zap/zaptest"
@@ -54,8 +64,8 @@ func (m *MockTripRepo) Popular(ctx context.Context, limit int) ([]models.Trip, e
 	args := m.Called(ctx, limit)
 	return args.Get(0).([]models.Trip), args.Error(1)
 }
-func (m *MockTripRepo) IncrementViews(ctx context.Context, id int) error {
-	return m.Called(ctx, id).Error(0)
+func (m *MockTripRepo) IncrementViews(ctx context.Context, id int, day time.Time) error {
+	return m.Called(ctx, id, day).Error(0)
 }
 func (m *MockTripRepo) IncrementBuys(ctx context.Context, id int) error {
 	return m.Called(ctx, id).Error(0)
@@ -69,7 +79,9 @@ func TestTripService_Create_InvalidInput(t *testing.T) {
 		nil,
 		nil,
 		nil,
-		"test-frontend",
+		nil,
+		nil,
+		"",
 		zaptest.NewLogger(t).Sugar(),
 	)
 
@@ -89,7 +101,9 @@ func TestTripService_Create_InvalidDate(t *testing.T) {
 		nil,
 		nil,
 		nil,
-		"test-frontend",
+		nil,
+		nil,
+		"",
 		zaptest.NewLogger(t).Sugar(),
 	)
 	req := models.CreateTripRequest{
@@ -109,7 +123,9 @@ func TestTripService_Create_Success(t *testing.T) {
 		nil,
 		nil,
 		nil,
-		"test-frontend",
+		nil,
+		nil,
+		"",
 		zaptest.NewLogger(t).Sugar(),
 	)
 
@@ -134,7 +150,9 @@ func TestTripService_Get_NotFound(t *testing.T) {
 		nil,
 		nil,
 		nil,
-		"test-frontend",
+		nil,
+		nil,
+		"",
 		zaptest.NewLogger(t).Sugar(),
 	)
 
@@ -153,7 +171,9 @@ func TestTripService_Update_InvalidDate(t *testing.T) {
 		nil,
 		nil,
 		nil,
-		"test-frontend",
+		nil,
+		nil,
+		"",
 		zaptest.NewLogger(t).Sugar(),
 	)
 
@@ -174,7 +194,9 @@ func TestTripService_Delete_Success(t *testing.T) {
 		nil,
 		nil,
 		nil,
-		"test-frontend",
+		nil,
+		nil,
+		"",
 		zaptest.NewLogger(t).Sugar(),
 	)
 
@@ -191,15 +213,90 @@ func TestTripService_IncrementViews_Buys(t *testing.T) {
 		nil,
 		nil,
 		nil,
-		"test-frontend",
+		nil,
+		nil,
+		"",
 		zaptest.NewLogger(t).Sugar(),
 	)
 
-	mockRepo.On("IncrementViews", mock.Anything, 1).Return(nil)
+	mockRepo.On("IncrementViews", mock.Anything, 1, mock.Anything).Return(nil)
 	mockRepo.On("IncrementBuys", mock.Anything, 1).Return(nil)
 
 	assert.NoError(t, svc.IncrementViews(context.Background(), 1))
 	assert.NoError(t, svc.IncrementBuys(context.Background(), 1))
 }
 
+func TestTripService_IncrementViews_DayInDigestLocation(t *testing.T) {
+	loc := time.FixedZone("UTC+14", 14*60*60)
+	mockRepo := new(MockTripRepo)
+	svc := services.NewTripService(mockRepo, nil, nil, nil, nil, nil, nil, "", zaptest.NewLogger(t).Sugar()).
+		UseViewsLocation(loc)
+
+	// день просмотра — календарный день в часовом поясе сводок, даже если у сервера он другой
+	mockRepo.On("IncrementViews", mock.Anything, 1, mock.MatchedBy(func(day time.Time) bool {
+		now := time.Now().In(loc)
+		return day.Location() == loc && day.Year() == now.Year() && day.YearDay() == now.YearDay()
+	})).Return(nil)
+
+	assert.NoError(t, svc.IncrementViews(context.Background(), 1))
+	mockRepo.AssertExpectations(t)
+}
+
+// telegramInitData — initData мини-приложения, подписанная токеном бота, как это делает Telegram
+func telegramInitData(botToken string, userID string) string {
+	values := url.Values{
+		"auth_date": {strconv.FormatInt(time.Now().Unix(), 10)},
+		"user":      {`{"id":` + userID + `}`},
+	}
+	pairs := []string{}
+	for key := range values {
+		pairs = append(pairs, key+"="+values.Get(key))
+	}
+	sort.Strings(pairs)
+	secret := hmac.New(sha256.New, []byte("WebAppData"))
+	secret.Write([]byte(botToken))
+	mac := hmac.New(sha256.New, secret.Sum(nil))
+	mac.Write([]byte(strings.Join(pairs, "\n")))
+	values.Set("hash", hex.EncodeToString(mac.Sum(nil)))
+	return values.Encode()
+}
+
+func TestTripService_BuyKeepsOnlyVerifiedTelegramChat(t *testing.T) {
+	chat := int64(123456789)
+	cases := map[string]struct {
+		initData string
+		want     *int64
+	}{
+		"подписано ботом":       {telegramInitData("bot-token", "123456789"), &chat},
+		"подписано чужим ботом": {telegramInitData("other-token", "123456789"), nil},
+		"без initData":          {"", nil},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			db := testutil.NewMockDB(t)
+			svc := services.NewTripService(new(MockTripRepo), repository.NewOrderRepo(db), nil, nil, nil, nil, nil, "",
+				zaptest.NewLogger(t).Sugar()).UseTelegramWebApp("bot-token")
+
+			db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) { // клиент
+				return testutil.NewSliceRow([]any{5}), nil
+			})
+			db.ExpectQueryRow(func(_ context.Context, _ string, args []any) (pgx.Row, error) {
+				assert.Equal(t, c.want, args[7])
+				return testutil.NewSliceRow([]any{7, time.Now()}), nil
+			})
+			db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) { // история статусов
+				return testutil.NewSliceRow([]any{1, time.Now()}), nil
+			})
+
+			_, err := svc.BuyWithoutTrip(context.Background(), models.BuyRequest{
+				UserName:         "Иван",
+				UserPhone:        "+79991234567",
+				TelegramInitData: c.initData,
+			})
+			assert.NoError(t, err)
+			db.Verify(t)
+		})
+	}
+}
+
 func ptr(s string) *string { return &s }
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/notifications"
+	"github.com/Ramcache/travel-backend/internal/repository"
+)
+
+var (
+	// ErrInvalidCode — код неверный, истёк или попытки ввода исчерпаны
+	ErrInvalidCode      = errors.New("invalid verification code")
+	ErrAlreadyVerified  = errors.New("already verified")
+	ErrEmailNotVerified = errors.New("email is not verified")
+)
+
+const (
+	verificationCodeLength = 6
+	// не чаще одного кода в минуту на адрес — защита от засыпания письмами и SMS
+	verificationCooldown = time.Minute
+)
+
+// VerificationConfig — коды подтверждения
+type VerificationConfig struct {
+	// сколько действует код
+	CodeTTL time.Duration
+	// сколько раз можно ввести код, прежде чем он сгорит
+	MaxAttempts int
+}
+
+// VerificationService подтверждает email и телефон пользователя кодом из письма или SMS.
+// Коды уходят событием auth.verification. С подтверждённым телефоном к аккаунту
+// привязываются анонимные заказы, оформленные на этот номер.
+type VerificationService struct {
+	users         repository.UserRepoI
+	repo          *repository.VerificationRepo
+	orders        OrderClaimer
+	notifications *NotificationService
+	cfg           VerificationConfig
+	log           *zap.SugaredLogger
+}
+
+func NewVerificationService(
+	users repository.UserRepoI,
+	repo *repository.VerificationRepo,
+	orders OrderClaimer,
+	notifications *NotificationService,
+	cfg VerificationConfig,
+	log *zap.SugaredLogger,
+) *VerificationService {
+	if cfg.CodeTTL <= 0 {
+		cfg.CodeTTL = 15 * time.Minute
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	return &VerificationService{users: users, repo: repo, orders: orders, notifications: notifications, cfg: cfg, log: log}
+}
+
+// SendEmailCode отправляет код на email пользователя
+func (s *VerificationService) SendEmailCode(ctx context.Context, user *models.User) error {
+	if user.EmailVerifiedAt != nil {
+		return ErrAlreadyVerified
+	}
+	return s.send(ctx, user, models.VerifyEmail, user.Email)
+}
+
+// ResendEmail — повторная отправка кода по адресу. Незнакомый или уже подтверждённый
+// адрес — не ошибка: по ответу нельзя узнать, зарегистрирован ли он
+func (s *VerificationService) ResendEmail(ctx context.Context, email string) error {
+	user, err := s.users.GetByEmail(ctx, email)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.SendEmailCode(ctx, user); err != nil && !errors.Is(err, ErrAlreadyVerified) {
+		return err
+	}
+	return nil
+}
+
+// VerifyEmail подтверждает email кодом из письма
+func (s *VerificationService) VerifyEmail(ctx context.Context, email, code string) error {
+	user, err := s.users.GetByEmail(ctx, email)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrInvalidCode
+	}
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+	if err := s.check(ctx, user.ID, models.VerifyEmail, code); err != nil {
+		return err
+	}
+	s.log.Infow("email_verified", "user_id", user.ID)
+	return nil
+}
+
+// SendPhoneCode отправляет код по SMS на телефон из профиля
+func (s *VerificationService) SendPhoneCode(ctx context.Context, userID int) error {
+	user, err := s.user(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.Phone == nil {
+		return helpers.ErrInvalidInput("phone is not set in profile")
+	}
+	if user.PhoneVerifiedAt != nil {
+		return ErrAlreadyVerified
+	}
+	return s.send(ctx, user, models.VerifyPhone, *user.Phone)
+}
+
+// VerifyPhone подтверждает телефон кодом из SMS и привязывает к пользователю
+// анонимные заказы с этим номером; возвращает, сколько заказов привязано
+func (s *VerificationService) VerifyPhone(ctx context.Context, userID int, code string) (int, error) {
+	user, err := s.user(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if user.Phone == nil {
+		return 0, ErrInvalidCode
+	}
+	if user.PhoneVerifiedAt != nil {
+		return 0, ErrAlreadyVerified
+	}
+	if err := s.check(ctx, userID, models.VerifyPhone, code); err != nil {
+		return 0, err
+	}
+
+	claimed := 0
+	if s.orders != nil {
+		claimed, err = s.orders.ClaimByPhone(ctx, userID, *user.Phone)
+		if err != nil {
+			// телефон подтверждён, заказы привяжутся при следующем входе
+			s.log.Errorw("orders_claim_failed", "user_id", userID, "err", err)
+			claimed = 0
+		}
+	}
+	s.log.Infow("phone_verified", "user_id", userID, "orders_claimed", claimed)
+	return claimed, nil
+}
+
+func (s *VerificationService) user(ctx context.Context, id int) (*models.User, error) {
+	u, err := s.users.GetByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	return u, err
+}
+
+// send создаёт код и кладёт сообщение в outbox в одной транзакции.
+// Повторный запрос раньше verificationCooldown молча пропускается
+func (s *VerificationService) send(ctx context.Context, user *models.User, kind, target string) error {
+	code, err := helpers.RandomDigits(verificationCodeLength)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	expiresAt := now.Add(s.cfg.CodeTTL)
+
+	sent := false
+	err = s.repo.Tx(ctx, func(tx repository.DB) error {
+		ok, err := s.repo.WithTx(tx).Create(ctx, user.ID, kind, target, hashToken(code), expiresAt, verificationCooldown)
+		if err != nil || !ok {
+			return err
+		}
+		sent = true
+		return s.notifications.Enqueue(ctx, tx, notifications.VerificationRequested{
+			User: user, Kind: kind, Code: code, ExpiresAt: expiresAt, At: now,
+		})
+	})
+	if err != nil {
+		s.log.Errorw("verification_code_failed", "user_id", user.ID, "kind", kind, "err", err)
+		return err
+	}
+	if !sent {
+		s.log.Warnw("Повторный запрос кода подтверждения слишком рано", "user_id", user.ID, "kind", kind)
+		return nil
+	}
+	s.log.Infow("verification_code_sent", "user_id", user.ID, "kind", kind)
+	return nil
+}
+
+// check засчитывает попытку и при верном коде отмечает адрес подтверждённым
+func (s *VerificationService) check(ctx context.Context, userID int, kind, code string) error {
+	c, err := s.repo.Attempt(ctx, userID, kind, s.cfg.MaxAttempts)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrInvalidCode
+	}
+	if err != nil {
+		return err
+	}
+	if hashToken(code) != c.CodeHash {
+		s.log.Warnw("Неверный код подтверждения", "user_id", userID, "kind", kind, "attempt", c.Attempts)
+		return ErrInvalidCode
+	}
+
+	err = s.repo.Confirm(ctx, userID, kind, c)
+	if errors.Is(err, repository.ErrNotFound) {
+		// адрес сменили после отправки кода
+		return ErrInvalidCode
+	}
+	return err
+}
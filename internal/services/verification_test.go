@@ -0,0 +1,241 @@
+package services_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/Ramcache/travel-backend/internal/helpers"
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/notifications"
+	"github.com/Ramcache/travel-backend/internal/repository"
+	"github.com/Ramcache/travel-backend/internal/services"
+	"github.com/Ramcache/travel-backend/internal/testutil"
+)
+
+func newVerificationService(t *testing.T, db *testutil.MockDB, users *MockUserRepo, orders services.OrderClaimer) *services.VerificationService {
+	t.Helper()
+	email := notifications.NewRecorder(notifications.ChannelEmail)
+	sms := notifications.NewRecorder(notifications.ChannelSMS)
+	tg := notifications.NewRecorder(notifications.ChannelTelegram)
+	// коды менеджерам не уходят, даже если такой маршрут настроен
+	notify := newNotificationService(t, db, "auth.verification=email:customer,sms:customer,telegram", email, sms, tg)
+	return services.NewVerificationService(users, repository.NewVerificationRepo(db), orders, notify,
+		services.VerificationConfig{CodeTTL: 15 * time.Minute, MaxAttempts: 5}, zaptest.NewLogger(t).Sugar())
+}
+
+// expectCode — действующий код: ID, адрес, хэш и номер попытки
+func expectCode(db *testutil.MockDB, target, code string, attempt int) {
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{int64(3), target, sha256Hex(code), attempt}), nil
+	})
+}
+
+var codeRe = regexp.MustCompile(`\b\d{6}\b`)
+
+func TestVerificationService_SendEmailCode(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newVerificationService(t, db, new(MockUserRepo), nil)
+	user := &models.User{ID: 7, Email: "ivan@example.com", FullName: "Иван"}
+
+	db.ExpectQueryRow(func(_ context.Context, sql string, args []any) (pgx.Row, error) {
+		assert.Contains(t, sql, "FROM verification_codes")
+		assert.Equal(t, []any{7, models.VerifyEmail, 60.0}, args)
+		return testutil.NewSliceRow([]any{false}), nil
+	})
+	db.ExpectExec(func(context.Context, string, []any) (pgconn.CommandTag, error) {
+		return pgconn.NewCommandTag("UPDATE 0"), nil
+	})
+	var storedHash string
+	db.ExpectExec(func(_ context.Context, sql string, args []any) (pgconn.CommandTag, error) {
+		assert.Contains(t, sql, "INSERT INTO verification_codes")
+		assert.Equal(t, "ivan@example.com", args[2])
+		storedHash = args[3].(string)
+		assert.WithinDuration(t, time.Now().Add(15*time.Minute), args[4].(time.Time), time.Minute)
+		return pgconn.NewCommandTag("INSERT 0 1"), nil
+	})
+	var subject string
+	db.ExpectQueryRow(func(_ context.Context, _ string, args []any) (pgx.Row, error) {
+		assert.Equal(t, notifications.ChannelEmail, args[1])
+		assert.Equal(t, []string{"ivan@example.com"}, args[3])
+		subject = args[4].(string)
+		return testutil.NewSliceRow([]any{1, models.NotificationPending, time.Now(), time.Now(), time.Now()}), nil
+	})
+
+	require.NoError(t, svc.SendEmailCode(context.Background(), user))
+	db.Verify(t)
+
+	// в письме — код, в базе — только его хэш
+	code := codeRe.FindString(subject)
+	require.NotEmpty(t, code, subject)
+	assert.Equal(t, sha256Hex(code), storedHash)
+}
+
+func TestVerificationService_ResendEmail_Quiet(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	users := new(MockUserRepo)
+	svc := newVerificationService(t, db, users, nil)
+
+	verified := time.Now()
+	users.On("GetByEmail", mock.Anything, "nobody@example.com").Return(nil, repository.ErrNotFound)
+	users.On("GetByEmail", mock.Anything, "done@example.com").
+		Return(&models.User{ID: 8, Email: "done@example.com", EmailVerifiedAt: &verified}, nil)
+	users.On("GetByEmail", mock.Anything, "ivan@example.com").Return(&models.User{ID: 7, Email: "ivan@example.com"}, nil)
+	// предыдущий код отправлен меньше минуты назад: нового нет, письма нет
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{true}), nil
+	})
+
+	for _, email := range []string{"nobody@example.com", "done@example.com", "ivan@example.com"} {
+		require.NoError(t, svc.ResendEmail(context.Background(), email), email)
+	}
+	db.Verify(t)
+}
+
+func TestVerificationService_VerifyEmail(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	users := new(MockUserRepo)
+	svc := newVerificationService(t, db, users, nil)
+
+	users.On("GetByEmail", mock.Anything, "ivan@example.com").Return(&models.User{ID: 7, Email: "ivan@example.com"}, nil)
+	db.ExpectQueryRow(func(_ context.Context, sql string, args []any) (pgx.Row, error) {
+		assert.Contains(t, sql, "attempts = attempts + 1")
+		assert.Equal(t, []any{7, models.VerifyEmail, 5}, args)
+		return testutil.NewSliceRow([]any{int64(3), "ivan@example.com", sha256Hex("123456"), 1}), nil
+	})
+	db.ExpectExec(func(_ context.Context, _ string, args []any) (pgconn.CommandTag, error) {
+		assert.Equal(t, []any{int64(3)}, args)
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+	db.ExpectExec(func(_ context.Context, sql string, args []any) (pgconn.CommandTag, error) {
+		assert.Contains(t, sql, "email_verified_at = now()")
+		assert.Equal(t, []any{7, "ivan@example.com"}, args)
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+
+	require.NoError(t, svc.VerifyEmail(context.Background(), "ivan@example.com", "123456"))
+	db.Verify(t)
+}
+
+func TestVerificationService_VerifyEmail_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect func(db *testutil.MockDB)
+	}{
+		{"wrong code", func(db *testutil.MockDB) { expectCode(db, "ivan@example.com", "654321", 2) }},
+		{"no active code", func(db *testutil.MockDB) {
+			// истёк, использован или попытки исчерпаны
+			db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+				return nil, pgx.ErrNoRows
+			})
+		}},
+		{"email changed", func(db *testutil.MockDB) {
+			expectCode(db, "old@example.com", "123456", 1)
+			db.ExpectExec(func(context.Context, string, []any) (pgconn.CommandTag, error) {
+				return pgconn.NewCommandTag("UPDATE 1"), nil
+			})
+			db.ExpectExec(func(context.Context, string, []any) (pgconn.CommandTag, error) {
+				return pgconn.NewCommandTag("UPDATE 0"), nil
+			})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testutil.NewMockDB(t)
+			users := new(MockUserRepo)
+			svc := newVerificationService(t, db, users, nil)
+			users.On("GetByEmail", mock.Anything, "ivan@example.com").Return(&models.User{ID: 7, Email: "ivan@example.com"}, nil)
+			tt.expect(db)
+
+			err := svc.VerifyEmail(context.Background(), "ivan@example.com", "123456")
+			assert.ErrorIs(t, err, services.ErrInvalidCode)
+			db.Verify(t)
+		})
+	}
+}
+
+func TestVerificationService_VerifyPhone_ClaimsOrders(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	users := new(MockUserRepo)
+	orders := new(MockOrderClaimer)
+	svc := newVerificationService(t, db, users, orders)
+
+	users.On("GetByID", mock.Anything, 7).Return(&models.User{ID: 7, Phone: ptr("+79990000000")}, nil)
+	expectCode(db, "+79990000000", "123456", 1)
+	db.ExpectExec(func(context.Context, string, []any) (pgconn.CommandTag, error) {
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+	db.ExpectExec(func(_ context.Context, sql string, args []any) (pgconn.CommandTag, error) {
+		assert.Contains(t, sql, "phone_verified_at = now()")
+		assert.Equal(t, []any{7, "+79990000000"}, args)
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	})
+	orders.On("ClaimByPhone", mock.Anything, 7, "+79990000000").Return(2, nil)
+
+	n, err := svc.VerifyPhone(context.Background(), 7, "123456")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	db.Verify(t)
+	orders.AssertExpectations(t)
+}
+
+func TestVerificationService_SendPhoneCode_Rejects(t *testing.T) {
+	verified := time.Now()
+	db := testutil.NewMockDB(t)
+	users := new(MockUserRepo)
+	svc := newVerificationService(t, db, users, nil)
+
+	users.On("GetByID", mock.Anything, 7).Return(&models.User{ID: 7}, nil)
+	users.On("GetByID", mock.Anything, 8).Return(&models.User{ID: 8, Phone: ptr("+79990000000"), PhoneVerifiedAt: &verified}, nil)
+	users.On("GetByID", mock.Anything, 9).Return(nil, repository.ErrNotFound)
+
+	assert.True(t, helpers.IsInvalidInput(svc.SendPhoneCode(context.Background(), 7)))
+	assert.ErrorIs(t, svc.SendPhoneCode(context.Background(), 8), services.ErrAlreadyVerified)
+	assert.ErrorIs(t, svc.SendPhoneCode(context.Background(), 9), services.ErrNotFound)
+	db.Verify(t)
+}
+
+func TestAuthService_Login_RequiresVerifiedEmail(t *testing.T) {
+	repo := new(MockUserRepo)
+	svc, db := newAuthService(t, repo, nil)
+	svc.UseVerification(nil, true)
+
+	hash, _ := helpers.HashPassword("123456")
+	repo.On("GetByEmail", mock.Anything, "new@mail.com").
+		Return(&models.User{ID: 1, Email: "new@mail.com", Password: hash}, nil)
+
+	resp, err := svc.Login(context.Background(), models.LoginRequest{Email: "new@mail.com", Password: "123456"})
+	assert.Nil(t, resp)
+	assert.ErrorIs(t, err, services.ErrEmailNotVerified)
+	// сессия не открывается
+	db.Verify(t)
+}
+
+func TestNotificationService_HidesVerificationCodes(t *testing.T) {
+	db := testutil.NewMockDB(t)
+	svc := newNotificationService(t, db, notifications.DefaultRoutes)
+	now := time.Now()
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{2, "auth.verification", notifications.ChannelSMS, notifications.AudienceCustomer,
+			[]string{"+79990000000"}, "", "Код подтверждения: 123456", nil, nil, nil, models.NotificationSent, 1, nil, now, &now, now, now}), nil
+	})
+	db.ExpectQueryRow(func(context.Context, string, []any) (pgx.Row, error) {
+		return testutil.NewSliceRow([]any{2, "auth.verification", notifications.ChannelSMS, notifications.AudienceCustomer,
+			[]string{"+79990000000"}, "", "Код подтверждения: 123456", nil, nil, nil, models.NotificationSent, 1, nil, now, &now, now, now}), nil
+	})
+
+	n, err := svc.Get(context.Background(), 2)
+	require.NoError(t, err)
+	assert.NotContains(t, n.Body, "123456")
+	_, err = svc.Resend(context.Background(), 2)
+	assert.ErrorIs(t, err, services.ErrNotificationSecret)
+	db.Verify(t)
+}
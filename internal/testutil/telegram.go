@@ -0,0 +1,59 @@
+package testutil
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// BotCall is a single request received by FakeBotAPI.
+type BotCall struct {
+	Method  string
+	Payload map[string]any
+}
+
+// FakeBotAPI is a local Telegram Bot API server that records every call
+// and answers {"ok":true}. Point notifications.TelegramConfig.BaseURL at URL.
+type FakeBotAPI struct {
+	URL string
+
+	mu    sync.Mutex
+	calls []BotCall
+}
+
+// NewFakeBotAPI starts the server; it is closed when the test finishes.
+func NewFakeBotAPI(t *testing.T) *FakeBotAPI {
+	t.Helper()
+	f := &FakeBotAPI{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// /bot<token>/<method>
+		method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		var payload map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("fake bot api: decode %s: %v", method, err)
+		}
+		f.mu.Lock()
+		f.calls = append(f.calls, BotCall{Method: method, Payload: payload})
+		f.mu.Unlock()
+		_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+	}))
+	t.Cleanup(srv.Close)
+	f.URL = srv.URL
+	return f
+}
+
+// Calls returns the recorded calls of the given method in order.
+func (f *FakeBotAPI) Calls(method string) []BotCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var list []BotCall
+	for _, c := range f.calls {
+		if c.Method == method {
+			list = append(list, c)
+		}
+	}
+	return list
+}
@@ -1,14 +1,26 @@
 package validators
 
 import (
-	"github.com/go-playground/validator/v10"
 	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/Ramcache/travel-backend/internal/phone"
 )
 
 var Validate *validator.Validate
 
+func init() {
+	Init()
+}
+
 func Init() {
 	Validate = validator.New()
+	// phone — номер разбирается в E.164 с учётом страны по умолчанию (PHONE_DEFAULT_COUNTRY)
+	_ = Validate.RegisterValidation("phone", func(fl validator.FieldLevel) bool {
+		_, err := phone.Parse(fl.Field().String(), phone.DefaultCountry())
+		return err == nil
+	})
 }
 
 func TranslateValidationErrors(err error) string {
@@ -19,6 +31,10 @@ func TranslateValidationErrors(err error) string {
 	var msgs []string
 	for _, e := range err.(validator.ValidationErrors) {
 		field := e.Field()
+		if e.Tag() == "phone" {
+			msgs = append(msgs, "Некорректный номер телефона")
+			continue
+		}
 		switch field {
 		case "City":
 			msgs = append(msgs, "Поле 'Город' обязательно")
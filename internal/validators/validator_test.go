@@ -0,0 +1,19 @@
+package validators_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Ramcache/travel-backend/internal/models"
+	"github.com/Ramcache/travel-backend/internal/validators"
+)
+
+func TestPhoneRule(t *testing.T) {
+	err := validators.Validate.Struct(models.FeedbackRequest{UserName: "Иван", UserPhone: "8 (928) 123-45-67"})
+	assert.NoError(t, err)
+
+	err = validators.Validate.Struct(models.FeedbackRequest{UserName: "Иван", UserPhone: "12-34"})
+	assert.Error(t, err)
+	assert.Equal(t, "Некорректный номер телефона", validators.TranslateValidationErrors(err))
+}
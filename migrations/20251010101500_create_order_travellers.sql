@@ -0,0 +1,30 @@
+-- +goose Up
+CREATE TABLE order_travellers (
+                                  id SERIAL PRIMARY KEY,
+                                  order_id INT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+                                  full_name TEXT NOT NULL,
+                                  birth_date DATE,
+                                  gender TEXT,
+                                  traveller_type TEXT NOT NULL DEFAULT 'adult'
+                                      CHECK (traveller_type IN ('adult', 'child', 'infant')),
+                                  room TEXT,
+                                  price NUMERIC(12,2),
+                                  created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_order_travellers_order_id ON order_travellers(order_id);
+
+ALTER TABLE orders ADD COLUMN total_price NUMERIC(12,2);
+
+ALTER TABLE trips
+    ADD COLUMN child_price NUMERIC(12,2),
+    ADD COLUMN infant_price NUMERIC(12,2);
+
+-- +goose Down
+ALTER TABLE trips
+    DROP COLUMN IF EXISTS child_price,
+    DROP COLUMN IF EXISTS infant_price;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS total_price;
+
+DROP TABLE IF EXISTS order_travellers;
@@ -0,0 +1,38 @@
+-- +goose Up
+CREATE TABLE traveller_profiles (
+                                    id SERIAL PRIMARY KEY,
+                                    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                    full_name TEXT NOT NULL,
+                                    gender TEXT,
+    -- чувствительные поля хранятся зашифрованными (AES-GCM, ключ APP_ENCRYPTION_KEY)
+                                    birth_date_enc TEXT NOT NULL DEFAULT '',
+                                    passport_number_enc TEXT NOT NULL DEFAULT '',
+                                    passport_expiry_enc TEXT NOT NULL DEFAULT '',
+                                    nationality_enc TEXT NOT NULL DEFAULT '',
+                                    passport_last4 TEXT NOT NULL DEFAULT '',
+                                    created_at TIMESTAMP NOT NULL DEFAULT now(),
+                                    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_traveller_profiles_user_id ON traveller_profiles(user_id);
+
+ALTER TABLE order_travellers
+    ADD COLUMN profile_id INT REFERENCES traveller_profiles(id) ON DELETE SET NULL;
+
+CREATE TABLE audit_log (
+                           id SERIAL PRIMARY KEY,
+                           actor_id INT REFERENCES users(id) ON DELETE SET NULL,
+                           action TEXT NOT NULL,
+                           entity TEXT NOT NULL,
+                           entity_id INT,
+                           details TEXT,
+                           ip TEXT,
+                           created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_audit_log_entity ON audit_log(entity, entity_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS audit_log;
+ALTER TABLE order_travellers DROP COLUMN IF EXISTS profile_id;
+DROP TABLE IF EXISTS traveller_profiles;
@@ -0,0 +1,40 @@
+-- +goose Up
+-- приводим исторические статусы к единому жизненному циклу
+UPDATE orders SET status = lower(btrim(status)) WHERE status <> lower(btrim(status));
+UPDATE orders SET status = 'new' WHERE status IS NULL OR status IN ('pending', '');
+UPDATE orders SET status = 'confirmed' WHERE status = 'in_progress';
+UPDATE orders SET status = 'completed' WHERE status = 'done';
+UPDATE orders SET status = 'cancelled' WHERE status = 'canceled';
+-- всё, что осталось неизвестным, — в начало цикла, иначе CHECK ниже не создастся
+UPDATE orders SET status = 'new'
+WHERE status NOT IN ('new', 'confirmed', 'paid', 'completed', 'cancelled', 'rejected', 'refunded');
+
+ALTER TABLE orders
+    ALTER COLUMN status SET DEFAULT 'new',
+    ALTER COLUMN status SET NOT NULL,
+    ADD CONSTRAINT orders_status_check
+        CHECK (status IN ('new', 'confirmed', 'paid', 'completed', 'cancelled', 'rejected', 'refunded'));
+
+CREATE TABLE order_status_history (
+                                      id SERIAL PRIMARY KEY,
+                                      order_id INT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+                                      from_status TEXT,
+                                      to_status TEXT NOT NULL,
+                                      actor_id INT REFERENCES users(id) ON DELETE SET NULL,
+                                      actor_name TEXT,
+                                      comment TEXT,
+                                      created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_order_status_history_order_id ON order_status_history(order_id, created_at);
+
+-- стартовая запись для уже существующих заказов
+INSERT INTO order_status_history (order_id, from_status, to_status, actor_name, created_at)
+SELECT id, NULL, status, 'system', COALESCE(created_at, now()) FROM orders;
+
+-- +goose Down
+DROP TABLE IF EXISTS order_status_history;
+
+ALTER TABLE orders
+    DROP CONSTRAINT IF EXISTS orders_status_check,
+    ALTER COLUMN status DROP NOT NULL;
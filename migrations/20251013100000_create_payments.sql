@@ -0,0 +1,35 @@
+-- +goose Up
+CREATE TABLE payments (
+                          id SERIAL PRIMARY KEY,
+                          order_id INT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+                          provider TEXT NOT NULL,
+                          external_id TEXT,
+                          amount NUMERIC(12,2) NOT NULL CHECK (amount > 0),
+                          refunded_amount NUMERIC(12,2) NOT NULL DEFAULT 0,
+                          currency TEXT NOT NULL DEFAULT 'RUB',
+                          status TEXT NOT NULL DEFAULT 'pending'
+                              CHECK (status IN ('pending', 'succeeded', 'canceled', 'refunded')),
+                          confirmation_url TEXT,
+                          created_at TIMESTAMP NOT NULL DEFAULT now(),
+                          updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_payments_order_id ON payments(order_id);
+CREATE UNIQUE INDEX idx_payments_provider_external_id ON payments(provider, external_id)
+    WHERE external_id IS NOT NULL;
+
+-- журнал обработанных вебхуков: повторная доставка того же события игнорируется
+CREATE TABLE payment_events (
+                                id SERIAL PRIMARY KEY,
+                                provider TEXT NOT NULL,
+                                event_id TEXT NOT NULL,
+                                payment_id INT REFERENCES payments(id) ON DELETE CASCADE,
+                                status TEXT NOT NULL,
+                                payload JSONB,
+                                created_at TIMESTAMP NOT NULL DEFAULT now(),
+                                UNIQUE (provider, event_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS payment_events;
+DROP TABLE IF EXISTS payments;
@@ -0,0 +1,35 @@
+-- +goose Up
+-- правила рассрочки тура: задаток и остаток, сроки относительно даты бронирования / старта / дедлайна
+CREATE TABLE trip_payment_rules (
+                                    id SERIAL PRIMARY KEY,
+                                    trip_id INT NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+                                    seq INT NOT NULL,
+                                    title TEXT NOT NULL,
+                                    percent NUMERIC(5,2) CHECK (percent > 0 AND percent <= 100),
+                                    amount NUMERIC(12,2) CHECK (amount > 0),
+                                    due_anchor TEXT NOT NULL
+                                        CHECK (due_anchor IN ('booking', 'start_date', 'booking_deadline')),
+                                    due_offset_days INT NOT NULL DEFAULT 0,
+                                    CHECK (percent IS NULL OR amount IS NULL),
+                                    UNIQUE (trip_id, seq)
+);
+
+CREATE TABLE order_installments (
+                                    id SERIAL PRIMARY KEY,
+                                    order_id INT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+                                    seq INT NOT NULL,
+                                    title TEXT NOT NULL,
+                                    amount NUMERIC(12,2) NOT NULL CHECK (amount > 0),
+                                    paid_amount NUMERIC(12,2) NOT NULL DEFAULT 0,
+                                    due_date DATE NOT NULL,
+                                    paid_at TIMESTAMP,
+                                    reminded_at TIMESTAMP,
+                                    created_at TIMESTAMP NOT NULL DEFAULT now(),
+                                    UNIQUE (order_id, seq)
+);
+
+CREATE INDEX idx_order_installments_due ON order_installments(due_date) WHERE paid_amount < amount;
+
+-- +goose Down
+DROP TABLE IF EXISTS order_installments;
+DROP TABLE IF EXISTS trip_payment_rules;
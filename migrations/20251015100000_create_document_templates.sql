@@ -0,0 +1,15 @@
+-- +goose Up
+-- шаблоны документов, редактируемые из админки (пока только договор)
+CREATE TABLE document_templates (
+                                    kind TEXT PRIMARY KEY,
+                                    body TEXT NOT NULL,
+                                    updated_by INT REFERENCES users(id) ON DELETE SET NULL,
+                                    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+-- чат клиента в Telegram (заявка из мини-приложения) — туда отправляются документы
+ALTER TABLE orders ADD COLUMN telegram_chat_id BIGINT;
+
+-- +goose Down
+ALTER TABLE orders DROP COLUMN IF EXISTS telegram_chat_id;
+DROP TABLE IF EXISTS document_templates;
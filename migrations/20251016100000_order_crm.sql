@@ -0,0 +1,44 @@
+-- +goose Up
+ALTER TABLE orders
+    ADD COLUMN assigned_to INT REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN next_call_at TIMESTAMPTZ,
+    ADD COLUMN next_call_reminded_at TIMESTAMPTZ,
+    ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_orders_assigned_to ON orders(assigned_to);
+CREATE INDEX idx_orders_next_call_at ON orders(next_call_at) WHERE next_call_at IS NOT NULL;
+CREATE INDEX idx_orders_tags ON orders USING GIN (tags);
+
+CREATE TABLE order_notes (
+    id SERIAL PRIMARY KEY,
+    order_id INT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    author_id INT REFERENCES users(id) ON DELETE SET NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_order_notes_order_id ON order_notes(order_id, created_at);
+
+-- история заказа: помимо смены статуса пишем переназначения менеджера
+ALTER TABLE order_status_history
+    ADD COLUMN event TEXT NOT NULL DEFAULT 'status',
+    ADD COLUMN from_assignee INT REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN to_assignee INT REFERENCES users(id) ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE order_status_history
+    DROP COLUMN IF EXISTS to_assignee,
+    DROP COLUMN IF EXISTS from_assignee,
+    DROP COLUMN IF EXISTS event;
+
+DROP TABLE IF EXISTS order_notes;
+
+DROP INDEX IF EXISTS idx_orders_tags;
+DROP INDEX IF EXISTS idx_orders_next_call_at;
+DROP INDEX IF EXISTS idx_orders_assigned_to;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS tags,
+    DROP COLUMN IF EXISTS next_call_reminded_at,
+    DROP COLUMN IF EXISTS next_call_at,
+    DROP COLUMN IF EXISTS assigned_to;
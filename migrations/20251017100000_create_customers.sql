@@ -0,0 +1,62 @@
+-- +goose Up
+-- клиент — один человек по нормализованному телефону (+ и только цифры)
+CREATE TABLE customers (
+    id SERIAL PRIMARY KEY,
+    phone TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL DEFAULT '',
+    -- после слияния дубликат указывает на основную запись
+    merged_into INT REFERENCES customers(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_customers_merged_into ON customers(merged_into);
+CREATE INDEX idx_customers_name_trgm ON customers USING GIN (name gin_trgm_ops);
+
+ALTER TABLE orders ADD COLUMN customer_id INT REFERENCES customers(id) ON DELETE SET NULL;
+ALTER TABLE feedbacks ADD COLUMN customer_id INT REFERENCES customers(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_orders_customer_id ON orders(customer_id);
+CREATE INDEX idx_feedbacks_customer_id ON feedbacks(customer_id);
+
+-- +goose StatementBegin
+CREATE FUNCTION normalize_phone(raw TEXT) RETURNS TEXT AS $$
+DECLARE
+    digits TEXT := regexp_replace(COALESCE(raw, ''), '\D', '', 'g');
+BEGIN
+    IF digits = '' THEN
+        RETURN NULL;
+    END IF;
+    IF length(digits) = 11 AND left(digits, 1) = '8' THEN
+        digits := '7' || substr(digits, 2);
+    ELSIF length(digits) = 10 AND left(digits, 1) = '9' THEN
+        digits := '7' || digits;
+    END IF;
+    RETURN '+' || digits;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+-- +goose StatementEnd
+
+-- существующие заявки: имя берём из самой свежей
+INSERT INTO customers (phone, name, created_at)
+SELECT DISTINCT ON (phone) phone, user_name, first_at
+FROM (
+    SELECT normalize_phone(user_phone) AS phone, user_name, created_at,
+           MIN(created_at) OVER (PARTITION BY normalize_phone(user_phone)) AS first_at
+    FROM (
+        SELECT user_phone, user_name, COALESCE(created_at, now()) AS created_at FROM orders
+        UNION ALL
+        SELECT user_phone, user_name, COALESCE(created_at, now()) FROM feedbacks
+    ) src
+) p
+WHERE phone IS NOT NULL
+ORDER BY phone, created_at DESC;
+
+UPDATE orders o SET customer_id = c.id FROM customers c WHERE c.phone = normalize_phone(o.user_phone);
+UPDATE feedbacks f SET customer_id = c.id FROM customers c WHERE c.phone = normalize_phone(f.user_phone);
+
+DROP FUNCTION normalize_phone(TEXT);
+
+-- +goose Down
+ALTER TABLE feedbacks DROP COLUMN IF EXISTS customer_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS customer_id;
+DROP TABLE IF EXISTS customers;
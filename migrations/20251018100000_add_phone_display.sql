@@ -0,0 +1,15 @@
+-- +goose Up
+-- user_phone хранит номер в E.164, user_phone_display — как показывать людям.
+-- Существующие номера приводятся командой `travel-api phones normalize`
+ALTER TABLE orders ADD COLUMN user_phone_display TEXT NOT NULL DEFAULT '';
+ALTER TABLE feedbacks ADD COLUMN user_phone_display TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX idx_orders_user_phone ON orders(user_phone);
+CREATE INDEX idx_feedbacks_user_phone ON feedbacks(user_phone);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_feedbacks_user_phone;
+DROP INDEX IF EXISTS idx_orders_user_phone;
+
+ALTER TABLE feedbacks DROP COLUMN IF EXISTS user_phone_display;
+ALTER TABLE orders DROP COLUMN IF EXISTS user_phone_display;
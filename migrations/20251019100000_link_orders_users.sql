@@ -0,0 +1,18 @@
+-- +goose Up
+-- заказы авторизованных пользователей; анонимные заказы привязываются при входе
+-- по подтверждённому телефону
+ALTER TABLE orders ADD COLUMN user_id INT REFERENCES users(id) ON DELETE SET NULL;
+CREATE INDEX idx_orders_user_id ON orders(user_id);
+
+-- телефон пользователя в E.164; phone_verified_at сбрасывается при смене номера
+ALTER TABLE users
+    ADD COLUMN phone TEXT,
+    ADD COLUMN phone_verified_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE users
+    DROP COLUMN IF EXISTS phone_verified_at,
+    DROP COLUMN IF EXISTS phone;
+
+DROP INDEX IF EXISTS idx_orders_user_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS user_id;
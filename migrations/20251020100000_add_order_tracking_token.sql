@@ -0,0 +1,15 @@
+-- +goose Up
+-- токен публичной ссылки на заказ (/orders/track/{token}) для клиентов без аккаунта
+ALTER TABLE orders ADD COLUMN tracking_token TEXT;
+
+-- gen_random_uuid() встроена начиная с PostgreSQL 13
+UPDATE orders
+SET tracking_token = replace(gen_random_uuid()::text || gen_random_uuid()::text, '-', '')
+WHERE tracking_token IS NULL;
+
+ALTER TABLE orders ALTER COLUMN tracking_token SET NOT NULL;
+CREATE UNIQUE INDEX idx_orders_tracking_token ON orders(tracking_token);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_orders_tracking_token;
+ALTER TABLE orders DROP COLUMN IF EXISTS tracking_token;
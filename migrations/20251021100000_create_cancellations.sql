@@ -0,0 +1,52 @@
+-- +goose Up
+-- клиент может запросить отмену заказа: заказ ждёт решения менеджера
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+ALTER TABLE orders ADD CONSTRAINT orders_status_check
+    CHECK (status IN ('new', 'confirmed', 'paid', 'cancellation_requested', 'completed', 'cancelled', 'rejected', 'refunded'));
+
+-- штрафы за отмену тура: отмена не позднее чем за days_before дней до начала — удерживается penalty_percent
+CREATE TABLE trip_cancellation_rules (
+    id SERIAL PRIMARY KEY,
+    trip_id INT NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+    days_before INT NOT NULL CHECK (days_before >= 0),
+    penalty_percent NUMERIC(5,2) NOT NULL CHECK (penalty_percent >= 0 AND penalty_percent <= 100),
+    UNIQUE (trip_id, days_before)
+);
+
+CREATE TABLE order_cancellations (
+    id SERIAL PRIMARY KEY,
+    order_id INT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    source TEXT NOT NULL CHECK (source IN ('profile', 'tracking')),
+    reason TEXT,
+    previous_status TEXT NOT NULL,
+    days_before INT,
+    paid_amount NUMERIC(12,2) NOT NULL DEFAULT 0,
+    penalty_percent NUMERIC(5,2) NOT NULL DEFAULT 0,
+    penalty_amount NUMERIC(12,2) NOT NULL DEFAULT 0,
+    refund_amount NUMERIC(12,2) NOT NULL DEFAULT 0,
+    refunded_amount NUMERIC(12,2) NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    decided_by INT REFERENCES users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMP,
+    comment TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_order_cancellations_order_id ON order_cancellations(order_id, created_at);
+-- не больше одной нерассмотренной заявки на заказ
+CREATE UNIQUE INDEX idx_order_cancellations_pending ON order_cancellations(order_id) WHERE status = 'pending';
+
+-- +goose Down
+DROP TABLE IF EXISTS order_cancellations;
+DROP TABLE IF EXISTS trip_cancellation_rules;
+
+UPDATE orders SET status = previous.status
+FROM (SELECT DISTINCT ON (order_id) order_id, from_status AS status
+      FROM order_status_history
+      WHERE to_status = 'cancellation_requested'
+      ORDER BY order_id, created_at DESC) previous
+WHERE orders.id = previous.order_id AND orders.status = 'cancellation_requested';
+
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+ALTER TABLE orders ADD CONSTRAINT orders_status_check
+    CHECK (status IN ('new', 'confirmed', 'paid', 'completed', 'cancelled', 'rejected', 'refunded'));
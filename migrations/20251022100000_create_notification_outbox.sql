@@ -0,0 +1,28 @@
+-- +goose Up
+-- исходящие уведомления: пишутся в той же транзакции, что и заказ/заявка,
+-- отправляет их фоновый диспетчер с повторами
+CREATE TABLE notification_outbox (
+    id SERIAL PRIMARY KEY,
+    event_type TEXT NOT NULL,
+    channel TEXT NOT NULL,
+    audience TEXT NOT NULL CHECK (audience IN ('admin', 'customer')),
+    -- пусто — получатели канала по умолчанию
+    recipients TEXT[] NOT NULL DEFAULT '{}',
+    subject TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL,
+    link_text TEXT,
+    link_url TEXT,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'dead')),
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT now(),
+    sent_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_notification_outbox_due ON notification_outbox(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_notification_outbox_status ON notification_outbox(status, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS notification_outbox;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- тексты уведомлений, которые меняют из админки; нет шаблона — встроенный текст
+CREATE TABLE notification_templates (
+    id SERIAL PRIMARY KEY,
+    event_type TEXT NOT NULL,
+    audience TEXT NOT NULL DEFAULT 'admin' CHECK (audience IN ('admin', 'customer')),
+    channel TEXT NOT NULL CHECK (channel IN ('telegram', 'email', 'sms')),
+    locale TEXT NOT NULL DEFAULT 'ru',
+    subject TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL,
+    link_text TEXT,
+    link_url TEXT,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    updated_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    UNIQUE (event_type, audience, channel, locale)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS notification_templates;
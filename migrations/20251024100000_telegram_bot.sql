@@ -0,0 +1,10 @@
+-- +goose Up
+-- Telegram-аккаунт сотрудника: кнопки бота в чате менеджеров работают от его имени
+ALTER TABLE users ADD COLUMN telegram_id BIGINT UNIQUE;
+
+-- кнопки бота (подтвердить/отклонить/взять себе) в исходящих уведомлениях
+ALTER TABLE notification_outbox ADD COLUMN actions JSONB NOT NULL DEFAULT '[]';
+
+-- +goose Down
+ALTER TABLE notification_outbox DROP COLUMN IF EXISTS actions;
+ALTER TABLE users DROP COLUMN IF EXISTS telegram_id;
@@ -0,0 +1,32 @@
+-- +goose Up
+-- чаты менеджеров в Telegram, куда расходятся уведомления по правилам
+CREATE TABLE notification_destinations (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    chat_id TEXT NOT NULL,
+    -- тихие часы "HH:MM"–"HH:MM" в часовом поясе timezone; сообщения копятся до их конца
+    quiet_from TEXT CHECK (quiet_from ~ '^([01][0-9]|2[0-3]):[0-5][0-9]$'),
+    quiet_to TEXT CHECK (quiet_to ~ '^([01][0-9]|2[0-3]):[0-5][0-9]$'),
+    timezone TEXT NOT NULL DEFAULT 'Europe/Moscow',
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    CHECK ((quiet_from IS NULL) = (quiet_to IS NULL))
+);
+
+-- правило: события, подходящие под все заданные условия, уходят в чат; пустое условие — любое значение
+CREATE TABLE notification_rules (
+    id SERIAL PRIMARY KEY,
+    destination_id INT NOT NULL REFERENCES notification_destinations(id) ON DELETE CASCADE,
+    event_type TEXT,
+    trip_type TEXT,
+    departure_city TEXT,
+    min_amount NUMERIC(12, 2),
+    max_amount NUMERIC(12, 2)
+);
+
+CREATE INDEX idx_notification_rules_destination ON notification_rules(destination_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS notification_rules;
+DROP TABLE IF EXISTS notification_destinations;
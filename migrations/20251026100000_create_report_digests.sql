@@ -0,0 +1,22 @@
+-- +goose Up
+-- просмотры туров по дням: для топа просмотров и конверсии за период
+CREATE TABLE trip_daily_views (
+    trip_id INT NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    views INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (trip_id, day)
+);
+
+CREATE INDEX idx_trip_daily_views_day ON trip_daily_views(day);
+
+-- отправленные сводки: каждая за свой период уходит один раз, даже при нескольких экземплярах API
+CREATE TABLE report_digest_runs (
+    period TEXT NOT NULL CHECK (period IN ('daily', 'weekly')),
+    period_start DATE NOT NULL,
+    sent_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (period, period_start)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS report_digest_runs;
+DROP TABLE IF EXISTS trip_daily_views;
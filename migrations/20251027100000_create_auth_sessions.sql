@@ -0,0 +1,21 @@
+-- +goose Up
+-- сессии входа: refresh-токен (хранится только sha256) и jti действующего access-токена.
+-- Отзыв сессии или удаление пользователя сразу делает его токены недействительными
+CREATE TABLE auth_sessions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    access_jti TEXT NOT NULL,
+    refresh_hash TEXT NOT NULL UNIQUE,
+    -- предыдущий refresh-токен: его повторное предъявление — признак кражи, сессия отзывается
+    previous_refresh_hash TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    last_used_at TIMESTAMP NOT NULL DEFAULT now(),
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX idx_auth_sessions_user ON auth_sessions(user_id) WHERE revoked_at IS NULL;
+CREATE INDEX idx_auth_sessions_previous_refresh ON auth_sessions(previous_refresh_hash);
+
+-- +goose Down
+DROP TABLE IF EXISTS auth_sessions;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- одноразовые токены сброса пароля; хранится только sha256 токена из письма
+CREATE TABLE password_reset_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP
+);
+
+CREATE INDEX idx_password_reset_tokens_user ON password_reset_tokens(user_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS password_reset_tokens;
@@ -0,0 +1,25 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP;
+-- аккаунты, созданные до подтверждения почты, считаются подтверждёнными:
+-- иначе при AUTH_REQUIRE_VERIFIED_EMAIL=true они не смогут войти
+UPDATE users SET email_verified_at = created_at;
+
+-- коды подтверждения email и телефона; хранится только sha256 кода.
+-- target — адрес, на который ушёл код: если его сменили, код не подтвердит новый
+CREATE TABLE verification_codes (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('email', 'phone')),
+    target TEXT NOT NULL,
+    code_hash TEXT NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP
+);
+
+CREATE INDEX idx_verification_codes_user ON verification_codes(user_id, kind, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS verification_codes;
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified_at;
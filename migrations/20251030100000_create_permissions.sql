@@ -0,0 +1,68 @@
+-- +goose Up
+-- встроенные роли (user, admin, manager) нельзя удалить; у admin всегда все права
+ALTER TABLE roles
+    ADD COLUMN description TEXT NOT NULL DEFAULT '',
+    ADD COLUMN is_system BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE roles SET is_system = true, description = 'Клиент сайта, без доступа к админке' WHERE name = 'user';
+UPDATE roles SET is_system = true, description = 'Полный доступ' WHERE name = 'admin';
+UPDATE roles SET is_system = true, description = 'Заказы, клиенты и туры без удаления' WHERE name = 'manager';
+
+INSERT INTO roles (name, description) VALUES ('editor', 'Новости и медиа');
+
+-- права; код проверяется в middleware RequirePermission
+CREATE TABLE permissions (
+    code TEXT PRIMARY KEY,
+    description TEXT NOT NULL
+);
+
+INSERT INTO permissions (code, description) VALUES
+    ('users.read', 'Просмотр пользователей'),
+    ('users.write', 'Создание, изменение и удаление пользователей'),
+    ('roles.manage', 'Управление ролями и назначение ролей пользователям'),
+    ('travellers.read', 'Паспортные данные туристов и журнал доступа к ним'),
+    ('trips.read', 'Просмотр туров, отелей и правил оплаты и отмены'),
+    ('trips.write', 'Создание и изменение туров, отелей, маршрутов и правил'),
+    ('trips.delete', 'Удаление туров'),
+    ('news.write', 'Новости и их категории'),
+    ('media.write', 'Загрузка и удаление файлов'),
+    ('orders.read', 'Просмотр заказов, платежей и документов'),
+    ('orders.write', 'Обработка заказов: статусы, заметки, назначение, отмены'),
+    ('orders.delete', 'Удаление заказов'),
+    ('payments.write', 'Приём платежей и возвраты'),
+    ('customers.read', 'Просмотр клиентов'),
+    ('customers.write', 'Объединение клиентов'),
+    ('feedback.read', 'Просмотр заявок на консультацию'),
+    ('feedback.write', 'Обработка и удаление заявок на консультацию'),
+    ('stats.read', 'Статистика и сводки'),
+    ('notifications.manage', 'Уведомления, шаблоны и чаты'),
+    ('documents.manage', 'Шаблоны документов'),
+    ('cache.purge', 'Сброс кэша Cloudflare');
+
+CREATE TABLE role_permissions (
+    role_id INT NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission TEXT NOT NULL REFERENCES permissions(code) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission)
+);
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.code FROM roles r CROSS JOIN permissions p WHERE r.name = 'admin';
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.code FROM roles r CROSS JOIN permissions p
+WHERE r.name = 'manager' AND p.code IN (
+    'travellers.read', 'trips.read', 'trips.write', 'media.write', 'orders.read', 'orders.write',
+    'payments.write', 'customers.read', 'customers.write', 'feedback.read', 'feedback.write', 'stats.read'
+);
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.code FROM roles r CROSS JOIN permissions p
+WHERE r.name = 'editor' AND p.code IN ('trips.read', 'news.write', 'media.write');
+
+-- +goose Down
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
+UPDATE users SET role_id = (SELECT id FROM roles WHERE name = 'user')
+WHERE role_id IN (SELECT id FROM roles WHERE NOT is_system);
+DELETE FROM roles WHERE NOT is_system;
+ALTER TABLE roles DROP COLUMN is_system, DROP COLUMN description;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- неудачные попытки входа по email и по IP: задержки между попытками и временная блокировка
+CREATE TABLE login_throttles (
+    kind TEXT NOT NULL CHECK (kind IN ('email', 'ip')),
+    key TEXT NOT NULL,
+    -- неудачи подряд; счёт начинается заново, если с последней прошло больше окна блокировки
+    failures INT NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP NOT NULL DEFAULT now(),
+    locked_until TIMESTAMP,
+    PRIMARY KEY (kind, key)
+);
+
+CREATE INDEX idx_login_throttles_locked ON login_throttles(locked_until) WHERE locked_until IS NOT NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS login_throttles;
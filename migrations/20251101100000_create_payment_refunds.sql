@@ -0,0 +1,19 @@
+-- +goose Up
+-- возвраты по платежам: строка создаётся до запроса к провайдеру, её id входит в ключ
+-- идемпотентности, поэтому повтор после сбоя не вернёт деньги второй раз
+CREATE TABLE payment_refunds (
+    id SERIAL PRIMARY KEY,
+    payment_id INT NOT NULL REFERENCES payments(id) ON DELETE CASCADE,
+    amount NUMERIC(12,2) NOT NULL CHECK (amount > 0),
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+    external_id TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_payment_refunds_payment_id ON payment_refunds(payment_id);
+-- незавершённый возврат по платежу один: следующий ждёт, пока он не проведён или не отклонён
+CREATE UNIQUE INDEX idx_payment_refunds_pending ON payment_refunds(payment_id) WHERE status = 'pending';
+
+-- +goose Down
+DROP TABLE IF EXISTS payment_refunds;
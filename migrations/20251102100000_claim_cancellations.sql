@@ -0,0 +1,24 @@
+-- +goose Up
+-- одобрение или отклонение сначала забирает заявку в processing: возвраты по ней
+-- выполняет только тот, кто её забрал, повторное нажатие получает отказ
+ALTER TABLE order_cancellations ADD COLUMN claimed_at TIMESTAMP;
+
+ALTER TABLE order_cancellations DROP CONSTRAINT IF EXISTS order_cancellations_status_check;
+ALTER TABLE order_cancellations ADD CONSTRAINT order_cancellations_status_check
+    CHECK (status IN ('pending', 'processing', 'approved', 'rejected'));
+
+DROP INDEX IF EXISTS idx_order_cancellations_pending;
+CREATE UNIQUE INDEX idx_order_cancellations_pending ON order_cancellations(order_id)
+    WHERE status IN ('pending', 'processing');
+
+-- +goose Down
+UPDATE order_cancellations SET status = 'pending' WHERE status = 'processing';
+
+DROP INDEX IF EXISTS idx_order_cancellations_pending;
+CREATE UNIQUE INDEX idx_order_cancellations_pending ON order_cancellations(order_id) WHERE status = 'pending';
+
+ALTER TABLE order_cancellations DROP CONSTRAINT IF EXISTS order_cancellations_status_check;
+ALTER TABLE order_cancellations ADD CONSTRAINT order_cancellations_status_check
+    CHECK (status IN ('pending', 'approved', 'rejected'));
+
+ALTER TABLE order_cancellations DROP COLUMN IF EXISTS claimed_at;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- конец тихих часов считается в часовом поясе чата; в TIMESTAMP без зоны такое время
+-- сохранялось бы по часам чата и сравнивалось с now() сервера БД со сдвигом на разницу поясов
+ALTER TABLE notification_outbox
+    ALTER COLUMN next_attempt_at TYPE TIMESTAMPTZ USING next_attempt_at;
+
+-- +goose Down
+ALTER TABLE notification_outbox
+    ALTER COLUMN next_attempt_at TYPE TIMESTAMP USING next_attempt_at;
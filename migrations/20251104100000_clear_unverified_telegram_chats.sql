@@ -0,0 +1,8 @@
+-- +goose Up
+-- chat_id приходил из публичной заявки без проверки; теперь он берётся только из подписанной
+-- initData мини-приложения, а прежние значения подтвердить нельзя — документы в них не отправляем
+UPDATE orders SET telegram_chat_id = NULL WHERE telegram_chat_id IS NOT NULL;
+
+-- +goose Down
+-- стёртые chat_id не восстанавливаются
+SELECT 1;